@@ -8,6 +8,7 @@ import (
 	"io"
 	"math/rand"
 	"net"
+	"os"
 
 	quic "github.com/lucas-clemente/quic-go"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -31,7 +32,7 @@ var _ = Describe("Benchmarks", func() {
 				rand.Read(data) // no need to check for an error. math.Rand.Read never errors
 			})
 
-			Measure("transferring a file", func(b Benchmarker) {
+			transferFile := func(b Benchmarker) {
 				var ln quic.Listener
 				serverAddr := make(chan net.Addr)
 				handshakeChan := make(chan struct{})
@@ -86,6 +87,17 @@ var _ = Describe("Benchmarks", func() {
 
 				ln.Close()
 				sess.CloseWithError(0, "")
+			}
+
+			Measure("transferring a file", func(b Benchmarker) {
+				transferFile(b)
+			}, 3)
+
+			// On Linux, packets are sent using Generic Segmentation Offload (GSO) by default.
+			Measure("transferring a file, without GSO", func(b Benchmarker) {
+				os.Setenv("QUIC_GO_DISABLE_GSO", "true")
+				defer os.Unsetenv("QUIC_GO_DISABLE_GSO")
+				transferFile(b)
 			}, 3)
 		})
 	}
@@ -1,7 +1,9 @@
 package quic
 
 import (
+	"runtime"
 	"sync"
+	"sync/atomic"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 )
@@ -13,6 +15,9 @@ type packetBuffer struct {
 	// It doesn't support concurrent use.
 	// It is > 1 when used for coalesced packet.
 	refCount int
+
+	// inUse is true while the buffer is taken from the pool.
+	inUse bool
 }
 
 // Split increases the refCount.
@@ -56,25 +61,67 @@ func (b *packetBuffer) Len() protocol.ByteCount {
 }
 
 func (b *packetBuffer) putBack() {
-	if cap(b.Data) != int(protocol.MaxPacketBufferSize) {
+	switch cap(b.Data) {
+	case int(protocol.MaxPacketBufferSize):
+		bufferPool.Put(b)
+	case int(protocol.MaxGSOBufferSize):
+		largeBufferPool.Put(b)
+	default:
 		panic("putPacketBuffer called with packet of wrong size!")
 	}
-	bufferPool.Put(b)
+	b.inUse = false
+	atomic.AddInt64(&packetBufferMemory, -int64(cap(b.Data)))
+}
+
+var bufferPool, largeBufferPool sync.Pool
+
+// packetBufferMemory is the number of bytes of all packet buffers taken from the pools that were not put back yet.
+// It is accessed atomically.
+var packetBufferMemory int64
+
+// packetBufferMemoryExhausted says if the packet buffers in use exceed protocol.MaxPacketBufferMemory.
+// Received packets are dropped in that case, such that the pools don't grow unboundedly.
+func packetBufferMemoryExhausted() bool {
+	return atomic.LoadInt64(&packetBufferMemory) > int64(protocol.MaxPacketBufferMemory)
+}
+
+func (b *packetBuffer) take() {
+	b.inUse = true
+	atomic.AddInt64(&packetBufferMemory, int64(cap(b.Data)))
 }
 
-var bufferPool sync.Pool
+// newPacketBuffer allocates a new packet buffer for the pools.
+// Not every buffer is put back into the pool (e.g. packets that are still queued when a session is closed).
+// These buffers are garbage collected, and must not count towards protocol.MaxPacketBufferMemory any more.
+func newPacketBuffer(size protocol.ByteCount) *packetBuffer {
+	b := &packetBuffer{Data: make([]byte, 0, size)}
+	runtime.SetFinalizer(b, func(b *packetBuffer) {
+		if b.inUse {
+			atomic.AddInt64(&packetBufferMemory, -int64(cap(b.Data)))
+		}
+	})
+	return b
+}
 
 func getPacketBuffer() *packetBuffer {
 	buf := bufferPool.Get().(*packetBuffer)
+	buf.take()
+	buf.refCount = 1
+	buf.Data = buf.Data[:0]
+	return buf
+}
+
+// getLargePacketBuffer gets a buffer that can hold multiple packets.
+// It is used for sending packets using GSO.
+func getLargePacketBuffer() *packetBuffer {
+	buf := largeBufferPool.Get().(*packetBuffer)
+	buf.take()
 	buf.refCount = 1
 	buf.Data = buf.Data[:0]
 	return buf
 }
 
 func init() {
-	bufferPool.New = func() interface{} {
-		return &packetBuffer{
-			Data: make([]byte, 0, protocol.MaxPacketBufferSize),
-		}
-	}
+	bufferPool.New = func() interface{} { return newPacketBuffer(protocol.MaxPacketBufferSize) }
+	largeBufferPool.New = func() interface{} { return newPacketBuffer(protocol.MaxGSOBufferSize) }
 }
@@ -1,6 +1,8 @@
 package quic
 
 import (
+	"sync/atomic"
+
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 
 	. "github.com/onsi/ginkgo"
@@ -13,11 +15,39 @@ var _ = Describe("Buffer Pool", func() {
 		Expect(buf.Data).To(HaveCap(int(protocol.MaxPacketBufferSize)))
 	})
 
+	It("returns large buffers of cap", func() {
+		buf := getLargePacketBuffer()
+		Expect(buf.Data).To(HaveCap(int(protocol.MaxGSOBufferSize)))
+	})
+
 	It("releases buffers", func() {
 		buf := getPacketBuffer()
 		buf.Release()
 	})
 
+	It("releases large buffers", func() {
+		buf := getLargePacketBuffer()
+		buf.Release()
+	})
+
+	It("counts the memory used by packet buffers", func() {
+		before := atomic.LoadInt64(&packetBufferMemory)
+		buf := getPacketBuffer()
+		largeBuf := getLargePacketBuffer()
+		Expect(atomic.LoadInt64(&packetBufferMemory)).To(Equal(before + int64(protocol.MaxPacketBufferSize+protocol.MaxGSOBufferSize)))
+		buf.Release()
+		Expect(atomic.LoadInt64(&packetBufferMemory)).To(Equal(before + int64(protocol.MaxGSOBufferSize)))
+		largeBuf.Release()
+		Expect(atomic.LoadInt64(&packetBufferMemory)).To(Equal(before))
+	})
+
+	It("says when the packet buffers use too much memory", func() {
+		Expect(packetBufferMemoryExhausted()).To(BeFalse())
+		atomic.AddInt64(&packetBufferMemory, int64(protocol.MaxPacketBufferMemory))
+		defer atomic.AddInt64(&packetBufferMemory, -int64(protocol.MaxPacketBufferMemory))
+		Expect(packetBufferMemoryExhausted()).To(BeTrue())
+	})
+
 	It("gets the length", func() {
 		buf := getPacketBuffer()
 		buf.Data = append(buf.Data, []byte("foobar")...)
@@ -8,6 +8,7 @@ import (
 	"net"
 	"strings"
 
+	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/logging"
@@ -43,7 +44,7 @@ type client struct {
 
 var (
 	// make it possible to mock connection ID generation in the tests
-	generateConnectionID           = protocol.GenerateConnectionID
+	generateConnectionID           = func(g ConnectionIDGenerator) (protocol.ConnectionID, error) { return g.GenerateConnectionID() }
 	generateConnectionIDForInitial = protocol.GenerateConnectionIDForInitial
 )
 
@@ -238,6 +239,10 @@ func newClient(
 
 		tlsConf.ServerName = sni
 	}
+	if config.SessionTicketStore != nil {
+		tlsConf = tlsConf.Clone()
+		tlsConf.ClientSessionCache = handshake.NewClientSessionCache(config.SessionTicketStore)
+	}
 
 	// check that all versions are actually supported
 	if config != nil {
@@ -248,7 +253,7 @@ func newClient(
 		}
 	}
 
-	srcConnID, err := generateConnectionID(config.ConnectionIDLength)
+	srcConnID, err := generateConnectionID(config.ConnectionIDGenerator)
 	if err != nil {
 		return nil, err
 	}
@@ -88,13 +88,13 @@ var _ = Describe("Client", func() {
 	})
 
 	Context("Dialing", func() {
-		var origGenerateConnectionID func(int) (protocol.ConnectionID, error)
+		var origGenerateConnectionID func(ConnectionIDGenerator) (protocol.ConnectionID, error)
 		var origGenerateConnectionIDForInitial func() (protocol.ConnectionID, error)
 
 		BeforeEach(func() {
 			origGenerateConnectionID = generateConnectionID
 			origGenerateConnectionIDForInitial = generateConnectionIDForInitial
-			generateConnectionID = func(int) (protocol.ConnectionID, error) {
+			generateConnectionID = func(ConnectionIDGenerator) (protocol.ConnectionID, error) {
 				return connID, nil
 			}
 			generateConnectionIDForInitial = func() (protocol.ConnectionID, error) {
@@ -176,6 +176,48 @@ var _ = Describe("Client", func() {
 			Eventually(hostnameChan).Should(Receive(Equal("foobar")))
 		})
 
+		It("uses the SessionTicketStore", func() {
+			manager := NewMockPacketHandlerManager(mockCtrl)
+			manager.EXPECT().Add(gomock.Any(), gomock.Any())
+			manager.EXPECT().Destroy()
+			mockMultiplexer.EXPECT().AddConn(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(manager, nil)
+
+			store := NewMockSessionTicketStore(mockCtrl)
+			tlsConfChan := make(chan *tls.Config, 1)
+			newClientSession = func(
+				_ sendConn,
+				_ sessionRunner,
+				_ protocol.ConnectionID,
+				_ protocol.ConnectionID,
+				_ *Config,
+				tlsConf *tls.Config,
+				_ protocol.PacketNumber,
+				_ bool,
+				_ bool,
+				_ logging.ConnectionTracer,
+				_ utils.Logger,
+				_ protocol.VersionNumber,
+			) quicSession {
+				tlsConfChan <- tlsConf
+				sess := NewMockQuicSession(mockCtrl)
+				sess.EXPECT().run()
+				sess.EXPECT().HandshakeComplete().Return(context.Background())
+				return sess
+			}
+			tlsConf.ServerName = "foobar"
+			_, err := DialAddr("localhost:17890", tlsConf, &Config{SessionTicketStore: store})
+			Expect(err).ToNot(HaveOccurred())
+			var conf *tls.Config
+			Eventually(tlsConfChan).Should(Receive(&conf))
+			Expect(conf).ToNot(BeIdenticalTo(tlsConf))
+			Expect(tlsConf.ClientSessionCache).To(BeNil())
+			Expect(conf.ServerName).To(Equal("foobar"))
+			store.EXPECT().Get("foobar").Return([]byte("invalid"), true)
+			store.EXPECT().Put("foobar", nil)
+			_, ok := conf.ClientSessionCache.Get("foobar")
+			Expect(ok).To(BeFalse())
+		})
+
 		It("allows passing host without port as server name", func() {
 			manager := NewMockPacketHandlerManager(mockCtrl)
 			manager.EXPECT().Add(gomock.Any(), gomock.Any())
@@ -29,6 +29,27 @@ func validateConfig(config *Config) error {
 	if config.MaxIncomingUniStreams > 1<<60 {
 		return errors.New("invalid value for Config.MaxIncomingUniStreams")
 	}
+	if config.ConnectionIDGenerator != nil {
+		if l := config.ConnectionIDGenerator.ConnectionIDLen(); l != 0 && (l < 4 || l > protocol.MaxConnIDLen) {
+			return errors.New("invalid value for Config.ConnectionIDGenerator: invalid connection ID length")
+		}
+	}
+	if config.PreferredAddress != nil {
+		return validatePreferredAddress(config.PreferredAddress)
+	}
+	return nil
+}
+
+func validatePreferredAddress(addr *PreferredAddress) error {
+	if addr.IPv4 == nil && addr.IPv6 == nil {
+		return errors.New("invalid value for Config.PreferredAddress: no address set")
+	}
+	if addr.IPv4 != nil && (addr.IPv4.IP.To4() == nil || addr.IPv4.IP.IsUnspecified()) {
+		return errors.New("invalid value for Config.PreferredAddress: invalid IPv4 address")
+	}
+	if addr.IPv6 != nil && (addr.IPv6.IP.To16() == nil || addr.IPv6.IP.To4() != nil || addr.IPv6.IP.IsUnspecified()) {
+		return errors.New("invalid value for Config.PreferredAddress: invalid IPv6 address")
+	}
 	return nil
 }
 
@@ -36,12 +57,16 @@ func validateConfig(config *Config) error {
 // it may be called with nil
 func populateServerConfig(config *Config) *Config {
 	config = populateConfig(config)
-	if config.ConnectionIDLength == 0 {
+	if config.ConnectionIDLength == 0 && config.ConnectionIDGenerator == nil {
 		config.ConnectionIDLength = protocol.DefaultConnectionIDLength
 	}
+	populateConnectionIDGenerator(config)
 	if config.AcceptToken == nil {
 		config.AcceptToken = defaultAcceptToken
 	}
+	if config.ZeroRTTReplayProtector == nil {
+		config.ZeroRTTReplayProtector = NewZeroRTTReplayCache(0)
+	}
 	return config
 }
 
@@ -49,12 +74,22 @@ func populateServerConfig(config *Config) *Config {
 // it may be called with nil
 func populateClientConfig(config *Config, createdPacketConn bool) *Config {
 	config = populateConfig(config)
-	if config.ConnectionIDLength == 0 && !createdPacketConn {
+	if config.ConnectionIDLength == 0 && config.ConnectionIDGenerator == nil && !createdPacketConn {
 		config.ConnectionIDLength = protocol.DefaultConnectionIDLength
 	}
+	populateConnectionIDGenerator(config)
 	return config
 }
 
+// populateConnectionIDGenerator makes sure that the ConnectionIDGenerator and the ConnectionIDLength are consistent.
+func populateConnectionIDGenerator(config *Config) {
+	if config.ConnectionIDGenerator == nil {
+		config.ConnectionIDGenerator = &protocol.DefaultConnectionIDGenerator{ConnLen: config.ConnectionIDLength}
+		return
+	}
+	config.ConnectionIDLength = config.ConnectionIDGenerator.ConnectionIDLen()
+}
+
 func populateConfig(config *Config) *Config {
 	if config == nil {
 		config = &Config{}
@@ -99,24 +134,50 @@ func populateConfig(config *Config) *Config {
 	} else if maxIncomingUniStreams < 0 {
 		maxIncomingUniStreams = 0
 	}
+	datagramSendQueueLen := config.DatagramSendQueueLen
+	if datagramSendQueueLen <= 0 {
+		datagramSendQueueLen = protocol.DefaultDatagramSendQueueLen
+	}
+	keyUpdateInterval := config.KeyUpdateInterval
+	if keyUpdateInterval == 0 {
+		keyUpdateInterval = protocol.KeyUpdateInterval
+	}
 
 	return &Config{
 		Versions:                       versions,
 		HandshakeIdleTimeout:           handshakeIdleTimeout,
 		MaxIdleTimeout:                 idleTimeout,
 		AcceptToken:                    config.AcceptToken,
+		TokenKeys:                      config.TokenKeys,
+		HandshakeLimits:                config.HandshakeLimits,
 		KeepAlive:                      config.KeepAlive,
 		InitialStreamReceiveWindow:     initialStreamReceiveWindow,
 		MaxStreamReceiveWindow:         maxStreamReceiveWindow,
 		InitialConnectionReceiveWindow: initialConnectionReceiveWindow,
 		MaxConnectionReceiveWindow:     maxConnectionReceiveWindow,
+		AllowConnectionWindowIncrease:  config.AllowConnectionWindowIncrease,
+		MemoryBudget:                   config.MemoryBudget,
 		MaxIncomingStreams:             maxIncomingStreams,
 		MaxIncomingUniStreams:          maxIncomingUniStreams,
 		ConnectionIDLength:             config.ConnectionIDLength,
+		ConnectionIDGenerator:          config.ConnectionIDGenerator,
 		StatelessResetKey:              config.StatelessResetKey,
 		TokenStore:                     config.TokenStore,
+		SessionTicketStore:             config.SessionTicketStore,
+		GetAppDataForSessionTicket:     config.GetAppDataForSessionTicket,
+		Accept0RTTWithAppData:          config.Accept0RTTWithAppData,
+		ZeroRTTReplayProtector:         config.ZeroRTTReplayProtector,
 		EnableDatagrams:                config.EnableDatagrams,
+		DatagramSendQueueLen:           datagramSendQueueLen,
+		DatagramDropPolicy:             config.DatagramDropPolicy,
+		EnableAckFrequency:             config.EnableAckFrequency,
+		EnableResetStreamAt:            config.EnableResetStreamAt,
+		KeyUpdateInterval:              keyUpdateInterval,
+		KeyUpdateTimeInterval:          config.KeyUpdateTimeInterval,
 		DisablePathMTUDiscovery:        config.DisablePathMTUDiscovery,
+		DisableActiveMigration:         config.DisableActiveMigration,
+		PreferredAddress:               config.PreferredAddress,
+		CongestionControl:              config.CongestionControl,
 		Tracer:                         config.Tracer,
 	}
 }
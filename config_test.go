@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 
@@ -30,6 +31,27 @@ var _ = Describe("Config", func() {
 		It("errors on too large values for MaxIncomingUniStreams", func() {
 			Expect(validateConfig(&Config{MaxIncomingUniStreams: 1<<60 + 1})).To(MatchError("invalid value for Config.MaxIncomingUniStreams"))
 		})
+
+		It("validates the length of connection IDs generated by the ConnectionIDGenerator", func() {
+			Expect(validateConfig(&Config{ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 0}})).To(Succeed())
+			Expect(validateConfig(&Config{ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 4}})).To(Succeed())
+			Expect(validateConfig(&Config{ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 20}})).To(Succeed())
+			Expect(validateConfig(&Config{ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 3}})).To(MatchError("invalid value for Config.ConnectionIDGenerator: invalid connection ID length"))
+			Expect(validateConfig(&Config{ConnectionIDGenerator: &protocol.DefaultConnectionIDGenerator{ConnLen: 21}})).To(MatchError("invalid value for Config.ConnectionIDGenerator: invalid connection ID length"))
+		})
+
+		It("validates the preferred address", func() {
+			ipv4 := &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+			ipv6 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 1234}
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: ipv4}})).To(Succeed())
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv6: ipv6}})).To(Succeed())
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: ipv4, IPv6: ipv6}})).To(Succeed())
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{}})).To(MatchError("invalid value for Config.PreferredAddress: no address set"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: ipv6}})).To(MatchError("invalid value for Config.PreferredAddress: invalid IPv4 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv4: &net.UDPAddr{IP: net.IPv4zero}}})).To(MatchError("invalid value for Config.PreferredAddress: invalid IPv4 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv6: ipv4}})).To(MatchError("invalid value for Config.PreferredAddress: invalid IPv6 address"))
+			Expect(validateConfig(&Config{PreferredAddress: &PreferredAddress{IPv6: &net.UDPAddr{IP: net.IPv6unspecified}}})).To(MatchError("invalid value for Config.PreferredAddress: invalid IPv6 address"))
+		})
 	})
 
 	configWithNonZeroNonFunctionFields := func() *Config {
@@ -45,18 +67,25 @@ var _ = Describe("Config", func() {
 			}
 
 			switch fn := typ.Field(i).Name; fn {
-			case "AcceptToken", "GetLogWriter":
+			case "AcceptToken", "GetLogWriter", "CongestionControl", "AllowConnectionWindowIncrease",
+				"GetAppDataForSessionTicket", "Accept0RTTWithAppData":
 				// Can't compare functions.
 			case "Versions":
 				f.Set(reflect.ValueOf([]VersionNumber{1, 2, 3}))
 			case "ConnectionIDLength":
 				f.Set(reflect.ValueOf(8))
+			case "ConnectionIDGenerator":
+				f.Set(reflect.ValueOf(&protocol.DefaultConnectionIDGenerator{ConnLen: 8}))
 			case "HandshakeIdleTimeout":
 				f.Set(reflect.ValueOf(time.Second))
 			case "MaxIdleTimeout":
 				f.Set(reflect.ValueOf(time.Hour))
 			case "TokenStore":
 				f.Set(reflect.ValueOf(NewLRUTokenStore(2, 3)))
+			case "SessionTicketStore":
+				f.Set(reflect.ValueOf(NewMockSessionTicketStore(mockCtrl)))
+			case "ZeroRTTReplayProtector":
+				f.Set(reflect.ValueOf(NewZeroRTTReplayCache(time.Hour)))
 			case "InitialStreamReceiveWindow":
 				f.Set(reflect.ValueOf(uint64(1234)))
 			case "MaxStreamReceiveWindow":
@@ -71,12 +100,34 @@ var _ = Describe("Config", func() {
 				f.Set(reflect.ValueOf(int64(12)))
 			case "StatelessResetKey":
 				f.Set(reflect.ValueOf([]byte{1, 2, 3, 4}))
+			case "TokenKeys":
+				f.Set(reflect.ValueOf([]TokenKey{{1, 2, 3, 4}}))
+			case "HandshakeLimits":
+				f.Set(reflect.ValueOf(&HandshakeLimits{MaxUnvalidatedHandshakes: 42}))
+			case "MemoryBudget":
+				f.Set(reflect.ValueOf(NewMemoryBudget(1000, 100)))
 			case "KeepAlive":
 				f.Set(reflect.ValueOf(true))
 			case "EnableDatagrams":
 				f.Set(reflect.ValueOf(true))
+			case "DatagramSendQueueLen":
+				f.Set(reflect.ValueOf(14))
+			case "DatagramDropPolicy":
+				f.Set(reflect.ValueOf(DatagramDropNewest))
+			case "EnableAckFrequency":
+				f.Set(reflect.ValueOf(true))
+			case "EnableResetStreamAt":
+				f.Set(reflect.ValueOf(true))
+			case "KeyUpdateInterval":
+				f.Set(reflect.ValueOf(uint64(13)))
+			case "KeyUpdateTimeInterval":
+				f.Set(reflect.ValueOf(time.Minute))
 			case "DisablePathMTUDiscovery":
 				f.Set(reflect.ValueOf(true))
+			case "DisableActiveMigration":
+				f.Set(reflect.ValueOf(true))
+			case "PreferredAddress":
+				f.Set(reflect.ValueOf(&PreferredAddress{IPv4: &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}}))
 			case "Tracer":
 				f.Set(reflect.ValueOf(mocklogging.NewMockTracer(mockCtrl)))
 			default:
@@ -137,6 +188,19 @@ var _ = Describe("Config", func() {
 			Expect(calledAcceptToken).To(BeTrue())
 		})
 
+		It("populates the congestion control factory", func() {
+			var calledCongestionControl bool
+			c1 := &Config{
+				CongestionControl: func(info *congestion.ConnectionInfo) congestion.SendAlgorithmWithDebugInfos {
+					calledCongestionControl = true
+					return congestion.NewCubic(info)
+				},
+			}
+			c2 := populateConfig(c1)
+			Expect(c2.CongestionControl(&congestion.ConnectionInfo{RTTStats: &congestion.RTTStats{}})).ToNot(BeNil())
+			Expect(calledCongestionControl).To(BeTrue())
+		})
+
 		It("copies non-function fields", func() {
 			c := configWithNonZeroNonFunctionFields()
 			Expect(populateConfig(c)).To(Equal(c))
@@ -145,6 +209,8 @@ var _ = Describe("Config", func() {
 		It("populates empty fields with default values", func() {
 			c := populateConfig(&Config{})
 			Expect(c.Versions).To(Equal(protocol.SupportedVersions))
+			Expect(c.KeyUpdateInterval).To(BeEquivalentTo(protocol.KeyUpdateInterval))
+			Expect(c.DatagramSendQueueLen).To(Equal(protocol.DefaultDatagramSendQueueLen))
 			Expect(c.HandshakeIdleTimeout).To(Equal(protocol.DefaultHandshakeIdleTimeout))
 			Expect(c.InitialStreamReceiveWindow).To(BeEquivalentTo(protocol.DefaultInitialMaxStreamData))
 			Expect(c.MaxStreamReceiveWindow).To(BeEquivalentTo(protocol.DefaultMaxReceiveStreamFlowControlWindow))
@@ -153,22 +219,36 @@ var _ = Describe("Config", func() {
 			Expect(c.MaxIncomingStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingStreams))
 			Expect(c.MaxIncomingUniStreams).To(BeEquivalentTo(protocol.DefaultMaxIncomingUniStreams))
 			Expect(c.DisablePathMTUDiscovery).To(BeFalse())
+			Expect(c.DisableActiveMigration).To(BeFalse())
 		})
 
 		It("populates empty fields with default values, for the server", func() {
 			c := populateServerConfig(&Config{})
 			Expect(c.ConnectionIDLength).To(Equal(protocol.DefaultConnectionIDLength))
+			Expect(c.ConnectionIDGenerator).To(Equal(&protocol.DefaultConnectionIDGenerator{ConnLen: protocol.DefaultConnectionIDLength}))
 			Expect(c.AcceptToken).ToNot(BeNil())
+			Expect(c.ZeroRTTReplayProtector).To(BeAssignableToTypeOf(&ZeroRTTReplayCache{}))
 		})
 
 		It("sets a default connection ID length if we didn't create the conn, for the client", func() {
 			c := populateClientConfig(&Config{}, false)
 			Expect(c.ConnectionIDLength).To(Equal(protocol.DefaultConnectionIDLength))
+			Expect(c.ConnectionIDGenerator.ConnectionIDLen()).To(Equal(protocol.DefaultConnectionIDLength))
 		})
 
 		It("doesn't set a default connection ID length if we created the conn, for the client", func() {
 			c := populateClientConfig(&Config{}, true)
 			Expect(c.ConnectionIDLength).To(BeZero())
+			Expect(c.ConnectionIDGenerator.ConnectionIDLen()).To(BeZero())
+		})
+
+		It("uses the connection ID length of the ConnectionIDGenerator", func() {
+			gen := &protocol.DefaultConnectionIDGenerator{ConnLen: 7}
+			c := populateServerConfig(&Config{ConnectionIDLength: 5, ConnectionIDGenerator: gen})
+			Expect(c.ConnectionIDLength).To(Equal(7))
+			Expect(c.ConnectionIDGenerator).To(Equal(gen))
+			c = populateClientConfig(&Config{ConnectionIDGenerator: gen}, true)
+			Expect(c.ConnectionIDLength).To(Equal(7))
 		})
 	})
 })
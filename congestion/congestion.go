@@ -0,0 +1,46 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+)
+
+// BandwidthFromDelta calculates the bandwidth from a number of bytes and a time delta
+func BandwidthFromDelta(bytes ByteCount, delta time.Duration) Bandwidth {
+	return congestion.BandwidthFromDelta(bytes, delta)
+}
+
+// NewPacer creates a new token bucket pacer.
+// The pacing rate is derived from the bandwidth estimate returned by getBandwidth.
+func NewPacer(getBandwidth func() Bandwidth) Pacer {
+	return congestion.NewPacer(getBandwidth)
+}
+
+// NewPacer creates a new token bucket pacer for the connection.
+// It is initialized with the InitialMaxDatagramSize of the connection.
+// The congestion controller needs to update the pacer when its SetMaxDatagramSize method is called.
+func (i *ConnectionInfo) NewPacer(getBandwidth func() Bandwidth) Pacer {
+	p := congestion.NewPacer(getBandwidth)
+	if i.InitialMaxDatagramSize > 0 {
+		p.SetMaxDatagramSize(i.InitialMaxDatagramSize)
+	}
+	return p
+}
+
+// NewCubic creates a new Cubic congestion controller.
+func NewCubic(info *ConnectionInfo) SendAlgorithmWithDebugInfos {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, info.RTTStats, info.InitialMaxDatagramSize, false, info.Tracer)
+}
+
+// NewReno creates a new NewReno congestion controller.
+// This is the congestion controller that quic-go uses by default.
+func NewReno(info *ConnectionInfo) SendAlgorithmWithDebugInfos {
+	return congestion.NewCubicSender(congestion.DefaultClock{}, info.RTTStats, info.InitialMaxDatagramSize, true, info.Tracer)
+}
+
+// NewBBR creates a new BBR (version 2) congestion controller.
+// State transitions are reported to the connection's tracer.
+func NewBBR(info *ConnectionInfo) SendAlgorithmWithDebugInfos {
+	return congestion.NewBBRSender(congestion.DefaultClock{}, info.RTTStats, info.InitialMaxDatagramSize, info.Tracer)
+}
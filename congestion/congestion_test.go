@@ -0,0 +1,75 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Congestion Control", func() {
+	var info *ConnectionInfo
+
+	BeforeEach(func() {
+		info = &ConnectionInfo{
+			Perspective:            protocol.PerspectiveClient,
+			RTTStats:               &RTTStats{},
+			InitialMaxDatagramSize: 1200,
+		}
+	})
+
+	It("creates a NewReno sender", func() {
+		cc := NewReno(info)
+		Expect(cc.InSlowStart()).To(BeTrue())
+		Expect(cc.GetCongestionWindow()).To(BeNumerically(">", 1200))
+	})
+
+	It("creates a Cubic sender", func() {
+		cc := NewCubic(info)
+		Expect(cc.InSlowStart()).To(BeTrue())
+		Expect(cc.GetCongestionWindow()).To(BeNumerically(">", 1200))
+	})
+
+	It("creates a BBR sender", func() {
+		cc := NewBBR(info)
+		Expect(cc.InSlowStart()).To(BeTrue())
+		Expect(cc.InRecovery()).To(BeFalse())
+		Expect(cc.GetCongestionWindow()).To(BeNumerically(">", 1200))
+		// BBR only uses application data packets for bandwidth sampling
+		_, ok := cc.(SendAlgorithmWithEncryptionLevel)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("uses the RTT stats", func() {
+		cc := NewReno(info)
+		info.RTTStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+		cwnd := cc.GetCongestionWindow()
+		// The congestion window is sent in one RTT, so the pacer allows sending all packets within 100ms.
+		for i := 0; i < int(cwnd/1200); i++ {
+			cc.OnPacketSent(time.Now(), 0, protocol.PacketNumber(i), 1200, true)
+		}
+		Expect(cc.TimeUntilSend(0)).To(BeTemporally("<", time.Now().Add(100*time.Millisecond)))
+	})
+
+	It("creates a pacer", func() {
+		p := NewPacer(func() Bandwidth { return BandwidthFromDelta(1200*100, time.Second) })
+		Expect(p.TimeUntilSend()).To(BeZero())
+		now := time.Now()
+		budget := p.Budget(now)
+		Expect(budget).To(BeNumerically(">=", 1200))
+		p.SentPacket(now, budget)
+		Expect(p.TimeUntilSend()).To(BeTemporally(">", now))
+	})
+
+	It("creates a pacer for the connection", func() {
+		info.InitialMaxDatagramSize = 1400
+		p := info.NewPacer(func() Bandwidth { return BandwidthFromDelta(1400*100, time.Second) })
+		now := time.Now()
+		// the pacer allows sending a burst of packets of the initial max datagram size
+		Expect(p.Budget(now)).To(BeNumerically(">=", 1400))
+		p.SentPacket(now, p.Budget(now)-1399)
+		Expect(p.TimeUntilSend()).To(BeTemporally(">", now))
+	})
+})
@@ -0,0 +1,74 @@
+// Package congestion defines the congestion control interface for quic-go.
+// It allows applications to use their own congestion control algorithm.
+// This package should not be considered stable
+package congestion
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/congestion"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+type (
+	// A ByteCount is used to count bytes.
+	ByteCount = protocol.ByteCount
+	// The PacketNumber is the packet number of a packet.
+	PacketNumber = protocol.PacketNumber
+	// The Perspective is the role of a QUIC endpoint (client or server).
+	Perspective = protocol.Perspective
+	// The EncryptionLevel is the encryption level of a packet.
+	EncryptionLevel = protocol.EncryptionLevel
+	// Bandwidth is the bandwidth of a connection, in bits per second.
+	Bandwidth = congestion.Bandwidth
+	// The RTTStats contain the RTT measurements of a connection.
+	RTTStats = utils.RTTStats
+	// A Clock returns the current time.
+	Clock = congestion.Clock
+
+	// A SendAlgorithm performs congestion control.
+	SendAlgorithm = congestion.SendAlgorithm
+	// A SendAlgorithmWithDebugInfos is a SendAlgorithm that exposes some debug infos.
+	// This is the interface that needs to be implemented by a congestion controller used by quic-go.
+	SendAlgorithmWithDebugInfos = congestion.SendAlgorithmWithDebugInfos
+	// A SendAlgorithmWithEncryptionLevel is a SendAlgorithm that needs to know the encryption level of packets,
+	// since packet numbers are only unique within a packet number space.
+	// If a congestion controller implements it, its methods are called instead of OnPacketSent, OnPacketAcked and OnPacketLost.
+	SendAlgorithmWithEncryptionLevel = congestion.SendAlgorithmWithEncryptionLevel
+	// A Pacer paces the sending of packets.
+	Pacer = congestion.Pacer
+)
+
+const (
+	// BitsPerSecond is 1 bit per second
+	BitsPerSecond = congestion.BitsPerSecond
+	// BytesPerSecond is 1 byte per second
+	BytesPerSecond = congestion.BytesPerSecond
+)
+
+const (
+	// EncryptionInitial is the Initial encryption level
+	EncryptionInitial EncryptionLevel = protocol.EncryptionInitial
+	// EncryptionHandshake is the Handshake encryption level
+	EncryptionHandshake EncryptionLevel = protocol.EncryptionHandshake
+	// Encryption0RTT is the 0-RTT encryption level
+	Encryption0RTT EncryptionLevel = protocol.Encryption0RTT
+	// Encryption1RTT is the 1-RTT encryption level
+	Encryption1RTT EncryptionLevel = protocol.Encryption1RTT
+)
+
+// ConnectionInfo contains information about the connection a congestion controller is created for.
+// quic-go doesn't pace packets itself, it relies on the congestion controller's TimeUntilSend and HasPacingBudget.
+// Congestion controllers can use NewPacer to create a pacer for the connection.
+type ConnectionInfo struct {
+	Perspective Perspective
+	// RTTStats are the RTT measurements of the connection.
+	// They are updated by quic-go whenever a new RTT sample is taken.
+	RTTStats *RTTStats
+	// InitialMaxDatagramSize is the maximum datagram size used before Path MTU Discovery increases it.
+	// Later increases are signaled by calling SetMaxDatagramSize.
+	InitialMaxDatagramSize ByteCount
+	// Tracer is the tracer of the connection.
+	// It is nil if tracing is disabled for this connection.
+	Tracer logging.ConnectionTracer
+}
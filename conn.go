@@ -13,6 +13,12 @@ import (
 type connection interface {
 	ReadPacket() (*receivedPacket, error)
 	WritePacket(b []byte, addr net.Addr, oob []byte) (int, error)
+	// WritePackets sends multiple packets to the same address.
+	// All packets are segmentSize bytes large, only the last one may be shorter.
+	// If GSO is supported, the packets are sent using a single syscall.
+	WritePackets(b []byte, segmentSize int, addr net.Addr, oob []byte) error
+	// SupportsGSO says if the connection supports Generic Segmentation Offload.
+	SupportsGSO() bool
 	LocalAddr() net.Addr
 	io.Closer
 }
@@ -63,3 +69,21 @@ func (c *basicConn) ReadPacket() (*receivedPacket, error) {
 func (c *basicConn) WritePacket(b []byte, addr net.Addr, _ []byte) (n int, err error) {
 	return c.PacketConn.WriteTo(b, addr)
 }
+
+func (c *basicConn) WritePackets(b []byte, segmentSize int, addr net.Addr, oob []byte) error {
+	return writePacketsIndividually(c, b, segmentSize, addr, oob)
+}
+
+func (c *basicConn) SupportsGSO() bool { return false }
+
+// writePacketsIndividually sends out the packets contained in b one by one.
+func writePacketsIndividually(c connection, b []byte, segmentSize int, addr net.Addr, oob []byte) error {
+	for len(b) > 0 {
+		l := utils.Min(len(b), segmentSize)
+		if _, err := c.WritePacket(b[:l], addr, oob); err != nil {
+			return err
+		}
+		b = b[l:]
+	}
+	return nil
+}
@@ -2,7 +2,14 @@
 
 package quic
 
-import "golang.org/x/sys/unix"
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// The read batch size is 1, since recvmmsg is only supported on Linux.
+const readBatchSize = 1
 
 const msgTypeIPTOS = unix.IP_RECVTOS
 
@@ -15,3 +22,13 @@ const (
 	msgTypeIPv4PKTINFO = 0x7
 	msgTypeIPv6PKTINFO = 0x2e
 )
+
+func isGSOSupported(syscall.RawConn) bool { return false }
+
+func appendUDPSegmentSizeMsg(b []byte, _ uint16) []byte { return b }
+
+func isGSOError(error) bool { return false }
+
+func enableGRO(syscall.RawConn) bool { return false }
+
+func parseUDPGROMsg(unix.SocketControlMessage) (int, bool) { return 0, false }
@@ -2,7 +2,13 @@
 
 package quic
 
-import "golang.org/x/sys/unix"
+import (
+	"errors"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
 
 const msgTypeIPTOS = unix.IP_TOS
 
@@ -15,3 +21,66 @@ const (
 	msgTypeIPv4PKTINFO = unix.IP_PKTINFO
 	msgTypeIPv6PKTINFO = unix.IPV6_PKTINFO
 )
+
+// On Linux, recvmmsg is used to read multiple packets using a single syscall.
+const readBatchSize = 8
+
+// UDP_SEGMENT and UDP_GRO are not defined by the version of golang.org/x/sys we're using.
+// See include/uapi/linux/udp.h.
+const (
+	udpSegment = 103
+	udpGRO     = 104
+)
+
+// isGSOSupported checks if the kernel supports UDP_SEGMENT (added in Linux 4.18).
+func isGSOSupported(conn syscall.RawConn) bool {
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		_, serr = unix.GetsockoptInt(int(fd), unix.IPPROTO_UDP, udpSegment)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// appendUDPSegmentSizeMsg appends a control message that instructs the kernel
+// to split the datagram into segments of the given size.
+func appendUDPSegmentSizeMsg(b []byte, size uint16) []byte {
+	startLen := len(b)
+	const dataLen = 2 // payload is a uint16
+	b = append(b, make([]byte, unix.CmsgSpace(dataLen))...)
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&b[startLen]))
+	h.Level = syscall.IPPROTO_UDP
+	h.Type = udpSegment
+	h.SetLen(unix.CmsgLen(dataLen))
+	offset := startLen + unix.CmsgSpace(0)
+	*(*uint16)(unsafe.Pointer(&b[offset])) = size
+	return b
+}
+
+// isGSOError checks if the kernel rejected sending a datagram using GSO.
+// This happens if the network interface doesn't support checksum offloading (EIO),
+// or if the segmentation request is invalid for this socket (EINVAL).
+func isGSOError(err error) bool {
+	return errors.Is(err, unix.EIO) || errors.Is(err, unix.EINVAL)
+}
+
+// enableGRO enables UDP Generic Receive Offload (added in Linux 5.0).
+// The kernel then coalesces multiple datagrams of the same size into a single datagram.
+func enableGRO(conn syscall.RawConn) bool {
+	var serr error
+	if err := conn.Control(func(fd uintptr) {
+		serr = unix.SetsockoptInt(int(fd), unix.IPPROTO_UDP, udpGRO, 1)
+	}); err != nil {
+		return false
+	}
+	return serr == nil
+}
+
+// parseUDPGROMsg parses the control message containing the segment size of a coalesced datagram.
+func parseUDPGROMsg(msg unix.SocketControlMessage) (int, bool) {
+	if msg.Header.Level != unix.IPPROTO_UDP || msg.Header.Type != udpGRO || len(msg.Data) < 4 {
+		return 0, false
+	}
+	return int(*(*int32)(unsafe.Pointer(&msg.Data[0]))), true
+}
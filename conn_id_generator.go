@@ -10,11 +10,14 @@ import (
 )
 
 type connIDGenerator struct {
-	connIDLen  int
+	generator  ConnectionIDGenerator
 	highestSeq uint64
 
 	activeSrcConnIDs        map[uint64]protocol.ConnectionID
 	initialClientDestConnID protocol.ConnectionID
+	// The connection ID sent in the preferred_address transport parameter.
+	// It is registered when the handshake completes.
+	preferredAddrConnID protocol.ConnectionID
 
 	addConnectionID        func(protocol.ConnectionID)
 	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken
@@ -29,6 +32,7 @@ type connIDGenerator struct {
 func newConnIDGenerator(
 	initialConnectionID protocol.ConnectionID,
 	initialClientDestConnID protocol.ConnectionID, // nil for the client
+	generator ConnectionIDGenerator,
 	addConnectionID func(protocol.ConnectionID),
 	getStatelessResetToken func(protocol.ConnectionID) protocol.StatelessResetToken,
 	removeConnectionID func(protocol.ConnectionID),
@@ -38,7 +42,7 @@ func newConnIDGenerator(
 	version protocol.VersionNumber,
 ) *connIDGenerator {
 	m := &connIDGenerator{
-		connIDLen:              initialConnectionID.Len(),
+		generator:              generator,
 		activeSrcConnIDs:       make(map[uint64]protocol.ConnectionID),
 		addConnectionID:        addConnectionID,
 		getStatelessResetToken: getStatelessResetToken,
@@ -54,15 +58,13 @@ func newConnIDGenerator(
 }
 
 func (m *connIDGenerator) SetMaxActiveConnIDs(limit uint64) error {
-	if m.connIDLen == 0 {
+	if m.generator.ConnectionIDLen() == 0 {
 		return nil
 	}
 	// The active_connection_id_limit transport parameter is the number of
 	// connection IDs the peer will store. This limit includes the connection ID
 	// used during the handshake, and the one sent in the preferred_address
 	// transport parameter.
-	// We currently don't send the preferred_address transport parameter,
-	// so we can issue (limit - 1) connection IDs.
 	for i := uint64(len(m.activeSrcConnIDs)); i < utils.MinUint64(limit, protocol.MaxIssuedConnectionIDs); i++ {
 		if err := m.issueNewConnID(); err != nil {
 			return err
@@ -96,7 +98,7 @@ func (m *connIDGenerator) issueNewConnID() error {
 	if protocol.UseRetireBugBackwardsCompatibilityMode(RetireBugBackwardsCompatibilityMode, m.version) {
 		return nil
 	}
-	connID, err := protocol.GenerateConnectionID(m.connIDLen)
+	connID, err := m.generator.GenerateConnectionID()
 	if err != nil {
 		return err
 	}
@@ -111,11 +113,42 @@ func (m *connIDGenerator) issueNewConnID() error {
 	return nil
 }
 
+// GeneratePreferredAddressConnectionID generates the connection ID sent in the preferred_address transport parameter.
+// It has sequence number 1, see section 5.1.1 of RFC 9000.
+// It must be called before any other connection ID is issued.
+func (m *connIDGenerator) GeneratePreferredAddressConnectionID() (protocol.ConnectionID, protocol.StatelessResetToken, error) {
+	if m.highestSeq != 0 {
+		panic("expected no connection IDs to have been issued yet")
+	}
+	connID, err := m.generator.GenerateConnectionID()
+	if err != nil {
+		return nil, protocol.StatelessResetToken{}, err
+	}
+	m.highestSeq = 1
+	m.activeSrcConnIDs[1] = connID
+	m.preferredAddrConnID = connID
+	return connID, m.getStatelessResetToken(connID), nil
+}
+
 func (m *connIDGenerator) SetHandshakeComplete() {
 	if m.initialClientDestConnID != nil {
 		m.retireConnectionID(m.initialClientDestConnID)
 		m.initialClientDestConnID = nil
 	}
+	// The client only uses the preferred address after the handshake is confirmed.
+	if m.preferredAddrConnID != nil {
+		m.addConnectionID(m.preferredAddrConnID)
+		m.preferredAddrConnID = nil
+	}
+}
+
+// ConnectionIDs returns all connection IDs that are currently active.
+func (m *connIDGenerator) ConnectionIDs() []protocol.ConnectionID {
+	connIDs := make([]protocol.ConnectionID, 0, len(m.activeSrcConnIDs))
+	for _, connID := range m.activeSrcConnIDs {
+		connIDs = append(connIDs, connID)
+	}
+	return connIDs
 }
 
 func (m *connIDGenerator) RemoveAll() {
@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -10,6 +11,19 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+type mockConnIDGenerator struct {
+	connIDLen int
+	generate  func() (protocol.ConnectionID, error)
+}
+
+func (g *mockConnIDGenerator) GenerateConnectionID() (protocol.ConnectionID, error) {
+	return g.generate()
+}
+
+func (g *mockConnIDGenerator) ConnectionIDLen() int {
+	return g.connIDLen
+}
+
 var _ = Describe("Connection ID Generator", func() {
 	var (
 		addedConnIDs       []protocol.ConnectionID
@@ -35,6 +49,7 @@ var _ = Describe("Connection ID Generator", func() {
 		g = newConnIDGenerator(
 			initialConnID,
 			initialClientDestConnID,
+			&protocol.DefaultConnectionIDGenerator{ConnLen: initialConnID.Len()},
 			func(c protocol.ConnectionID) { addedConnIDs = append(addedConnIDs, c) },
 			connIDToToken,
 			func(c protocol.ConnectionID) { removedConnIDs = append(removedConnIDs, c) },
@@ -63,6 +78,34 @@ var _ = Describe("Connection ID Generator", func() {
 		}
 	})
 
+	It("uses the connection ID generator", func() {
+		var counter byte
+		g.generator = &mockConnIDGenerator{
+			connIDLen: 5,
+			generate: func() (protocol.ConnectionID, error) {
+				counter++
+				return protocol.ConnectionID{counter, 0, 0, 0, counter}, nil
+			},
+		}
+		Expect(g.SetMaxActiveConnIDs(3)).To(Succeed())
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{
+			{1, 0, 0, 0, 1},
+			{2, 0, 0, 0, 2},
+		}))
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).ConnectionID).To(Equal(protocol.ConnectionID{1, 0, 0, 0, 1}))
+	})
+
+	It("returns the error when the connection ID generator fails", func() {
+		testErr := errors.New("generator failed")
+		g.generator = &mockConnIDGenerator{
+			connIDLen: 5,
+			generate:  func() (protocol.ConnectionID, error) { return nil, testErr },
+		}
+		Expect(g.SetMaxActiveConnIDs(3)).To(MatchError(testErr))
+		Expect(addedConnIDs).To(BeEmpty())
+	})
+
 	It("doesn't issue new connection IDs in RetireBugBackwardsCompatibilityMode", func() {
 		RetireBugBackwardsCompatibilityMode = true
 		defer func() { RetireBugBackwardsCompatibilityMode = false }()
@@ -172,6 +215,24 @@ var _ = Describe("Connection ID Generator", func() {
 		Expect(retiredConnIDs[0]).To(Equal(initialClientDestConnID))
 	})
 
+	It("generates the connection ID for the preferred_address", func() {
+		connID, token, err := g.GeneratePreferredAddressConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(connID.Len()).To(Equal(initialConnID.Len()))
+		Expect(token).To(Equal(connIDToToken(connID)))
+		// the connection ID is only used after the handshake completes
+		Expect(addedConnIDs).To(BeEmpty())
+		Expect(g.SetMaxActiveConnIDs(4)).To(Succeed())
+		// the preferred_address connection ID counts towards the limit
+		Expect(queuedFrames).To(HaveLen(2))
+		Expect(queuedFrames[0].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(2))
+		Expect(queuedFrames[1].(*wire.NewConnectionIDFrame).SequenceNumber).To(BeEquivalentTo(3))
+		addedConnIDs = nil
+		g.SetHandshakeComplete()
+		Expect(addedConnIDs).To(Equal([]protocol.ConnectionID{connID}))
+		Expect(g.ConnectionIDs()).To(ContainElement(connID))
+	})
+
 	It("removes all connection IDs", func() {
 		Expect(g.SetMaxActiveConnIDs(5)).To(Succeed())
 		Expect(queuedFrames).To(HaveLen(4))
@@ -17,6 +17,9 @@ type connIDManager struct {
 	highestRetired            uint64
 	activeConnectionID        protocol.ConnectionID
 	activeStatelessResetToken *protocol.StatelessResetToken
+	// The connection ID used for probing a new path.
+	// It is removed from the queue, such that it is not used on the current path.
+	reserved *utils.NewConnectionID
 
 	// We change the connection ID after sending on average
 	// protocol.PacketsPerConnectionID packets. The actual value is randomized
@@ -44,8 +47,14 @@ func newConnIDManager(
 	}
 }
 
-func (h *connIDManager) AddFromPreferredAddress(connID protocol.ConnectionID, resetToken protocol.StatelessResetToken) error {
-	return h.addConnectionID(1, connID, resetToken)
+// AddFromPreferredAddress adds the connection ID from the preferred_address transport parameter.
+// It is reserved for probing the path to the server's preferred address, see section 9.6 of RFC 9000.
+func (h *connIDManager) AddFromPreferredAddress(connID protocol.ConnectionID, resetToken protocol.StatelessResetToken) {
+	h.reserved = &utils.NewConnectionID{
+		SequenceNumber:      1,
+		ConnectionID:        connID,
+		StatelessResetToken: resetToken,
+	}
 }
 
 func (h *connIDManager) Add(f *wire.NewConnectionIDFrame) error {
@@ -83,11 +92,17 @@ func (h *connIDManager) add(f *wire.NewConnectionIDFrame) error {
 			h.queue.Remove(el)
 		}
 		h.highestRetired = f.RetirePriorTo
+		if h.reserved != nil && h.reserved.SequenceNumber < f.RetirePriorTo {
+			h.RetireReservedConnectionID()
+		}
 	}
 
 	if f.SequenceNumber == h.activeSequenceNumber {
 		return nil
 	}
+	if h.reserved != nil && f.SequenceNumber == h.reserved.SequenceNumber {
+		return nil
+	}
 
 	if err := h.addConnectionID(f.SequenceNumber, f.ConnectionID, f.StatelessResetToken); err != nil {
 		return err
@@ -152,6 +167,60 @@ func (h *connIDManager) updateConnectionID() {
 	h.addStatelessResetToken(*h.activeStatelessResetToken)
 }
 
+// ReserveConnectionID takes an unused connection ID from the queue.
+// It is used for probing a new path, see section 9.5 of RFC 9000.
+// It returns false if no unused connection ID is available.
+func (h *connIDManager) ReserveConnectionID() (protocol.ConnectionID, bool) {
+	// If the peer uses zero-length connection IDs, there's no connection ID to switch to.
+	if h.activeConnectionID.Len() == 0 {
+		return h.activeConnectionID, true
+	}
+	if h.reserved != nil {
+		return h.reserved.ConnectionID, true
+	}
+	if h.queue.Len() == 0 {
+		return nil, false
+	}
+	front := h.queue.Remove(h.queue.Front())
+	h.reserved = &front
+	return front.ConnectionID, true
+}
+
+// ActivateReservedConnectionID switches to the reserved connection ID, retiring the currently active one.
+// It is called when migration to the new path succeeded.
+// It returns false if the reserved connection ID was retired in the mean time.
+func (h *connIDManager) ActivateReservedConnectionID() bool {
+	if h.activeConnectionID.Len() == 0 {
+		return true
+	}
+	if h.reserved == nil {
+		return false
+	}
+	h.queue.PushFront(*h.reserved)
+	h.reserved = nil
+	h.updateConnectionID()
+	return true
+}
+
+// RetireReservedConnectionID retires the reserved connection ID, if any.
+// It is called when migration to the new path failed.
+func (h *connIDManager) RetireReservedConnectionID() {
+	if h.reserved == nil {
+		return
+	}
+	h.queueControlFrame(&wire.RetireConnectionIDFrame{
+		SequenceNumber: h.reserved.SequenceNumber,
+	})
+	h.highestRetired = utils.MaxUint64(h.highestRetired, h.reserved.SequenceNumber)
+	h.reserved = nil
+}
+
+// StatelessResetToken returns the stateless reset token of the active connection ID.
+// It returns nil if the peer didn't provide one.
+func (h *connIDManager) StatelessResetToken() *protocol.StatelessResetToken {
+	return h.activeStatelessResetToken
+}
+
 func (h *connIDManager) Close() {
 	if h.activeStatelessResetToken != nil {
 		h.removeStatelessResetToken(*h.activeStatelessResetToken)
@@ -359,4 +359,68 @@ var _ = Describe("Connection ID Manager", func() {
 		Expect(removedTokens).To(HaveLen(1))
 		Expect(removedTokens[0]).To(Equal(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}))
 	})
+
+	Context("reserving connection IDs for path migration", func() {
+		BeforeEach(func() {
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        protocol.ConnectionID{1, 2, 3, 4},
+				StatelessResetToken: protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			})).To(Succeed())
+		})
+
+		It("reserves a connection ID and activates it", func() {
+			connID, ok := m.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
+			// reserving again returns the same connection ID
+			connID, ok = m.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
+			// the reserved connection ID isn't used on the current path
+			m.SetHandshakeComplete()
+			Expect(m.Get()).To(Equal(initialConnID))
+			Expect(m.ActivateReservedConnectionID()).To(BeTrue())
+			Expect(m.Get()).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
+			Expect(*tokenAdded).To(Equal(protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}))
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 0}))
+		})
+
+		It("doesn't reserve a connection ID if none is available", func() {
+			_, ok := m.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			Expect(m.ActivateReservedConnectionID()).To(BeTrue())
+			_, ok = m.ReserveConnectionID()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("retires the reserved connection ID", func() {
+			_, ok := m.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			m.RetireReservedConnectionID()
+			Expect(frameQueue).To(Equal([]wire.Frame{&wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			Expect(m.ActivateReservedConnectionID()).To(BeFalse())
+			Expect(m.Get()).To(Equal(initialConnID))
+		})
+
+		It("retires the reserved connection ID when the peer asks to retire it", func() {
+			_, ok := m.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			Expect(m.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber: 2,
+				ConnectionID:   protocol.ConnectionID{2, 3, 4, 5},
+				RetirePriorTo:  2,
+			})).To(Succeed())
+			Expect(frameQueue).To(ContainElement(&wire.RetireConnectionIDFrame{SequenceNumber: 1}))
+			Expect(m.ActivateReservedConnectionID()).To(BeFalse())
+		})
+
+		It("uses the zero-length connection ID, if the peer uses zero-length connection IDs", func() {
+			m.ChangeInitialConnID(protocol.ConnectionID{})
+			connID, ok := m.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			Expect(connID.Len()).To(BeZero())
+			Expect(m.ActivateReservedConnectionID()).To(BeTrue())
+		})
+	})
 })
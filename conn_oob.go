@@ -7,11 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 	"unsafe"
 
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 	"golang.org/x/sys/unix"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
@@ -20,6 +24,39 @@ import (
 
 const ecnMask uint8 = 0x3
 
+// Setting this environment variable to "true" disables the use of Generic Segmentation Offload.
+const disableGSOEnv = "QUIC_GO_DISABLE_GSO"
+
+// Setting this environment variable to "true" disables the use of Generic Receive Offload.
+const disableGROEnv = "QUIC_GO_DISABLE_GRO"
+
+// The kernel coalesces up to 64 KB into a single GRO datagram.
+const groBufferSize = 1 << 16
+
+// A batchConn reads multiple packets using a single syscall.
+type batchConn interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// singleReadConn is used for connections that don't support reading batches.
+// It reads a single packet on every call to ReadBatch.
+type singleReadConn struct {
+	OOBCapablePacketConn
+}
+
+func (c *singleReadConn) ReadBatch(ms []ipv4.Message, _ int) (int, error) {
+	msg := &ms[0]
+	n, oobn, flags, addr, err := c.ReadMsgUDP(msg.Buffers[0], msg.OOB)
+	if err != nil {
+		return 0, err
+	}
+	msg.N = n
+	msg.NN = oobn
+	msg.Flags = flags
+	msg.Addr = addr
+	return 1, nil
+}
+
 func inspectReadBuffer(c interface{}) (int, error) {
 	conn, ok := c.(interface {
 		SyscallConn() (syscall.RawConn, error)
@@ -43,7 +80,22 @@ func inspectReadBuffer(c interface{}) (int, error) {
 
 type oobConn struct {
 	OOBCapablePacketConn
-	oobBuffer []byte
+	batchConn batchConn
+
+	messages []ipv4.Message
+	// buffers holds the packet buffers the messages are read into.
+	// Only used if GRO is disabled.
+	buffers []*packetBuffer
+	// groBuffers holds the buffers the messages are read into, if GRO is enabled.
+	// Coalesced datagrams are split and copied into packet buffers.
+	groBuffers [][]byte
+	// packets that were read, but not yet returned by ReadPacket
+	queue   []*receivedPacket
+	readPos int
+
+	// gsoEnabled is set if the kernel supports GSO.
+	// It is reset when the kernel rejects sending a packet using GSO.
+	gsoEnabled utils.AtomicBool
 }
 
 var _ connection = &oobConn{}
@@ -94,29 +146,140 @@ func newConn(c OOBCapablePacketConn) (*oobConn, error) {
 			return nil, errors.New("activating packet info failed for both IPv4 and IPv6")
 		}
 	}
-	return &oobConn{
+	// Reading batches requires access to the socket.
+	var bc batchConn
+	var batchSize int
+	if udpConn, ok := c.(*net.UDPConn); ok {
+		batchSize = readBatchSize
+		if utils.IsIPv4(udpConn.LocalAddr().(*net.UDPAddr).IP) {
+			bc = ipv4.NewPacketConn(udpConn)
+		} else {
+			bc = ipv6.NewPacketConn(udpConn)
+		}
+	} else {
+		batchSize = 1
+		bc = &singleReadConn{OOBCapablePacketConn: c}
+	}
+	oobConn := &oobConn{
 		OOBCapablePacketConn: c,
-		oobBuffer:            make([]byte, 128),
-	}, nil
+		batchConn:            bc,
+		messages:             make([]ipv4.Message, batchSize),
+		queue:                make([]*receivedPacket, 0, batchSize),
+	}
+	for i := range oobConn.messages {
+		oobConn.messages[i].Buffers = make([][]byte, 1)
+		oobConn.messages[i].OOB = make([]byte, 128)
+	}
+	if disabled, _ := strconv.ParseBool(os.Getenv(disableGROEnv)); !disabled && enableGRO(rawConn) {
+		utils.DefaultLogger.Debugf("Activating Generic Receive Offload.")
+		oobConn.groBuffers = make([][]byte, batchSize)
+		for i := range oobConn.groBuffers {
+			oobConn.groBuffers[i] = make([]byte, groBufferSize)
+		}
+	} else {
+		oobConn.buffers = make([]*packetBuffer, batchSize)
+	}
+	if disabled, _ := strconv.ParseBool(os.Getenv(disableGSOEnv)); !disabled && isGSOSupported(rawConn) {
+		utils.DefaultLogger.Debugf("Activating Generic Segmentation Offload.")
+		oobConn.gsoEnabled.Set(true)
+	}
+	return oobConn, nil
 }
 
+// ReadPacket returns the next packet.
+// Packets are read in batches, such that a single syscall returns multiple packets.
 func (c *oobConn) ReadPacket() (*receivedPacket, error) {
-	buffer := getPacketBuffer()
-	// The packet size should not exceed protocol.MaxPacketBufferSize bytes
-	// If it does, we only read a truncated packet, which will then end up undecryptable
-	buffer.Data = buffer.Data[:protocol.MaxPacketBufferSize]
-	c.oobBuffer = c.oobBuffer[:cap(c.oobBuffer)]
-	n, oobn, _, addr, err := c.OOBCapablePacketConn.ReadMsgUDP(buffer.Data, c.oobBuffer)
+	for c.readPos == len(c.queue) {
+		c.queue = c.queue[:0]
+		c.readPos = 0
+		if err := c.readBatch(); err != nil {
+			return nil, err
+		}
+	}
+	p := c.queue[c.readPos]
+	c.queue[c.readPos] = nil
+	c.readPos++
+	return p, nil
+}
+
+func (c *oobConn) readBatch() error {
+	for i := range c.messages {
+		msg := &c.messages[i]
+		if c.groBuffers != nil {
+			msg.Buffers[0] = c.groBuffers[i]
+		} else {
+			if c.buffers[i] == nil {
+				c.buffers[i] = getPacketBuffer()
+			}
+			// The packet size should not exceed protocol.MaxPacketBufferSize bytes
+			// If it does, we only read a truncated packet, which will then end up undecryptable
+			msg.Buffers[0] = c.buffers[i].Data[:protocol.MaxPacketBufferSize]
+		}
+		msg.OOB = msg.OOB[:cap(msg.OOB)]
+	}
+	n, err := c.batchConn.ReadBatch(c.messages, 0)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	rcvTime := time.Now()
+	for i := 0; i < n; i++ {
+		msg := &c.messages[i]
+		ecn, info, segmentSize, err := parseOOB(msg.OOB[:msg.NN])
+		if err != nil {
+			// Only drop this packet, and still deliver the other packets of the batch.
+			// The packet buffer is reused for the next batch.
+			utils.DefaultLogger.Debugf("Dropping packet from %s: failed to parse control message: %s", msg.Addr, err)
+			continue
+		}
+		data := msg.Buffers[0][:msg.N]
+		if c.groBuffers == nil {
+			c.queue = append(c.queue, &receivedPacket{
+				remoteAddr: msg.Addr,
+				rcvTime:    rcvTime,
+				data:       data,
+				ecn:        ecn,
+				info:       info,
+				buffer:     c.buffers[i],
+			})
+			c.buffers[i] = nil
+			continue
+		}
+		// The kernel might have coalesced multiple datagrams of segmentSize bytes.
+		// Only the last datagram may be shorter.
+		if segmentSize == 0 {
+			segmentSize = len(data)
+		}
+		for len(data) > 0 {
+			l := utils.Min(len(data), segmentSize)
+			buffer := getPacketBuffer()
+			// If the datagram is larger than protocol.MaxPacketBufferSize bytes, it is truncated,
+			// and will then end up undecryptable.
+			buffer.Data = append(buffer.Data, data[:utils.Min(l, int(protocol.MaxPacketBufferSize))]...)
+			c.queue = append(c.queue, &receivedPacket{
+				remoteAddr: msg.Addr,
+				rcvTime:    rcvTime,
+				data:       buffer.Data,
+				ecn:        ecn,
+				info:       info,
+				buffer:     buffer,
+			})
+			data = data[l:]
+		}
 	}
-	ctrlMsgs, err := unix.ParseSocketControlMessage(c.oobBuffer[:oobn])
+	return nil
+}
+
+// parseOOB parses the control messages received with a packet.
+// It returns the ECN bits, the packet info, and the segment size, if the datagram was coalesced using GRO.
+func parseOOB(oob []byte) (protocol.ECN, *packetInfo, int, error) {
+	ctrlMsgs, err := unix.ParseSocketControlMessage(oob)
 	if err != nil {
-		return nil, err
+		return 0, nil, 0, err
 	}
 	var ecn protocol.ECN
 	var destIP net.IP
 	var ifIndex uint32
+	var segmentSize int
 	for _, ctrlMsg := range ctrlMsgs {
 		if ctrlMsg.Header.Level == unix.IPPROTO_IP {
 			switch ctrlMsg.Header.Type {
@@ -153,6 +316,9 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 				}
 			}
 		}
+		if size, ok := parseUDPGROMsg(ctrlMsg); ok {
+			segmentSize = size
+		}
 	}
 	var info *packetInfo
 	if destIP != nil {
@@ -161,14 +327,7 @@ func (c *oobConn) ReadPacket() (*receivedPacket, error) {
 			ifIndex: ifIndex,
 		}
 	}
-	return &receivedPacket{
-		remoteAddr: addr,
-		rcvTime:    time.Now(),
-		data:       buffer.Data[:n],
-		ecn:        ecn,
-		info:       info,
-		buffer:     buffer,
-	}, nil
+	return ecn, info, segmentSize, nil
 }
 
 func (c *oobConn) WritePacket(b []byte, addr net.Addr, oob []byte) (n int, err error) {
@@ -176,6 +335,25 @@ func (c *oobConn) WritePacket(b []byte, addr net.Addr, oob []byte) (n int, err e
 	return n, err
 }
 
+func (c *oobConn) WritePackets(b []byte, segmentSize int, addr net.Addr, oob []byte) error {
+	if len(b) <= segmentSize || !c.gsoEnabled.Get() {
+		return writePacketsIndividually(c, b, segmentSize, addr, oob)
+	}
+	// copy the OOB data, so we don't modify the caller's slice
+	gsoOOB := appendUDPSegmentSizeMsg(append([]byte(nil), oob...), uint16(segmentSize))
+	_, err := c.WritePacket(b, addr, gsoOOB)
+	if err != nil && isGSOError(err) {
+		utils.DefaultLogger.Debugf("Sending packets using GSO failed (%s). Disabling GSO.", err)
+		c.gsoEnabled.Set(false)
+		return writePacketsIndividually(c, b, segmentSize, addr, oob)
+	}
+	return err
+}
+
+func (c *oobConn) SupportsGSO() bool {
+	return c.gsoEnabled.Get()
+}
+
 func (info *packetInfo) OOB() []byte {
 	if info == nil {
 		return nil
@@ -0,0 +1,215 @@
+// +build linux
+
+package quic
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"golang.org/x/net/ipv4"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GSO", func() {
+	newSender := func() *oobConn {
+		addr, err := net.ResolveUDPAddr("udp4", "localhost:0")
+		Expect(err).ToNot(HaveOccurred())
+		udpConn, err := net.ListenUDP("udp4", addr)
+		Expect(err).ToNot(HaveOccurred())
+		c, err := newConn(udpConn)
+		Expect(err).ToNot(HaveOccurred())
+		return c
+	}
+
+	runServer := func() (*net.UDPConn, <-chan []byte) {
+		addr, err := net.ResolveUDPAddr("udp4", "localhost:0")
+		Expect(err).ToNot(HaveOccurred())
+		udpConn, err := net.ListenUDP("udp4", addr)
+		Expect(err).ToNot(HaveOccurred())
+		packetChan := make(chan []byte, 1000)
+		go func() {
+			for {
+				b := make([]byte, 1500)
+				n, _, err := udpConn.ReadFrom(b)
+				if err != nil {
+					return
+				}
+				packetChan <- b[:n]
+			}
+		}()
+		return udpConn, packetChan
+	}
+
+	receive := func(packetChan <-chan []byte, num int) [][]byte {
+		var data [][]byte
+		for i := 0; i < num; i++ {
+			var b []byte
+			Eventually(packetChan).Should(Receive(&b))
+			data = append(data, b)
+		}
+		Consistently(packetChan, scaleDuration(20*time.Millisecond)).ShouldNot(Receive())
+		return data
+	}
+
+	It("sends multiple packets using a single syscall", func() {
+		conn, packetChan := runServer()
+		defer conn.Close()
+		sender := newSender()
+		defer sender.Close()
+		if !sender.SupportsGSO() {
+			Skip("GSO not supported")
+		}
+
+		Expect(sender.WritePackets([]byte("foobarfoobarfoo"), 6, conn.LocalAddr(), nil)).To(Succeed())
+		Expect(receive(packetChan, 3)).To(Equal([][]byte{[]byte("foobar"), []byte("foobar"), []byte("foo")}))
+		Expect(sender.SupportsGSO()).To(BeTrue())
+	})
+
+	It("falls back to sending packets one by one if the kernel rejects the GSO request", func() {
+		conn, packetChan := runServer()
+		defer conn.Close()
+		sender := newSender()
+		defer sender.Close()
+		if !sender.SupportsGSO() {
+			Skip("GSO not supported")
+		}
+
+		// The kernel refuses to split a datagram into more than 128 segments (64 on older kernels).
+		data := make([]byte, 130)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		Expect(sender.WritePackets(data, 1, conn.LocalAddr(), nil)).To(Succeed())
+		received := receive(packetChan, 130)
+		for i, b := range received {
+			Expect(b).To(Equal([]byte{byte(i)}))
+		}
+		Expect(sender.SupportsGSO()).To(BeFalse())
+	})
+
+	It("doesn't use GSO if disabled using the environment variable", func() {
+		os.Setenv(disableGSOEnv, "true")
+		defer os.Unsetenv(disableGSOEnv)
+		conn, packetChan := runServer()
+		defer conn.Close()
+		sender := newSender()
+		defer sender.Close()
+		Expect(sender.SupportsGSO()).To(BeFalse())
+
+		Expect(sender.WritePackets([]byte("foobarfoobarfoo"), 6, conn.LocalAddr(), nil)).To(Succeed())
+		Expect(receive(packetChan, 3)).To(Equal([][]byte{[]byte("foobar"), []byte("foobar"), []byte("foo")}))
+	})
+})
+
+// corruptOOBBatchConn corrupts the control message of one message of every batch.
+type corruptOOBBatchConn struct {
+	batchConn
+	corrupt int
+}
+
+func (c *corruptOOBBatchConn) ReadBatch(ms []ipv4.Message, flags int) (int, error) {
+	n, err := c.batchConn.ReadBatch(ms, flags)
+	if n > c.corrupt {
+		// the length in the header exceeds the length of the control message
+		ms[c.corrupt].NN = copy(ms[c.corrupt].OOB, bytes.Repeat([]byte{0xff}, 32))
+	}
+	return n, err
+}
+
+var _ = Describe("Reading batches of packets", func() {
+	newConnWithEnv := func(env string) (*net.UDPConn, *oobConn) {
+		if env != "" {
+			os.Setenv(env, "true")
+			defer os.Unsetenv(env)
+		}
+		addr, err := net.ResolveUDPAddr("udp4", "localhost:0")
+		Expect(err).ToNot(HaveOccurred())
+		udpConn, err := net.ListenUDP("udp4", addr)
+		Expect(err).ToNot(HaveOccurred())
+		c, err := newConn(udpConn)
+		Expect(err).ToNot(HaveOccurred())
+		return udpConn, c
+	}
+
+	sendPackets := func(addr net.Addr, num int) {
+		conn, err := net.DialUDP("udp4", nil, addr.(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		for i := 0; i < num; i++ {
+			_, err := conn.Write([]byte{byte(i), byte(i)})
+			Expect(err).ToNot(HaveOccurred())
+		}
+	}
+
+	It("reads multiple packets using a single syscall", func() {
+		udpConn, conn := newConnWithEnv(disableGROEnv)
+		defer udpConn.Close()
+		Expect(conn.groBuffers).To(BeNil())
+
+		const num = 2*readBatchSize + 3
+		sendPackets(udpConn.LocalAddr(), num)
+		time.Sleep(scaleDuration(10 * time.Millisecond)) // wait for all packets to arrive
+		for i := 0; i < num; i++ {
+			p, err := conn.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.data).To(Equal([]byte{byte(i), byte(i)}))
+			Expect(p.buffer.Data).To(HaveCap(int(protocol.MaxPacketBufferSize)))
+			if i == 0 {
+				// the first syscall read a full batch
+				Expect(conn.queue).To(HaveLen(readBatchSize))
+			}
+			p.buffer.Release()
+		}
+	})
+
+	It("only drops packets with invalid control messages", func() {
+		udpConn, conn := newConnWithEnv(disableGROEnv)
+		defer udpConn.Close()
+		conn.batchConn = &corruptOOBBatchConn{batchConn: conn.batchConn, corrupt: 1}
+
+		sendPackets(udpConn.LocalAddr(), 3)
+		time.Sleep(scaleDuration(10 * time.Millisecond)) // wait for all packets to arrive
+		p, err := conn.ReadPacket()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.data).To(Equal([]byte{0, 0}))
+		p, err = conn.ReadPacket()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.data).To(Equal([]byte{2, 2}))
+		Expect(conn.readPos).To(Equal(len(conn.queue)))
+	})
+
+	It("splits datagrams coalesced using GRO", func() {
+		udpConn, conn := newConnWithEnv("")
+		defer udpConn.Close()
+		if conn.groBuffers == nil {
+			Skip("GRO not supported")
+		}
+		senderConn, sender := newConnWithEnv("")
+		defer senderConn.Close()
+		if !sender.SupportsGSO() {
+			Skip("GSO not supported")
+		}
+
+		Expect(sender.WritePackets([]byte("foobarfoobarfoo"), 6, udpConn.LocalAddr(), nil)).To(Succeed())
+		var data [][]byte
+		for i := 0; i < 3; i++ {
+			p, err := conn.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.remoteAddr).To(Equal(senderConn.LocalAddr()))
+			Expect(p.buffer.Data).To(HaveCap(int(protocol.MaxPacketBufferSize)))
+			data = append(data, p.data)
+		}
+		Expect(data).To(Equal([][]byte{[]byte("foobar"), []byte("foobar"), []byte("foo")}))
+	})
+
+	It("doesn't use GRO if disabled using the environment variable", func() {
+		udpConn, conn := newConnWithEnv(disableGROEnv)
+		defer udpConn.Close()
+		Expect(conn.groBuffers).To(BeNil())
+	})
+})
@@ -127,6 +127,30 @@ var _ = Describe("OOB Conn Test", func() {
 		})
 	})
 
+	It("reads packets from connections that are not a *net.UDPConn", func() {
+		addr, err := net.ResolveUDPAddr("udp4", "localhost:0")
+		Expect(err).ToNot(HaveOccurred())
+		udpConn, err := net.ListenUDP("udp4", addr)
+		Expect(err).ToNot(HaveOccurred())
+		defer udpConn.Close()
+		conn, err := newConn(struct{ *net.UDPConn }{udpConn})
+		Expect(err).ToNot(HaveOccurred())
+
+		sender, err := net.DialUDP("udp4", nil, udpConn.LocalAddr().(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer sender.Close()
+		for i := 0; i < 3; i++ {
+			_, err := sender.Write([]byte{byte(i)})
+			Expect(err).ToNot(HaveOccurred())
+		}
+		for i := 0; i < 3; i++ {
+			p, err := conn.ReadPacket()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(p.data).To(Equal([]byte{byte(i)}))
+			Expect(p.remoteAddr).To(Equal(sender.LocalAddr()))
+		}
+	})
+
 	Context("Packet Info conn", func() {
 		sendPacket := func(network string, addr *net.UDPAddr) net.Addr {
 			conn, err := net.DialUDP(network, nil, addr)
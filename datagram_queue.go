@@ -1,62 +1,234 @@
 package quic
 
 import (
+	"errors"
+	"sync"
+	"time"
+
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
+var errDatagramDropped = errors.New("datagram dropped from the send queue")
+
+type queuedDatagram struct {
+	frame     *wire.DatagramFrame
+	deadline  time.Time
+	onOutcome func(DatagramOutcome) // may be nil
+	// dequeued is closed when the datagram is removed from the send queue.
+	// Only set for datagrams queued by AddAndWait.
+	dequeued chan struct{}
+	// dropped is set before dequeued is closed, if the datagram was removed without being sent.
+	dropped bool
+}
+
+func (d *queuedDatagram) expired(now time.Time) bool {
+	return !d.deadline.IsZero() && now.After(d.deadline)
+}
+
 type datagramQueue struct {
-	sendQueue chan *wire.DatagramFrame
-	rcvQueue  chan []byte
+	mutex       sync.Mutex
+	sendQueue   []*queuedDatagram
+	maxQueueLen int
+	dropPolicy  DatagramDropPolicy
+	maxDataLen  protocol.ByteCount
+	// dequeued is closed (and replaced) every time a datagram is removed from the send queue
+	dequeued chan struct{}
+	// datagrams that were sent, and for which the outcome still needs to be reported
+	inFlight map[*queuedDatagram]struct{}
+
+	rcvQueue chan []byte
 
 	closeErr error
 	closed   chan struct{}
 
 	hasData func()
 
-	dequeued chan struct{}
-
 	logger utils.Logger
 }
 
-func newDatagramQueue(hasData func(), logger utils.Logger) *datagramQueue {
+func newDatagramQueue(hasData func(), maxQueueLen int, dropPolicy DatagramDropPolicy, logger utils.Logger) *datagramQueue {
 	return &datagramQueue{
-		hasData:   hasData,
-		sendQueue: make(chan *wire.DatagramFrame, 1),
-		rcvQueue:  make(chan []byte, protocol.DatagramRcvQueueLen),
-		dequeued:  make(chan struct{}),
-		closed:    make(chan struct{}),
-		logger:    logger,
+		hasData:     hasData,
+		maxQueueLen: maxQueueLen,
+		dropPolicy:  dropPolicy,
+		rcvQueue:    make(chan []byte, protocol.DatagramRcvQueueLen),
+		dequeued:    make(chan struct{}),
+		inFlight:    make(map[*queuedDatagram]struct{}),
+		closed:      make(chan struct{}),
+		logger:      logger,
 	}
 }
 
-// AddAndWait queues a new DATAGRAM frame for sending.
-// It blocks until the frame has been dequeued.
-func (h *datagramQueue) AddAndWait(f *wire.DatagramFrame) error {
+// SetMaxDataLen sets the maximum payload size of a DATAGRAM frame that can be sent.
+// Queued datagrams that exceed the new limit are dropped.
+func (h *datagramQueue) SetMaxDataLen(l protocol.ByteCount) {
+	h.mutex.Lock()
+	h.maxDataLen = l
+	var dropped []*queuedDatagram
+	for i := 0; i < len(h.sendQueue); i++ {
+		if d := h.sendQueue[i]; protocol.ByteCount(len(d.frame.Data)) > l {
+			dropped = append(dropped, d)
+			h.removeLocked(i, true)
+			i--
+		}
+	}
+	h.mutex.Unlock()
+
+	h.reportDropped(dropped)
+}
+
+// MaxDataLen returns the maximum payload size of a DATAGRAM frame that can be sent.
+func (h *datagramQueue) MaxDataLen() protocol.ByteCount {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return h.maxDataLen
+}
+
+// Add queues a new DATAGRAM frame for sending. It doesn't block.
+// If the send queue is full, a datagram is dropped according to the drop policy.
+// Datagrams that haven't been sent when the deadline expires are dropped.
+func (h *datagramQueue) Add(f *wire.DatagramFrame, deadline time.Time, onOutcome func(DatagramOutcome)) error {
+	d := &queuedDatagram{frame: f, deadline: deadline, onOutcome: onOutcome}
+
+	h.mutex.Lock()
 	select {
-	case h.sendQueue <- f:
-		h.hasData()
 	case <-h.closed:
+		h.mutex.Unlock()
 		return h.closeErr
+	default:
+	}
+	dropped := h.removeExpiredLocked(time.Now())
+	if len(h.sendQueue) >= h.maxQueueLen {
+		switch h.dropPolicy {
+		case DatagramDropNewest:
+			dropped = append(dropped, d)
+			d = nil
+		default:
+			dropped = append(dropped, h.sendQueue[0])
+			h.removeLocked(0, true)
+		}
+	}
+	if d != nil {
+		h.sendQueue = append(h.sendQueue, d)
+	}
+	h.mutex.Unlock()
+
+	h.reportDropped(dropped)
+	if d != nil {
+		h.hasData()
+	}
+	return nil
+}
+
+// AddAndWait queues a new DATAGRAM frame for sending.
+// If the send queue is full, it waits until a datagram is dequeued.
+// It then blocks until the frame has been dequeued.
+// If the frame is dropped from the send queue instead of being sent, an error is returned.
+func (h *datagramQueue) AddAndWait(f *wire.DatagramFrame) error {
+	d := &queuedDatagram{frame: f, dequeued: make(chan struct{})}
+	for {
+		h.mutex.Lock()
+		select {
+		case <-h.closed:
+			h.mutex.Unlock()
+			return h.closeErr
+		default:
+		}
+		if len(h.sendQueue) < h.maxQueueLen {
+			h.sendQueue = append(h.sendQueue, d)
+			h.mutex.Unlock()
+			break
+		}
+		dequeued := h.dequeued
+		h.mutex.Unlock()
+
+		select {
+		case <-dequeued:
+		case <-h.closed:
+			return h.closeErr
+		}
 	}
+	h.hasData()
 
 	select {
-	case <-h.dequeued:
+	case <-d.dequeued:
+		if d.dropped {
+			return errDatagramDropped
+		}
 		return nil
 	case <-h.closed:
 		return h.closeErr
 	}
 }
 
-// Get dequeues a DATAGRAM frame for sending.
-func (h *datagramQueue) Get() *wire.DatagramFrame {
-	select {
-	case f := <-h.sendQueue:
-		h.dequeued <- struct{}{}
-		return f
-	default:
-		return nil
+// Get dequeues a DATAGRAM frame for sending, if the frame is not larger than maxLen.
+// Datagrams whose deadline has expired are dropped.
+// The outcome of the datagram needs to be reported using ReportOutcome.
+func (h *datagramQueue) Get(maxLen protocol.ByteCount, v protocol.VersionNumber) *queuedDatagram {
+	h.mutex.Lock()
+	dropped := h.removeExpiredLocked(time.Now())
+	var d *queuedDatagram
+	if len(h.sendQueue) > 0 && h.sendQueue[0].frame.Length(v) <= maxLen {
+		d = h.sendQueue[0]
+		h.removeLocked(0, false)
+		if d.onOutcome != nil {
+			h.inFlight[d] = struct{}{}
+		}
+	}
+	h.mutex.Unlock()
+
+	h.reportDropped(dropped)
+	return d
+}
+
+// ReportOutcome reports the outcome of a datagram returned by Get.
+// The outcome is only reported once, and not at all if the queue was already closed.
+func (h *datagramQueue) ReportOutcome(d *queuedDatagram, outcome DatagramOutcome) {
+	h.mutex.Lock()
+	_, ok := h.inFlight[d]
+	delete(h.inFlight, d)
+	h.mutex.Unlock()
+
+	if ok {
+		d.onOutcome(outcome)
+	}
+}
+
+func (h *datagramQueue) removeExpiredLocked(now time.Time) []*queuedDatagram {
+	var expired []*queuedDatagram
+	for i := 0; i < len(h.sendQueue); i++ {
+		if d := h.sendQueue[i]; d.expired(now) {
+			expired = append(expired, d)
+			h.removeLocked(i, true)
+			i--
+		}
+	}
+	return expired
+}
+
+func (h *datagramQueue) removeLocked(i int, dropped bool) {
+	d := h.sendQueue[i]
+	copy(h.sendQueue[i:], h.sendQueue[i+1:])
+	h.sendQueue[len(h.sendQueue)-1] = nil
+	h.sendQueue = h.sendQueue[:len(h.sendQueue)-1]
+	if d.dequeued != nil {
+		d.dropped = dropped
+		close(d.dequeued)
+	}
+	close(h.dequeued)
+	h.dequeued = make(chan struct{})
+}
+
+// reportDropped must not be called while holding the mutex.
+func (h *datagramQueue) reportDropped(dropped []*queuedDatagram) {
+	for _, d := range dropped {
+		h.logger.Debugf("Dropping DATAGRAM frame (%d bytes payload)", len(d.frame.Data))
+		if d.onOutcome != nil {
+			d.onOutcome(DatagramDropped)
+		}
 	}
 }
 
@@ -81,7 +253,25 @@ func (h *datagramQueue) Receive() ([]byte, error) {
 	}
 }
 
+// CloseWithError closes the queue.
+// Queued datagrams are reported as dropped.
+// Sent datagrams that were neither acknowledged nor declared lost yet are reported as lost.
 func (h *datagramQueue) CloseWithError(e error) {
+	h.mutex.Lock()
 	h.closeErr = e
 	close(h.closed)
+	dropped := h.sendQueue
+	h.sendQueue = nil
+	inFlight := h.inFlight
+	h.inFlight = nil
+	h.mutex.Unlock()
+
+	for _, d := range dropped {
+		if d.onOutcome != nil {
+			d.onOutcome(DatagramDropped)
+		}
+	}
+	for d := range inFlight {
+		d.onOutcome(DatagramLost)
+	}
 }
@@ -2,7 +2,9 @@ package quic
 
 import (
 	"errors"
+	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/utils"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 
@@ -18,12 +20,20 @@ var _ = Describe("Datagram Queue", func() {
 		queued = make(chan struct{}, 100)
 		queue = newDatagramQueue(func() {
 			queued <- struct{}{}
-		}, utils.DefaultLogger)
+		}, 3, DatagramDropOldest, utils.DefaultLogger)
 	})
 
 	Context("sending", func() {
+		getData := func() []byte {
+			d := queue.Get(protocol.MaxByteCount, protocol.VersionTLS)
+			if d == nil {
+				return nil
+			}
+			return d.frame.Data
+		}
+
 		It("returns nil when there's no datagram to send", func() {
-			Expect(queue.Get()).To(BeNil())
+			Expect(queue.Get(protocol.MaxByteCount, protocol.VersionTLS)).To(BeNil())
 		})
 
 		It("queues a datagram", func() {
@@ -36,11 +46,150 @@ var _ = Describe("Datagram Queue", func() {
 
 			Eventually(queued).Should(HaveLen(1))
 			Consistently(done).ShouldNot(BeClosed())
-			f := queue.Get()
-			Expect(f).ToNot(BeNil())
-			Expect(f.Data).To(Equal([]byte("foobar")))
+			Expect(getData()).To(Equal([]byte("foobar")))
+			Eventually(done).Should(BeClosed())
+			Expect(queue.Get(protocol.MaxByteCount, protocol.VersionTLS)).To(BeNil())
+		})
+
+		It("waits for space in the send queue", func() {
+			for i := 0; i < 3; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, time.Time{}, nil)).To(Succeed())
+			}
+			Expect(queued).To(HaveLen(3))
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")})).To(Succeed())
+			}()
+
+			Consistently(queued).Should(HaveLen(3))
+			Expect(getData()).To(Equal([]byte{0}))
+			Eventually(queued).Should(HaveLen(4))
+			Expect(getData()).To(Equal([]byte{1}))
+			Expect(getData()).To(Equal([]byte{2}))
+			Consistently(done).ShouldNot(BeClosed())
+			Expect(getData()).To(Equal([]byte("foobar")))
 			Eventually(done).Should(BeClosed())
-			Expect(queue.Get()).To(BeNil())
+		})
+
+		It("queues datagrams without blocking", func() {
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, time.Time{}, nil)).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, time.Time{}, nil)).To(Succeed())
+			Expect(queued).To(HaveLen(2))
+			Expect(getData()).To(Equal([]byte("foo")))
+			Expect(getData()).To(Equal([]byte("bar")))
+			Expect(getData()).To(BeNil())
+		})
+
+		It("drops the oldest datagram when the queue is full", func() {
+			var outcomes []DatagramOutcome
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte{0}}, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			for i := 1; i < 4; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, time.Time{}, nil)).To(Succeed())
+			}
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramDropped}))
+			Expect(getData()).To(Equal([]byte{1}))
+			Expect(getData()).To(Equal([]byte{2}))
+			Expect(getData()).To(Equal([]byte{3}))
+			Expect(getData()).To(BeNil())
+		})
+
+		It("returns an error when a datagram queued by AddAndWait is dropped", func() {
+			errChan := make(chan error, 1)
+			go func() {
+				defer GinkgoRecover()
+				errChan <- queue.AddAndWait(&wire.DatagramFrame{Data: []byte("foobar")})
+			}()
+
+			Eventually(queued).Should(HaveLen(1))
+			for i := 0; i < 3; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, time.Time{}, nil)).To(Succeed())
+			}
+			Eventually(errChan).Should(Receive(MatchError(errDatagramDropped)))
+			Expect(getData()).To(Equal([]byte{0}))
+		})
+
+		It("drops the newest datagram when the queue is full", func() {
+			queue = newDatagramQueue(func() {}, 3, DatagramDropNewest, utils.DefaultLogger)
+			for i := 0; i < 3; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, time.Time{}, nil)).To(Succeed())
+			}
+			var outcomes []DatagramOutcome
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte{3}}, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramDropped}))
+			Expect(getData()).To(Equal([]byte{0}))
+			Expect(getData()).To(Equal([]byte{1}))
+			Expect(getData()).To(Equal([]byte{2}))
+			Expect(getData()).To(BeNil())
+		})
+
+		It("drops datagrams when their deadline expires", func() {
+			var outcomes []DatagramOutcome
+			deadline := time.Now().Add(scaleDuration(20 * time.Millisecond))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, deadline, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("bar")}, time.Time{}, nil)).To(Succeed())
+			time.Sleep(scaleDuration(30 * time.Millisecond))
+			Expect(getData()).To(Equal([]byte("bar")))
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramDropped}))
+		})
+
+		It("doesn't count expired datagrams towards the queue length", func() {
+			queue = newDatagramQueue(func() {}, 3, DatagramDropNewest, utils.DefaultLogger)
+			deadline := time.Now().Add(scaleDuration(20 * time.Millisecond))
+			for i := 0; i < 3; i++ {
+				Expect(queue.Add(&wire.DatagramFrame{Data: []byte{byte(i)}}, deadline, nil)).To(Succeed())
+			}
+			time.Sleep(scaleDuration(30 * time.Millisecond))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte{3}}, time.Time{}, nil)).To(Succeed())
+			Expect(getData()).To(Equal([]byte{3}))
+			Expect(getData()).To(BeNil())
+		})
+
+		It("only dequeues datagrams that fit", func() {
+			f := &wire.DatagramFrame{DataLenPresent: true, Data: []byte("foobar")}
+			Expect(queue.Add(f, time.Time{}, nil)).To(Succeed())
+			Expect(queue.Get(f.Length(protocol.VersionTLS)-1, protocol.VersionTLS)).To(BeNil())
+			d := queue.Get(f.Length(protocol.VersionTLS), protocol.VersionTLS)
+			Expect(d).ToNot(BeNil())
+			Expect(d.frame).To(Equal(f))
+		})
+
+		It("drops queued datagrams that exceed the maximum data length", func() {
+			queue.SetMaxDataLen(10)
+			Expect(queue.MaxDataLen()).To(Equal(protocol.ByteCount(10)))
+			var outcomes []DatagramOutcome
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foo")}, time.Time{}, nil)).To(Succeed())
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			queue.SetMaxDataLen(5)
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramDropped}))
+			Expect(getData()).To(Equal([]byte("foo")))
+			Expect(getData()).To(BeNil())
+		})
+
+		It("reports the outcome of sent datagrams once", func() {
+			var outcomes []DatagramOutcome
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			d := queue.Get(protocol.MaxByteCount, protocol.VersionTLS)
+			Expect(d).ToNot(BeNil())
+			Expect(outcomes).To(BeEmpty())
+			queue.ReportOutcome(d, DatagramAcked)
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramAcked}))
+			queue.ReportOutcome(d, DatagramLost)
+			queue.CloseWithError(errors.New("test error"))
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramAcked}))
+		})
+
+		It("reports sent datagrams as lost when closed", func() {
+			var outcomes []DatagramOutcome
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			d := queue.Get(protocol.MaxByteCount, protocol.VersionTLS)
+			Expect(d).ToNot(BeNil())
+			queue.CloseWithError(errors.New("test error"))
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramLost}))
+			// the outcome is only reported once
+			queue.ReportOutcome(d, DatagramAcked)
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramLost}))
 		})
 
 		It("closes", func() {
@@ -54,6 +203,15 @@ var _ = Describe("Datagram Queue", func() {
 			queue.CloseWithError(errors.New("test error"))
 			Eventually(errChan).Should(Receive(MatchError("test error")))
 		})
+
+		It("drops queued datagrams when closed", func() {
+			var outcomes []DatagramOutcome
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+			queue.CloseWithError(errors.New("test error"))
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramDropped}))
+			Expect(queue.Add(&wire.DatagramFrame{Data: []byte("foobar")}, time.Time{}, nil)).To(MatchError("test error"))
+			Expect(queue.Get(protocol.MaxByteCount, protocol.VersionTLS)).To(BeNil())
+		})
 	})
 
 	Context("receiving", func() {
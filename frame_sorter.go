@@ -204,6 +204,19 @@ func (s *frameSorter) deleteConsecutive(pos protocol.ByteCount) {
 	}
 }
 
+// BufferedBytes returns the number of bytes queued in the frame sorter,
+// and the number of bytes queued behind a gap, i.e. that can't be popped yet.
+func (s *frameSorter) BufferedBytes() (total, outOfOrder protocol.ByteCount) {
+	firstGapEnd := s.gaps.Front().Value.End
+	for offset, entry := range s.queue {
+		total += protocol.ByteCount(len(entry.Data))
+		if offset >= firstGapEnd {
+			outOfOrder += protocol.ByteCount(len(entry.Data))
+		}
+	}
+	return
+}
+
 func (s *frameSorter) Pop() (protocol.ByteCount, []byte, func()) {
 	entry, ok := s.queue[s.readPos]
 	if !ok {
@@ -123,6 +123,29 @@ var _ = Describe("frame sorter", func() {
 		Expect(s.HasMoreData()).To(BeFalse())
 	})
 
+	It("counts the buffered bytes", func() {
+		total, outOfOrder := s.BufferedBytes()
+		Expect(total).To(BeZero())
+		Expect(outOfOrder).To(BeZero())
+		Expect(s.Push([]byte("foo"), 0, nil)).To(Succeed())
+		Expect(s.Push([]byte("bar"), 3, nil)).To(Succeed())
+		Expect(s.Push([]byte("lorem"), 10, nil)).To(Succeed())
+		Expect(s.Push([]byte("ipsum"), 20, nil)).To(Succeed())
+		total, outOfOrder = s.BufferedBytes()
+		Expect(total).To(BeEquivalentTo(16))
+		Expect(outOfOrder).To(BeEquivalentTo(10))
+		_, data, _ := s.Pop()
+		Expect(data).To(Equal([]byte("foo")))
+		total, outOfOrder = s.BufferedBytes()
+		Expect(total).To(BeEquivalentTo(13))
+		Expect(outOfOrder).To(BeEquivalentTo(10))
+		// fill the first gap
+		Expect(s.Push([]byte("abcd"), 6, nil)).To(Succeed())
+		total, outOfOrder = s.BufferedBytes()
+		Expect(total).To(BeEquivalentTo(17))
+		Expect(outOfOrder).To(BeEquivalentTo(5))
+	})
+
 	Context("Gap handling", func() {
 		var dataCounter uint8
 
@@ -20,6 +20,7 @@ type framer interface {
 	AppendStreamFrames([]ackhandler.Frame, protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount)
 
 	Handle0RTTRejection() error
+	SetVersion(protocol.VersionNumber)
 }
 
 type framerI struct {
@@ -28,8 +29,14 @@ type framerI struct {
 	streamGetter streamGetter
 	version      protocol.VersionNumber
 
-	activeStreams map[protocol.StreamID]struct{}
-	streamQueue   []protocol.StreamID
+	activeStreams map[protocol.StreamID]protocol.Priority
+	// There's one queue for every urgency level.
+	// Queues with a lower urgency are served first.
+	streamQueues [protocol.MaxUrgency + 1][]protocol.StreamID
+	// Streams that were added, or whose priority might have changed, since they were last scheduled.
+	// Their priority is read when STREAM frames are popped the next time.
+	pendingStreams    []protocol.StreamID
+	pendingStreamsSet map[protocol.StreamID]struct{}
 
 	controlFrameMutex sync.Mutex
 	controlFrames     []wire.Frame
@@ -42,15 +49,16 @@ func newFramer(
 	v protocol.VersionNumber,
 ) framer {
 	return &framerI{
-		streamGetter:  streamGetter,
-		activeStreams: make(map[protocol.StreamID]struct{}),
-		version:       v,
+		streamGetter:      streamGetter,
+		activeStreams:     make(map[protocol.StreamID]protocol.Priority),
+		pendingStreamsSet: make(map[protocol.StreamID]struct{}),
+		version:           v,
 	}
 }
 
 func (f *framerI) HasData() bool {
 	f.mutex.Lock()
-	hasData := len(f.streamQueue) > 0
+	hasData := len(f.activeStreams) > 0 || len(f.pendingStreams) > 0
 	f.mutex.Unlock()
 	if hasData {
 		return true
@@ -84,32 +92,88 @@ func (f *framerI) AppendControlFrames(frames []ackhandler.Frame, maxLen protocol
 	return frames, length
 }
 
+// AddActiveStream queues a stream for sending.
+// If the stream is already queued, it is rescheduled if its priority changed.
+// The priority is read from the stream when it is scheduled,
+// so it can't be outdated if it is changed concurrently.
 func (f *framerI) AddActiveStream(id protocol.StreamID) {
 	f.mutex.Lock()
-	if _, ok := f.activeStreams[id]; !ok {
-		f.streamQueue = append(f.streamQueue, id)
-		f.activeStreams[id] = struct{}{}
+	if _, ok := f.pendingStreamsSet[id]; !ok {
+		f.pendingStreamsSet[id] = struct{}{}
+		f.pendingStreams = append(f.pendingStreams, id)
 	}
 	f.mutex.Unlock()
 }
 
+// schedulePendingStreams puts the pending streams into the queue for their current urgency.
+// It must be called with the mutex held.
+func (f *framerI) schedulePendingStreams() {
+	for _, id := range f.pendingStreams {
+		delete(f.pendingStreamsSet, id)
+		oldPrio, ok := f.activeStreams[id]
+		str, err := f.streamGetter.GetOrOpenSendStream(id)
+		// The stream can be nil if it completed after it said it had data.
+		if str == nil || err != nil {
+			if ok {
+				f.removeFromQueue(id, oldPrio.Urgency)
+				delete(f.activeStreams, id)
+			}
+			continue
+		}
+		prio := str.Priority()
+		if ok && oldPrio == prio {
+			continue
+		}
+		f.activeStreams[id] = prio
+		if ok {
+			if oldPrio.Urgency == prio.Urgency {
+				continue
+			}
+			f.removeFromQueue(id, oldPrio.Urgency)
+		}
+		f.streamQueues[prio.Urgency] = append(f.streamQueues[prio.Urgency], id)
+	}
+	f.pendingStreams = f.pendingStreams[:0]
+}
+
+func (f *framerI) removeFromQueue(id protocol.StreamID, urgency uint8) {
+	queue := f.streamQueues[urgency]
+	for i, qid := range queue {
+		if qid == id {
+			f.streamQueues[urgency] = append(queue[:i], queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// nextStream returns the first stream of the most urgent non-empty queue.
+func (f *framerI) nextStream() (protocol.StreamID, uint8) {
+	for urgency, queue := range f.streamQueues {
+		if len(queue) > 0 {
+			return queue[0], uint8(urgency)
+		}
+	}
+	return protocol.InvalidStreamID, 0
+}
+
 func (f *framerI) AppendStreamFrames(frames []ackhandler.Frame, maxLen protocol.ByteCount) ([]ackhandler.Frame, protocol.ByteCount) {
 	var length protocol.ByteCount
 	var lastFrame *ackhandler.Frame
 	f.mutex.Lock()
+	f.schedulePendingStreams()
 	// pop STREAM frames, until less than MinStreamFrameSize bytes are left in the packet
-	numActiveStreams := len(f.streamQueue)
+	numActiveStreams := len(f.activeStreams)
 	for i := 0; i < numActiveStreams; i++ {
 		if protocol.MinStreamFrameSize+length > maxLen {
 			break
 		}
-		id := f.streamQueue[0]
-		f.streamQueue = f.streamQueue[1:]
+		id, urgency := f.nextStream()
 		// This should never return an error. Better check it anyway.
-		// The stream will only be in the streamQueue, if it enqueued itself there.
+		// The stream will only be in the streamQueues, if it enqueued itself there.
 		str, err := f.streamGetter.GetOrOpenSendStream(id)
 		// The stream can be nil if it completed after it said it had data.
 		if str == nil || err != nil {
+			f.streamQueues[urgency] = f.streamQueues[urgency][1:]
 			delete(f.activeStreams, id)
 			continue
 		}
@@ -119,11 +183,15 @@ func (f *framerI) AppendStreamFrames(frames []ackhandler.Frame, maxLen protocol.
 		// the STREAM frame (which will always have the DataLen set).
 		remainingLen += quicvarint.Len(uint64(remainingLen))
 		frame, hasMoreData := str.popStreamFrame(remainingLen)
-		if hasMoreData { // put the stream back in the queue (at the end)
-			f.streamQueue = append(f.streamQueue, id)
-		} else { // no more data to send. Stream is not active any more
+		if !hasMoreData { // no more data to send. Stream is not active any more
+			f.streamQueues[urgency] = f.streamQueues[urgency][1:]
 			delete(f.activeStreams, id)
+		} else if f.activeStreams[id].Incremental {
+			// Incremental streams share the bandwidth with the other streams of the same urgency.
+			// Put the stream back at the end of the queue.
+			f.streamQueues[urgency] = append(f.streamQueues[urgency][1:], id)
 		}
+		// Non-incremental streams stay at the front of the queue, such that they're sent one after the other.
 		// The frame can be nil
 		// * if the receiveStream was canceled after it said it had data
 		// * the remaining size doesn't allow us to add another STREAM frame
@@ -149,10 +217,16 @@ func (f *framerI) Handle0RTTRejection() error {
 	defer f.mutex.Unlock()
 
 	f.controlFrameMutex.Lock()
-	f.streamQueue = f.streamQueue[:0]
+	for urgency := range f.streamQueues {
+		f.streamQueues[urgency] = f.streamQueues[urgency][:0]
+	}
 	for id := range f.activeStreams {
 		delete(f.activeStreams, id)
 	}
+	for id := range f.pendingStreamsSet {
+		delete(f.pendingStreamsSet, id)
+	}
+	f.pendingStreams = f.pendingStreams[:0]
 	var j int
 	for i, frame := range f.controlFrames {
 		switch frame.(type) {
@@ -169,3 +243,7 @@ func (f *framerI) Handle0RTTRejection() error {
 	f.controlFrameMutex.Unlock()
 	return nil
 }
+
+func (f *framerI) SetVersion(v protocol.VersionNumber) {
+	f.version = v
+}
@@ -23,6 +23,7 @@ var _ = Describe("Framer", func() {
 	var (
 		framer           framer
 		stream1, stream2 *MockSendStreamI
+		prio1, prio2     protocol.Priority
 		streamGetter     *MockStreamGetter
 		version          protocol.VersionNumber
 	)
@@ -33,6 +34,10 @@ var _ = Describe("Framer", func() {
 		stream1.EXPECT().StreamID().Return(protocol.StreamID(5)).AnyTimes()
 		stream2 = NewMockSendStreamI(mockCtrl)
 		stream2.EXPECT().StreamID().Return(protocol.StreamID(6)).AnyTimes()
+		prio1 = protocol.DefaultPriority
+		prio2 = protocol.DefaultPriority
+		stream1.EXPECT().Priority().DoAndReturn(func() protocol.Priority { return prio1 }).AnyTimes()
+		stream2.EXPECT().Priority().DoAndReturn(func() protocol.Priority { return prio2 }).AnyTimes()
 		framer = newFramer(streamGetter, version)
 	})
 
@@ -114,7 +119,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("returns STREAM frames", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			f := &wire.StreamFrame{
 				StreamID:       id1,
 				Data:           []byte("foobar"),
@@ -130,7 +135,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("says if it has data", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(3)
 			Expect(framer.HasData()).To(BeFalse())
 			framer.AddActiveStream(id1)
 			Expect(framer.HasData()).To(BeTrue())
@@ -149,7 +154,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("appends to a frame slice", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			f := &wire.StreamFrame{
 				StreamID:       id1,
 				Data:           []byte("foobar"),
@@ -169,7 +174,7 @@ var _ = Describe("Framer", func() {
 
 		It("skips a stream that was reported active, but was completed shortly after", func() {
 			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(nil, nil)
-			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
 			f := &wire.StreamFrame{
 				StreamID:       id2,
 				Data:           []byte("foobar"),
@@ -184,8 +189,8 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("skips a stream that was reported active, but doesn't have any data", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
-			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
 			f := &wire.StreamFrame{
 				StreamID:       id2,
 				Data:           []byte("foobar"),
@@ -201,7 +206,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("pops from a stream multiple times, if it has enough data", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(3)
 			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
 			f2 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, true)
@@ -219,8 +224,8 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("re-queues a stream at the end, if it has enough data", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
-			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(3)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
 			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
 			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
 			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
@@ -244,8 +249,8 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("only dequeues data from each stream once per packet", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
-			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
 			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
 			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
 			// both streams have more data, and will be re-queued
@@ -261,8 +266,8 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("returns multiple normal frames in the order they were reported active", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
-			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
 			f1 := &wire.StreamFrame{Data: []byte("foobar")}
 			f2 := &wire.StreamFrame{Data: []byte("foobaz")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, false)
@@ -276,7 +281,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("only asks a stream for data once, even if it was reported active multiple times", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			f := &wire.StreamFrame{Data: []byte("foobar")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false) // only one call to this function
 			framer.AddActiveStream(id1)
@@ -293,7 +298,7 @@ var _ = Describe("Framer", func() {
 
 		It("pops maximum size STREAM frames", func() {
 			for i := protocol.MinStreamFrameSize; i < 2000; i++ {
-				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 				stream1.EXPECT().popStreamFrame(gomock.Any()).DoAndReturn(func(size protocol.ByteCount) (*ackhandler.Frame, bool) {
 					f := &wire.StreamFrame{
 						StreamID:       id1,
@@ -314,8 +319,8 @@ var _ = Describe("Framer", func() {
 
 		It("pops multiple STREAM frames", func() {
 			for i := 2 * protocol.MinStreamFrameSize; i < 2000; i++ {
-				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
-				streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil)
+				streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+				streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
 				stream1.EXPECT().popStreamFrame(gomock.Any()).DoAndReturn(func(size protocol.ByteCount) (*ackhandler.Frame, bool) {
 					f := &wire.StreamFrame{
 						StreamID:       id2,
@@ -346,7 +351,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("pops frames that when asked for the the minimum STREAM frame size", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			f := &wire.StreamFrame{Data: []byte("foobar")}
 			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f}, false)
 			framer.AddActiveStream(id1)
@@ -359,7 +364,7 @@ var _ = Describe("Framer", func() {
 		})
 
 		It("stops iterating when the remaining size is smaller than the minimum STREAM frame size", func() {
-			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil)
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
 			// pop a frame such that the remaining size is one byte less than the minimum STREAM frame size
 			f := &wire.StreamFrame{
 				StreamID:       id1,
@@ -382,4 +387,105 @@ var _ = Describe("Framer", func() {
 			Expect(length).To(BeZero())
 		})
 	})
+
+	Context("prioritizing streams", func() {
+		It("sends data on more urgent streams first", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			prio1 = protocol.Priority{Urgency: 5}
+			framer.AddActiveStream(id1)
+			prio2 = protocol.Priority{Urgency: 1}
+			framer.AddActiveStream(id2)
+			frames, _ := framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(frames[1].Frame).To(Equal(f1))
+		})
+
+		It("starves less urgent streams", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(3)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			prio2 = protocol.Priority{Urgency: 4, Incremental: true}
+			framer.AddActiveStream(id2)
+			prio1 = protocol.Priority{Urgency: 2, Incremental: true}
+			framer.AddActiveStream(id1)
+			for _, f := range []*wire.StreamFrame{f11, f12, f2} {
+				frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f))
+			}
+		})
+
+		It("sends non-incremental streams one after the other", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(3)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			prio1 = protocol.Priority{Urgency: 3}
+			framer.AddActiveStream(id1)
+			prio2 = protocol.Priority{Urgency: 3}
+			framer.AddActiveStream(id2)
+			for _, f := range []*wire.StreamFrame{f11, f12, f2} {
+				frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(Equal(f))
+			}
+		})
+
+		It("uses the priority at the time the stream is scheduled", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(2)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(2)
+			f1 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f1}, false)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id2)
+			prio2 = protocol.Priority{Urgency: 0}
+			framer.AddActiveStream(id2)
+			frames, _ := framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(frames[1].Frame).To(Equal(f1))
+			Expect(framer.HasData()).To(BeFalse())
+		})
+
+		It("reschedules a stream when its priority changes", func() {
+			streamGetter.EXPECT().GetOrOpenSendStream(id1).Return(stream1, nil).Times(3)
+			streamGetter.EXPECT().GetOrOpenSendStream(id2).Return(stream2, nil).Times(3)
+			f11 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobar")}
+			f12 := &wire.StreamFrame{StreamID: id1, Data: []byte("foobaz")}
+			f2 := &wire.StreamFrame{StreamID: id2, Data: []byte("raboof")}
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f11}, true)
+			framer.AddActiveStream(id1)
+			framer.AddActiveStream(id2)
+			frames, _ := framer.AppendStreamFrames(nil, protocol.MinStreamFrameSize)
+			Expect(frames).To(HaveLen(1))
+			Expect(frames[0].Frame).To(Equal(f11))
+			// stream 1 would be next, but stream 2 becomes more urgent
+			prio2 = protocol.Priority{Urgency: 1}
+			framer.AddActiveStream(id2)
+			stream2.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f2}, false)
+			stream1.EXPECT().popStreamFrame(gomock.Any()).Return(&ackhandler.Frame{Frame: f12}, false)
+			frames, _ = framer.AppendStreamFrames(nil, 1000)
+			Expect(frames).To(HaveLen(2))
+			Expect(frames[0].Frame).To(Equal(f2))
+			Expect(frames[1].Frame).To(Equal(f12))
+			Expect(framer.HasData()).To(BeFalse())
+		})
+	})
 })
@@ -133,6 +133,12 @@ func getFrames() []wire.Frame {
 			ErrorCode: quic.ErrorCode(getRandomNumber()),
 			FinalSize: protocol.MaxByteCount,
 		},
+		&wire.ResetStreamAtFrame{
+			StreamID:     protocol.StreamID(getRandomNumber()),
+			ErrorCode:    quic.ErrorCode(getRandomNumber()),
+			FinalSize:    protocol.MaxByteCount,
+			ReliableSize: protocol.ByteCount(getRandomNumber()),
+		},
 		&wire.StopSendingFrame{
 			StreamID:  protocol.StreamID(getRandomNumber()),
 			ErrorCode: quic.ErrorCode(getRandomNumber()),
@@ -249,6 +255,16 @@ func getFrames() []wire.Frame {
 		Data: data2,
 	})
 
+	frames = append(frames, []wire.Frame{
+		&wire.AckFrequencyFrame{
+			SequenceNumber:        getRandomNumber(),
+			AckElicitingThreshold: getRandomNumber(),
+			RequestMaxAckDelay:    time.Duration(getRandomNumber()%(1<<14)) * time.Millisecond,
+			ReorderingThreshold:   getRandomNumber(),
+		},
+		&wire.ImmediateAckFrame{},
+	}...)
+
 	return frames
 }
 
@@ -87,6 +87,7 @@ func main() {
 			ClientSessionCache: tls.NewLRUClientSessionCache(1),
 		},
 		false,
+		handshake.KeyUpdatePolicy{},
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -106,6 +107,9 @@ func main() {
 		runner,
 		config,
 		false,
+		handshake.KeyUpdatePolicy{},
+		nil,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -146,7 +150,7 @@ messageLoop:
 		}
 	}
 
-	ticket, err := server.GetSessionTicket()
+	ticket, err := server.GetSessionTicket(nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -384,6 +384,7 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		runner,
 		clientConf,
 		enable0RTTClient,
+		handshake.KeyUpdatePolicy{},
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("client"),
@@ -401,6 +402,9 @@ func runHandshake(runConfig [confLen]byte, messageConfig uint8, clientConf *tls.
 		runner,
 		serverConf,
 		enable0RTTServer,
+		handshake.KeyUpdatePolicy{},
+		nil,
+		nil,
 		utils.NewRTTStats(),
 		nil,
 		utils.DefaultLogger.WithPrefix("server"),
@@ -485,7 +489,7 @@ messageLoop:
 	}
 
 	if sendSessionTicket && !serverConf.SessionTicketsDisabled {
-		ticket, err := server.GetSessionTicket()
+		ticket, err := server.GetSessionTicket(nil)
 		if err != nil {
 			panic(err)
 		}
@@ -0,0 +1,202 @@
+package quic
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+// A rateLimiter is a token bucket.
+// It allows events at the configured rate (per second), with bursts of up to one second worth of events.
+type rateLimiter struct {
+	rate     float64
+	tokens   float64
+	lastTime time.Time
+}
+
+func newRateLimiter(rate int, now time.Time) *rateLimiter {
+	return &rateLimiter{
+		rate:     float64(rate),
+		tokens:   float64(rate),
+		lastTime: now,
+	}
+}
+
+func (r *rateLimiter) update(now time.Time) {
+	if now.After(r.lastTime) {
+		r.tokens += now.Sub(r.lastTime).Seconds() * r.rate
+		if r.tokens > r.rate {
+			r.tokens = r.rate
+		}
+		r.lastTime = now
+	}
+}
+
+// Allow says if an event is allowed, and consumes a token if it is.
+func (r *rateLimiter) Allow(now time.Time) bool {
+	r.update(now)
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// Available says if an event would be allowed, without consuming a token.
+func (r *rateLimiter) Available(now time.Time) bool {
+	r.update(now)
+	return r.tokens >= 1
+}
+
+// IsIdle says if the bucket has been refilled completely.
+func (r *rateLimiter) IsIdle(now time.Time) bool {
+	r.update(now)
+	return r.tokens >= r.rate
+}
+
+// The handshakeLimiter is used by the server to decide if an Initial packet starting a new handshake is accepted,
+// and if the client has to validate its address using a Retry first.
+// ReceivedInitial and RequireRetry are only called from the server's run loop.
+type handshakeLimiter struct {
+	// counters, accessed atomically
+	// They are the first fields of the struct, so they are 64-bit aligned on 32-bit platforms.
+	initialsReceived     uint64
+	initialsRateLimited  uint64
+	retriesSent          uint64
+	retriesSentUnderLoad uint64
+
+	adaptiveRetry bool
+
+	maxUnvalidatedHandshakes int32 // 0 if not limited
+	unvalidatedHandshakes    int32 // accessed atomically
+	unvalidatedRate          *rateLimiter
+
+	maxInitialRatePerSubnet int // 0 if not limited
+	subnets                 map[string]*rateLimiter
+	lastCleanup             time.Time
+}
+
+// newHandshakeLimiter creates a new handshakeLimiter.
+// If limits is nil, Initial packets are not rate limited, and a Retry is required for every client that didn't validate its address.
+func newHandshakeLimiter(limits *HandshakeLimits, now time.Time) *handshakeLimiter {
+	l := &handshakeLimiter{lastCleanup: now}
+	if limits == nil {
+		return l
+	}
+	l.adaptiveRetry = true
+	switch maxHandshakes := limits.MaxUnvalidatedHandshakes; {
+	case maxHandshakes == 0:
+		l.maxUnvalidatedHandshakes = protocol.DefaultMaxUnvalidatedHandshakes
+	case maxHandshakes > 0:
+		l.maxUnvalidatedHandshakes = int32(maxHandshakes)
+	}
+	switch rate := limits.MaxUnvalidatedHandshakeRate; {
+	case rate == 0:
+		l.unvalidatedRate = newRateLimiter(protocol.DefaultMaxUnvalidatedHandshakeRate, now)
+	case rate > 0:
+		l.unvalidatedRate = newRateLimiter(rate, now)
+	}
+	switch rate := limits.MaxInitialRatePerSubnet; {
+	case rate == 0:
+		l.maxInitialRatePerSubnet = protocol.DefaultMaxInitialRatePerSubnet
+	case rate > 0:
+		l.maxInitialRatePerSubnet = rate
+	}
+	if l.maxInitialRatePerSubnet > 0 {
+		l.subnets = make(map[string]*rateLimiter)
+	}
+	return l
+}
+
+// ReceivedInitial is called for every Initial packet that starts a new handshake.
+// It returns false if the packet should be dropped, because too many Initial packets were received from the client's subnet.
+func (l *handshakeLimiter) ReceivedInitial(addr net.Addr, now time.Time) bool {
+	atomic.AddUint64(&l.initialsReceived, 1)
+	if l.maxInitialRatePerSubnet == 0 {
+		return true
+	}
+	// Buckets of subnets that haven't sent any Initial packets for a while are full again, so we can delete them.
+	// This iterates over all subnets, so it is done at most once per second.
+	if now.Sub(l.lastCleanup) > time.Second {
+		for subnet, r := range l.subnets {
+			if r.IsIdle(now) {
+				delete(l.subnets, subnet)
+			}
+		}
+		l.lastCleanup = now
+	}
+	subnet := getSubnet(addr)
+	r, ok := l.subnets[subnet]
+	if !ok {
+		// If we're tracking too many subnets, we don't rate limit new subnets.
+		// The limit on unvalidated handshakes still applies.
+		if len(l.subnets) >= protocol.MaxRateLimitedSubnets {
+			return true
+		}
+		r = newRateLimiter(l.maxInitialRatePerSubnet, now)
+		l.subnets[subnet] = r
+	}
+	if !r.Allow(now) {
+		atomic.AddUint64(&l.initialsRateLimited, 1)
+		return false
+	}
+	return true
+}
+
+// RequireRetry is called when a client didn't present a token.
+// It says if the client has to validate its address using a Retry.
+// If not, the caller must call StartedUnvalidatedHandshake once it starts the handshake.
+func (l *handshakeLimiter) RequireRetry(now time.Time) bool {
+	if !l.adaptiveRetry {
+		return true
+	}
+	if (l.maxUnvalidatedHandshakes > 0 && atomic.LoadInt32(&l.unvalidatedHandshakes) >= l.maxUnvalidatedHandshakes) ||
+		(l.unvalidatedRate != nil && !l.unvalidatedRate.Available(now)) {
+		atomic.AddUint64(&l.retriesSentUnderLoad, 1)
+		return true
+	}
+	return false
+}
+
+func (l *handshakeLimiter) SentRetry() {
+	atomic.AddUint64(&l.retriesSent, 1)
+}
+
+// StartedUnvalidatedHandshake is called when a handshake with an unvalidated client is started.
+// It counts towards the rate of unvalidated handshakes.
+func (l *handshakeLimiter) StartedUnvalidatedHandshake(now time.Time) {
+	if l.unvalidatedRate != nil {
+		l.unvalidatedRate.Allow(now)
+	}
+	atomic.AddInt32(&l.unvalidatedHandshakes, 1)
+}
+
+// CompletedUnvalidatedHandshake is called when a handshake with an unvalidated client completes or fails.
+func (l *handshakeLimiter) CompletedUnvalidatedHandshake() {
+	atomic.AddInt32(&l.unvalidatedHandshakes, -1)
+}
+
+func (l *handshakeLimiter) Metrics() logging.HandshakeMetrics {
+	return logging.HandshakeMetrics{
+		UnvalidatedHandshakes: int(atomic.LoadInt32(&l.unvalidatedHandshakes)),
+		InitialsReceived:      atomic.LoadUint64(&l.initialsReceived),
+		InitialsRateLimited:   atomic.LoadUint64(&l.initialsRateLimited),
+		RetriesSent:           atomic.LoadUint64(&l.retriesSent),
+		RetriesSentUnderLoad:  atomic.LoadUint64(&l.retriesSentUnderLoad),
+	}
+}
+
+// getSubnet returns the subnet that an address is rate limited by.
+func getSubnet(addr net.Addr) string {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return addr.String()
+	}
+	if ip := udpAddr.IP.To4(); ip != nil {
+		return ip.Mask(net.CIDRMask(protocol.InitialRateLimitPrefixLenIPv4, 32)).String()
+	}
+	return udpAddr.IP.Mask(net.CIDRMask(protocol.InitialRateLimitPrefixLenIPv6, 128)).String()
+}
@@ -0,0 +1,169 @@
+package quic
+
+import (
+	"net"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handshake Limiter", func() {
+	Context("rate limiter", func() {
+		It("allows bursts", func() {
+			now := time.Now()
+			r := newRateLimiter(10, now)
+			for i := 0; i < 10; i++ {
+				Expect(r.Allow(now)).To(BeTrue())
+			}
+			Expect(r.Allow(now)).To(BeFalse())
+		})
+
+		It("refills the bucket", func() {
+			now := time.Now()
+			r := newRateLimiter(10, now)
+			for i := 0; i < 10; i++ {
+				Expect(r.Allow(now)).To(BeTrue())
+			}
+			Expect(r.IsIdle(now)).To(BeFalse())
+			now = now.Add(100 * time.Millisecond)
+			Expect(r.Allow(now)).To(BeTrue())
+			Expect(r.Allow(now)).To(BeFalse())
+			now = now.Add(time.Hour)
+			Expect(r.IsIdle(now)).To(BeTrue())
+			for i := 0; i < 10; i++ {
+				Expect(r.Allow(now)).To(BeTrue())
+			}
+			Expect(r.Allow(now)).To(BeFalse())
+		})
+	})
+
+	It("determines the subnet", func() {
+		Expect(getSubnet(&net.UDPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337})).To(Equal("192.168.1.0"))
+		Expect(getSubnet(&net.UDPAddr{IP: net.ParseIP("2001:db8:1234:5678::1"), Port: 1337})).To(Equal("2001:db8:1234::"))
+		Expect(getSubnet(&net.TCPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337})).To(Equal("192.168.1.42:1337"))
+	})
+
+	Context("without limits", func() {
+		It("always requires a Retry", func() {
+			l := newHandshakeLimiter(nil, time.Now())
+			Expect(l.RequireRetry(time.Now())).To(BeTrue())
+			Expect(l.Metrics().RetriesSentUnderLoad).To(BeZero())
+		})
+
+		It("doesn't rate limit Initial packets", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(nil, now)
+			addr := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337}
+			for i := 0; i < 10*protocol.DefaultMaxInitialRatePerSubnet; i++ {
+				Expect(l.ReceivedInitial(addr, now)).To(BeTrue())
+			}
+			Expect(l.Metrics().InitialsReceived).To(BeEquivalentTo(10 * protocol.DefaultMaxInitialRatePerSubnet))
+		})
+	})
+
+	Context("with limits", func() {
+		It("uses the default values", func() {
+			l := newHandshakeLimiter(&HandshakeLimits{}, time.Now())
+			Expect(l.maxUnvalidatedHandshakes).To(BeEquivalentTo(protocol.DefaultMaxUnvalidatedHandshakes))
+			Expect(l.unvalidatedRate.rate).To(BeEquivalentTo(protocol.DefaultMaxUnvalidatedHandshakeRate))
+			Expect(l.maxInitialRatePerSubnet).To(Equal(protocol.DefaultMaxInitialRatePerSubnet))
+		})
+
+		It("disables limits set to negative values", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{
+				MaxUnvalidatedHandshakes:    -1,
+				MaxUnvalidatedHandshakeRate: -1,
+				MaxInitialRatePerSubnet:     -1,
+			}, now)
+			addr := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337}
+			for i := 0; i < 10*protocol.DefaultMaxUnvalidatedHandshakeRate; i++ {
+				Expect(l.ReceivedInitial(addr, now)).To(BeTrue())
+				Expect(l.RequireRetry(now)).To(BeFalse())
+				l.StartedUnvalidatedHandshake(now)
+			}
+		})
+
+		It("requires a Retry when there are too many unvalidated handshakes", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{MaxUnvalidatedHandshakes: 2}, now)
+			Expect(l.RequireRetry(now)).To(BeFalse())
+			l.StartedUnvalidatedHandshake(now)
+			Expect(l.RequireRetry(now)).To(BeFalse())
+			l.StartedUnvalidatedHandshake(now)
+			Expect(l.RequireRetry(now)).To(BeTrue())
+			Expect(l.Metrics().UnvalidatedHandshakes).To(Equal(2))
+			Expect(l.Metrics().RetriesSentUnderLoad).To(BeEquivalentTo(1))
+			l.CompletedUnvalidatedHandshake()
+			Expect(l.RequireRetry(now)).To(BeFalse())
+			Expect(l.Metrics().UnvalidatedHandshakes).To(Equal(1))
+		})
+
+		It("requires a Retry when the rate of unvalidated handshakes is too high", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{MaxUnvalidatedHandshakeRate: 5}, now)
+			for i := 0; i < 5; i++ {
+				Expect(l.RequireRetry(now)).To(BeFalse())
+				l.StartedUnvalidatedHandshake(now)
+				l.CompletedUnvalidatedHandshake()
+			}
+			Expect(l.RequireRetry(now)).To(BeTrue())
+			Expect(l.RequireRetry(now.Add(time.Second))).To(BeFalse())
+		})
+
+		It("only counts handshakes that were started towards the rate", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{MaxUnvalidatedHandshakeRate: 2}, now)
+			for i := 0; i < 5; i++ {
+				Expect(l.RequireRetry(now)).To(BeFalse())
+			}
+			l.StartedUnvalidatedHandshake(now)
+			Expect(l.RequireRetry(now)).To(BeFalse())
+			l.StartedUnvalidatedHandshake(now)
+			Expect(l.RequireRetry(now)).To(BeTrue())
+		})
+
+		It("rate limits Initial packets per subnet", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{MaxInitialRatePerSubnet: 3}, now)
+			addr1 := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337}
+			addr2 := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 43), Port: 1337}
+			addr3 := &net.UDPAddr{IP: net.IPv4(192, 168, 2, 42), Port: 1337}
+			Expect(l.ReceivedInitial(addr1, now)).To(BeTrue())
+			Expect(l.ReceivedInitial(addr2, now)).To(BeTrue())
+			Expect(l.ReceivedInitial(addr1, now)).To(BeTrue())
+			Expect(l.ReceivedInitial(addr2, now)).To(BeFalse())
+			// a different subnet
+			Expect(l.ReceivedInitial(addr3, now)).To(BeTrue())
+			Expect(l.Metrics().InitialsReceived).To(BeEquivalentTo(5))
+			Expect(l.Metrics().InitialsRateLimited).To(BeEquivalentTo(1))
+			Expect(l.ReceivedInitial(addr2, now.Add(time.Second/2))).To(BeTrue())
+		})
+
+		It("deletes state for idle subnets", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{MaxInitialRatePerSubnet: 3}, now)
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337}, now)).To(BeTrue())
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 2, 42), Port: 1337}, now.Add(time.Second))).To(BeTrue())
+			Expect(l.subnets).To(HaveLen(2))
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 3, 42), Port: 1337}, now.Add(1100*time.Millisecond))).To(BeTrue())
+			Expect(l.subnets).To(HaveLen(2))
+			Expect(l.subnets).ToNot(HaveKey("192.168.1.0"))
+		})
+
+		It("deletes state for idle subnets at most once per second", func() {
+			now := time.Now()
+			l := newHandshakeLimiter(&HandshakeLimits{MaxInitialRatePerSubnet: 3}, now)
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 1, 42), Port: 1337}, now.Add(time.Second))).To(BeTrue())
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 2, 42), Port: 1337}, now.Add(1100*time.Millisecond))).To(BeTrue())
+			// the first subnet is idle, but the last cleanup was less than a second ago
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 3, 42), Port: 1337}, now.Add(2*time.Second))).To(BeTrue())
+			Expect(l.subnets).To(HaveLen(3))
+			Expect(l.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(192, 168, 3, 42), Port: 1337}, now.Add(3*time.Second))).To(BeTrue())
+			Expect(l.subnets).To(HaveLen(1))
+		})
+	})
+})
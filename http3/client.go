@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/lucas-clemente/quic-go"
@@ -235,6 +236,10 @@ func (c *client) RoundTrip(req *http.Request) (*http.Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	// The Priority header field applies to the request body we're sending.
+	if prio, ok := req.Header["Priority"]; ok {
+		str.SetPriority(parsePriority(strings.Join(prio, ",")))
+	}
 
 	// Request Cancellation:
 	// This go routine keeps running even after RoundTrip() returns.
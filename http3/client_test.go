@@ -67,7 +67,7 @@ var _ = Describe("Client", func() {
 		var dialAddrCalled bool
 		dialAddr = func(_ string, tlsConf *tls.Config, quicConf *quic.Config) (quic.EarlySession, error) {
 			Expect(quicConf).To(Equal(defaultQuicConfig))
-			Expect(tlsConf.NextProtos).To(Equal([]string{nextProtoH3Draft29}))
+			Expect(tlsConf.NextProtos).To(Equal([]string{nextProtoH3}))
 			Expect(quicConf.Versions).To(Equal([]protocol.VersionNumber{protocol.VersionTLS}))
 			dialAddrCalled = true
 			return nil, errors.New("test done")
@@ -107,7 +107,7 @@ var _ = Describe("Client", func() {
 		) (quic.EarlySession, error) {
 			Expect(hostname).To(Equal("localhost:1337"))
 			Expect(tlsConfP.ServerName).To(Equal(tlsConf.ServerName))
-			Expect(tlsConfP.NextProtos).To(Equal([]string{nextProtoH3Draft29}))
+			Expect(tlsConfP.NextProtos).To(Equal([]string{nextProtoH3}))
 			Expect(quicConfP.MaxIdleTimeout).To(Equal(quicConf.MaxIdleTimeout))
 			dialAddrCalled = true
 			return nil, errors.New("test done")
@@ -542,6 +542,24 @@ var _ = Describe("Client", func() {
 				Expect(hfs).To(HaveKeyWithValue(":path", "/upload"))
 			})
 
+			It("sets the priority from the Priority header", func() {
+				request.Header.Set("Priority", "u=1, i")
+				done := make(chan struct{})
+				str.EXPECT().SetPriority(quic.Priority{Urgency: 1, Incremental: true})
+				gomock.InOrder(
+					str.EXPECT().Close().Do(func() { close(done) }),
+					str.EXPECT().CancelWrite(gomock.Any()).MaxTimes(1), // when reading the response errors
+				)
+				str.EXPECT().Read(gomock.Any()).DoAndReturn(func([]byte) (int, error) {
+					<-done
+					return 0, errors.New("test done")
+				})
+				_, err := client.RoundTrip(request)
+				Expect(err).To(MatchError("test done"))
+				hfs := decodeHeader(strBuf)
+				Expect(hfs).To(HaveKeyWithValue("priority", "u=1, i"))
+			})
+
 			It("returns the error that occurred when reading the body", func() {
 				request.Body.(*mockBody).readErr = errors.New("testErr")
 				done := make(chan struct{})
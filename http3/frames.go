@@ -2,6 +2,7 @@ package http3
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -48,6 +49,8 @@ func parseNextFrame(b io.Reader) (frame, error) {
 		return &headersFrame{Length: l}, nil
 	case 0x4:
 		return parseSettingsFrame(br, l)
+	case framePriorityUpdateRequest:
+		return parsePriorityUpdateFrame(br, l)
 	case 0x3: // CANCEL_PUSH
 		fallthrough
 	case 0x5: // PUSH_PROMISE
@@ -158,3 +161,40 @@ func (f *settingsFrame) Write(b *bytes.Buffer) {
 		quicvarint.Write(b, val)
 	}
 }
+
+// PRIORITY_UPDATE frame for request streams, see RFC 9218, section 7.
+const framePriorityUpdateRequest = 0xf0700
+
+type priorityUpdateFrame struct {
+	StreamID      uint64 // the Prioritized Element ID
+	PriorityValue string // the Priority Field Value
+}
+
+func parsePriorityUpdateFrame(r io.Reader, l uint64) (*priorityUpdateFrame, error) {
+	if l > 8*(1<<10) {
+		return nil, fmt.Errorf("unexpected size for PRIORITY_UPDATE frame: %d", l)
+	}
+	buf := make([]byte, l)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	b := bytes.NewReader(buf)
+	id, err := quicvarint.Read(b)
+	if err != nil {
+		return nil, errors.New("PRIORITY_UPDATE frame too short")
+	}
+	return &priorityUpdateFrame{
+		StreamID:      id,
+		PriorityValue: string(buf[len(buf)-b.Len():]),
+	}, nil
+}
+
+func (f *priorityUpdateFrame) Write(b *bytes.Buffer) {
+	quicvarint.Write(b, framePriorityUpdateRequest)
+	quicvarint.Write(b, uint64(quicvarint.Len(f.StreamID))+uint64(len(f.PriorityValue)))
+	quicvarint.Write(b, f.StreamID)
+	b.WriteString(f.PriorityValue)
+}
@@ -180,4 +180,51 @@ var _ = Describe("Frames", func() {
 			})
 		})
 	})
+
+	Context("PRIORITY_UPDATE frames", func() {
+		It("parses", func() {
+			payload := appendVarInt(nil, 0x1337)
+			payload = append(payload, []byte("u=1, i")...)
+			data := appendVarInt(nil, framePriorityUpdateRequest)
+			data = appendVarInt(data, uint64(len(payload)))
+			data = append(data, payload...)
+			frame, err := parseNextFrame(bytes.NewReader(data))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(&priorityUpdateFrame{StreamID: 0x1337, PriorityValue: "u=1, i"}))
+		})
+
+		It("writes", func() {
+			f := &priorityUpdateFrame{StreamID: 0xdeadbeef, PriorityValue: "u=7"}
+			buf := &bytes.Buffer{}
+			f.Write(buf)
+			frame, err := parseNextFrame(buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame).To(Equal(f))
+		})
+
+		It("rejects frames that are too short", func() {
+			data := appendVarInt(nil, framePriorityUpdateRequest)
+			data = appendVarInt(data, 0)
+			_, err := parseNextFrame(bytes.NewReader(data))
+			Expect(err).To(MatchError("PRIORITY_UPDATE frame too short"))
+		})
+
+		It("rejects frames that are too large", func() {
+			data := appendVarInt(nil, framePriorityUpdateRequest)
+			data = appendVarInt(data, 1<<20)
+			_, err := parseNextFrame(bytes.NewReader(data))
+			Expect(err).To(MatchError("unexpected size for PRIORITY_UPDATE frame: 1048576"))
+		})
+
+		It("errors on EOF", func() {
+			f := &priorityUpdateFrame{StreamID: 4, PriorityValue: "u=2"}
+			buf := &bytes.Buffer{}
+			f.Write(buf)
+			data := buf.Bytes()
+			for i := range data {
+				_, err := parseNextFrame(bytes.NewReader(data[:i]))
+				Expect(err).To(MatchError(io.EOF))
+			}
+		})
+	})
 })
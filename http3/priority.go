@@ -0,0 +1,132 @@
+package http3
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// The maximum number of PRIORITY_UPDATE frames we buffer for request streams that haven't been opened yet.
+const maxPendingPriorityUpdates = 64
+
+// parsePriority parses the value of a Priority header field or of a PRIORITY_UPDATE frame,
+// as defined in RFC 9218, section 4.
+// Parameters that are unknown or that have an invalid value are ignored,
+// and the default value (urgency 3, non-incremental) is used instead.
+func parsePriority(value string) quic.Priority {
+	prio := quic.Priority{Urgency: protocol.DefaultUrgency}
+	for _, member := range strings.Split(value, ",") {
+		member = strings.TrimSpace(member)
+		// ignore the parameters of the dictionary member
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		key, val := member, "?1" // a member without a value is a Boolean true
+		if i := strings.IndexByte(member, '='); i >= 0 {
+			key, val = member[:i], member[i+1:]
+		}
+		switch key {
+		case "u":
+			u, err := strconv.ParseUint(val, 10, 8)
+			if err != nil || u > protocol.MaxUrgency {
+				continue
+			}
+			prio.Urgency = uint8(u)
+		case "i":
+			switch val {
+			case "?0":
+				prio.Incremental = false
+			case "?1":
+				prio.Incremental = true
+			}
+		}
+	}
+	return prio
+}
+
+type requestStream struct {
+	str quic.Stream
+	// set when a PRIORITY_UPDATE frame was received for this stream
+	priorityUpdated bool
+}
+
+// requestStreams keeps track of the request streams of a connection,
+// such that the PRIORITY_UPDATE frames received on the control stream can be applied to them.
+type requestStreams struct {
+	mutex sync.Mutex
+
+	streams         map[quic.StreamID]*requestStream
+	highestStreamID quic.StreamID
+	// PRIORITY_UPDATE frames can arrive before the request stream was accepted.
+	pending map[quic.StreamID]quic.Priority
+}
+
+func newRequestStreams() *requestStreams {
+	return &requestStreams{
+		streams:         make(map[quic.StreamID]*requestStream),
+		highestStreamID: protocol.InvalidStreamID,
+		pending:         make(map[quic.StreamID]quic.Priority),
+	}
+}
+
+// Add adds a newly accepted request stream.
+// If a PRIORITY_UPDATE frame was received for this stream before, the priority is applied.
+func (r *requestStreams) Add(str quic.Stream) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	id := str.StreamID()
+	if id > r.highestStreamID {
+		r.highestStreamID = id
+	}
+	s := &requestStream{str: str}
+	if prio, ok := r.pending[id]; ok {
+		delete(r.pending, id)
+		str.SetPriority(prio)
+		s.priorityUpdated = true
+	}
+	r.streams[id] = s
+}
+
+// Remove removes a request stream after the request was handled.
+func (r *requestStreams) Remove(id quic.StreamID) {
+	r.mutex.Lock()
+	delete(r.streams, id)
+	r.mutex.Unlock()
+}
+
+// SetHeaderPriority applies the priority signaled in the Priority header field of the request.
+// A PRIORITY_UPDATE frame takes precedence over the header field.
+func (r *requestStreams) SetHeaderPriority(id quic.StreamID, prio quic.Priority) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	s, ok := r.streams[id]
+	if !ok || s.priorityUpdated {
+		return
+	}
+	s.str.SetPriority(prio)
+}
+
+// UpdatePriority applies the priority signaled in a PRIORITY_UPDATE frame.
+func (r *requestStreams) UpdatePriority(id quic.StreamID, prio quic.Priority) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if s, ok := r.streams[id]; ok {
+		s.str.SetPriority(prio)
+		s.priorityUpdated = true
+		return
+	}
+	// If the stream was already accepted, the request was already handled.
+	if id <= r.highestStreamID {
+		return
+	}
+	if _, ok := r.pending[id]; !ok && len(r.pending) >= maxPendingPriorityUpdates {
+		return
+	}
+	r.pending[id] = prio
+}
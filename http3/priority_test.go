@@ -0,0 +1,108 @@
+package http3
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/lucas-clemente/quic-go"
+	mockquic "github.com/lucas-clemente/quic-go/internal/mocks/quic"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Priorities", func() {
+	Context("parsing", func() {
+		It("uses the default priority", func() {
+			Expect(parsePriority("")).To(Equal(quic.Priority{Urgency: 3}))
+		})
+
+		It("parses the urgency", func() {
+			Expect(parsePriority("u=0")).To(Equal(quic.Priority{Urgency: 0}))
+			Expect(parsePriority("u=7")).To(Equal(quic.Priority{Urgency: 7}))
+		})
+
+		It("parses the incremental flag", func() {
+			Expect(parsePriority("i")).To(Equal(quic.Priority{Urgency: 3, Incremental: true}))
+			Expect(parsePriority("i=?1")).To(Equal(quic.Priority{Urgency: 3, Incremental: true}))
+			Expect(parsePriority("i=?0")).To(Equal(quic.Priority{Urgency: 3}))
+		})
+
+		It("parses both parameters", func() {
+			Expect(parsePriority("u=5, i")).To(Equal(quic.Priority{Urgency: 5, Incremental: true}))
+			Expect(parsePriority("i,u=1")).To(Equal(quic.Priority{Urgency: 1, Incremental: true}))
+		})
+
+		It("ignores invalid values", func() {
+			Expect(parsePriority("u=8")).To(Equal(quic.Priority{Urgency: 3}))
+			Expect(parsePriority("u=-1")).To(Equal(quic.Priority{Urgency: 3}))
+			Expect(parsePriority("u=foo, i=bar")).To(Equal(quic.Priority{Urgency: 3}))
+		})
+
+		It("ignores unknown parameters and parameters of members", func() {
+			Expect(parsePriority("foo=bar, u=2;x=y, i;z")).To(Equal(quic.Priority{Urgency: 2, Incremental: true}))
+		})
+	})
+
+	Context("request streams", func() {
+		var streams *requestStreams
+
+		newStream := func(id quic.StreamID) *mockquic.MockStream {
+			str := mockquic.NewMockStream(mockCtrl)
+			str.EXPECT().StreamID().Return(id).AnyTimes()
+			return str
+		}
+
+		BeforeEach(func() {
+			streams = newRequestStreams()
+		})
+
+		It("applies the priority from the header", func() {
+			str := newStream(4)
+			streams.Add(str)
+			str.EXPECT().SetPriority(quic.Priority{Urgency: 1})
+			streams.SetHeaderPriority(4, quic.Priority{Urgency: 1})
+		})
+
+		It("applies PRIORITY_UPDATEs", func() {
+			str := newStream(4)
+			streams.Add(str)
+			str.EXPECT().SetPriority(quic.Priority{Urgency: 1})
+			streams.UpdatePriority(4, quic.Priority{Urgency: 1})
+		})
+
+		It("prefers PRIORITY_UPDATEs over the header", func() {
+			str := newStream(4)
+			streams.Add(str)
+			str.EXPECT().SetPriority(quic.Priority{Urgency: 1})
+			streams.UpdatePriority(4, quic.Priority{Urgency: 1})
+			streams.SetHeaderPriority(4, quic.Priority{Urgency: 6})
+		})
+
+		It("buffers PRIORITY_UPDATEs for streams that haven't been accepted yet", func() {
+			streams.UpdatePriority(8, quic.Priority{Urgency: 2, Incremental: true})
+			str := newStream(8)
+			str.EXPECT().SetPriority(quic.Priority{Urgency: 2, Incremental: true})
+			streams.Add(str)
+			// the header is ignored
+			streams.SetHeaderPriority(8, quic.Priority{Urgency: 6})
+		})
+
+		It("ignores PRIORITY_UPDATEs for streams that were already handled", func() {
+			str := newStream(8)
+			streams.Add(str)
+			streams.Remove(8)
+			streams.UpdatePriority(4, quic.Priority{Urgency: 1})
+			streams.UpdatePriority(8, quic.Priority{Urgency: 1})
+			Expect(streams.pending).To(BeEmpty())
+		})
+
+		It("limits the number of buffered PRIORITY_UPDATEs", func() {
+			for i := 0; i < 2*maxPendingPriorityUpdates; i++ {
+				streams.UpdatePriority(quic.StreamID(4*i), quic.Priority{Urgency: 1})
+			}
+			Expect(streams.pending).To(HaveLen(maxPendingPriorityUpdates))
+			str := newStream(quic.StreamID(4 * (maxPendingPriorityUpdates + 1)))
+			str.EXPECT().SetPriority(gomock.Any()).Times(0)
+			streams.Add(str)
+		})
+	})
+})
@@ -30,6 +30,7 @@ var (
 )
 
 const (
+	nextProtoH3        = "h3"
 	nextProtoH3Draft29 = "h3-29"
 	nextProtoH3Draft32 = "h3-32"
 	nextProtoH3Draft34 = "h3-34"
@@ -43,7 +44,10 @@ const (
 )
 
 func versionToALPN(v protocol.VersionNumber) string {
-	if v == protocol.VersionTLS || v == protocol.VersionDraft29 {
+	if v == protocol.Version1 {
+		return nextProtoH3
+	}
+	if v == protocol.VersionDraft29 {
 		return nextProtoH3Draft29
 	}
 	if v == protocol.VersionDraft32 {
@@ -161,6 +165,9 @@ func (s *Server) serveImpl(tlsConf *tls.Config, conn net.PacketConn) error {
 				if qconn.GetQUICVersion() == protocol.VersionDraft34 {
 					proto = nextProtoH3Draft34
 				}
+				if qconn.GetQUICVersion() == protocol.Version1 {
+					proto = nextProtoH3
+				}
 			}
 			config := tlsConf
 			if tlsConf.GetConfigForClient != nil {
@@ -246,7 +253,8 @@ func (s *Server) handleConn(sess quic.EarlySession) {
 	(&settingsFrame{Datagram: s.EnableDatagrams}).Write(buf)
 	str.Write(buf.Bytes())
 
-	go s.handleUnidirectionalStreams(sess)
+	streams := newRequestStreams()
+	go s.handleUnidirectionalStreams(sess, streams)
 
 	// Process all requests immediately.
 	// It's the client's responsibility to decide which requests are eligible for 0-RTT.
@@ -256,8 +264,10 @@ func (s *Server) handleConn(sess quic.EarlySession) {
 			s.logger.Debugf("Accepting stream failed: %s", err)
 			return
 		}
+		streams.Add(str)
 		go func() {
-			rerr := s.handleRequest(sess, str, decoder, func() {
+			defer streams.Remove(str.StreamID())
+			rerr := s.handleRequest(sess, str, streams, decoder, func() {
 				sess.CloseWithError(quic.ErrorCode(errorFrameUnexpected), "")
 			})
 			if rerr.err != nil || rerr.streamErr != 0 || rerr.connErr != 0 {
@@ -279,7 +289,7 @@ func (s *Server) handleConn(sess quic.EarlySession) {
 	}
 }
 
-func (s *Server) handleUnidirectionalStreams(sess quic.EarlySession) {
+func (s *Server) handleUnidirectionalStreams(sess quic.EarlySession, streams *requestStreams) {
 	for {
 		str, err := sess.AcceptUniStream(context.Background())
 		if err != nil {
@@ -317,19 +327,44 @@ func (s *Server) handleUnidirectionalStreams(sess quic.EarlySession) {
 				sess.CloseWithError(quic.ErrorCode(errorMissingSettings), "")
 				return
 			}
-			if !sf.Datagram {
-				return
-			}
 			// If datagram support was enabled on our side as well as on the client side,
 			// we can expect it to have been negotiated both on the transport and on the HTTP/3 layer.
 			// Note: ConnectionState() will block until the handshake is complete (relevant when using 0-RTT).
-			if s.EnableDatagrams && !sess.ConnectionState().SupportsDatagrams {
+			if sf.Datagram && s.EnableDatagrams && !sess.ConnectionState().SupportsDatagrams {
 				sess.CloseWithError(quic.ErrorCode(errorSettingsError), "missing QUIC Datagram support")
+				return
 			}
+			s.handleControlStream(sess, str, streams)
 		}(str)
 	}
 }
 
+// handleControlStream handles the frames sent on the control stream after the SETTINGS frame.
+func (s *Server) handleControlStream(sess quic.EarlySession, str quic.ReceiveStream, streams *requestStreams) {
+	for {
+		f, err := parseNextFrame(str)
+		if err != nil {
+			if err != io.EOF {
+				sess.CloseWithError(quic.ErrorCode(errorFrameError), "")
+			}
+			return
+		}
+		switch f := f.(type) {
+		case *settingsFrame, *dataFrame, *headersFrame:
+			sess.CloseWithError(quic.ErrorCode(errorFrameUnexpected), "")
+			return
+		case *priorityUpdateFrame:
+			id := quic.StreamID(f.StreamID)
+			// Only client-initiated bidirectional streams can be prioritized.
+			if id.Type() != protocol.StreamTypeBidi || id.InitiatedBy() != protocol.PerspectiveClient {
+				sess.CloseWithError(quic.ErrorCode(errorIDError), fmt.Sprintf("invalid stream ID in PRIORITY_UPDATE frame: %d", id))
+				return
+			}
+			streams.UpdatePriority(id, parsePriority(f.PriorityValue))
+		}
+	}
+}
+
 func (s *Server) maxHeaderBytes() uint64 {
 	if s.Server.MaxHeaderBytes <= 0 {
 		return http.DefaultMaxHeaderBytes
@@ -337,7 +372,7 @@ func (s *Server) maxHeaderBytes() uint64 {
 	return uint64(s.Server.MaxHeaderBytes)
 }
 
-func (s *Server) handleRequest(sess quic.Session, str quic.Stream, decoder *qpack.Decoder, onFrameError func()) requestError {
+func (s *Server) handleRequest(sess quic.Session, str quic.Stream, streams *requestStreams, decoder *qpack.Decoder, onFrameError func()) requestError {
 	frame, err := parseNextFrame(str)
 	if err != nil {
 		return newStreamError(errorRequestIncomplete, err)
@@ -364,6 +399,10 @@ func (s *Server) handleRequest(sess quic.Session, str quic.Stream, decoder *qpac
 		return newStreamError(errorGeneralProtocolError, err)
 	}
 
+	if prio, ok := req.Header["Priority"]; ok {
+		streams.SetHeaderPriority(str.StreamID(), parsePriority(strings.Join(prio, ",")))
+	}
+
 	req.RemoteAddr = sess.RemoteAddr().String()
 	req.Body = newRequestBody(str, onFrameError)
 
@@ -138,7 +138,7 @@ var _ = Describe("Server", func() {
 			}).AnyTimes()
 			str.EXPECT().CancelRead(gomock.Any())
 
-			Expect(s.handleRequest(sess, str, qpackDecoder, nil)).To(Equal(requestError{}))
+			Expect(s.handleRequest(sess, str, newRequestStreams(), qpackDecoder, nil)).To(Equal(requestError{}))
 			var req *http.Request
 			Eventually(requestChan).Should(Receive(&req))
 			Expect(req.Host).To(Equal("www.example.com"))
@@ -155,7 +155,7 @@ var _ = Describe("Server", func() {
 			str.EXPECT().Write(gomock.Any()).DoAndReturn(responseBuf.Write).AnyTimes()
 			str.EXPECT().CancelRead(gomock.Any())
 
-			serr := s.handleRequest(sess, str, qpackDecoder, nil)
+			serr := s.handleRequest(sess, str, newRequestStreams(), qpackDecoder, nil)
 			Expect(serr.err).ToNot(HaveOccurred())
 			hfs := decodeHeader(responseBuf)
 			Expect(hfs).To(HaveKeyWithValue(":status", []string{"200"}))
@@ -172,12 +172,29 @@ var _ = Describe("Server", func() {
 			str.EXPECT().Write(gomock.Any()).DoAndReturn(responseBuf.Write).AnyTimes()
 			str.EXPECT().CancelRead(gomock.Any())
 
-			serr := s.handleRequest(sess, str, qpackDecoder, nil)
+			serr := s.handleRequest(sess, str, newRequestStreams(), qpackDecoder, nil)
 			Expect(serr.err).ToNot(HaveOccurred())
 			hfs := decodeHeader(responseBuf)
 			Expect(hfs).To(HaveKeyWithValue(":status", []string{"500"}))
 		})
 
+		It("sets the priority from the Priority header", func() {
+			s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			exampleGetRequest.Header.Set("Priority", "u=0")
+			setRequest(encodeRequest(exampleGetRequest))
+			str.EXPECT().StreamID().Return(quic.StreamID(4)).AnyTimes()
+			str.EXPECT().Context().Return(reqContext)
+			str.EXPECT().Write(gomock.Any()).DoAndReturn(func(p []byte) (int, error) {
+				return len(p), nil
+			}).AnyTimes()
+			str.EXPECT().CancelRead(gomock.Any())
+			str.EXPECT().SetPriority(quic.Priority{Urgency: 0})
+
+			streams := newRequestStreams()
+			streams.Add(str)
+			Expect(s.handleRequest(sess, str, streams, qpackDecoder, nil)).To(Equal(requestError{}))
+		})
+
 		It("doesn't close the stream if the handler called DataStream()", func() {
 			s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				str := w.(DataStreamer).DataStream()
@@ -189,7 +206,7 @@ var _ = Describe("Server", func() {
 			str.EXPECT().Write([]byte("foobar"))
 			// don't EXPECT CancelRead()
 
-			serr := s.handleRequest(sess, str, qpackDecoder, nil)
+			serr := s.handleRequest(sess, str, newRequestStreams(), qpackDecoder, nil)
 			Expect(serr.err).ToNot(HaveOccurred())
 		})
 
@@ -320,6 +337,30 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("errors when the client sends a second SETTINGS frame", func() {
+				buf := &bytes.Buffer{}
+				quicvarint.Write(buf, streamTypeControlStream)
+				(&settingsFrame{}).Write(buf)
+				(&settingsFrame{}).Write(buf)
+				controlStr := mockquic.NewMockStream(mockCtrl)
+				controlStr.EXPECT().Read(gomock.Any()).DoAndReturn(buf.Read).AnyTimes()
+				sess.EXPECT().AcceptUniStream(gomock.Any()).DoAndReturn(func(context.Context) (quic.ReceiveStream, error) {
+					return controlStr, nil
+				})
+				sess.EXPECT().AcceptUniStream(gomock.Any()).DoAndReturn(func(context.Context) (quic.ReceiveStream, error) {
+					<-testDone
+					return nil, errors.New("test done")
+				})
+				done := make(chan struct{})
+				sess.EXPECT().CloseWithError(gomock.Any(), gomock.Any()).Do(func(code quic.ErrorCode, _ string) {
+					defer GinkgoRecover()
+					Expect(code).To(BeEquivalentTo(errorFrameUnexpected))
+					close(done)
+				})
+				s.handleConn(sess)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("errors when the client opens a push stream", func() {
 				buf := &bytes.Buffer{}
 				quicvarint.Write(buf, streamTypePushStream)
@@ -370,6 +411,36 @@ var _ = Describe("Server", func() {
 			})
 		})
 
+		Context("handling the control stream", func() {
+			var sess *mockquic.MockEarlySession
+
+			BeforeEach(func() {
+				sess = mockquic.NewMockEarlySession(mockCtrl)
+			})
+
+			It("applies PRIORITY_UPDATE frames", func() {
+				buf := &bytes.Buffer{}
+				(&priorityUpdateFrame{StreamID: 4, PriorityValue: "u=6, i"}).Write(buf)
+				controlStr := mockquic.NewMockStream(mockCtrl)
+				controlStr.EXPECT().Read(gomock.Any()).DoAndReturn(buf.Read).AnyTimes()
+				str := mockquic.NewMockStream(mockCtrl)
+				str.EXPECT().StreamID().Return(quic.StreamID(4)).AnyTimes()
+				str.EXPECT().SetPriority(quic.Priority{Urgency: 6, Incremental: true})
+				streams := newRequestStreams()
+				streams.Add(str)
+				s.handleControlStream(sess, controlStr, streams)
+			})
+
+			It("errors when a PRIORITY_UPDATE frame references an invalid stream", func() {
+				buf := &bytes.Buffer{}
+				(&priorityUpdateFrame{StreamID: 3, PriorityValue: "u=6"}).Write(buf)
+				controlStr := mockquic.NewMockStream(mockCtrl)
+				controlStr.EXPECT().Read(gomock.Any()).DoAndReturn(buf.Read).AnyTimes()
+				sess.EXPECT().CloseWithError(quic.ErrorCode(errorIDError), gomock.Any())
+				s.handleControlStream(sess, controlStr, newRequestStreams())
+			})
+		})
+
 		Context("stream- and connection-level errors", func() {
 			var sess *mockquic.MockEarlySession
 			testDone := make(chan struct{})
@@ -387,6 +458,7 @@ var _ = Describe("Server", func() {
 				})
 				sess.EXPECT().AcceptStream(gomock.Any()).Return(str, nil)
 				sess.EXPECT().AcceptStream(gomock.Any()).Return(nil, errors.New("done"))
+				str.EXPECT().StreamID().AnyTimes()
 				sess.EXPECT().RemoteAddr().Return(addr).AnyTimes()
 				sess.EXPECT().LocalAddr().AnyTimes()
 			})
@@ -515,7 +587,7 @@ var _ = Describe("Server", func() {
 			}).AnyTimes()
 			str.EXPECT().CancelRead(quic.ErrorCode(errorNoError))
 
-			serr := s.handleRequest(sess, str, qpackDecoder, nil)
+			serr := s.handleRequest(sess, str, newRequestStreams(), qpackDecoder, nil)
 			Expect(serr.err).ToNot(HaveOccurred())
 			Eventually(handlerCalled).Should(BeClosed())
 		})
@@ -538,7 +610,7 @@ var _ = Describe("Server", func() {
 			}).AnyTimes()
 			str.EXPECT().CancelRead(quic.ErrorCode(errorNoError))
 
-			serr := s.handleRequest(sess, str, qpackDecoder, nil)
+			serr := s.handleRequest(sess, str, newRequestStreams(), qpackDecoder, nil)
 			Expect(serr.err).ToNot(HaveOccurred())
 			Eventually(handlerCalled).Should(BeClosed())
 		})
@@ -591,6 +663,14 @@ var _ = Describe("Server", func() {
 			Expect(s.SetQuicHeaders(hdr)).To(Succeed())
 			Expect(hdr).To(Equal(http.Header{"Alt-Svc": {`h3-32=":443"; ma=2592000,h3-29=":443"; ma=2592000`}}))
 		})
+
+		It("uses the h3 ALPN for QUIC v1", func() {
+			s.Server.Addr = ":443"
+			s.QuicConfig.Versions = []quic.VersionNumber{quic.Version1, quic.VersionDraft29}
+			hdr := http.Header{}
+			Expect(s.SetQuicHeaders(hdr)).To(Succeed())
+			Expect(hdr).To(Equal(http.Header{"Alt-Svc": {`h3=":443"; ma=2592000,h3-29=":443"; ma=2592000`}}))
+		})
 	})
 
 	It("errors when ListenAndServe is called with s.Server nil", func() {
@@ -712,6 +792,9 @@ var _ = Describe("Server", func() {
 			c, err = conf.GetConfigForClient(&tls.ClientHelloInfo{Conn: newMockConn(protocol.VersionDraft32)})
 			ExpectWithOffset(1, err).ToNot(HaveOccurred())
 			ExpectWithOffset(1, c.NextProtos).To(Equal([]string{nextProtoH3Draft32}))
+			c, err = conf.GetConfigForClient(&tls.ClientHelloInfo{Conn: newMockConn(protocol.Version1)})
+			ExpectWithOffset(1, err).ToNot(HaveOccurred())
+			ExpectWithOffset(1, c.NextProtos).To(Equal([]string{nextProtoH3}))
 		}
 
 		It("uses the quic.Config to start the QUIC server", func() {
@@ -0,0 +1,73 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+
+	"github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK frequency", func() {
+	// transfer sends data from the server to the client,
+	// and returns the number of ACK_FREQUENCY frames sent by the server
+	transfer := func(serverEnableAckFrequency, clientEnableAckFrequency bool) (numAckFrequencyFrames int) {
+		serverTracer := newPacketTracer()
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				EnableAckFrequency: serverEnableAckFrequency,
+				Tracer:             newTracer(func() logging.ConnectionTracer { return serverTracer }),
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.OpenUniStreamSync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableAckFrequency: clientEnableAckFrequency}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+		for _, p := range serverTracer.getSentPackets() {
+			for _, f := range p.frames {
+				if _, ok := f.(*logging.AckFrequencyFrame); ok {
+					numAckFrequencyFrames++
+				}
+			}
+		}
+		return
+	}
+
+	It("requests a lower ack frequency when both peers enable the extension", func() {
+		Expect(transfer(true, true)).ToNot(BeZero())
+	})
+
+	It("doesn't use the extension if the peer doesn't support it", func() {
+		Expect(transfer(true, false)).To(BeZero())
+	})
+})
@@ -1,14 +1,18 @@
 package self_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"math/rand"
 	"net"
+	"sync/atomic"
 
 	quic "github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quiclb"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -87,4 +91,46 @@ var _ = Describe("Connection ID lengths tests", func() {
 		defer ln.Close()
 		runClient(ln.Addr(), clientConf)
 	})
+
+	It("uses QUIC-LB connection IDs", func() {
+		lbConf := &quiclb.Config{
+			Mode:        quiclb.ModeStreamCipher,
+			ServerIDLen: 2,
+			NonceLen:    8,
+			Key:         bytes.Repeat([]byte{0x42}, 16),
+		}
+		serverID := []byte{0xca, 0xfe}
+		generator, err := quiclb.NewGenerator(lbConf, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		decoder, err := quiclb.NewDecoder(lbConf)
+		Expect(err).ToNot(HaveOccurred())
+		serverConf := getQuicConfig(&quic.Config{
+			ConnectionIDGenerator: generator,
+			Versions:              []protocol.VersionNumber{protocol.VersionTLS},
+		})
+		ln := runServer(serverConf)
+		defer ln.Close()
+
+		var numShortHeaderPackets, numUnroutable int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DropPacket: func(dir quicproxy.Direction, b []byte) bool {
+				// check the connection ID of all 1-RTT packets sent by the client
+				if dir != quicproxy.DirectionIncoming || b[0]&0x80 > 0 {
+					return false
+				}
+				atomic.AddInt32(&numShortHeaderPackets, 1)
+				if id, err := decoder.ServerID(b[1:]); err != nil || !bytes.Equal(id, serverID) {
+					atomic.AddInt32(&numUnroutable, 1)
+				}
+				return false
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		runClient(proxy.LocalAddr(), getQuicConfig(&quic.Config{Versions: []protocol.VersionNumber{protocol.VersionTLS}}))
+		Expect(atomic.LoadInt32(&numShortHeaderPackets)).ToNot(BeZero())
+		Expect(atomic.LoadInt32(&numUnroutable)).To(BeZero())
+	})
 })
@@ -139,3 +139,144 @@ var _ = Describe("Datagram test", func() {
 		})
 	}
 })
+
+var _ = Describe("Datagram send queue", func() {
+	It("reports the outcome of datagrams", func() {
+		const num = 100
+
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true, DatagramSendQueueLen: num}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		// drop 10% of Short Header packets sent from the server
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+				return 5 * time.Millisecond
+			},
+			DropPacket: func(dir quicproxy.Direction, packet []byte) bool {
+				if dir == quicproxy.DirectionIncoming || packet[0]&0x80 > 0 {
+					return false
+				}
+				return mrand.Int()%10 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		var acked, lost, dropped int32
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.MaxDatagramSize()).To(BeNumerically(">", 1000))
+			for i := 0; i < num; i++ {
+				b := make([]byte, 8)
+				binary.BigEndian.PutUint64(b, uint64(i))
+				Expect(sess.SendDatagram(b, &quic.DatagramSendOptions{
+					OnOutcome: func(o quic.DatagramOutcome) {
+						switch o {
+						case quic.DatagramAcked:
+							atomic.AddInt32(&acked, 1)
+						case quic.DatagramLost:
+							atomic.AddInt32(&lost, 1)
+						case quic.DatagramDropped:
+							atomic.AddInt32(&dropped, 1)
+						}
+					},
+				})).To(Succeed())
+				time.Sleep(time.Millisecond)
+			}
+		}()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		var received int32
+		go func() {
+			for {
+				if _, err := sess.ReceiveMessage(); err != nil {
+					return
+				}
+				atomic.AddInt32(&received, 1)
+			}
+		}()
+
+		Eventually(func() int32 {
+			return atomic.LoadInt32(&acked) + atomic.LoadInt32(&lost)
+		}, 5*time.Second).Should(BeEquivalentTo(num))
+		Expect(atomic.LoadInt32(&dropped)).To(BeZero())
+		fmt.Fprintf(GinkgoWriter, "%d datagrams acknowledged, %d lost, %d received.\n", atomic.LoadInt32(&acked), atomic.LoadInt32(&lost), atomic.LoadInt32(&received))
+		Expect(atomic.LoadInt32(&acked)).To(BeNumerically("<=", atomic.LoadInt32(&received)))
+		Expect(atomic.LoadInt32(&acked)).To(BeNumerically(">", num*7/10))
+		sess.CloseWithError(0, "")
+	})
+
+	It("reports an outcome for every datagram when the session is closed", func() {
+		const num = 50
+
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true, DatagramSendQueueLen: num}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		// delay packets, such that datagrams are still in flight when the session is closed
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+				return 25 * time.Millisecond
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		var acked, lost, dropped int32
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < num; i++ {
+				Expect(sess.SendDatagram(make([]byte, 8), &quic.DatagramSendOptions{
+					OnOutcome: func(o quic.DatagramOutcome) {
+						switch o {
+						case quic.DatagramAcked:
+							atomic.AddInt32(&acked, 1)
+						case quic.DatagramLost:
+							atomic.AddInt32(&lost, 1)
+						case quic.DatagramDropped:
+							atomic.AddInt32(&dropped, 1)
+						}
+					},
+				})).To(Succeed())
+			}
+			time.Sleep(5 * time.Millisecond)
+			sess.CloseWithError(0, "")
+		}()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableDatagrams: true}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+
+		Eventually(func() int32 {
+			return atomic.LoadInt32(&acked) + atomic.LoadInt32(&lost) + atomic.LoadInt32(&dropped)
+		}, 5*time.Second).Should(BeEquivalentTo(num))
+		Expect(atomic.LoadInt32(&lost)).ToNot(BeZero())
+		Consistently(func() int32 {
+			return atomic.LoadInt32(&acked) + atomic.LoadInt32(&lost) + atomic.LoadInt32(&dropped)
+		}, 100*time.Millisecond).Should(BeEquivalentTo(num))
+	})
+})
@@ -0,0 +1,206 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Flow control auto-tuning", func() {
+	const rtt = 10 * time.Millisecond
+
+	It("asks before increasing the connection flow control window", func() {
+		const maxIncrease = 1 << 20
+		var mutex sync.Mutex
+		var increased uint64
+		var sessions []quic.Session
+		var denied bool
+		ln, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				AllowConnectionWindowIncrease: func(sess quic.Session, delta uint64) bool {
+					mutex.Lock()
+					defer mutex.Unlock()
+					sessions = append(sessions, sess)
+					if increased+delta > maxIncrease {
+						denied = true
+						return false
+					}
+					increased += delta
+					return true
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		serverSessChan := make(chan quic.Session, 1)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			sess, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverSessChan <- sess
+			str, err := sess.AcceptUniStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := ioutil.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRDataLong))
+		}()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		str, err := sess.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRDataLong)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+
+		var serverSess quic.Session
+		Eventually(serverSessChan).Should(Receive(&serverSess))
+		Eventually(done, 10*time.Second).Should(BeClosed())
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(sessions).ToNot(BeEmpty())
+		for _, s := range sessions {
+			Expect(s).To(BeIdenticalTo(serverSess))
+		}
+		Expect(increased).To(BeNumerically("<=", maxIncrease))
+		Expect(denied).To(BeTrue())
+	})
+
+	It("limits the memory used for buffering data using a MemoryBudget", func() {
+		const maxIncrease = 1 << 20
+		// the budget allows the initial window, the crypto buffers and an increase of up to 1 MB
+		budget := quic.NewMemoryBudget(512<<10+48<<10+maxIncrease, 0)
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{MemoryBudget: budget}))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			sess, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(budget.Stats().Sessions).To(Equal(1))
+			str, err := sess.AcceptUniStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := ioutil.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRDataLong))
+		}()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRDataLong)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		Eventually(done, 10*time.Second).Should(BeClosed())
+
+		stats := budget.Stats()
+		Expect(stats.Reserved).To(BeNumerically("<=", stats.Total))
+		Expect(stats.DeniedWindowIncreases).ToNot(BeZero())
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+		Eventually(func() int { return budget.Stats().Sessions }).Should(BeZero())
+		Expect(budget.Stats().Reserved).To(BeZero())
+	})
+	It("doesn't reserve memory for window increases denied by AllowConnectionWindowIncrease", func() {
+		budget := quic.NewMemoryBudget(1<<30, 0)
+		var askedForIncrease int32
+		ln, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				MemoryBudget: budget,
+				AllowConnectionWindowIncrease: func(quic.Session, uint64) bool {
+					atomic.StoreInt32(&askedForIncrease, 1)
+					return false
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+
+		reservedChan := make(chan uint64, 1)
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			sess, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			reservedChan <- budget.Stats().Reserved
+			str, err := sess.AcceptUniStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			data, err := ioutil.ReadAll(str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(Equal(PRDataLong))
+		}()
+
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		str, err := sess.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRDataLong)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		Eventually(done, 10*time.Second).Should(BeClosed())
+
+		var reserved uint64
+		Eventually(reservedChan).Should(Receive(&reserved))
+		Expect(atomic.LoadInt32(&askedForIncrease)).To(BeEquivalentTo(1))
+		stats := budget.Stats()
+		Expect(stats.Reserved).To(Equal(reserved))
+		Expect(stats.DeniedWindowIncreases).To(BeZero())
+	})
+})
@@ -2,6 +2,7 @@ package self_test
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"github.com/lucas-clemente/quic-go/integrationtests/tools/israce"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/logging"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -46,6 +48,24 @@ func (c *tokenStore) Pop(key string) *quic.ClientToken {
 	return c.store.Pop(key)
 }
 
+type negotiatedVersion struct {
+	chosen                         logging.VersionNumber
+	clientVersions, serverVersions []logging.VersionNumber
+}
+
+type versionNegotiationTracer struct {
+	connTracer
+	negotiated chan negotiatedVersion
+}
+
+func newVersionNegotiationTracer() *versionNegotiationTracer {
+	return &versionNegotiationTracer{negotiated: make(chan negotiatedVersion, 1)}
+}
+
+func (t *versionNegotiationTracer) NegotiatedVersion(chosen logging.VersionNumber, clientVersions, serverVersions []logging.VersionNumber) {
+	t.negotiated <- negotiatedVersion{chosen: chosen, clientVersions: clientVersions, serverVersions: serverVersions}
+}
+
 var _ = Describe("Handshake tests", func() {
 	var (
 		server        quic.Listener
@@ -132,6 +152,97 @@ var _ = Describe("Handshake tests", func() {
 		})
 	}
 
+	Context("compatible version negotiation", func() {
+		It("switches to the version preferred by the server", func() {
+			serverConfig.Versions = []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34}
+			runServer(getTLSConfig())
+			defer server.Close()
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{
+					Versions: []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.(versioner).GetVersion()).To(Equal(protocol.Version1))
+			str, err := sess.OpenStreamSync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+		})
+
+		It("keeps the version if the server doesn't prefer a compatible version", func() {
+			serverConfig.Versions = []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1}
+			runServer(getTLSConfig())
+			defer server.Close()
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{
+					Versions: []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.(versioner).GetVersion()).To(Equal(protocol.VersionDraft34))
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+		})
+
+		It("uses the negotiated version on both sides", func() {
+			serverTracer := newVersionNegotiationTracer()
+			serverConfig.Versions = []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34}
+			serverConfig.Tracer = newTracer(func() logging.ConnectionTracer { return serverTracer })
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), serverConfig)
+			Expect(err).ToNot(HaveOccurred())
+			defer ln.Close()
+
+			serverSessChan := make(chan quic.Session, 1)
+			go func() {
+				defer GinkgoRecover()
+				sess, err := ln.Accept(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				str, err := sess.AcceptStream(context.Background())
+				Expect(err).ToNot(HaveOccurred())
+				data, err := ioutil.ReadAll(str)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal(PRData))
+				serverSessChan <- sess
+			}()
+
+			clientTracer := newVersionNegotiationTracer()
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				getQuicConfig(&quic.Config{
+					Versions: []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+					Tracer:   newTracer(func() logging.ConnectionTracer { return clientTracer }),
+				}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer sess.CloseWithError(0, "")
+			str, err := sess.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+
+			var serverSess quic.Session
+			Eventually(serverSessChan, 5*time.Second).Should(Receive(&serverSess))
+			Expect(sess.(versioner).GetVersion()).To(Equal(protocol.Version1))
+			Expect(serverSess.(versioner).GetVersion()).To(Equal(protocol.Version1))
+			Expect(serverTracer.negotiated).To(Receive(Equal(negotiatedVersion{
+				chosen:         protocol.Version1,
+				clientVersions: []logging.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+				serverVersions: []logging.VersionNumber{protocol.Version1, protocol.VersionDraft34},
+			})))
+			Expect(clientTracer.negotiated).To(Receive(Equal(negotiatedVersion{
+				chosen:         protocol.Version1,
+				clientVersions: []logging.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+			})))
+		})
+	})
+
 	Context("using different cipher suites", func() {
 		for n, id := range map[string]uint16{
 			"TLS_AES_128_GCM_SHA256":       tls.TLS_AES_128_GCM_SHA256,
@@ -464,6 +575,60 @@ var _ = Describe("Handshake tests", func() {
 			Eventually(done).Should(BeClosed())
 		})
 
+		It("accepts tokens issued by a different server using the same token key", func() {
+			var oldKey, newKey quic.TokenKey
+			rand.Read(oldKey[:])
+			rand.Read(newKey[:])
+			tokenChan := make(chan *quic.Token, 100)
+			serverConfig.AcceptToken = func(addr net.Addr, token *quic.Token) bool {
+				if token != nil && !token.IsRetryToken {
+					tokenChan <- token
+				}
+				return true
+			}
+
+			runServer := func(keys []quic.TokenKey) quic.Listener {
+				conf := serverConfig.Clone()
+				conf.TokenKeys = keys
+				server, err := quic.ListenAddr("localhost:0", getTLSConfig(), conf)
+				Expect(err).ToNot(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					for {
+						if _, err := server.Accept(context.Background()); err != nil {
+							return
+						}
+					}
+				}()
+				return server
+			}
+
+			puts := make(chan string, 100)
+			quicConf := getQuicConfig(&quic.Config{TokenStore: newTokenStore(make(chan string, 100), puts)})
+			server1 := runServer([]quic.TokenKey{oldKey})
+			defer server1.Close()
+			sess, err := quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server1.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				quicConf,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Eventually(puts).Should(Receive())
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+			// The second server already uses a new key, but still accepts tokens protected with the old key.
+			server2 := runServer([]quic.TokenKey{newKey, oldKey})
+			defer server2.Close()
+			sess, err = quic.DialAddr(
+				fmt.Sprintf("localhost:%d", server2.Addr().(*net.UDPAddr).Port),
+				getTLSClientConfig(),
+				quicConf,
+			)
+			Expect(err).ToNot(HaveOccurred())
+			defer sess.CloseWithError(0, "")
+			Expect(tokenChan).To(Receive())
+		})
+
 		It("rejects invalid Retry token with the INVALID_TOKEN error", func() {
 			tokenChan := make(chan *quic.Token, 10)
 			serverConfig.AcceptToken = func(addr net.Addr, token *quic.Token) bool {
@@ -7,7 +7,6 @@ import (
 	"net"
 
 	quic "github.com/lucas-clemente/quic-go"
-	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/logging"
 
@@ -59,9 +58,9 @@ func (t *keyUpdateConnTracer) ReceivedPacket(hdr *logging.ExtendedHeader, size l
 var _ = Describe("Key Update tests", func() {
 	var server quic.Listener
 
-	runServer := func() {
+	runServer := func(conf *quic.Config) {
 		var err error
-		server, err = quic.ListenAddr("localhost:0", getTLSConfig(), nil)
+		server, err = quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(conf))
 		Expect(err).ToNot(HaveOccurred())
 
 		go func() {
@@ -76,16 +75,25 @@ var _ = Describe("Key Update tests", func() {
 		}()
 	}
 
-	It("downloads a large file", func() {
-		origKeyUpdateInterval := handshake.KeyUpdateInterval
-		defer func() { handshake.KeyUpdateInterval = origKeyUpdateInterval }()
-		handshake.KeyUpdateInterval = 1 // update keys as frequently as possible
+	BeforeEach(func() {
+		sentHeaders = nil
+		receivedHeaders = nil
+	})
+
+	AfterEach(func() {
+		Expect(server.Close()).To(Succeed())
+	})
 
-		runServer()
+	It("downloads a large file", func() {
+		// update keys as frequently as possible
+		runServer(&quic.Config{KeyUpdateInterval: 1})
 		sess, err := quic.DialAddr(
 			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
 			getTLSClientConfig(),
-			getQuicConfig(&quic.Config{Tracer: newTracer(func() logging.ConnectionTracer { return &keyUpdateConnTracer{} })}),
+			getQuicConfig(&quic.Config{
+				KeyUpdateInterval: 1,
+				Tracer:            newTracer(func() logging.ConnectionTracer { return &keyUpdateConnTracer{} }),
+			}),
 		)
 		Expect(err).ToNot(HaveOccurred())
 		str, err := sess.AcceptUniStream(context.Background())
@@ -100,4 +108,26 @@ var _ = Describe("Key Update tests", func() {
 		Expect(keyPhasesReceived).To(BeNumerically(">", 10))
 		Expect(keyPhasesReceived).To(BeNumerically("~", keyPhasesSent, 2))
 	})
+
+	It("forces a key update", func() {
+		runServer(nil)
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{Tracer: newTracer(func() logging.ConnectionTracer { return &keyUpdateConnTracer{} })}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		sess.ForceKeyUpdate()
+		str, err := sess.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRDataLong))
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+		// The first 1-RTT packet counts as a key phase change as well.
+		keyPhasesSent, keyPhasesReceived := countKeyPhases()
+		Expect(keyPhasesSent).To(Equal(2))
+		Expect(keyPhasesReceived).To(Equal(2))
+	})
 })
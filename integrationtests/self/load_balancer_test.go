@@ -0,0 +1,134 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	"github.com/lucas-clemente/quic-go/quiclb"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load Balancer", func() {
+	const numBackends = 3
+
+	lbConf := &quiclb.Config{
+		Mode:           quiclb.ModeBlockCipher,
+		ServerIDLen:    1,
+		ZeroPaddingLen: 4,
+		Key:            bytes.Repeat([]byte{0x42}, 16),
+	}
+
+	var (
+		backends []quic.Listener
+		// receives the index of the backend for every session accepted
+		acceptedSessions chan int
+		proxy            *quiclb.Proxy
+	)
+
+	BeforeEach(func() {
+		acceptedSessions = make(chan int, 100)
+		decoder, err := quiclb.NewDecoder(lbConf)
+		Expect(err).ToNot(HaveOccurred())
+		router := quiclb.NewRouter(decoder)
+		backends = nil
+		for i := 0; i < numBackends; i++ {
+			generator, err := quiclb.NewGenerator(lbConf, []byte{byte(i)})
+			Expect(err).ToNot(HaveOccurred())
+			ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(&quic.Config{ConnectionIDGenerator: generator}))
+			Expect(err).ToNot(HaveOccurred())
+			backends = append(backends, ln)
+			Expect(router.AddBackend([]byte{byte(i)}, ln.Addr().(*net.UDPAddr))).To(Succeed())
+
+			backend := i
+			go func() {
+				defer GinkgoRecover()
+				for {
+					sess, err := ln.Accept(context.Background())
+					if err != nil {
+						return
+					}
+					acceptedSessions <- backend
+					go func() {
+						str, err := sess.AcceptStream(context.Background())
+						if err != nil {
+							return
+						}
+						io.Copy(str, str)
+					}()
+				}
+			}()
+		}
+		proxy, err = quiclb.ListenProxy("localhost:0", router)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(proxy.Close()).To(Succeed())
+		for _, ln := range backends {
+			Expect(ln.Close()).To(Succeed())
+		}
+	})
+
+	echo := func(str quic.Stream, data []byte) {
+		_, err := str.Write(data)
+		Expect(err).ToNot(HaveOccurred())
+		b := make([]byte, len(data))
+		_, err = io.ReadFull(str, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(Equal(data))
+	}
+
+	newConn := func() *net.UDPConn {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		return conn
+	}
+
+	It("distributes connections between the backends", func() {
+		const numConns = 20
+		for i := 0; i < numConns; i++ {
+			sess, err := quic.DialAddr(fmt.Sprintf("localhost:%d", proxy.LocalAddr().(*net.UDPAddr).Port), getTLSClientConfig(), getQuicConfig(nil))
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			echo(str, PRData)
+			Expect(sess.CloseWithError(0, "")).To(Succeed())
+		}
+		counts := make(map[int]int)
+		for i := 0; i < numConns; i++ {
+			var backend int
+			Eventually(acceptedSessions).Should(Receive(&backend))
+			counts[backend]++
+		}
+		Expect(len(counts)).To(BeNumerically(">", 1))
+		Consistently(acceptedSessions, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("routes packets to the same backend after the client migrated", func() {
+		conn1 := newConn()
+		defer conn1.Close()
+		conn2 := newConn()
+		defer conn2.Close()
+
+		sess, err := quic.Dial(conn1, proxy.LocalAddr(), "localhost", getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		str, err := sess.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		echo(str, []byte("before migration"))
+		Eventually(acceptedSessions).Should(Receive())
+
+		// wait until the server issued new connection IDs, and the handshake is confirmed
+		Eventually(func() error { return sess.MigrateTo(conn2) }, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+		Expect(sess.LocalAddr()).To(Equal(conn2.LocalAddr()))
+		echo(str, []byte("after migration"))
+		Consistently(acceptedSessions, 50*time.Millisecond).ShouldNot(Receive())
+	})
+})
@@ -0,0 +1,121 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection Migration", func() {
+	var server quic.Listener
+
+	runServer := func(conf *quic.Config) <-chan quic.Session {
+		var err error
+		server, err = quic.ListenAddr("localhost:0", getTLSConfig(), conf)
+		Expect(err).ToNot(HaveOccurred())
+		sessChan := make(chan quic.Session, 1)
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			if err != nil {
+				return
+			}
+			sessChan <- sess
+			str, err := sess.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			io.Copy(str, str)
+		}()
+		return sessChan
+	}
+
+	AfterEach(func() {
+		Expect(server.Close()).To(Succeed())
+	})
+
+	dial := func(conn net.PacketConn) (quic.Session, quic.Stream) {
+		serverAddr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port))
+		Expect(err).ToNot(HaveOccurred())
+		sess, err := quic.Dial(conn, serverAddr, "localhost", getTLSClientConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		return sess, str
+	}
+
+	echo := func(str quic.Stream, data []byte) {
+		_, err := str.Write(data)
+		Expect(err).ToNot(HaveOccurred())
+		b := make([]byte, len(data))
+		_, err = io.ReadFull(str, b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b).To(Equal(data))
+	}
+
+	newConn := func() *net.UDPConn {
+		addr, err := net.ResolveUDPAddr("udp", "localhost:0")
+		Expect(err).ToNot(HaveOccurred())
+		conn, err := net.ListenUDP("udp", addr)
+		Expect(err).ToNot(HaveOccurred())
+		return conn
+	}
+
+	It("migrates to a new local address", func() {
+		runServer(getQuicConfig(nil))
+		conn1 := newConn()
+		defer conn1.Close()
+		conn2 := newConn()
+		defer conn2.Close()
+
+		sess, str := dial(conn1)
+		defer sess.CloseWithError(0, "")
+		echo(str, []byte("before migration"))
+		// wait until the server issued new connection IDs, and the handshake is confirmed
+		Eventually(func() error { return sess.MigrateTo(conn2) }, 5*time.Second, 50*time.Millisecond).Should(Succeed())
+		Expect(sess.LocalAddr()).To(Equal(conn2.LocalAddr()))
+		// The old path isn't used any more.
+		Expect(conn1.Close()).To(Succeed())
+		echo(str, PRData)
+	})
+
+	It("doesn't migrate if the server disabled active migration", func() {
+		runServer(getQuicConfig(&quic.Config{DisableActiveMigration: true}))
+		conn1 := newConn()
+		defer conn1.Close()
+		conn2 := newConn()
+		defer conn2.Close()
+
+		sess, str := dial(conn1)
+		defer sess.CloseWithError(0, "")
+		echo(str, []byte("foobar"))
+		Expect(sess.MigrateTo(conn2)).To(MatchError("the server disabled active migration"))
+		Expect(sess.LocalAddr()).To(Equal(conn1.LocalAddr()))
+		echo(str, []byte("foobar"))
+	})
+
+	It("migrates to the server's preferred address", func() {
+		serverSessChan := runServer(getQuicConfig(&quic.Config{
+			PreferredAddress: &quic.PreferredAddress{IPv4: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}},
+			// doesn't apply to the preferred address
+			DisableActiveMigration: true,
+		}))
+		conn := newConn()
+		defer conn.Close()
+
+		sess, str := dial(conn)
+		defer sess.CloseWithError(0, "")
+		echo(str, []byte("before migration"))
+		var serverSess quic.Session
+		Eventually(serverSessChan).Should(Receive(&serverSess))
+		Eventually(func() int { return sess.RemoteAddr().(*net.UDPAddr).Port }, 5*time.Second).ShouldNot(Equal(server.Addr().(*net.UDPAddr).Port))
+		Expect(sess.RemoteAddr().(*net.UDPAddr).IP.Equal(net.IPv4(127, 0, 0, 1))).To(BeTrue())
+		echo(str, PRData)
+		Expect(serverSess.LocalAddr().(*net.UDPAddr).Port).To(Equal(sess.RemoteAddr().(*net.UDPAddr).Port))
+	})
+})
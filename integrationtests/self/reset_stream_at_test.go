@@ -0,0 +1,92 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RESET_STREAM_AT", func() {
+	const errorCode = 1234
+
+	// transfer sends PRData from the server to the client, and cancels the stream after the data was written.
+	// It returns the data read by the client.
+	transfer := func(serverEnableResetStreamAt, clientEnableResetStreamAt bool, reliableSize uint64) []byte {
+		server, err := quic.ListenAddr(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{EnableResetStreamAt: serverEnableResetStreamAt}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		// drop every 10th packet sent by the server, after the handshake completed
+		var num int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+				return 5 * time.Millisecond
+			},
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				if dir != quicproxy.DirectionOutgoing {
+					return false
+				}
+				n := atomic.AddInt32(&num, 1)
+				return n > 10 && n%10 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.OpenUniStreamSync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			str.CancelWriteAt(errorCode, reliableSize)
+		}()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(&quic.Config{EnableResetStreamAt: clientEnableResetStreamAt}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		str, err := sess.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(str)
+		Expect(err).To(MatchError(fmt.Sprintf("stream %d was reset with error code %d", str.StreamID(), errorCode)))
+		return data
+	}
+
+	It("delivers the data up to the reliable size", func() {
+		reliableSize := len(PRData) / 2
+		data := transfer(true, true, uint64(reliableSize))
+		// data beyond the reliable size might have been received before the RESET_STREAM_AT frame
+		Expect(len(data)).To(BeNumerically(">=", reliableSize))
+		Expect(data).To(Equal(PRData[:len(data)]))
+	})
+
+	It("delivers all data when the reliable size is the size of the stream", func() {
+		data := transfer(true, true, uint64(len(PRData)))
+		Expect(data).To(Equal(PRData))
+	})
+
+	It("resets the stream immediately if the peer doesn't support the extension", func() {
+		data := transfer(true, false, uint64(len(PRData)/2))
+		Expect(data).To(Equal(PRData[:len(data)]))
+	})
+})
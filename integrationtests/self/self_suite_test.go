@@ -333,6 +333,7 @@ func (t *tracer) TracerForConnection(p logging.Perspective, odcid logging.Connec
 func (t *tracer) SentPacket(net.Addr, *logging.Header, logging.ByteCount, []logging.Frame) {}
 func (t *tracer) DroppedPacket(net.Addr, logging.PacketType, logging.ByteCount, logging.PacketDropReason) {
 }
+func (t *tracer) UpdatedHandshakeMetrics(logging.HandshakeMetrics) {}
 
 type connTracer struct{}
 
@@ -347,7 +348,9 @@ func (t *connTracer) RestoredTransportParameters(*logging.TransportParameters) {
 func (t *connTracer) SentPacket(hdr *logging.ExtendedHeader, size logging.ByteCount, ack *logging.AckFrame, frames []logging.Frame) {
 }
 func (t *connTracer) ReceivedVersionNegotiationPacket(*logging.Header, []logging.VersionNumber) {}
-func (t *connTracer) ReceivedRetry(*logging.Header)                                             {}
+func (t *connTracer) NegotiatedVersion(logging.VersionNumber, []logging.VersionNumber, []logging.VersionNumber) {
+}
+func (t *connTracer) ReceivedRetry(*logging.Header) {}
 func (t *connTracer) ReceivedPacket(hdr *logging.ExtendedHeader, size logging.ByteCount, frames []logging.Frame) {
 }
 func (t *connTracer) BufferedPacket(logging.PacketType)                                             {}
@@ -358,17 +361,19 @@ func (t *connTracer) UpdatedMetrics(rttStats *logging.RTTStats, cwnd, bytesInFli
 func (t *connTracer) AcknowledgedPacket(logging.EncryptionLevel, logging.PacketNumber) {}
 func (t *connTracer) LostPacket(logging.EncryptionLevel, logging.PacketNumber, logging.PacketLossReason) {
 }
-func (t *connTracer) UpdatedCongestionState(logging.CongestionState)                     {}
-func (t *connTracer) UpdatedPTOCount(value uint32)                                       {}
-func (t *connTracer) UpdatedKeyFromTLS(logging.EncryptionLevel, logging.Perspective)     {}
-func (t *connTracer) UpdatedKey(generation logging.KeyPhase, remote bool)                {}
-func (t *connTracer) DroppedEncryptionLevel(logging.EncryptionLevel)                     {}
-func (t *connTracer) DroppedKey(logging.KeyPhase)                                        {}
-func (t *connTracer) SetLossTimer(logging.TimerType, logging.EncryptionLevel, time.Time) {}
-func (t *connTracer) LossTimerExpired(logging.TimerType, logging.EncryptionLevel)        {}
-func (t *connTracer) LossTimerCanceled()                                                 {}
-func (t *connTracer) Debug(string, string)                                               {}
-func (t *connTracer) Close()                                                             {}
+func (t *connTracer) UpdatedCongestionState(logging.CongestionState)                           {}
+func (t *connTracer) UpdatedPTOCount(value uint32)                                             {}
+func (t *connTracer) UpdatedKeyFromTLS(logging.EncryptionLevel, logging.Perspective)           {}
+func (t *connTracer) UpdatedKey(generation logging.KeyPhase, remote bool)                      {}
+func (t *connTracer) DroppedEncryptionLevel(logging.EncryptionLevel)                           {}
+func (t *connTracer) DroppedKey(logging.KeyPhase)                                              {}
+func (t *connTracer) ApproachingAEADLimit(logging.AEADLimit, logging.KeyPhase, uint64, uint64) {}
+func (t *connTracer) ReachedAEADLimit(logging.AEADLimit, logging.KeyPhase, uint64)             {}
+func (t *connTracer) SetLossTimer(logging.TimerType, logging.EncryptionLevel, time.Time)       {}
+func (t *connTracer) LossTimerExpired(logging.TimerType, logging.EncryptionLevel)              {}
+func (t *connTracer) LossTimerCanceled()                                                       {}
+func (t *connTracer) Debug(string, string)                                                     {}
+func (t *connTracer) Close()                                                                   {}
 
 type packet struct {
 	time   time.Time
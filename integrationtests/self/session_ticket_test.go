@@ -0,0 +1,211 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+
+	quic "github.com/lucas-clemente/quic-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// sessionTicketStore is a quic.SessionTicketStore that can be serialized,
+// simulating a store that persists session tickets across process restarts.
+type sessionTicketStore struct {
+	mutex   sync.Mutex
+	tickets map[string][]byte
+}
+
+var _ quic.SessionTicketStore = &sessionTicketStore{}
+
+func newSessionTicketStore() *sessionTicketStore {
+	return &sessionTicketStore{tickets: make(map[string][]byte)}
+}
+
+func (s *sessionTicketStore) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	t, ok := s.tickets[key]
+	return t, ok
+}
+
+func (s *sessionTicketStore) Put(key string, ticket []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ticket == nil {
+		delete(s.tickets, key)
+		return
+	}
+	s.tickets[key] = ticket
+}
+
+func (s *sessionTicketStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.tickets)
+}
+
+// Copy creates a new store containing copies of the serialized session tickets.
+func (s *sessionTicketStore) Copy() *sessionTicketStore {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c := newSessionTicketStore()
+	for k, v := range s.tickets {
+		c.tickets[k] = append([]byte{}, v...)
+	}
+	return c
+}
+
+var _ = Describe("Session Tickets", func() {
+	It("uses 0-RTT with session tickets from a SessionTicketStore, and application data", func() {
+		var mutex sync.Mutex
+		appState := []byte("state 1")
+		var receivedAppData [][]byte
+		ln, err := quic.ListenAddrEarly(
+			"localhost:0",
+			getTLSConfig(),
+			getQuicConfig(&quic.Config{
+				GetAppDataForSessionTicket: func() []byte {
+					mutex.Lock()
+					defer mutex.Unlock()
+					return appState
+				},
+				Accept0RTTWithAppData: func(appData []byte) bool {
+					mutex.Lock()
+					defer mutex.Unlock()
+					receivedAppData = append(receivedAppData, appData)
+					return bytes.Equal(appData, appState)
+				},
+			}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		go func() {
+			defer GinkgoRecover()
+			for {
+				sess, err := ln.Accept(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					str, err := sess.AcceptUniStream(context.Background())
+					if err != nil {
+						return
+					}
+					data, err := ioutil.ReadAll(str)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(data).To(Equal(PRData))
+					Expect(sess.ConnectionState().TLS.Used0RTT).To(BeTrue())
+					Expect(sess.CloseWithError(0, "")).To(Succeed())
+				}()
+			}
+		}()
+		addr := fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port)
+
+		// dial the first session in order to receive a session ticket
+		store := newSessionTicketStore()
+		sess, err := quic.DialAddr(addr, getTLSClientConfig(), getQuicConfig(&quic.Config{SessionTicketStore: store}))
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(store.Len).Should(Equal(1))
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+		// Use a copy of the store, and a new tls.Config.
+		// This is equivalent to restoring the session tickets after a restart.
+		store = store.Copy()
+		sess, err = quic.DialAddrEarly(addr, getTLSClientConfig(), getQuicConfig(&quic.Config{SessionTicketStore: store}))
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.OpenUniStream()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = str.Write(PRData)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(str.Close()).To(Succeed())
+		Expect(sess.ConnectionState().TLS.Used0RTT).To(BeTrue())
+		Eventually(sess.Context().Done()).Should(BeClosed())
+		mutex.Lock()
+		Expect(receivedAppData).To(Equal([][]byte{[]byte("state 1")}))
+		// change the application state, so that 0-RTT is rejected
+		appState = []byte("state 2")
+		mutex.Unlock()
+
+		// the server issued a new session ticket on the last session
+		Eventually(store.Len).Should(Equal(1))
+		earlySess, err := quic.DialAddrEarly(addr, getTLSClientConfig(), getQuicConfig(&quic.Config{SessionTicketStore: store.Copy()}))
+		Expect(err).ToNot(HaveOccurred())
+		defer earlySess.CloseWithError(0, "")
+		Eventually(earlySess.HandshakeComplete().Done()).Should(BeClosed())
+		Expect(earlySess.ConnectionState().TLS.DidResume).To(BeTrue())
+		Expect(earlySess.ConnectionState().TLS.Used0RTT).To(BeFalse())
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(receivedAppData).To(HaveLen(2))
+		Expect(receivedAppData[1]).To(Equal([]byte("state 1")))
+	})
+
+	It("rejects replayed 0-RTT, and tells the application if stream data was received in 0-RTT", func() {
+		ln, err := quic.ListenAddrEarly("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		used0RTT := make(chan bool, 3)
+		go func() {
+			defer GinkgoRecover()
+			for {
+				sess, err := ln.Accept(context.Background())
+				if err != nil {
+					return
+				}
+				go func() {
+					defer GinkgoRecover()
+					str, err := sess.AcceptUniStream(context.Background())
+					if err != nil {
+						return
+					}
+					data, err := ioutil.ReadAll(str)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(data).To(Equal(PRData))
+					used0RTT <- str.Used0RTT()
+				}()
+			}
+		}()
+		addr := fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port)
+
+		sendData := func(sess quic.EarlySession) {
+			str, err := sess.OpenUniStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+		}
+
+		// dial the first session in order to receive a session ticket
+		store := newSessionTicketStore()
+		sess, err := quic.DialAddrEarly(addr, getTLSClientConfig(), getQuicConfig(&quic.Config{SessionTicketStore: store}))
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(sess.HandshakeComplete().Done()).Should(BeClosed())
+		sendData(sess)
+		Eventually(used0RTT).Should(Receive(BeFalse()))
+		Eventually(store.Len).Should(Equal(1))
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+		// Use the session ticket for 0-RTT.
+		// Copying the store allows using the same session ticket again, simulating a replay.
+		sess, err = quic.DialAddrEarly(addr, getTLSClientConfig(), getQuicConfig(&quic.Config{SessionTicketStore: store.Copy()}))
+		Expect(err).ToNot(HaveOccurred())
+		sendData(sess)
+		Eventually(used0RTT).Should(Receive(BeTrue()))
+		Expect(sess.ConnectionState().TLS.Used0RTT).To(BeTrue())
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+
+		sess, err = quic.DialAddrEarly(addr, getTLSClientConfig(), getQuicConfig(&quic.Config{SessionTicketStore: store.Copy()}))
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		Eventually(sess.HandshakeComplete().Done()).Should(BeClosed())
+		Expect(sess.ConnectionState().TLS.DidResume).To(BeTrue())
+		Expect(sess.ConnectionState().TLS.Used0RTT).To(BeFalse())
+	})
+})
@@ -0,0 +1,106 @@
+package self_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"time"
+
+	quic "github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Connection statistics", func() {
+	const rtt = 20 * time.Millisecond
+
+	It("reports statistics", func() {
+		ln, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		serverSessChan := make(chan quic.Session, 1)
+		serverStreamStatsChan := make(chan quic.SendStreamStats, 1)
+		go func() {
+			defer GinkgoRecover()
+			sess, err := ln.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			serverSessChan <- sess
+			str, err := sess.OpenStream()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = str.Write(PRData)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			Expect(str.WaitForAcks(context.Background())).To(Succeed())
+			serverStreamStatsChan <- str.SendStats()
+		}()
+
+		var numPackets int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr:  fmt.Sprintf("localhost:%d", ln.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration { return rtt / 2 },
+			DropPacket: func(dir quicproxy.Direction, b []byte) bool {
+				// only drop 1-RTT packets sent by the server
+				if dir != quicproxy.DirectionOutgoing || b[0]&0x80 > 0 {
+					return false
+				}
+				n := atomic.AddInt32(&numPackets, 1)
+				return n >= 10 && n < 13
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		str, err := sess.AcceptStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadAll(str)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(PRData))
+		Expect(str.ReceiveStats()).To(Equal(quic.ReceiveStreamStats{
+			BytesReceived: uint64(len(PRData)),
+			BytesRead:     uint64(len(PRData)),
+		}))
+
+		stats := sess.Stats()
+		Expect(stats.MinRTT).To(BeNumerically(">=", rtt))
+		Expect(stats.SmoothedRTT).To(BeNumerically(">=", rtt))
+		Expect(stats.LatestRTT).To(BeNumerically(">=", rtt))
+		Expect(stats.MTU).To(BeNumerically(">=", 1200))
+		Expect(stats.PacketsSent).ToNot(BeZero())
+		Expect(stats.PacketsReceived).ToNot(BeZero())
+		Expect(stats.BytesReceived).To(BeNumerically(">", len(PRData)))
+
+		var serverSess quic.Session
+		Eventually(serverSessChan).Should(Receive(&serverSess))
+		serverStats := serverSess.Stats()
+		Expect(serverStats.PacketsSent).To(BeNumerically(">=", stats.PacketsReceived))
+		Expect(serverStats.BytesSent).To(BeNumerically(">=", stats.BytesReceived))
+		Expect(serverStats.PacketsLost).ToNot(BeZero())
+		Expect(serverStats.BytesLost).ToNot(BeZero())
+		Expect(serverStats.BytesRetransmitted).ToNot(BeZero())
+		Expect(serverStats.BytesRetransmitted).To(BeNumerically("<=", serverStats.BytesLost))
+		Expect(serverStats.CongestionWindow).ToNot(BeZero())
+
+		// the server waits until all stream data was acknowledged
+		var streamStats quic.SendStreamStats
+		Eventually(serverStreamStatsChan).Should(Receive(&streamStats))
+		Expect(streamStats.BytesWritten).To(BeEquivalentTo(len(PRData)))
+		Expect(streamStats.BytesSent).To(BeEquivalentTo(len(PRData)))
+		Expect(streamStats.BytesAcked).To(BeEquivalentTo(len(PRData)))
+		Expect(streamStats.BytesRetransmitted).ToNot(BeZero())
+
+		Expect(sess.CloseWithError(0, "")).To(Succeed())
+		Eventually(sess.Context().Done()).Should(BeClosed())
+		// statistics are still available after the session was closed
+		Expect(sess.Stats().PacketsReceived).To(BeNumerically(">=", stats.PacketsReceived))
+	})
+})
@@ -31,6 +31,7 @@ func (t *customTracer) TracerForConnection(p logging.Perspective, odcid logging.
 func (t *customTracer) SentPacket(net.Addr, *logging.Header, logging.ByteCount, []logging.Frame) {}
 func (t *customTracer) DroppedPacket(net.Addr, logging.PacketType, logging.ByteCount, logging.PacketDropReason) {
 }
+func (t *customTracer) UpdatedHandshakeMetrics(logging.HandshakeMetrics) {}
 
 type customConnTracer struct{}
 
@@ -47,6 +48,8 @@ func (t *customConnTracer) SentPacket(hdr *logging.ExtendedHeader, size logging.
 
 func (t *customConnTracer) ReceivedVersionNegotiationPacket(*logging.Header, []logging.VersionNumber) {
 }
+func (t *customConnTracer) NegotiatedVersion(logging.VersionNumber, []logging.VersionNumber, []logging.VersionNumber) {
+}
 func (t *customConnTracer) ReceivedRetry(*logging.Header) {}
 func (t *customConnTracer) ReceivedPacket(hdr *logging.ExtendedHeader, size logging.ByteCount, frames []logging.Frame) {
 }
@@ -60,12 +63,15 @@ func (t *customConnTracer) UpdatedMetrics(rttStats *logging.RTTStats, cwnd, byte
 func (t *customConnTracer) AcknowledgedPacket(logging.EncryptionLevel, logging.PacketNumber) {}
 func (t *customConnTracer) LostPacket(logging.EncryptionLevel, logging.PacketNumber, logging.PacketLossReason) {
 }
-func (t *customConnTracer) UpdatedCongestionState(logging.CongestionState)                     {}
-func (t *customConnTracer) UpdatedPTOCount(value uint32)                                       {}
-func (t *customConnTracer) UpdatedKeyFromTLS(logging.EncryptionLevel, logging.Perspective)     {}
-func (t *customConnTracer) UpdatedKey(generation logging.KeyPhase, remote bool)                {}
-func (t *customConnTracer) DroppedEncryptionLevel(logging.EncryptionLevel)                     {}
-func (t *customConnTracer) DroppedKey(logging.KeyPhase)                                        {}
+func (t *customConnTracer) UpdatedCongestionState(logging.CongestionState)                 {}
+func (t *customConnTracer) UpdatedPTOCount(value uint32)                                   {}
+func (t *customConnTracer) UpdatedKeyFromTLS(logging.EncryptionLevel, logging.Perspective) {}
+func (t *customConnTracer) UpdatedKey(generation logging.KeyPhase, remote bool)            {}
+func (t *customConnTracer) DroppedEncryptionLevel(logging.EncryptionLevel)                 {}
+func (t *customConnTracer) DroppedKey(logging.KeyPhase)                                    {}
+func (t *customConnTracer) ApproachingAEADLimit(logging.AEADLimit, logging.KeyPhase, uint64, uint64) {
+}
+func (t *customConnTracer) ReachedAEADLimit(logging.AEADLimit, logging.KeyPhase, uint64)       {}
 func (t *customConnTracer) SetLossTimer(logging.TimerType, logging.EncryptionLevel, time.Time) {}
 func (t *customConnTracer) LossTimerExpired(logging.TimerType, logging.EncryptionLevel)        {}
 func (t *customConnTracer) LossTimerCanceled()                                                 {}
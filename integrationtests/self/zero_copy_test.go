@@ -0,0 +1,133 @@
+package self_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/lucas-clemente/quic-go"
+	quicproxy "github.com/lucas-clemente/quic-go/integrationtests/tools/proxy"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Zero-copy stream I/O", func() {
+	It("transfers data using WriteBuffer and ReadBuffer", func() {
+		const chunkSize = 16 * 1024
+
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		// drop some packets, so that retransmissions reference the buffers passed to WriteBuffer
+		var num int32
+		proxy, err := quicproxy.NewQuicProxy("localhost:0", &quicproxy.Opts{
+			RemoteAddr: fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			DelayPacket: func(quicproxy.Direction, []byte) time.Duration {
+				return 5 * time.Millisecond
+			},
+			DropPacket: func(dir quicproxy.Direction, _ []byte) bool {
+				n := atomic.AddInt32(&num, 1)
+				return n > 10 && n%20 == 0
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		var numReleased int32
+		numChunks := (len(PRData) + chunkSize - 1) / chunkSize
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.OpenUniStreamSync(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < len(PRData); i += chunkSize {
+				end := i + chunkSize
+				if end > len(PRData) {
+					end = len(PRData)
+				}
+				chunk := make([]byte, end-i)
+				copy(chunk, PRData[i:end])
+				n, err := str.WriteBuffer(chunk, func() { atomic.AddInt32(&numReleased, 1) })
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(len(chunk)))
+			}
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", proxy.LocalPort()),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		str, err := sess.AcceptUniStream(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		var data []byte
+		for {
+			b, release, err := str.ReadBuffer()
+			data = append(data, b...)
+			if len(b) > 0 {
+				release()
+			}
+			if err == io.EOF {
+				break
+			}
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(data).To(Equal(PRData))
+		// all buffers are released once the data has been acknowledged
+		Eventually(func() int32 { return atomic.LoadInt32(&numReleased) }).Should(BeEquivalentTo(numChunks))
+	})
+
+	It("echoes data using ReadFrom, WriteTo and Peek", func() {
+		server, err := quic.ListenAddr("localhost:0", getTLSConfig(), getQuicConfig(nil))
+		Expect(err).ToNot(HaveOccurred())
+		defer server.Close()
+
+		go func() {
+			defer GinkgoRecover()
+			sess, err := server.Accept(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			str, err := sess.AcceptStream(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			b, err := str.Peek()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(PRData).To(HavePrefix(string(b)))
+			// io.Copy uses the stream's WriteTo method
+			n, err := io.Copy(str, str)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(len(PRData)))
+			Expect(str.Close()).To(Succeed())
+		}()
+
+		sess, err := quic.DialAddr(
+			fmt.Sprintf("localhost:%d", server.Addr().(*net.UDPAddr).Port),
+			getTLSClientConfig(),
+			getQuicConfig(nil),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer sess.CloseWithError(0, "")
+		str, err := sess.OpenStream()
+		Expect(err).ToNot(HaveOccurred())
+		go func() {
+			defer GinkgoRecover()
+			n, err := str.ReadFrom(bytes.NewReader(PRData))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(len(PRData)))
+			Expect(str.Close()).To(Succeed())
+		}()
+		buf := &bytes.Buffer{}
+		n, err := str.WriteTo(buf)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(BeEquivalentTo(len(PRData)))
+		Expect(buf.Bytes()).To(Equal(PRData))
+	})
+})
@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/logging"
@@ -25,9 +26,16 @@ var RetireBugBackwardsCompatibilityMode bool
 // The StreamID is the ID of a QUIC stream.
 type StreamID = protocol.StreamID
 
+// A ConnectionID is a QUIC Connection ID, as defined in RFC 9000.
+type ConnectionID = protocol.ConnectionID
+
 // A VersionNumber is a QUIC version number.
 type VersionNumber = protocol.VersionNumber
 
+// A Priority is the priority of a stream, following the Extensible Prioritization Scheme (RFC 9218).
+// Newly opened streams have an urgency of 3 and are incremental.
+type Priority = protocol.Priority
+
 const (
 	// VersionDraft29 is IETF QUIC draft-29
 	VersionDraft29 = protocol.VersionDraft29
@@ -35,6 +43,8 @@ const (
 	VersionDraft32 = protocol.VersionDraft32
 	// VersionDraft34 is IETF QUIC draft-34
 	VersionDraft34 = protocol.VersionDraft34
+	// Version1 is RFC 9000
+	Version1 = protocol.Version1
 )
 
 // A Token can be used to verify the ownership of the client address.
@@ -47,6 +57,22 @@ type Token struct {
 	SentTime     time.Time
 }
 
+// A ConnectionIDGenerator generates the connection IDs that an endpoint issues to its peer.
+// It can be used to encode routing information into connection IDs, e.g. for a load balancer (see the quiclb package).
+// Implementations must be safe for concurrent use.
+type ConnectionIDGenerator interface {
+	// GenerateConnectionID generates a new connection ID.
+	// Connection IDs must be unique, and have the length returned by ConnectionIDLen.
+	GenerateConnectionID() (ConnectionID, error)
+	// ConnectionIDLen returns the length of the connection IDs generated.
+	// It must either be 0, or any value between 4 and 20.
+	ConnectionIDLen() int
+}
+
+// A TokenKey is the key material used to protect tokens.
+// It should be generated using a cryptographically secure random number generator.
+type TokenKey = handshake.TokenProtectorKey
+
 // A ClientToken is a token received by the client.
 // It can be used to skip address validation on future connection attempts.
 type ClientToken struct {
@@ -64,6 +90,31 @@ type TokenStore interface {
 	Put(key string, token *ClientToken)
 }
 
+// A SessionTicketStore stores the session tickets received by the client.
+// In contrast to a tls.ClientSessionCache, session tickets are serialized,
+// which allows persisting them, e.g. across process restarts.
+// A serialized session ticket contains the secret used to resume the TLS session,
+// so it must be stored securely.
+// Implementations must be safe for concurrent use.
+type SessionTicketStore interface {
+	// Get returns the serialized session ticket associated with the given key.
+	Get(key string) (ticket []byte, ok bool)
+
+	// Put stores a serialized session ticket with the given key.
+	// If ticket is nil, the session ticket associated with this key must be removed.
+	Put(key string, ticket []byte)
+}
+
+// A ZeroRTTReplayProtector protects a server against replayed 0-RTT data.
+// An attacker can capture the first flight of a 0-RTT connection attempt, and send it to the server again.
+// Implementations must be safe for concurrent use.
+type ZeroRTTReplayProtector interface {
+	// Accept0RTT is called when a client attempts to use a session ticket for 0-RTT.
+	// The ticketID uniquely identifies the session ticket, and issuedAt is the time when the server issued it.
+	// If it returns false, 0-RTT is rejected, and the handshake continues without 0-RTT.
+	Accept0RTT(ticketID []byte, issuedAt time.Time) bool
+}
+
 // An ErrorCode is an application-defined error code.
 // Valid values range between 0 and MAX_UINT62.
 type ErrorCode = protocol.ApplicationErrorCode
@@ -97,6 +148,20 @@ type ReceiveStream interface {
 	// If the session was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	io.Reader
+	// ReadBuffer reads the next chunk of data from the stream, without copying it.
+	// The returned slice references the stream's receive buffer. It is only valid until
+	// release is called, and release must be called exactly once if len(b) > 0.
+	// The data only counts towards the flow control window once it was released.
+	// Like Read, it might return data and an error at the same time.
+	// ReadBuffer must not be called concurrently with Read.
+	ReadBuffer() (b []byte, release func(), err error)
+	// WriteTo writes data to w until there's no more data to read, or an error occurs.
+	// The data is written to w directly from the receive buffers, without copying it.
+	io.WriterTo
+	// Peek returns the data that will be returned by the next call to Read, without consuming it.
+	// It blocks until data is available, and returns the contiguous data received in a single STREAM frame.
+	// The returned slice is only valid until the next call to Read, ReadBuffer or WriteTo.
+	Peek() ([]byte, error)
 	// CancelRead aborts receiving on this stream.
 	// It will ask the peer to stop transmitting stream data.
 	// Read will unblock immediately, and future Read calls will fail.
@@ -107,6 +172,12 @@ type ReceiveStream interface {
 	// A zero value for t means Read will not time out.
 
 	SetReadDeadline(t time.Time) error
+	// ReceiveStats returns statistics about the receive direction of the stream.
+	ReceiveStats() ReceiveStreamStats
+	// Used0RTT says if any data on this stream was received in 0-RTT packets.
+	// 0-RTT data can be replayed by an attacker, so a server should only
+	// act on it if processing it multiple times has no side effects (e.g. for idempotent requests).
+	Used0RTT() bool
 }
 
 // A SendStream is a unidirectional Send Stream.
@@ -121,6 +192,20 @@ type SendStream interface {
 	// If the session was closed due to a timeout, the error satisfies
 	// the net.Error interface, and Timeout() will be true.
 	io.Writer
+	// WriteBuffer writes data to the stream, without copying it.
+	// Ownership of b is transferred to the stream: it must not be modified until release is called.
+	// release is called once all STREAM frames referencing b have been acknowledged by the peer,
+	// or when the stream is canceled. It is always called, even if an error is returned.
+	// release is called from the session's run loop, and must not block.
+	// Like Write, WriteBuffer blocks until all data has been sent (or an error occurs),
+	// but it doesn't buffer the last bytes of b, so Close always sends a separate FIN.
+	// It must not be called concurrently with Write.
+	WriteBuffer(b []byte, release func()) (int, error)
+	// ReadFrom reads data from r until io.EOF or an error occurs, and writes it to the stream.
+	// The data is read into buffers that are passed to the stream without copying them (see WriteBuffer).
+	// Data is only read from r when flow control allows sending it.
+	// It must not be called concurrently with Write.
+	io.ReaderFrom
 	// Close closes the write-direction of the stream.
 	// Future calls to Write are not permitted after calling Close.
 	// It must not be called concurrently with Write.
@@ -131,6 +216,13 @@ type SendStream interface {
 	// Write will unblock immediately, and future calls to Write will fail.
 	// When called multiple times or after closing the stream it is a no-op.
 	CancelWrite(ErrorCode)
+	// CancelWriteAt aborts sending on this stream, but guarantees that the first offset bytes
+	// of the stream are still delivered to the peer (using a RESET_STREAM_AT frame).
+	// If offset is larger than the number of bytes written, it is reduced to that number.
+	// Write will unblock immediately, and future calls to Write will fail.
+	// Calling it again with a smaller offset reduces the amount of data that is delivered.
+	// If the peer doesn't support the reliable stream reset extension, it behaves like CancelWrite.
+	CancelWriteAt(code ErrorCode, offset uint64)
 	// The context is canceled as soon as the write-side of the stream is closed.
 	// This happens when Close() or CancelWrite() is called, or when the peer
 	// cancels the read-side of their stream.
@@ -142,6 +234,19 @@ type SendStream interface {
 	// some of the data was successfully written.
 	// A zero value for t means Write will not time out.
 	SetWriteDeadline(t time.Time) error
+	// SetPriority sets the priority of the stream.
+	// Data on streams with a lower urgency is sent before data on streams with a higher urgency.
+	// Urgencies larger than 7 are treated as 7.
+	SetPriority(Priority)
+	// Priority returns the priority of the stream.
+	Priority() Priority
+	// SendStats returns statistics about the send direction of the stream.
+	SendStats() SendStreamStats
+	// WaitForAcks blocks until all data written to the stream (and the FIN, if the stream was closed)
+	// has been acknowledged by the peer. This can be used to make sure that the peer received
+	// all data before closing the session.
+	// It returns an error if the stream is canceled, if the session is closed, or when the context is canceled.
+	WaitForAcks(context.Context) error
 }
 
 // StreamError is returned by Read and Write when the peer cancels the stream.
@@ -187,6 +292,13 @@ type Session interface {
 	LocalAddr() net.Addr
 	// RemoteAddr returns the address of the peer.
 	RemoteAddr() net.Addr
+	// MigrateTo migrates the session to a new local address, using the net.PacketConn.
+	// It blocks until the new path was validated, or until path validation failed.
+	// The session keeps using the old path if the migration fails.
+	// Only clients can migrate. Migration fails if the server disabled active migration,
+	// or if the server didn't provide an unused connection ID.
+	// Warning: This API should not be considered stable and might change soon.
+	MigrateTo(net.PacketConn) error
 	// Close the connection with an error.
 	// The error string will be sent to the peer.
 	CloseWithError(ErrorCode, string) error
@@ -197,10 +309,26 @@ type Session interface {
 	// It blocks until the handshake completes.
 	// Warning: This API should not be considered stable and might change soon.
 	ConnectionState() ConnectionState
+	// Stats returns statistics about the QUIC connection.
+	// It is safe to call Stats concurrently, also after the session was closed.
+	// Warning: This API should not be considered stable and might change soon.
+	Stats() ConnectionStats
+	// ForceKeyUpdate initiates an update of the 1-RTT keys.
+	// The key update is performed as soon as RFC 9001 allows it, i.e. after handshake confirmation,
+	// and after a packet sent with the current keys has been acknowledged.
+	ForceKeyUpdate()
 
 	// SendMessage sends a message as a datagram.
+	// It blocks until the datagram has been dequeued for sending.
 	// See https://datatracker.ietf.org/doc/draft-pauly-quic-datagram/.
 	SendMessage([]byte) error
+	// SendDatagram queues a datagram for sending. It doesn't block.
+	// If the send queue is full, a datagram is dropped according to the Config.DatagramDropPolicy.
+	// The options may be nil.
+	SendDatagram([]byte, *DatagramSendOptions) error
+	// MaxDatagramSize returns the maximum size of a datagram that can currently be sent.
+	// It returns 0 if datagrams can't be sent (yet).
+	MaxDatagramSize() int
 	// ReceiveMessage gets a message received in a datagram.
 	// See https://datatracker.ietf.org/doc/draft-pauly-quic-datagram/.
 	ReceiveMessage() ([]byte, error)
@@ -221,9 +349,19 @@ type EarlySession interface {
 	NextSession() Session
 }
 
+// A PreferredAddress is the server's preferred address.
+// At least one of IPv4 and IPv6 must be set.
+type PreferredAddress struct {
+	IPv4 *net.UDPAddr
+	IPv6 *net.UDPAddr
+}
+
 // Config contains all configuration data needed for a QUIC server or client.
 type Config struct {
-	// The QUIC versions that can be negotiated.
+	// The QUIC versions that can be negotiated, in order of preference.
+	// The client uses the first version for the handshake.
+	// If the server prefers a compatible version (like QUIC v1 over draft-34) that the client also supports,
+	// it switches to that version without an additional round trip (see RFC 9368).
 	// If not set, it uses all versions available.
 	// Warning: This API should not be considered stable and will change soon.
 	Versions []VersionNumber
@@ -234,6 +372,10 @@ type Config struct {
 	// If used for a server, or dialing on a packet conn, a 4 byte connection ID will be used.
 	// When dialing on a packet conn, the ConnectionIDLength value must be the same for every Dial call.
 	ConnectionIDLength int
+	// The ConnectionIDGenerator is used to generate all connection IDs that are issued to the peer.
+	// If set, ConnectionIDLength is ignored, and the length returned by the generator's ConnectionIDLen is used.
+	// If not set, random connection IDs of length ConnectionIDLength are used.
+	ConnectionIDGenerator ConnectionIDGenerator
 	// HandshakeIdleTimeout is the idle timeout before completion of the handshake.
 	// Specifically, if we don't receive any packet from the peer within this time, the connection attempt is aborted.
 	// If this value is zero, the timeout is set to 5 seconds.
@@ -252,11 +394,54 @@ type Config struct {
 	//   * else, that it was issued within the last 24 hours.
 	// This option is only valid for the server.
 	AcceptToken func(clientAddr net.Addr, token *Token) bool
+	// TokenKeys are used to protect the tokens sent in Retry packets and in NEW_TOKEN frames.
+	// New tokens are protected using the first key. Tokens protected by any of the keys are accepted.
+	// Servers that share the same keys accept each other's tokens, e.g. when running behind a load balancer,
+	// or after a restart.
+	// To rotate keys, first add the new key at the end of the list on all servers,
+	// then move it to the front, and finally remove the old key once tokens protected with it have expired.
+	// If empty, a random key is generated when the server is started.
+	// This option is only valid for the server.
+	TokenKeys []TokenKey
+	// HandshakeLimits protect the server from floods of Initial packets.
+	// If set, the server only requires clients that don't present a token
+	// to validate their address using a Retry while it is under load.
+	// Clients that present a token that is rejected by AcceptToken always have to do a Retry.
+	// If not set, the server requires address validation whenever AcceptToken rejects the token.
+	// This option is only valid for the server.
+	HandshakeLimits *HandshakeLimits
 	// The TokenStore stores tokens received from the server.
 	// Tokens are used to skip address validation on future connection attempts.
 	// The key used to store tokens is the ServerName from the tls.Config, if set
 	// otherwise the token is associated with the server's IP address.
 	TokenStore TokenStore
+	// The SessionTicketStore stores session tickets received from the server, in serialized form.
+	// Session tickets are used to resume TLS sessions, and to send 0-RTT data (see DialEarly).
+	// Since the stored session tickets can be persisted, session resumption works across process restarts.
+	// If set, it is used instead of the ClientSessionCache of the tls.Config.
+	// This option is only valid for the client.
+	SessionTicketStore SessionTicketStore
+	// GetAppDataForSessionTicket is called by the server when it issues a session ticket.
+	// The data is stored in the session ticket, which is encrypted, and can only be read by the server.
+	// The data is stored in every session ticket, no matter if 0-RTT is enabled.
+	// When the client uses the session ticket for 0-RTT, it is passed to Accept0RTTWithAppData.
+	// Note that the TLS stack only hands the session ticket back to the server when the client attempts 0-RTT,
+	// so the data is not available when a session is resumed without 0-RTT.
+	// This option is only valid for the server.
+	GetAppDataForSessionTicket func() []byte
+	// Accept0RTTWithAppData is called by the server when a client attempts 0-RTT,
+	// with the data that was saved in the session ticket using GetAppDataForSessionTicket.
+	// This can be used to check that the application state (e.g. HTTP/3 SETTINGS) is compatible with the current state.
+	// If it returns false, 0-RTT is rejected, and the handshake continues without 0-RTT.
+	// This option is only valid for the server.
+	Accept0RTTWithAppData func(appData []byte) bool
+	// ZeroRTTReplayProtector protects the server against replayed 0-RTT data.
+	// It is only consulted if 0-RTT would otherwise be accepted.
+	// If not set, a ZeroRTTReplayCache is used, allowing every session ticket to be used for 0-RTT only once.
+	// Servers that share their session ticket keys need to share the replay protection as well.
+	// Since replay protection can't be perfect, applications should use Used0RTT to check if stream data was received in 0-RTT.
+	// This option is only valid for the server.
+	ZeroRTTReplayProtector ZeroRTTReplayProtector
 	// InitialStreamReceiveWindow is the initial size of the stream-level flow control window for receiving data.
 	// If the application is consuming data quickly enough, the flow control auto-tuning algorithm
 	// will increase the window up to MaxStreamReceiveWindow.
@@ -273,6 +458,20 @@ type Config struct {
 	// MaxConnectionReceiveWindow is the connection-level flow control window for receiving data.
 	// If this value is zero, it will default to 15 MB.
 	MaxConnectionReceiveWindow uint64
+	// AllowConnectionWindowIncrease is called every time the connection flow controller attempts
+	// to increase the connection flow control window, with the size of the increase.
+	// If it returns false, the window is not increased.
+	// This can be used to limit the memory used for buffering data across all sessions.
+	// To avoid deadlocks, it is not valid to call other functions on the session or on streams in this callback.
+	// If not set, the window is increased up to MaxConnectionReceiveWindow.
+	AllowConnectionWindowIncrease func(sess Session, delta uint64) bool
+	// MemoryBudget limits the memory used for buffering received stream and crypto data.
+	// It doesn't account for packet buffers.
+	// The same MemoryBudget can be used for multiple Listeners and Dialers, to limit the memory used by all of their sessions.
+	// If AllowConnectionWindowIncrease is set as well, both have to allow an increase of the connection flow control window.
+	// AllowConnectionWindowIncrease is only called if the MemoryBudget allows the increase.
+	// If not set, the memory usage is only limited by the connection flow control window of every session.
+	MemoryBudget *MemoryBudget
 	// MaxIncomingStreams is the maximum number of concurrent bidirectional streams that a peer is allowed to open.
 	// Values above 2^60 are invalid.
 	// If not set, it will default to 100.
@@ -291,10 +490,110 @@ type Config struct {
 	// DisablePathMTUDiscovery disables Path MTU Discovery (RFC 8899).
 	// Packets will then be at most 1252 (IPv4) / 1232 (IPv6) bytes in size.
 	DisablePathMTUDiscovery bool
+	// DisableActiveMigration tells the client that it must not migrate to a new address.
+	// Even if set, the server still handles NAT rebindings.
+	// Only valid for the server.
+	DisableActiveMigration bool
+	// PreferredAddress is the address that the server would like clients to migrate to after the handshake,
+	// see section 9.6 of RFC 9000.
+	// The server listens on these addresses, in addition to the address passed to Listen / ListenAddr.
+	// If the port is 0, a random port is chosen.
+	// Clients migrate to the preferred address automatically, even if DisableActiveMigration is set.
+	// Only valid for the server.
+	PreferredAddress *PreferredAddress
 	// See https://datatracker.ietf.org/doc/draft-ietf-quic-datagram/.
 	// Datagrams will only be available when both peers enable datagram support.
 	EnableDatagrams bool
-	Tracer          logging.Tracer
+	// DatagramSendQueueLen is the maximum number of datagrams queued for sending.
+	// If zero, it defaults to 32.
+	DatagramSendQueueLen int
+	// DatagramDropPolicy determines which datagram is dropped when SendDatagram is called while the send queue is full.
+	// If not set, the oldest queued datagram is dropped.
+	DatagramDropPolicy DatagramDropPolicy
+	// EnableAckFrequency enables the ACK frequency extension,
+	// see https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/.
+	// If both peers enable it, the number of ACKs sent by the peer is reduced as the congestion window grows.
+	// This saves CPU and upstream bandwidth on high-throughput transfers.
+	EnableAckFrequency bool
+	// EnableResetStreamAt enables the reliable stream reset extension,
+	// see https://datatracker.ietf.org/doc/draft-ietf-quic-reliable-stream-reset/.
+	// It allows SendStream.CancelWriteAt to reset a stream while still delivering the data up to a certain offset.
+	EnableResetStreamAt bool
+	// KeyUpdateInterval is the maximum number of packets sent or received with the same 1-RTT keys.
+	// A key update is initiated when it is reached.
+	// If zero, it defaults to 100,000 packets.
+	KeyUpdateInterval uint64
+	// KeyUpdateTimeInterval is the maximum time the same 1-RTT keys are used.
+	// A key update is initiated when it has passed.
+	// If zero, keys are only updated based on KeyUpdateInterval.
+	KeyUpdateTimeInterval time.Duration
+	// CongestionControl is called for every new connection to create its congestion controller.
+	// It is called again when the connection migrates to a new path.
+	// It must return a new congestion controller every time it is called.
+	// The congestion package provides NewReno, NewCubic and NewBBR.
+	// Congestion controllers that need to know the encryption level of packets implement congestion.SendAlgorithmWithEncryptionLevel.
+	// If not set, NewReno is used.
+	// Warning: This API should not be considered stable and might change soon.
+	CongestionControl func(*congestion.ConnectionInfo) congestion.SendAlgorithmWithDebugInfos
+	Tracer            logging.Tracer
+}
+
+// HandshakeLimits configure how the server handles new connection attempts under load.
+// Clients that haven't validated their address are called unvalidated clients.
+type HandshakeLimits struct {
+	// MaxUnvalidatedHandshakes is the maximum number of concurrent handshakes with unvalidated clients.
+	// When reached, new unvalidated clients have to validate their address using a Retry.
+	// If zero, it defaults to 256. If negative, the number of handshakes is not limited.
+	MaxUnvalidatedHandshakes int
+	// MaxUnvalidatedHandshakeRate is the maximum rate (per second) of new handshakes with unvalidated clients.
+	// When exceeded, new unvalidated clients have to validate their address using a Retry.
+	// If zero, it defaults to 1000. If negative, the rate is not limited.
+	MaxUnvalidatedHandshakeRate int
+	// MaxInitialRatePerSubnet is the maximum rate (per second) of Initial packets that start a new handshake,
+	// accepted from a single subnet (a /24 for IPv4, a /48 for IPv6).
+	// Initial packets exceeding this rate are dropped.
+	// If zero, it defaults to 100. If negative, the rate is not limited.
+	MaxInitialRatePerSubnet int
+}
+
+// A DatagramDropPolicy determines which datagram is dropped when the send queue is full.
+type DatagramDropPolicy uint8
+
+const (
+	// DatagramDropOldest drops the datagram that has been queued the longest.
+	DatagramDropOldest DatagramDropPolicy = iota
+	// DatagramDropNewest drops the datagram that is being queued.
+	DatagramDropNewest
+)
+
+// A DatagramOutcome is the fate of a datagram queued by SendDatagram.
+type DatagramOutcome uint8
+
+const (
+	// DatagramAcked means that the packet containing the datagram was acknowledged.
+	DatagramAcked DatagramOutcome = iota + 1
+	// DatagramLost means that the packet containing the datagram was declared lost,
+	// or that the session was closed before the packet was acknowledged.
+	// Datagrams are never retransmitted.
+	DatagramLost
+	// DatagramDropped means that the datagram was never sent.
+	// It was dropped from the send queue, because the queue was full, its deadline expired,
+	// it didn't fit into a packet any more, or the session was closed.
+	DatagramDropped
+)
+
+// DatagramSendOptions are options for sending a datagram.
+type DatagramSendOptions struct {
+	// Deadline is the time by which the datagram has to be sent.
+	// If it hasn't been sent by then, it is dropped from the send queue.
+	// If zero, the datagram doesn't expire.
+	Deadline time.Time
+	// Unless SendDatagram returns an error, OnOutcome is called exactly once, when the outcome of the datagram is known.
+	// It may be called synchronously from SendDatagram (for this or any other queued datagram), when datagrams are dropped
+	// from the send queue because it is full or their deadline expired.
+	// Otherwise, it is called from the session's run loop.
+	// It is never called while the session holds a lock, but it must not block.
+	OnOutcome func(DatagramOutcome)
 }
 
 // ConnectionState records basic details about a QUIC connection
@@ -303,6 +602,69 @@ type ConnectionState struct {
 	SupportsDatagrams bool
 }
 
+// ConnectionStats contains statistics about a QUIC connection.
+type ConnectionStats struct {
+	// MinRTT is the minimum RTT observed on the current path.
+	MinRTT time.Duration
+	// LatestRTT is the most recent RTT sample.
+	LatestRTT time.Duration
+	// SmoothedRTT is the exponentially weighted moving average of the RTT samples.
+	SmoothedRTT time.Duration
+	// MeanDeviation is the mean deviation of the RTT samples.
+	MeanDeviation time.Duration
+
+	// CongestionWindow is the current congestion window, in bytes.
+	CongestionWindow uint64
+	// BytesInFlight is the number of bytes sent, but not yet acknowledged or declared lost.
+	BytesInFlight uint64
+	// MTU is the maximum size of the UDP payload of packets sent on the current path.
+	MTU uint64
+
+	PacketsSent     uint64
+	BytesSent       uint64
+	PacketsReceived uint64
+	BytesReceived   uint64
+	// PacketsLost and BytesLost count the packets declared lost.
+	// The frames contained in these packets are retransmitted, if necessary.
+	// Lost Path MTU probe packets are not counted.
+	PacketsLost uint64
+	BytesLost   uint64
+	// BytesRetransmitted is the number of bytes of frames from lost packets that were queued for retransmission.
+	// Frames that are not retransmitted are not counted, e.g. DATAGRAM and PING frames,
+	// and STREAM frames of streams that were canceled.
+	BytesRetransmitted uint64
+	// PTOCount is the number of times the Probe Timeout fired.
+	PTOCount uint64
+}
+
+// SendStreamStats contains statistics about the send direction of a stream.
+type SendStreamStats struct {
+	// BytesWritten is the number of bytes accepted by Write and WriteBuffer.
+	BytesWritten uint64
+	// BytesSent is the number of bytes sent, not counting retransmissions.
+	BytesSent uint64
+	// BytesRetransmitted is the number of bytes retransmitted after being declared lost.
+	BytesRetransmitted uint64
+	// BytesAcked is the number of bytes acknowledged by the peer.
+	BytesAcked uint64
+	// FlowControlBlockedTime is the time the stream had data to send,
+	// but was blocked by stream or connection flow control.
+	FlowControlBlockedTime time.Duration
+}
+
+// ReceiveStreamStats contains statistics about the receive direction of a stream.
+type ReceiveStreamStats struct {
+	// BytesReceived is the number of bytes received, not counting duplicate data.
+	BytesReceived uint64
+	// BytesRead is the number of bytes read by the application.
+	BytesRead uint64
+	// BytesBuffered is the number of bytes received, but not yet read by the application.
+	BytesBuffered uint64
+	// BytesBufferedOutOfOrder is the part of BytesBuffered that can't be read yet,
+	// since data at a lower offset is still missing.
+	BytesBufferedOutOfOrder uint64
+}
+
 // A Listener for incoming QUIC connections
 type Listener interface {
 	// Close the server. All active sessions will be closed.
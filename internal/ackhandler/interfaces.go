@@ -3,6 +3,7 @@ package ackhandler
 import (
 	"time"
 
+	"github.com/lucas-clemente/quic-go/internal/congestion"
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
@@ -32,6 +33,17 @@ type SentPacketHandler interface {
 	DropPackets(protocol.EncryptionLevel)
 	ResetForRetry() error
 	SetHandshakeConfirmed()
+	// MigratedPath is called when the connection switches to a new path.
+	// It resets the RTT estimate and replaces the congestion controller (see section 9.4 of RFC 9000).
+	// If cc is nil, the default congestion controller is used.
+	// If the new path hasn't been validated yet, sending is subject to the anti-amplification limit,
+	// until ValidatedPath is called.
+	MigratedPath(cc congestion.SendAlgorithmWithDebugInfos, peerAddressValidated bool)
+	// ReboundPath is called when the connection switches to a path on which only the peer's port changed,
+	// which is most likely the result of a NAT rebinding.
+	// The RTT estimate and the congestion controller are kept (see section 9.4 of RFC 9000).
+	ReboundPath(peerAddressValidated bool)
+	ValidatedPath()
 
 	// The SendMode determines if and what kind of packets can be sent.
 	SendMode() SendMode
@@ -41,6 +53,10 @@ type SentPacketHandler interface {
 	// HasPacingBudget says if the pacer allows sending of a (full size) packet at this moment.
 	HasPacingBudget() bool
 	SetMaxDatagramSize(count protocol.ByteCount)
+	// EnableAckFrequency is called when both endpoints support the ACK frequency extension.
+	EnableAckFrequency()
+	// GetAckFrequencyFrame returns an ACK_FREQUENCY frame, if the peer should change its ack frequency.
+	GetAckFrequencyFrame() *wire.AckFrequencyFrame
 
 	// only to be called once the handshake is complete
 	QueueProbePacket(protocol.EncryptionLevel) bool /* was a packet queued */
@@ -50,6 +66,22 @@ type SentPacketHandler interface {
 
 	GetLossDetectionTimeout() time.Time
 	OnLossDetectionTimeout() error
+
+	GetStats() Stats
+}
+
+// Stats are statistics about the packets sent on a connection.
+type Stats struct {
+	PacketsSent uint64
+	BytesSent   protocol.ByteCount
+	// Lost packets don't include lost Path MTU probe packets.
+	PacketsLost uint64
+	BytesLost   protocol.ByteCount
+	// PTOCount is the total number of times the PTO timer fired.
+	PTOCount uint64
+
+	CongestionWindow protocol.ByteCount
+	BytesInFlight    protocol.ByteCount
 }
 
 type sentPacketTracker interface {
@@ -62,6 +94,10 @@ type ReceivedPacketHandler interface {
 	IsPotentiallyDuplicate(protocol.PacketNumber, protocol.EncryptionLevel) bool
 	ReceivedPacket(pn protocol.PacketNumber, ecn protocol.ECN, encLevel protocol.EncryptionLevel, rcvTime time.Time, shouldInstigateAck bool) error
 	DropPackets(protocol.EncryptionLevel)
+	// SetAckFrequency applies the parameters requested by the peer in an ACK_FREQUENCY frame.
+	SetAckFrequency(*wire.AckFrequencyFrame)
+	// QueueImmediateAck is called when an IMMEDIATE_ACK frame is received.
+	QueueImmediateAck()
 
 	GetAlarmTimeout() time.Time
 	GetAckFrame(encLevel protocol.EncryptionLevel, onlyIfQueued bool) *wire.AckFrame
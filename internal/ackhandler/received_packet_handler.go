@@ -81,6 +81,15 @@ func (h *receivedPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
 	}
 }
 
+// ACK_FREQUENCY and IMMEDIATE_ACK frames only apply to the application data packet number space.
+func (h *receivedPacketHandler) SetAckFrequency(f *wire.AckFrequencyFrame) {
+	h.appDataPackets.SetAckFrequency(f)
+}
+
+func (h *receivedPacketHandler) QueueImmediateAck() {
+	h.appDataPackets.QueueImmediateAck()
+}
+
 func (h *receivedPacketHandler) GetAlarmTimeout() time.Time {
 	var initialAlarm, handshakeAlarm time.Time
 	if h.initialPackets != nil {
@@ -8,8 +8,13 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/wire"
 )
 
-// number of ack-eliciting packets received before sending an ack.
-const packetsBeforeAck = 2
+// The default number of ack-eliciting packets that may be received without sending an ACK.
+// Can be changed by the peer using an ACK_FREQUENCY frame.
+const defaultAckElicitingThreshold = 1
+
+// The default reordering threshold, see section 6.2 of draft-ietf-quic-ack-frequency.
+// A threshold of 1 means that an ACK is sent immediately when a packet is received out of order.
+const defaultReorderingThreshold = 1
 
 type receivedPacketTracker struct {
 	largestObserved             protocol.PacketNumber
@@ -19,13 +24,18 @@ type receivedPacketTracker struct {
 
 	packetHistory *receivedPacketHistory
 
-	maxAckDelay time.Duration
-	rttStats    *utils.RTTStats
+	maxAckDelay           time.Duration
+	ackElicitingThreshold uint64
+	reorderingThreshold   uint64
+	// ACK_FREQUENCY frames with a lower sequence number are outdated
+	nextAckFrequencySeq uint64
+
+	rttStats *utils.RTTStats
 
 	hasNewAck bool // true as soon as we received an ack-eliciting new packet
 	ackQueued bool // true once we received more than 2 (or later in the connection 10) ack-eliciting packets
 
-	ackElicitingPacketsReceivedSinceLastAck int
+	ackElicitingPacketsReceivedSinceLastAck uint64
 	ackAlarm                                time.Time
 	lastAck                                 *wire.AckFrame
 
@@ -40,11 +50,13 @@ func newReceivedPacketTracker(
 	version protocol.VersionNumber,
 ) *receivedPacketTracker {
 	return &receivedPacketTracker{
-		packetHistory: newReceivedPacketHistory(),
-		maxAckDelay:   protocol.MaxAckDelay,
-		rttStats:      rttStats,
-		logger:        logger,
-		version:       version,
+		packetHistory:         newReceivedPacketHistory(),
+		maxAckDelay:           protocol.MaxAckDelay,
+		ackElicitingThreshold: defaultAckElicitingThreshold,
+		reorderingThreshold:   defaultReorderingThreshold,
+		rttStats:              rttStats,
+		logger:                logger,
+		version:               version,
 	}
 }
 
@@ -89,6 +101,31 @@ func (h *receivedPacketTracker) IgnoreBelow(p protocol.PacketNumber) {
 	}
 }
 
+// SetAckFrequency applies the parameters of an ACK_FREQUENCY frame.
+// Frames that are older than the most recently applied one are ignored.
+func (h *receivedPacketTracker) SetAckFrequency(f *wire.AckFrequencyFrame) {
+	if f.SequenceNumber < h.nextAckFrequencySeq {
+		return
+	}
+	h.nextAckFrequencySeq = f.SequenceNumber + 1
+	h.ackElicitingThreshold = f.AckElicitingThreshold
+	h.maxAckDelay = f.RequestMaxAckDelay
+	h.reorderingThreshold = f.ReorderingThreshold
+	if h.logger.Debug() {
+		h.logger.Debugf("\tUpdating ack frequency: ack-eliciting threshold %d, max ack delay %s, reordering threshold %d", h.ackElicitingThreshold, h.maxAckDelay, h.reorderingThreshold)
+	}
+}
+
+// QueueImmediateAck makes sure that an ACK is sent right away.
+// It is called when an IMMEDIATE_ACK frame is received.
+func (h *receivedPacketTracker) QueueImmediateAck() {
+	if !h.ackQueued {
+		h.logger.Debugf("\tQueueing ACK because an IMMEDIATE_ACK frame was received.")
+	}
+	h.ackQueued = true
+	h.ackAlarm = time.Time{}
+}
+
 // isMissing says if a packet was reported missing in the last ACK.
 func (h *receivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 	if h.lastAck == nil || p < h.ignoreBelow {
@@ -97,12 +134,14 @@ func (h *receivedPacketTracker) isMissing(p protocol.PacketNumber) bool {
 	return p < h.lastAck.LargestAcked() && !h.lastAck.AcksPacket(p)
 }
 
+// hasNewMissingPackets says if there are missing packets that haven't been reported yet,
+// and enough packets were received after the gap to exceed the reordering threshold.
 func (h *receivedPacketTracker) hasNewMissingPackets() bool {
-	if h.lastAck == nil {
+	if h.lastAck == nil || h.reorderingThreshold == 0 {
 		return false
 	}
 	highestRange := h.packetHistory.GetHighestAckRange()
-	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && highestRange.Len() == 1
+	return highestRange.Smallest > h.lastAck.LargestAcked()+1 && uint64(highestRange.Len()) == h.reorderingThreshold
 }
 
 // maybeQueueAck queues an ACK, if necessary.
@@ -125,17 +164,18 @@ func (h *receivedPacketTracker) maybeQueueAck(pn protocol.PacketNumber, rcvTime
 	// Send an ACK if this packet was reported missing in an ACK sent before.
 	// Ack decimation with reordering relies on the timer to send an ACK, but if
 	// missing packets we reported in the previous ack, send an ACK immediately.
-	if wasMissing {
+	// The peer can disable this by setting the reordering threshold to 0.
+	if wasMissing && h.reorderingThreshold > 0 {
 		if h.logger.Debug() {
 			h.logger.Debugf("\tQueueing ACK because packet %d was missing before.", pn)
 		}
 		h.ackQueued = true
 	}
 
-	// send an ACK every 2 ack-eliciting packets
-	if h.ackElicitingPacketsReceivedSinceLastAck >= packetsBeforeAck {
+	// send an ACK once the ack-eliciting threshold is exceeded
+	if h.ackElicitingPacketsReceivedSinceLastAck > h.ackElicitingThreshold {
 		if h.logger.Debug() {
-			h.logger.Debugf("\tQueueing ACK because packet %d packets were received after the last ACK (using initial threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, packetsBeforeAck)
+			h.logger.Debugf("\tQueueing ACK because %d packets were received after the last ACK (using threshold: %d).", h.ackElicitingPacketsReceivedSinceLastAck, h.ackElicitingThreshold)
 		}
 		h.ackQueued = true
 	} else if h.ackAlarm.IsZero() {
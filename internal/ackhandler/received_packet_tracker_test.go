@@ -191,6 +191,98 @@ var _ = Describe("Received Packet Tracker", func() {
 			})
 		})
 
+		Context("ACK frequency", func() {
+			receiveAndAck10Packets := func() {
+				for i := 1; i <= 10; i++ {
+					tracker.ReceivedPacket(protocol.PacketNumber(i), protocol.ECNNon, time.Time{}, true)
+				}
+				Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+			}
+
+			It("uses the ack-eliciting threshold requested by the peer", func() {
+				receiveAndAck10Packets()
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 5,
+					RequestMaxAckDelay:    protocol.MaxAckDelay,
+					ReorderingThreshold:   1,
+				})
+				for pn := protocol.PacketNumber(11); pn <= 15; pn++ {
+					tracker.ReceivedPacket(pn, protocol.ECNNon, time.Now(), true)
+					Expect(tracker.ackQueued).To(BeFalse())
+				}
+				tracker.ReceivedPacket(16, protocol.ECNNon, time.Now(), true)
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("acknowledges every packet if the threshold is 0", func() {
+				receiveAndAck10Packets()
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{RequestMaxAckDelay: protocol.MaxAckDelay, ReorderingThreshold: 1})
+				tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("uses the max ack delay requested by the peer", func() {
+				receiveAndAck10Packets()
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 1,
+					RequestMaxAckDelay:    42 * time.Millisecond,
+					ReorderingThreshold:   1,
+				})
+				rcvTime := time.Now()
+				tracker.ReceivedPacket(11, protocol.ECNNon, rcvTime, true)
+				Expect(tracker.GetAlarmTimeout()).To(Equal(rcvTime.Add(42 * time.Millisecond)))
+			})
+
+			It("ignores outdated ACK_FREQUENCY frames", func() {
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 2, AckElicitingThreshold: 5})
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 1, AckElicitingThreshold: 3})
+				Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(5))
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 2, AckElicitingThreshold: 3})
+				Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(5))
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{SequenceNumber: 3, AckElicitingThreshold: 3})
+				Expect(tracker.ackElicitingThreshold).To(BeEquivalentTo(3))
+			})
+
+			It("doesn't queue an ACK for out-of-order packets if the reordering threshold is 0", func() {
+				receiveAndAck10Packets()
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    protocol.MaxAckDelay,
+				})
+				tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true) // 11 is missing
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.GetAckFrame(false)).ToNot(BeNil())
+				tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true) // 11 was reported missing
+				Expect(tracker.ackQueued).To(BeFalse())
+			})
+
+			It("queues an ACK once the reordering threshold is reached", func() {
+				receiveAndAck10Packets()
+				tracker.SetAckFrequency(&wire.AckFrequencyFrame{
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    protocol.MaxAckDelay,
+					ReorderingThreshold:   3,
+				})
+				// 11 is missing
+				tracker.ReceivedPacket(12, protocol.ECNNon, time.Now(), true)
+				tracker.ReceivedPacket(13, protocol.ECNNon, time.Now(), true)
+				Expect(tracker.ackQueued).To(BeFalse())
+				tracker.ReceivedPacket(14, protocol.ECNNon, time.Now(), true)
+				Expect(tracker.ackQueued).To(BeTrue())
+			})
+
+			It("queues an ACK when an IMMEDIATE_ACK frame is received", func() {
+				receiveAndAck10Packets()
+				tracker.ReceivedPacket(11, protocol.ECNNon, time.Now(), true)
+				Expect(tracker.ackQueued).To(BeFalse())
+				Expect(tracker.GetAlarmTimeout()).ToNot(BeZero())
+				tracker.QueueImmediateAck()
+				Expect(tracker.ackQueued).To(BeTrue())
+				Expect(tracker.GetAlarmTimeout()).To(BeZero())
+				Expect(tracker.GetAckFrame(true)).ToNot(BeNil())
+			})
+		})
+
 		Context("ACK generation", func() {
 			It("generates an ACK for an ack-eliciting packet, if no ACK is queued yet", func() {
 				tracker.ReceivedPacket(1, protocol.ECNNon, time.Now(), true)
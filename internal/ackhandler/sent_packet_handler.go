@@ -23,6 +23,10 @@ const (
 	amplificationFactor = 3
 	// We use Retry packets to derive an RTT estimate. Make sure we don't set the RTT to a super low value yet.
 	minRTTAfterRetry = 5 * time.Millisecond
+	// When using the ACK frequency extension, we ask the peer to send (at least) this many ACKs per congestion window.
+	acksPerCongestionWindow = 4
+	// The maximum ack-eliciting threshold we request from the peer.
+	maxAckElicitingThreshold = 10
 )
 
 type packetNumberSpace struct {
@@ -78,7 +82,11 @@ type sentPacketHandler struct {
 	bytesInFlight protocol.ByteCount
 
 	congestion congestion.SendAlgorithmWithDebugInfos
-	rttStats   *utils.RTTStats
+	// set if the congestion controller needs to know the encryption level of packets
+	congestionWithEncLevel congestion.SendAlgorithmWithEncryptionLevel
+	rttStats               *utils.RTTStats
+	// The max datagram size used for a new congestion controller, when migrating to a new path.
+	initialMaxDatagramSize protocol.ByteCount
 
 	// The number of times a PTO has been sent without receiving an ack.
 	ptoCount uint32
@@ -90,6 +98,19 @@ type sentPacketHandler struct {
 	// The alarm timeout
 	alarm time.Time
 
+	// Used for the ACK frequency extension, see draft-ietf-quic-ack-frequency.
+	ackFrequencyEnabled            bool
+	nextAckFrequencySeq            uint64
+	requestedAckElicitingThreshold uint64
+	maxDatagramSize                protocol.ByteCount
+
+	// statistics, see GetStats
+	packetsSent    uint64
+	totalBytesSent protocol.ByteCount
+	packetsLost    uint64
+	bytesLost      protocol.ByteCount
+	totalPTOCount  uint64
+
 	perspective protocol.Perspective
 
 	tracer logging.ConnectionTracer
@@ -105,30 +126,46 @@ func newSentPacketHandler(
 	initialPN protocol.PacketNumber,
 	initialMaxDatagramSize protocol.ByteCount,
 	rttStats *utils.RTTStats,
+	cc congestion.SendAlgorithmWithDebugInfos,
 	pers protocol.Perspective,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
 ) *sentPacketHandler {
-	congestion := congestion.NewCubicSender(
-		congestion.DefaultClock{},
-		rttStats,
-		initialMaxDatagramSize,
-		true, // use Reno
-		tracer,
-	)
+	if cc == nil {
+		cc = newDefaultCongestionController(rttStats, initialMaxDatagramSize, tracer)
+	}
 
-	return &sentPacketHandler{
+	h := &sentPacketHandler{
 		peerCompletedAddressValidation: pers == protocol.PerspectiveServer,
 		peerAddressValidated:           pers == protocol.PerspectiveClient,
 		initialPackets:                 newPacketNumberSpace(initialPN, false, rttStats),
 		handshakePackets:               newPacketNumberSpace(0, false, rttStats),
 		appDataPackets:                 newPacketNumberSpace(0, true, rttStats),
 		rttStats:                       rttStats,
-		congestion:                     congestion,
+		initialMaxDatagramSize:         initialMaxDatagramSize,
+		maxDatagramSize:                initialMaxDatagramSize,
+		requestedAckElicitingThreshold: 1, // the default value, see draft-ietf-quic-ack-frequency
 		perspective:                    pers,
 		tracer:                         tracer,
 		logger:                         logger,
 	}
+	h.setCongestionController(cc)
+	return h
+}
+
+func (h *sentPacketHandler) setCongestionController(cc congestion.SendAlgorithmWithDebugInfos) {
+	h.congestion = cc
+	h.congestionWithEncLevel, _ = cc.(congestion.SendAlgorithmWithEncryptionLevel)
+}
+
+func newDefaultCongestionController(rttStats *utils.RTTStats, initialMaxDatagramSize protocol.ByteCount, tracer logging.ConnectionTracer) congestion.SendAlgorithmWithDebugInfos {
+	return congestion.NewCubicSender(
+		congestion.DefaultClock{},
+		rttStats,
+		initialMaxDatagramSize,
+		true, // use Reno
+		tracer,
+	)
 }
 
 func (h *sentPacketHandler) DropPackets(encLevel protocol.EncryptionLevel) {
@@ -225,6 +262,8 @@ func (h *sentPacketHandler) packetsInFlight() int {
 
 func (h *sentPacketHandler) SentPacket(packet *Packet) {
 	h.bytesSent += packet.Length
+	h.packetsSent++
+	h.totalBytesSent += packet.Length
 	// For the client, drop the Initial packet number space when the first Handshake packet is sent.
 	if h.perspective == protocol.PerspectiveClient && packet.EncryptionLevel == protocol.EncryptionHandshake && h.initialPackets != nil {
 		h.dropPackets(protocol.EncryptionInitial)
@@ -272,7 +311,11 @@ func (h *sentPacketHandler) sentPacketImpl(packet *Packet) bool /* is ack-elicit
 			h.numProbesToSend--
 		}
 	}
-	h.congestion.OnPacketSent(packet.SendTime, h.bytesInFlight, packet.PacketNumber, packet.Length, isAckEliciting)
+	if h.congestionWithEncLevel != nil {
+		h.congestionWithEncLevel.OnPacketSentWithEncryptionLevel(packet.SendTime, h.bytesInFlight, packet.EncryptionLevel, packet.PacketNumber, packet.Length, isAckEliciting)
+	} else {
+		h.congestion.OnPacketSent(packet.SendTime, h.bytesInFlight, packet.PacketNumber, packet.Length, isAckEliciting)
+	}
 
 	return isAckEliciting
 }
@@ -321,7 +364,11 @@ func (h *sentPacketHandler) ReceivedAck(ack *wire.AckFrame, encLevel protocol.En
 	}
 	for _, p := range ackedPackets {
 		if p.includedInBytesInFlight && !p.declaredLost {
-			h.congestion.OnPacketAcked(p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			if h.congestionWithEncLevel != nil {
+				h.congestionWithEncLevel.OnPacketAckedWithEncryptionLevel(p.EncryptionLevel, p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			} else {
+				h.congestion.OnPacketAcked(p.PacketNumber, p.Length, priorInFlight, rcvTime)
+			}
 		}
 		h.removeFromBytesInFlight(p)
 	}
@@ -587,7 +634,13 @@ func (h *sentPacketHandler) detectLostPackets(now time.Time, encLevel protocol.E
 			h.removeFromBytesInFlight(p)
 			h.queueFramesForRetransmission(p)
 			if !p.IsPathMTUProbePacket {
-				h.congestion.OnPacketLost(p.PacketNumber, p.Length, priorInFlight)
+				h.packetsLost++
+				h.bytesLost += p.Length
+				if h.congestionWithEncLevel != nil {
+					h.congestionWithEncLevel.OnPacketLostWithEncryptionLevel(p.EncryptionLevel, p.PacketNumber, p.Length, priorInFlight)
+				} else {
+					h.congestion.OnPacketLost(p.PacketNumber, p.Length, priorInFlight)
+				}
 			}
 		}
 		return true, nil
@@ -623,6 +676,7 @@ func (h *sentPacketHandler) onVerifiedLossDetectionTimeout() error {
 
 	// PTO
 	h.ptoCount++
+	h.totalPTOCount++
 	if h.bytesInFlight > 0 {
 		_, encLevel = h.getPTOTimeAndSpace()
 		if h.logger.Debug() {
@@ -735,9 +789,51 @@ func (h *sentPacketHandler) HasPacingBudget() bool {
 }
 
 func (h *sentPacketHandler) SetMaxDatagramSize(s protocol.ByteCount) {
+	h.maxDatagramSize = s
 	h.congestion.SetMaxDatagramSize(s)
 }
 
+func (h *sentPacketHandler) GetStats() Stats {
+	return Stats{
+		PacketsSent:      h.packetsSent,
+		BytesSent:        h.totalBytesSent,
+		PacketsLost:      h.packetsLost,
+		BytesLost:        h.bytesLost,
+		PTOCount:         h.totalPTOCount,
+		CongestionWindow: h.congestion.GetCongestionWindow(),
+		BytesInFlight:    h.bytesInFlight,
+	}
+}
+
+func (h *sentPacketHandler) EnableAckFrequency() {
+	h.ackFrequencyEnabled = true
+}
+
+// GetAckFrequencyFrame returns an ACK_FREQUENCY frame if the ack-eliciting threshold
+// derived from the current congestion window changed since the last request.
+// A larger congestion window allows the peer to send fewer ACKs.
+// We don't change the max ack delay (so we don't need to adjust the PTO calculation),
+// and keep the default reordering threshold (so loss detection isn't delayed).
+func (h *sentPacketHandler) GetAckFrequencyFrame() *wire.AckFrequencyFrame {
+	if !h.ackFrequencyEnabled || !h.handshakeConfirmed {
+		return nil
+	}
+	packetsPerAck := uint64(h.congestion.GetCongestionWindow() / h.maxDatagramSize / acksPerCongestionWindow)
+	threshold := utils.MinUint64(utils.MaxUint64(packetsPerAck, 2)-1, maxAckElicitingThreshold)
+	if threshold == h.requestedAckElicitingThreshold {
+		return nil
+	}
+	h.requestedAckElicitingThreshold = threshold
+	f := &wire.AckFrequencyFrame{
+		SequenceNumber:        h.nextAckFrequencySeq,
+		AckElicitingThreshold: threshold,
+		RequestMaxAckDelay:    h.rttStats.MaxAckDelay(),
+		ReorderingThreshold:   1,
+	}
+	h.nextAckFrequencySeq++
+	return f
+}
+
 func (h *sentPacketHandler) isAmplificationLimited() bool {
 	if h.peerAddressValidated {
 		return false
@@ -824,3 +920,37 @@ func (h *sentPacketHandler) SetHandshakeConfirmed() {
 	// Make sure the timer is armed now, if necessary.
 	h.setLossDetectionTimer()
 }
+
+func (h *sentPacketHandler) MigratedPath(cc congestion.SendAlgorithmWithDebugInfos, peerAddressValidated bool) {
+	if cc == nil {
+		cc = newDefaultCongestionController(h.rttStats, h.initialMaxDatagramSize, h.tracer)
+	}
+	h.setCongestionController(cc)
+	h.rttStats.OnConnectionMigration()
+	h.switchedPath(peerAddressValidated)
+}
+
+func (h *sentPacketHandler) ReboundPath(peerAddressValidated bool) {
+	h.switchedPath(peerAddressValidated)
+}
+
+func (h *sentPacketHandler) switchedPath(peerAddressValidated bool) {
+	// Until the new path is validated, the anti-amplification limit applies to the data sent on that path.
+	h.peerAddressValidated = peerAddressValidated
+	h.bytesSent = 0
+	h.bytesReceived = 0
+	h.ptoCount = 0
+	if h.tracer != nil {
+		h.tracer.UpdatedPTOCount(0)
+		h.tracer.UpdatedMetrics(h.rttStats, h.congestion.GetCongestionWindow(), h.bytesInFlight, h.packetsInFlight())
+	}
+	h.setLossDetectionTimer()
+}
+
+func (h *sentPacketHandler) ValidatedPath() {
+	if h.peerAddressValidated {
+		return
+	}
+	h.peerAddressValidated = true
+	h.setLossDetectionTimer()
+}
@@ -14,6 +14,29 @@ import (
 	. "github.com/onsi/gomega"
 )
 
+type packetWithEncryptionLevel struct {
+	encLevel protocol.EncryptionLevel
+	pn       protocol.PacketNumber
+}
+
+// congestionWithEncryptionLevel is a congestion controller that needs to know the encryption level of packets.
+type congestionWithEncryptionLevel struct {
+	*mocks.MockSendAlgorithmWithDebugInfos
+
+	sent, acked []packetWithEncryptionLevel
+}
+
+func (c *congestionWithEncryptionLevel) OnPacketSentWithEncryptionLevel(_ time.Time, _ protocol.ByteCount, encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, _ protocol.ByteCount, _ bool) {
+	c.sent = append(c.sent, packetWithEncryptionLevel{encLevel: encLevel, pn: pn})
+}
+
+func (c *congestionWithEncryptionLevel) OnPacketAckedWithEncryptionLevel(encLevel protocol.EncryptionLevel, pn protocol.PacketNumber, _, _ protocol.ByteCount, _ time.Time) {
+	c.acked = append(c.acked, packetWithEncryptionLevel{encLevel: encLevel, pn: pn})
+}
+
+func (c *congestionWithEncryptionLevel) OnPacketLostWithEncryptionLevel(protocol.EncryptionLevel, protocol.PacketNumber, protocol.ByteCount, protocol.ByteCount) {
+}
+
 var _ = Describe("SentPacketHandler", func() {
 	var (
 		handler     *sentPacketHandler
@@ -27,7 +50,7 @@ var _ = Describe("SentPacketHandler", func() {
 	JustBeforeEach(func() {
 		lostPackets = nil
 		rttStats := utils.NewRTTStats()
-		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, perspective, nil, utils.DefaultLogger)
+		handler = newSentPacketHandler(42, protocol.InitialPacketSizeIPv4, rttStats, nil, perspective, nil, utils.DefaultLogger)
 		streamFrame = wire.StreamFrame{
 			StreamID: 5,
 			Data:     []byte{0x13, 0x37},
@@ -431,7 +454,12 @@ var _ = Describe("SentPacketHandler", func() {
 
 		JustBeforeEach(func() {
 			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
-			handler.congestion = cong
+			handler.setCongestionController(cong)
+		})
+
+		It("uses the congestion controller passed to the constructor", func() {
+			h := newSentPacketHandler(0, protocol.InitialPacketSizeIPv4, utils.NewRTTStats(), cong, protocol.PerspectiveClient, nil, utils.DefaultLogger)
+			Expect(h.congestion).To(Equal(cong))
 		})
 
 		It("should call OnSent", func() {
@@ -450,6 +478,22 @@ var _ = Describe("SentPacketHandler", func() {
 			})
 		})
 
+		It("passes the encryption level to congestion controllers that need it", func() {
+			cc := &congestionWithEncryptionLevel{MockSendAlgorithmWithDebugInfos: cong}
+			handler.setCongestionController(cc)
+			// the same packet number is used in the Handshake and in the 1-RTT packet number space
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 0, EncryptionLevel: protocol.EncryptionHandshake}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 0, EncryptionLevel: protocol.Encryption1RTT}))
+			Expect(cc.sent).To(Equal([]packetWithEncryptionLevel{
+				{encLevel: protocol.EncryptionHandshake, pn: 0},
+				{encLevel: protocol.Encryption1RTT, pn: 0},
+			}))
+			cong.EXPECT().MaybeExitSlowStart()
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 0, Largest: 0}}}
+			Expect(handler.ReceivedAck(ack, protocol.EncryptionHandshake, time.Now())).To(Succeed())
+			Expect(cc.acked).To(Equal([]packetWithEncryptionLevel{{encLevel: protocol.EncryptionHandshake, pn: 0}}))
+		})
+
 		It("should call MaybeExitSlowStart and OnPacketAcked", func() {
 			rcvTime := time.Now().Add(-5 * time.Second)
 			cong.EXPECT().OnPacketSent(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
@@ -955,6 +999,158 @@ var _ = Describe("SentPacketHandler", func() {
 		})
 	})
 
+	Context("path migration", func() {
+		It("resets the congestion controller and the RTT estimate", func() {
+			handler.rttStats.UpdateRTT(time.Second, 0, time.Now())
+			Expect(handler.rttStats.SmoothedRTT()).To(Equal(time.Second))
+			oldCong := handler.congestion
+			handler.MigratedPath(nil, true)
+			Expect(handler.congestion).ToNot(BeIdenticalTo(oldCong))
+			Expect(handler.congestion.InSlowStart()).To(BeTrue())
+			Expect(handler.rttStats.SmoothedRTT()).To(BeZero())
+		})
+
+		It("keeps the congestion controller and the RTT estimate after a NAT rebinding", func() {
+			handler.rttStats.UpdateRTT(time.Second, 0, time.Now())
+			oldCong := handler.congestion
+			handler.ReboundPath(true)
+			Expect(handler.congestion).To(BeIdenticalTo(oldCong))
+			Expect(handler.rttStats.SmoothedRTT()).To(Equal(time.Second))
+		})
+
+		It("uses the congestion controller passed in", func() {
+			cong := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			handler.MigratedPath(cong, true)
+			Expect(handler.congestion).To(Equal(cong))
+		})
+
+		It("passes the encryption level to a congestion controller passed in that needs it", func() {
+			cc := &congestionWithEncryptionLevel{MockSendAlgorithmWithDebugInfos: mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)}
+			handler.MigratedPath(cc, true)
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, EncryptionLevel: protocol.Encryption1RTT}))
+			Expect(cc.sent).To(Equal([]packetWithEncryptionLevel{{encLevel: protocol.Encryption1RTT, pn: 1}}))
+		})
+
+		It("applies the amplification limit until the new path is validated", func() {
+			handler.SetHandshakeConfirmed()
+			handler.MigratedPath(nil, false)
+			handler.ReceivedBytes(100)
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, Length: 300}))
+			Expect(handler.SendMode()).To(Equal(SendNone))
+			Expect(handler.GetLossDetectionTimeout()).To(BeZero())
+			handler.ValidatedPath()
+			Expect(handler.SendMode()).To(Equal(SendAny))
+			Expect(handler.GetLossDetectionTimeout()).ToNot(BeZero())
+		})
+	})
+
+	Context("ACK frequency", func() {
+		var cong *mocks.MockSendAlgorithmWithDebugInfos
+
+		JustBeforeEach(func() {
+			cong = mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+			handler.setCongestionController(cong)
+			handler.rttStats.SetMaxAckDelay(42 * time.Millisecond)
+		})
+
+		It("doesn't request an ack frequency if the peer doesn't support the extension", func() {
+			handler.SetHandshakeConfirmed()
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+		})
+
+		It("doesn't request an ack frequency before the handshake is confirmed", func() {
+			handler.EnableAckFrequency()
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+		})
+
+		It("requests an ack-eliciting threshold based on the congestion window", func() {
+			handler.EnableAckFrequency()
+			handler.SetHandshakeConfirmed()
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(32 * protocol.InitialPacketSizeIPv4))
+			f := handler.GetAckFrequencyFrame()
+			Expect(f).ToNot(BeNil())
+			Expect(f.SequenceNumber).To(BeZero())
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(32/acksPerCongestionWindow - 1))
+			Expect(f.RequestMaxAckDelay).To(Equal(42 * time.Millisecond))
+			Expect(f.ReorderingThreshold).To(BeEquivalentTo(1))
+			// nothing changed
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(32*protocol.InitialPacketSizeIPv4 + 1))
+			Expect(handler.GetAckFrequencyFrame()).To(BeNil())
+			// the congestion window was reduced
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(16 * protocol.InitialPacketSizeIPv4))
+			f = handler.GetAckFrequencyFrame()
+			Expect(f).ToNot(BeNil())
+			Expect(f.SequenceNumber).To(BeEquivalentTo(1))
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(16/acksPerCongestionWindow - 1))
+		})
+
+		It("limits the ack-eliciting threshold", func() {
+			handler.EnableAckFrequency()
+			handler.SetHandshakeConfirmed()
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(1000 * protocol.InitialPacketSizeIPv4))
+			f := handler.GetAckFrequencyFrame()
+			Expect(f).ToNot(BeNil())
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(maxAckElicitingThreshold))
+			// for small congestion windows, the default threshold is used
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(2 * protocol.InitialPacketSizeIPv4))
+			f = handler.GetAckFrequencyFrame()
+			Expect(f).ToNot(BeNil())
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(1))
+		})
+
+		It("uses the current max datagram size", func() {
+			handler.EnableAckFrequency()
+			handler.SetHandshakeConfirmed()
+			cong.EXPECT().SetMaxDatagramSize(protocol.ByteCount(2000))
+			handler.SetMaxDatagramSize(2000)
+			cong.EXPECT().GetCongestionWindow().Return(protocol.ByteCount(16 * 2000))
+			f := handler.GetAckFrequencyFrame()
+			Expect(f).ToNot(BeNil())
+			Expect(f.AckElicitingThreshold).To(BeEquivalentTo(16/acksPerCongestionWindow - 1))
+		})
+	})
+
+	Context("statistics", func() {
+		It("counts sent and lost packets", func() {
+			now := time.Now()
+			for i := protocol.PacketNumber(1); i <= 6; i++ {
+				handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: i, Length: 100}))
+			}
+			stats := handler.GetStats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(6))
+			Expect(stats.BytesSent).To(Equal(protocol.ByteCount(600)))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(600)))
+			Expect(stats.CongestionWindow).To(Equal(handler.congestion.GetCongestionWindow()))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 6, Largest: 6}}}
+			Expect(handler.ReceivedAck(ack, protocol.Encryption1RTT, now)).To(Succeed())
+			stats = handler.GetStats()
+			Expect(stats.PacketsSent).To(BeEquivalentTo(6))
+			Expect(stats.PacketsLost).To(BeEquivalentTo(3))
+			Expect(stats.BytesLost).To(Equal(protocol.ByteCount(300)))
+			Expect(stats.BytesInFlight).To(Equal(protocol.ByteCount(200)))
+		})
+
+		It("doesn't count lost Path MTU probe packets", func() {
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, Length: 1500, IsPathMTUProbePacket: true, SendTime: time.Now().Add(-time.Hour)}))
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 2, SendTime: time.Now().Add(-time.Hour)}))
+			ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 2}}}
+			Expect(handler.ReceivedAck(ack, protocol.Encryption1RTT, time.Now())).To(Succeed())
+			Expect(lostPackets).To(BeEmpty()) // Path MTU probe packets are not retransmitted
+			Expect(handler.GetStats().PacketsLost).To(BeZero())
+		})
+
+		It("counts PTOs, even after receiving an ACK", func() {
+			handler.ReceivedPacket(protocol.EncryptionHandshake)
+			handler.SetHandshakeConfirmed()
+			handler.SentPacket(ackElicitingPacket(&Packet{PacketNumber: 1, SendTime: time.Now().Add(-time.Hour)}))
+			Expect(handler.OnLossDetectionTimeout()).To(Succeed())
+			Expect(handler.GetStats().PTOCount).To(BeEquivalentTo(1))
+			Expect(handler.ReceivedAck(&wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 1}}}, protocol.Encryption1RTT, time.Now())).To(Succeed())
+			Expect(handler.ptoCount).To(BeZero())
+			Expect(handler.GetStats().PTOCount).To(BeEquivalentTo(1))
+		})
+	})
+
 	Context("Packet-based loss detection", func() {
 		It("declares packet below the packet loss threshold as lost", func() {
 			now := time.Now()
@@ -23,3 +23,17 @@ const (
 func BandwidthFromDelta(bytes protocol.ByteCount, delta time.Duration) Bandwidth {
 	return Bandwidth(bytes) * Bandwidth(time.Second) / Bandwidth(delta) * BytesPerSecond
 }
+
+func minBandwidth(a, b Bandwidth) Bandwidth {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxBandwidth(a, b Bandwidth) Bandwidth {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,131 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// The sentPacketState is the state of the connection at the time a packet was sent.
+type sentPacketState struct {
+	sentTime      time.Time
+	size          protocol.ByteCount
+	delivered     protocol.ByteCount
+	deliveredTime time.Time
+	firstSentTime time.Time
+	lost          protocol.ByteCount
+	bytesInFlight protocol.ByteCount // including this packet
+	isAppLimited  bool
+}
+
+// A bandwidthSample is a delivery rate sample, taken when a packet is acknowledged.
+type bandwidthSample struct {
+	bandwidth Bandwidth
+	rtt       time.Duration
+	// The number of bytes delivered when the acknowledged packet was sent.
+	priorDelivered protocol.ByteCount
+	// The number of bytes in flight when the acknowledged packet was sent.
+	bytesInFlight protocol.ByteCount
+	isAppLimited  bool
+}
+
+// The bandwidthSampler estimates the delivery rate of a connection, as described in
+// https://datatracker.ietf.org/doc/draft-cheng-iccrg-delivery-rate-estimation/.
+type bandwidthSampler struct {
+	// The total number of bytes acknowledged.
+	delivered protocol.ByteCount
+	// The time when the last packet was acknowledged.
+	deliveredTime time.Time
+	// The send time of the most recently acknowledged packet.
+	firstSentTime time.Time
+	// The total number of bytes declared lost.
+	lost protocol.ByteCount
+	// If non-zero, the connection is application-limited until this number of bytes has been delivered.
+	appLimitedUntil protocol.ByteCount
+
+	packets map[protocol.PacketNumber]*sentPacketState
+}
+
+func newBandwidthSampler() *bandwidthSampler {
+	return &bandwidthSampler{packets: make(map[protocol.PacketNumber]*sentPacketState)}
+}
+
+// OnPacketSent records the state of the connection when sending a packet.
+// Since packet numbers are only unique within a packet number space,
+// all packets passed to the bandwidthSampler must belong to the same packet number space.
+// bytesInFlight is the number of bytes in flight, including this packet.
+func (s *bandwidthSampler) OnPacketSent(sentTime time.Time, pn protocol.PacketNumber, size, bytesInFlight protocol.ByteCount) {
+	if bytesInFlight <= size {
+		// We're restarting after an idle period.
+		// Don't count the idle time in the delivery rate.
+		s.firstSentTime = sentTime
+		s.deliveredTime = sentTime
+	}
+	s.packets[pn] = &sentPacketState{
+		sentTime:      sentTime,
+		size:          size,
+		delivered:     s.delivered,
+		deliveredTime: s.deliveredTime,
+		firstSentTime: s.firstSentTime,
+		lost:          s.lost,
+		bytesInFlight: bytesInFlight,
+		isAppLimited:  s.appLimitedUntil > 0,
+	}
+}
+
+// OnPacketAcked generates a new bandwidth sample.
+// It returns false if the packet is unknown.
+func (s *bandwidthSampler) OnPacketAcked(ackTime time.Time, pn protocol.PacketNumber) (bandwidthSample, bool) {
+	p, ok := s.packets[pn]
+	if !ok {
+		return bandwidthSample{}, false
+	}
+	delete(s.packets, pn)
+
+	s.delivered += p.size
+	s.deliveredTime = ackTime
+	if p.sentTime.After(s.firstSentTime) {
+		s.firstSentTime = p.sentTime
+	}
+	if s.appLimitedUntil > 0 && s.delivered > s.appLimitedUntil {
+		s.appLimitedUntil = 0
+	}
+
+	sample := bandwidthSample{
+		rtt:            ackTime.Sub(p.sentTime),
+		priorDelivered: p.delivered,
+		bytesInFlight:  p.bytesInFlight,
+		isAppLimited:   p.isAppLimited,
+	}
+	// Use the longer of the send and the ACK interval.
+	// This avoids overestimating the bandwidth if ACKs are compressed.
+	interval := utils.MaxDuration(p.sentTime.Sub(p.firstSentTime), ackTime.Sub(p.deliveredTime))
+	if interval > 0 {
+		sample.bandwidth = BandwidthFromDelta(s.delivered-p.delivered, interval)
+	}
+	return sample, true
+}
+
+// OnPacketLost removes the packet and accounts for the lost bytes.
+// It returns the number of bytes that were in flight when the packet was sent.
+func (s *bandwidthSampler) OnPacketLost(pn protocol.PacketNumber, size protocol.ByteCount) protocol.ByteCount {
+	s.lost += size
+	p, ok := s.packets[pn]
+	if !ok {
+		return 0
+	}
+	delete(s.packets, pn)
+	return p.bytesInFlight
+}
+
+// OnAppLimited marks the connection as application-limited.
+// All packets sent until the bytes currently in flight have been acknowledged are marked as application-limited.
+func (s *bandwidthSampler) OnAppLimited(bytesInFlight protocol.ByteCount) {
+	s.appLimitedUntil = utils.MaxByteCount(s.delivered+bytesInFlight, 1)
+}
+
+// IsAppLimited says if the connection is currently application-limited.
+func (s *bandwidthSampler) IsAppLimited() bool {
+	return s.appLimitedUntil > 0
+}
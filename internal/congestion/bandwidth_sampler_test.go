@@ -0,0 +1,98 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bandwidth Sampler", func() {
+	var (
+		s   *bandwidthSampler
+		now time.Time
+	)
+
+	BeforeEach(func() {
+		s = newBandwidthSampler()
+		now = time.Now()
+	})
+
+	It("calculates the bandwidth", func() {
+		// send 10 packets, one every millisecond
+		for i := 0; i < 10; i++ {
+			s.OnPacketSent(now.Add(time.Duration(i)*time.Millisecond), protocol.PacketNumber(i), 1000, protocol.ByteCount(i+1)*1000)
+		}
+		// acknowledge them, one every millisecond, after an RTT of 100ms
+		var sample bandwidthSample
+		for i := 0; i < 10; i++ {
+			var ok bool
+			sample, ok = s.OnPacketAcked(now.Add(time.Duration(100+i)*time.Millisecond), protocol.PacketNumber(i))
+			Expect(ok).To(BeTrue())
+		}
+		Expect(sample.rtt).To(Equal(100 * time.Millisecond))
+		Expect(sample.isAppLimited).To(BeFalse())
+		// 10 packets were acknowledged in 109ms. 1000 bytes per ms is 8 Mbit/s.
+		Expect(sample.bandwidth).To(BeNumerically("~", BandwidthFromDelta(10000, 109*time.Millisecond), 1))
+		Expect(s.delivered).To(Equal(protocol.ByteCount(10000)))
+	})
+
+	It("uses the longer of the send and the ack interval", func() {
+		s.OnPacketSent(now, 1, 1000, 1000)
+		_, ok := s.OnPacketAcked(now.Add(100*time.Millisecond), 1)
+		Expect(ok).To(BeTrue())
+		s.OnPacketSent(now.Add(100*time.Millisecond), 2, 1000, 2000)
+		s.OnPacketSent(now.Add(150*time.Millisecond), 3, 1000, 3000)
+		_, ok = s.OnPacketAcked(now.Add(200*time.Millisecond), 2)
+		Expect(ok).To(BeTrue())
+		// The ACKs arrive compressed.
+		// The ACK interval is 101ms, but the send interval is 150ms.
+		sample, ok := s.OnPacketAcked(now.Add(201*time.Millisecond), 3)
+		Expect(ok).To(BeTrue())
+		Expect(sample.bandwidth).To(Equal(BandwidthFromDelta(2000, 150*time.Millisecond)))
+	})
+
+	It("ignores unknown packets", func() {
+		_, ok := s.OnPacketAcked(now, 1337)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("accounts for lost packets", func() {
+		s.OnPacketSent(now, 1, 1000, 1000)
+		s.OnPacketSent(now, 2, 1000, 2000)
+		Expect(s.OnPacketLost(2, 1000)).To(Equal(protocol.ByteCount(2000)))
+		Expect(s.lost).To(Equal(protocol.ByteCount(1000)))
+		_, ok := s.OnPacketAcked(now.Add(time.Second), 2)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("marks samples as application-limited", func() {
+		s.OnPacketSent(now, 1, 1000, 1000)
+		s.OnAppLimited(1000)
+		Expect(s.IsAppLimited()).To(BeTrue())
+		s.OnPacketSent(now, 2, 1000, 2000)
+		sample, ok := s.OnPacketAcked(now.Add(100*time.Millisecond), 1)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeFalse())
+		Expect(s.IsAppLimited()).To(BeTrue())
+		sample, ok = s.OnPacketAcked(now.Add(100*time.Millisecond), 2)
+		Expect(ok).To(BeTrue())
+		Expect(sample.isAppLimited).To(BeTrue())
+		// All packets sent while application-limited have been acknowledged.
+		Expect(s.IsAppLimited()).To(BeFalse())
+	})
+
+	It("doesn't count idle time", func() {
+		s.OnPacketSent(now, 1, 1000, 1000)
+		_, ok := s.OnPacketAcked(now.Add(100*time.Millisecond), 1)
+		Expect(ok).To(BeTrue())
+		// The connection is idle for a while.
+		now = now.Add(10 * time.Second)
+		s.OnPacketSent(now, 2, 1000, 1000)
+		sample, ok := s.OnPacketAcked(now.Add(100*time.Millisecond), 2)
+		Expect(ok).To(BeTrue())
+		Expect(sample.bandwidth).To(Equal(BandwidthFromDelta(1000, 100*time.Millisecond)))
+	})
+})
@@ -0,0 +1,668 @@
+package congestion
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+)
+
+// This file implements BBR (version 2), as described in
+// https://datatracker.ietf.org/doc/draft-cardwell-iccrg-bbr-congestion-control/.
+
+const (
+	// The pacing gain used during startup. 2.77 = 4 * ln(2).
+	bbrStartupPacingGain = 2.77
+	// The congestion window gain used during startup.
+	bbrStartupCwndGain = 2.0
+	// The pacing gain used during drain, to drain the queue created during startup.
+	bbrDrainPacingGain = 0.35
+	// The default congestion window gain.
+	bbrCwndGain = 2.0
+	// The congestion window gain used when probing for bandwidth.
+	bbrProbeUpCwndGain = 2.25
+	// The pacing gain used when probing for bandwidth.
+	bbrProbeUpPacingGain = 1.25
+	// The pacing gain used to drain the queue after probing for bandwidth.
+	bbrProbeDownPacingGain = 0.9
+	// During ProbeRTT, the congestion window is reduced to this fraction of the BDP.
+	bbrProbeRTTCwndGain = 0.5
+
+	// BBR exits startup if the bandwidth didn't increase by at least 25%...
+	bbrFullBandwidthThreshold = 1.25
+	// ... for 3 round trips.
+	bbrFullBandwidthRounds = 3
+	// BBR exits startup if the loss rate is too high, and at least this many packets were lost in the round.
+	bbrStartupFullLossCount = 8
+	// The maximum tolerated loss rate per round trip.
+	bbrLossThreshold = 0.02
+	// The multiplicative decrease applied to the short-term model when encountering loss.
+	bbrBeta = 0.7
+	// The fraction of inflight_hi that is left unused, to leave room for other flows.
+	bbrHeadroom = 0.15
+
+	// The window of the max bandwidth filter, in bandwidth probing cycles.
+	bbrBandwidthFilterCycles = 2
+	// The min RTT estimate expires after this time.
+	bbrMinRTTWindow = 10 * time.Second
+	// BBR enters ProbeRTT if the min RTT wasn't refreshed for this time.
+	bbrProbeRTTInterval = 5 * time.Second
+	// The minimum time spent in ProbeRTT.
+	bbrProbeRTTDuration = 200 * time.Millisecond
+	// The maximum number of round trips between two bandwidth probes,
+	// in order to be fair to Reno and Cubic flows.
+	bbrMaxRoundsBetweenProbes = 63
+	// The minimum wall clock time between two bandwidth probes is 2s, randomized by up to 1s.
+	bbrMinTimeBetweenProbes = 2 * time.Second
+
+	bbrMinCongestionWindowPackets = 4
+
+	// The RTT used to calculate the pacing rate before the first RTT sample was taken.
+	bbrInitialRTT = 100 * time.Millisecond
+)
+
+type bbrMode uint8
+
+const (
+	bbrModeStartup bbrMode = iota
+	bbrModeDrain
+	bbrModeProbeBandwidth
+	bbrModeProbeRTT
+)
+
+type bbrProbeBandwidthPhase uint8
+
+const (
+	bbrProbeBandwidthDown bbrProbeBandwidthPhase = iota
+	bbrProbeBandwidthCruise
+	bbrProbeBandwidthRefill
+	bbrProbeBandwidthUp
+)
+
+type bbrSender struct {
+	clock    Clock
+	rttStats *utils.RTTStats
+	pacer    *pacer
+	sampler  *bandwidthSampler
+	rand     utils.Rand
+
+	mode  bbrMode
+	phase bbrProbeBandwidthPhase
+
+	pacingGain float64
+	cwndGain   float64
+
+	// The long-term model.
+	maxBandwidthFilter *windowedMaxFilter
+	inflightHi         protocol.ByteCount
+	// The short-term model, reduced when encountering loss.
+	bandwidthLo Bandwidth
+	inflightLo  protocol.ByteCount
+	// The most recent delivery rate sample.
+	latestBandwidth Bandwidth
+
+	minRTT               time.Duration
+	minRTTTimestamp      time.Time
+	probeRTTMin          time.Duration
+	probeRTTMinTimestamp time.Time
+	probeRTTExpired      bool
+	probeRTTDoneTime     time.Time
+	probeRTTRoundDone    bool
+
+	// round trip counting
+	roundCount          uint64
+	roundStart          bool
+	nextRoundDelivered  protocol.ByteCount
+	roundStartDelivered protocol.ByteCount
+	roundStartLost      protocol.ByteCount
+	lostPacketsInRound  int
+	lossInRound         bool
+
+	// startup
+	fullBandwidthReached bool
+	fullBandwidth        Bandwidth
+	fullBandwidthCount   int
+
+	// bandwidth probing
+	cycleCount      uint64
+	cycleStart      time.Time
+	cycleStartRound uint64
+	phaseStartRound uint64
+	bwProbeWait     time.Duration
+	probeUpIncrease protocol.ByteCount
+
+	bytesInFlight           protocol.ByteCount
+	congestionWindow        protocol.ByteCount
+	initialCongestionWindow protocol.ByteCount
+	maxDatagramSize         protocol.ByteCount
+
+	lastState logging.CongestionState
+	tracer    logging.ConnectionTracer
+}
+
+var (
+	_ SendAlgorithm               = &bbrSender{}
+	_ SendAlgorithmWithDebugInfos = &bbrSender{}
+
+	_ SendAlgorithmWithEncryptionLevel = &bbrSender{}
+)
+
+// NewBBRSender makes a new BBR sender
+func NewBBRSender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize protocol.ByteCount,
+	tracer logging.ConnectionTracer,
+) *bbrSender {
+	return newBBRSender(clock, rttStats, initialMaxDatagramSize, initialCongestionWindow*initialMaxDatagramSize, tracer)
+}
+
+func newBBRSender(
+	clock Clock,
+	rttStats *utils.RTTStats,
+	initialMaxDatagramSize,
+	initialCongestionWindow protocol.ByteCount,
+	tracer logging.ConnectionTracer,
+) *bbrSender {
+	b := &bbrSender{
+		clock:                   clock,
+		rttStats:                rttStats,
+		sampler:                 newBandwidthSampler(),
+		maxBandwidthFilter:      newWindowedMaxFilter(bbrBandwidthFilterCycles),
+		inflightHi:              protocol.MaxByteCount,
+		inflightLo:              protocol.MaxByteCount,
+		bandwidthLo:             infBandwidth,
+		congestionWindow:        initialCongestionWindow,
+		initialCongestionWindow: initialCongestionWindow,
+		maxDatagramSize:         initialMaxDatagramSize,
+		tracer:                  tracer,
+	}
+	b.pacer = newPacerWithRate(b.pacingRate)
+	b.pacer.SetMaxDatagramSize(initialMaxDatagramSize)
+	b.enterStartup()
+	return b
+}
+
+// TimeUntilSend returns when the next packet should be sent.
+func (b *bbrSender) TimeUntilSend(_ protocol.ByteCount) time.Time {
+	return b.pacer.TimeUntilSend()
+}
+
+func (b *bbrSender) HasPacingBudget() bool {
+	return b.pacer.Budget(b.clock.Now()) >= b.maxDatagramSize
+}
+
+// OnPacketSent is called for packets sent in the application data packet number space.
+func (b *bbrSender) OnPacketSent(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	b.OnPacketSentWithEncryptionLevel(sentTime, bytesInFlight, protocol.Encryption1RTT, packetNumber, bytes, isRetransmittable)
+}
+
+func (b *bbrSender) OnPacketSentWithEncryptionLevel(
+	sentTime time.Time,
+	bytesInFlight protocol.ByteCount,
+	encLevel protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	bytes protocol.ByteCount,
+	isRetransmittable bool,
+) {
+	b.pacer.SentPacket(sentTime, bytes)
+	if !isRetransmittable {
+		return
+	}
+	b.bytesInFlight = bytesInFlight
+	if isApplicationData(encLevel) {
+		b.sampler.OnPacketSent(sentTime, packetNumber, bytes, bytesInFlight)
+	}
+}
+
+// Packet numbers are only unique within a packet number space.
+// Only packets in the application data packet number space are used for bandwidth sampling.
+// Initial and Handshake packets are only sent during the first few round trips of the connection.
+func isApplicationData(encLevel protocol.EncryptionLevel) bool {
+	return encLevel == protocol.Encryption0RTT || encLevel == protocol.Encryption1RTT
+}
+
+func (b *bbrSender) CanSend(bytesInFlight protocol.ByteCount) bool {
+	return bytesInFlight < b.GetCongestionWindow()
+}
+
+// MaybeExitSlowStart is a no-op for BBR.
+// BBR determines when to exit startup based on its bandwidth estimate.
+func (b *bbrSender) MaybeExitSlowStart() {}
+
+// OnPacketAcked is called for packets sent in the application data packet number space.
+func (b *bbrSender) OnPacketAcked(
+	packetNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.OnPacketAckedWithEncryptionLevel(protocol.Encryption1RTT, packetNumber, ackedBytes, priorInFlight, eventTime)
+}
+
+func (b *bbrSender) OnPacketAckedWithEncryptionLevel(
+	encLevel protocol.EncryptionLevel,
+	packetNumber protocol.PacketNumber,
+	ackedBytes protocol.ByteCount,
+	priorInFlight protocol.ByteCount,
+	eventTime time.Time,
+) {
+	b.bytesInFlight = b.subtractFromBytesInFlight(ackedBytes)
+	if !isApplicationData(encLevel) {
+		// Initial and Handshake packets aren't used for bandwidth sampling,
+		// but they still grow the congestion window.
+		b.updateCongestionWindow(ackedBytes)
+		return
+	}
+	sample, ok := b.sampler.OnPacketAcked(eventTime, packetNumber)
+	if !ok {
+		return
+	}
+	b.updateRound(sample)
+	b.updateMinRTT(sample.rtt, eventTime)
+	b.updateBandwidth(sample)
+	switch b.mode {
+	case bbrModeStartup:
+		b.checkFullBandwidthReached(sample)
+		if b.fullBandwidthReached {
+			b.enterDrain()
+		}
+	case bbrModeDrain:
+		if b.bytesInFlight <= b.bdp(1) {
+			b.enterProbeBandwidth(eventTime)
+		}
+	case bbrModeProbeBandwidth:
+		b.updateProbeBandwidthPhase(eventTime)
+	}
+	b.maybeEnterOrExitProbeRTT(eventTime)
+
+	// The SendAlgorithm doesn't learn if the application ran out of data.
+	// Pacing at the estimated bandwidth (or faster) should fill the pipe,
+	// so if less than one BDP is in flight, the application didn't supply enough data.
+	if b.pacingGain >= 1 && priorInFlight < b.bdp(1) {
+		b.sampler.OnAppLimited(b.bytesInFlight)
+	}
+	b.updateCongestionWindow(ackedBytes)
+}
+
+// OnPacketLost is called for packets sent in the application data packet number space.
+func (b *bbrSender) OnPacketLost(packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	b.OnPacketLostWithEncryptionLevel(protocol.Encryption1RTT, packetNumber, lostBytes, priorInFlight)
+}
+
+func (b *bbrSender) OnPacketLostWithEncryptionLevel(encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber, lostBytes, priorInFlight protocol.ByteCount) {
+	b.bytesInFlight = b.subtractFromBytesInFlight(lostBytes)
+	if !isApplicationData(encLevel) {
+		return
+	}
+	inflightAtSend := b.sampler.OnPacketLost(packetNumber, lostBytes)
+	b.lostPacketsInRound++
+	if !b.isLossTooHigh() {
+		return
+	}
+	switch {
+	case b.mode == bbrModeStartup:
+		if b.lostPacketsInRound >= bbrStartupFullLossCount {
+			b.fullBandwidthReached = true
+			b.inflightHi = utils.MaxByteCount(b.bdp(1), inflightAtSend)
+			b.enterDrain()
+		}
+	case b.mode == bbrModeProbeBandwidth && b.phase == bbrProbeBandwidthUp:
+		// We probed too far. Remember the amount of data in flight that caused the loss.
+		b.inflightHi = utils.MaxByteCount(inflightAtSend, protocol.ByteCount(bbrBeta*float64(b.targetInflight())))
+		b.startProbeBandwidthDown(b.clock.Now())
+	}
+	if !b.lossInRound && b.mode != bbrModeStartup {
+		// Reduce the short-term model once per round trip.
+		b.bandwidthLo = maxBandwidth(b.latestBandwidth, Bandwidth(bbrBeta*float64(minBandwidth(b.bandwidthLo, b.maxBandwidthFilter.GetBest()))))
+		b.inflightLo = protocol.ByteCount(bbrBeta * float64(utils.MinByteCount(b.inflightLo, b.congestionWindow)))
+		b.updateCongestionWindow(0)
+	}
+	b.lossInRound = true
+}
+
+// OnRetransmissionTimeout is called on an retransmission timeout
+func (b *bbrSender) OnRetransmissionTimeout(packetsRetransmitted bool) {
+	if !packetsRetransmitted {
+		return
+	}
+	b.congestionWindow = b.minCongestionWindow()
+}
+
+func (b *bbrSender) SetMaxDatagramSize(s protocol.ByteCount) {
+	if s < b.maxDatagramSize {
+		panic(fmt.Sprintf("congestion BUG: decreased max datagram size from %d to %d", b.maxDatagramSize, s))
+	}
+	cwndIsMinCwnd := b.congestionWindow == b.minCongestionWindow()
+	b.maxDatagramSize = s
+	if cwndIsMinCwnd {
+		b.congestionWindow = b.minCongestionWindow()
+	}
+	b.pacer.SetMaxDatagramSize(s)
+}
+
+func (b *bbrSender) InSlowStart() bool {
+	return b.mode == bbrModeStartup
+}
+
+// InRecovery returns false, since BBR doesn't use a loss recovery state.
+func (b *bbrSender) InRecovery() bool {
+	return false
+}
+
+func (b *bbrSender) GetCongestionWindow() protocol.ByteCount {
+	return b.congestionWindow
+}
+
+// BandwidthEstimate returns the current bandwidth estimate.
+// It returns 0 if no bandwidth sample has been taken yet.
+func (b *bbrSender) BandwidthEstimate() Bandwidth {
+	return minBandwidth(b.maxBandwidthFilter.GetBest(), b.bandwidthLo)
+}
+
+// pacingRate returns the pacing rate in bytes/s.
+func (b *bbrSender) pacingRate() uint64 {
+	bw := b.BandwidthEstimate()
+	if bw == 0 {
+		// We haven't taken any bandwidth samples yet.
+		// Use the initial congestion window and the RTT to calculate the rate.
+		srtt := b.rttStats.SmoothedRTT()
+		if srtt == 0 {
+			srtt = bbrInitialRTT
+		}
+		bw = BandwidthFromDelta(b.initialCongestionWindow, srtt)
+	}
+	rate := b.pacingGain * float64(bw/BytesPerSecond)
+	if rate < 1 {
+		return 1
+	}
+	if rate >= math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return uint64(rate)
+}
+
+func (b *bbrSender) minCongestionWindow() protocol.ByteCount {
+	return bbrMinCongestionWindowPackets * b.maxDatagramSize
+}
+
+func (b *bbrSender) maxCongestionWindow() protocol.ByteCount {
+	return protocol.MaxCongestionWindowPackets * b.maxDatagramSize
+}
+
+// bdp calculates the bandwidth-delay product, multiplied by the gain
+func (b *bbrSender) bdp(gain float64) protocol.ByteCount {
+	bw := b.BandwidthEstimate()
+	if b.minRTT == 0 || bw == 0 {
+		return b.initialCongestionWindow
+	}
+	return protocol.ByteCount(gain * float64(bw/BytesPerSecond) * b.minRTT.Seconds())
+}
+
+// targetInflight is the amount of data BBR aims to keep in flight.
+func (b *bbrSender) targetInflight() protocol.ByteCount {
+	return utils.MinByteCount(b.bdp(1), b.congestionWindow)
+}
+
+// inflightWithHeadroom is inflight_hi, leaving some headroom for other flows.
+func (b *bbrSender) inflightWithHeadroom() protocol.ByteCount {
+	if b.inflightHi == protocol.MaxByteCount {
+		return protocol.MaxByteCount
+	}
+	headroom := utils.MaxByteCount(protocol.ByteCount(bbrHeadroom*float64(b.inflightHi)), b.maxDatagramSize)
+	return utils.MaxByteCount(b.inflightHi-headroom, b.minCongestionWindow())
+}
+
+func (b *bbrSender) probeRTTCongestionWindow() protocol.ByteCount {
+	return utils.MaxByteCount(b.bdp(bbrProbeRTTCwndGain), b.minCongestionWindow())
+}
+
+func (b *bbrSender) subtractFromBytesInFlight(bytes protocol.ByteCount) protocol.ByteCount {
+	if bytes > b.bytesInFlight {
+		return 0
+	}
+	return b.bytesInFlight - bytes
+}
+
+func (b *bbrSender) isLossTooHigh() bool {
+	lost := b.sampler.lost - b.roundStartLost
+	delivered := b.sampler.delivered - b.roundStartDelivered
+	return float64(lost) > bbrLossThreshold*float64(lost+delivered)
+}
+
+func (b *bbrSender) updateRound(sample bandwidthSample) {
+	b.roundStart = false
+	if sample.priorDelivered < b.nextRoundDelivered {
+		return
+	}
+	b.startRound()
+	b.roundCount++
+	b.roundStart = true
+}
+
+func (b *bbrSender) startRound() {
+	b.nextRoundDelivered = b.sampler.delivered
+	b.roundStartDelivered = b.sampler.delivered
+	b.roundStartLost = b.sampler.lost
+	b.lostPacketsInRound = 0
+	b.lossInRound = false
+}
+
+func (b *bbrSender) updateMinRTT(rtt time.Duration, now time.Time) {
+	if rtt <= 0 {
+		return
+	}
+	b.probeRTTExpired = !b.probeRTTMinTimestamp.IsZero() && now.After(b.probeRTTMinTimestamp.Add(bbrProbeRTTInterval))
+	if b.probeRTTMin == 0 || rtt < b.probeRTTMin || b.probeRTTExpired {
+		b.probeRTTMin = rtt
+		b.probeRTTMinTimestamp = now
+	}
+	minRTTExpired := now.After(b.minRTTTimestamp.Add(bbrMinRTTWindow))
+	if b.minRTT == 0 || b.probeRTTMin < b.minRTT || minRTTExpired {
+		b.minRTT = b.probeRTTMin
+		b.minRTTTimestamp = b.probeRTTMinTimestamp
+	}
+}
+
+func (b *bbrSender) updateBandwidth(sample bandwidthSample) {
+	if sample.bandwidth == 0 {
+		return
+	}
+	b.latestBandwidth = sample.bandwidth
+	// Application-limited samples are only used if they increase the estimate.
+	if !sample.isAppLimited || sample.bandwidth >= b.maxBandwidthFilter.GetBest() {
+		b.maxBandwidthFilter.Update(sample.bandwidth, b.cycleCount)
+	}
+}
+
+func (b *bbrSender) checkFullBandwidthReached(sample bandwidthSample) {
+	if b.fullBandwidthReached || !b.roundStart || sample.isAppLimited {
+		return
+	}
+	if bw := b.maxBandwidthFilter.GetBest(); float64(bw) >= bbrFullBandwidthThreshold*float64(b.fullBandwidth) {
+		b.fullBandwidth = bw
+		b.fullBandwidthCount = 0
+		return
+	}
+	b.fullBandwidthCount++
+	b.fullBandwidthReached = b.fullBandwidthCount >= bbrFullBandwidthRounds
+}
+
+func (b *bbrSender) enterStartup() {
+	b.mode = bbrModeStartup
+	b.pacingGain = bbrStartupPacingGain
+	b.cwndGain = bbrStartupCwndGain
+	b.maybeTraceStateChange(logging.CongestionStateStartup)
+}
+
+func (b *bbrSender) enterDrain() {
+	b.mode = bbrModeDrain
+	b.pacingGain = bbrDrainPacingGain
+	b.cwndGain = bbrStartupCwndGain
+	b.maybeTraceStateChange(logging.CongestionStateDrain)
+}
+
+func (b *bbrSender) enterProbeBandwidth(now time.Time) {
+	b.mode = bbrModeProbeBandwidth
+	b.startProbeBandwidthDown(now)
+}
+
+func (b *bbrSender) setProbeBandwidthPhase(phase bbrProbeBandwidthPhase) {
+	b.phase = phase
+	b.phaseStartRound = b.roundCount
+	b.cwndGain = bbrCwndGain
+	switch phase {
+	case bbrProbeBandwidthDown:
+		b.pacingGain = bbrProbeDownPacingGain
+		b.maybeTraceStateChange(logging.CongestionStateProbeBandwidthDown)
+	case bbrProbeBandwidthCruise:
+		b.pacingGain = 1
+		b.maybeTraceStateChange(logging.CongestionStateProbeBandwidthCruise)
+	case bbrProbeBandwidthRefill:
+		b.pacingGain = 1
+		b.maybeTraceStateChange(logging.CongestionStateProbeBandwidthRefill)
+	case bbrProbeBandwidthUp:
+		b.pacingGain = bbrProbeUpPacingGain
+		b.cwndGain = bbrProbeUpCwndGain
+		b.maybeTraceStateChange(logging.CongestionStateProbeBandwidthUp)
+	}
+}
+
+func (b *bbrSender) startProbeBandwidthDown(now time.Time) {
+	b.cycleStart = now
+	b.cycleStartRound = b.roundCount
+	b.bwProbeWait = bbrMinTimeBetweenProbes + time.Duration(b.rand.Int31n(int32(time.Second/time.Millisecond)))*time.Millisecond
+	b.setProbeBandwidthPhase(bbrProbeBandwidthDown)
+}
+
+func (b *bbrSender) startProbeBandwidthRefill() {
+	// Forget the short-term model, and start a new bandwidth probing cycle.
+	b.bandwidthLo = infBandwidth
+	b.inflightLo = protocol.MaxByteCount
+	b.cycleCount++
+	b.startRound()
+	b.setProbeBandwidthPhase(bbrProbeBandwidthRefill)
+}
+
+func (b *bbrSender) startProbeBandwidthUp() {
+	b.probeUpIncrease = b.maxDatagramSize
+	b.startRound()
+	b.setProbeBandwidthPhase(bbrProbeBandwidthUp)
+}
+
+func (b *bbrSender) isTimeToProbeBandwidth(now time.Time) bool {
+	return now.Sub(b.cycleStart) >= b.bwProbeWait || b.roundCount-b.cycleStartRound >= bbrMaxRoundsBetweenProbes
+}
+
+func (b *bbrSender) updateProbeBandwidthPhase(now time.Time) {
+	switch b.phase {
+	case bbrProbeBandwidthDown:
+		if b.isTimeToProbeBandwidth(now) {
+			b.startProbeBandwidthRefill()
+			return
+		}
+		if b.bytesInFlight <= utils.MinByteCount(b.bdp(1), b.inflightWithHeadroom()) {
+			b.setProbeBandwidthPhase(bbrProbeBandwidthCruise)
+		}
+	case bbrProbeBandwidthCruise:
+		if b.isTimeToProbeBandwidth(now) {
+			b.startProbeBandwidthRefill()
+		}
+	case bbrProbeBandwidthRefill:
+		// Refill the pipe for one round trip, before starting to probe.
+		if b.roundStart {
+			b.startProbeBandwidthUp()
+		}
+	case bbrProbeBandwidthUp:
+		if b.roundStart && b.inflightHi != protocol.MaxByteCount && b.bytesInFlight+b.maxDatagramSize >= b.inflightHi {
+			// We didn't encounter any loss in the last round trip, and were limited by inflight_hi.
+			// Grow inflight_hi exponentially.
+			b.inflightHi += b.probeUpIncrease
+			b.probeUpIncrease *= 2
+		}
+		if b.roundCount > b.phaseStartRound && b.bytesInFlight >= b.bdp(bbrProbeUpPacingGain) {
+			b.startProbeBandwidthDown(now)
+		}
+	}
+}
+
+func (b *bbrSender) maybeEnterOrExitProbeRTT(now time.Time) {
+	if b.mode != bbrModeProbeRTT && b.probeRTTExpired {
+		b.mode = bbrModeProbeRTT
+		b.pacingGain = 1
+		b.cwndGain = bbrProbeRTTCwndGain
+		b.probeRTTDoneTime = time.Time{}
+		b.maybeTraceStateChange(logging.CongestionStateProbeRTT)
+	}
+	if b.mode != bbrModeProbeRTT {
+		return
+	}
+	if b.probeRTTDoneTime.IsZero() {
+		if b.bytesInFlight <= b.probeRTTCongestionWindow() {
+			b.probeRTTDoneTime = now.Add(bbrProbeRTTDuration)
+			b.probeRTTRoundDone = false
+			b.startRound()
+		}
+		return
+	}
+	if b.roundStart {
+		b.probeRTTRoundDone = true
+	}
+	if b.probeRTTRoundDone && !now.Before(b.probeRTTDoneTime) {
+		b.probeRTTMinTimestamp = now
+		b.probeRTTExpired = false
+		b.exitProbeRTT(now)
+	}
+}
+
+func (b *bbrSender) exitProbeRTT(now time.Time) {
+	b.bandwidthLo = infBandwidth
+	b.inflightLo = protocol.MaxByteCount
+	if !b.fullBandwidthReached {
+		b.enterStartup()
+		return
+	}
+	b.mode = bbrModeProbeBandwidth
+	b.cycleStart = now
+	b.cycleStartRound = b.roundCount
+	b.setProbeBandwidthPhase(bbrProbeBandwidthCruise)
+}
+
+func (b *bbrSender) updateCongestionWindow(ackedBytes protocol.ByteCount) {
+	target := b.bdp(b.cwndGain) + maxBurstPackets*b.maxDatagramSize
+	if b.fullBandwidthReached {
+		b.congestionWindow = utils.MinByteCount(b.congestionWindow+ackedBytes, target)
+	} else if b.congestionWindow < target || b.sampler.delivered < b.initialCongestionWindow {
+		b.congestionWindow += ackedBytes
+	}
+
+	// Apply the bounds of the model.
+	if b.mode == bbrModeProbeRTT {
+		b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.probeRTTCongestionWindow())
+	}
+	if b.mode == bbrModeProbeBandwidth && (b.phase == bbrProbeBandwidthDown || b.phase == bbrProbeBandwidthCruise) {
+		b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.inflightWithHeadroom())
+	} else {
+		b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.inflightHi)
+	}
+	b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.inflightLo)
+	b.congestionWindow = utils.MinByteCount(b.congestionWindow, b.maxCongestionWindow())
+	b.congestionWindow = utils.MaxByteCount(b.congestionWindow, b.minCongestionWindow())
+}
+
+func (b *bbrSender) maybeTraceStateChange(new logging.CongestionState) {
+	if b.tracer == nil || new == b.lastState {
+		return
+	}
+	b.tracer.UpdatedCongestionState(new)
+	b.lastState = new
+}
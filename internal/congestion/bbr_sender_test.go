@@ -0,0 +1,255 @@
+package congestion
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BBR Sender", func() {
+	const (
+		packetSize = protocol.ByteCount(1200)
+		linkRate   = 1250 * 1000 // 10 Mbit/s, in bytes/s
+		linkRTT    = 50 * time.Millisecond
+		linkBDP    = protocol.ByteCount(linkRate * 50 / 1000)
+	)
+
+	type inFlightPacket struct {
+		pn       protocol.PacketNumber
+		sentTime time.Time
+		ackTime  time.Time
+		lost     bool
+	}
+
+	var (
+		sender        *bbrSender
+		clock         mockClock
+		rttStats      *utils.RTTStats
+		bytesInFlight protocol.ByteCount
+		packetNumber  protocol.PacketNumber
+		inFlight      []inFlightPacket
+		linkFreeAt    time.Time
+		// The size of the bottleneck buffer, in bytes. Packets exceeding the buffer are dropped.
+		bufferSize protocol.ByteCount
+	)
+
+	BeforeEach(func() {
+		clock = mockClock(time.Now())
+		rttStats = utils.NewRTTStats()
+		sender = newBBRSender(&clock, rttStats, packetSize, 10*packetSize, nil)
+		bytesInFlight = 0
+		packetNumber = 0
+		inFlight = nil
+		linkFreeAt = time.Time{}
+		bufferSize = protocol.MaxByteCount
+	})
+
+	// simulate sends packets over a link with a fixed bandwidth and RTT
+	simulate := func(duration time.Duration) {
+		end := clock.Now().Add(duration)
+		for clock.Now().Before(end) {
+			now := clock.Now()
+			// process ACKs and losses
+			priorInFlight := bytesInFlight
+			for len(inFlight) > 0 && !inFlight[0].ackTime.After(now) {
+				p := inFlight[0]
+				inFlight = inFlight[1:]
+				bytesInFlight -= packetSize
+				if p.lost {
+					sender.OnPacketLost(p.pn, packetSize, priorInFlight)
+					continue
+				}
+				rttStats.UpdateRTT(now.Sub(p.sentTime), 0, now)
+				sender.OnPacketAcked(p.pn, packetSize, priorInFlight, now)
+			}
+			// send packets
+			for sender.CanSend(bytesInFlight) && !sender.TimeUntilSend(bytesInFlight).After(now) {
+				packetNumber++
+				bytesInFlight += packetSize
+				sender.OnPacketSent(now, bytesInFlight, packetNumber, packetSize, true)
+				queueStart := linkFreeAt
+				if queueStart.Before(now) {
+					queueStart = now
+				}
+				queued := protocol.ByteCount(queueStart.Sub(now).Seconds() * linkRate)
+				departure := queueStart.Add(time.Duration(float64(packetSize) / linkRate * float64(time.Second)))
+				p := inFlightPacket{pn: packetNumber, sentTime: now, ackTime: departure.Add(linkRTT)}
+				if queued > bufferSize {
+					p.lost = true
+				} else {
+					linkFreeAt = departure
+				}
+				inFlight = append(inFlight, p)
+			}
+			clock.Advance(100 * time.Microsecond)
+		}
+	}
+
+	It("starts in startup", func() {
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.InRecovery()).To(BeFalse())
+		Expect(sender.GetCongestionWindow()).To(Equal(10 * packetSize))
+		Expect(sender.BandwidthEstimate()).To(BeZero())
+	})
+
+	It("traces the initial state", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+		tracer.EXPECT().UpdatedCongestionState(logging.CongestionStateStartup)
+		newBBRSender(&clock, rttStats, packetSize, 10*packetSize, tracer)
+	})
+
+	It("grows the congestion window during startup", func() {
+		simulate(2 * linkRTT)
+		Expect(sender.InSlowStart()).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">", 10*packetSize))
+	})
+
+	It("exits startup and estimates the bandwidth", func() {
+		simulate(2 * time.Second)
+		Expect(sender.InSlowStart()).To(BeFalse())
+		Expect(sender.fullBandwidthReached).To(BeTrue())
+		Expect(sender.mode).To(Equal(bbrModeProbeBandwidth))
+		Expect(sender.BandwidthEstimate()).To(BeNumerically("~", linkRate*BytesPerSecond, linkRate*BytesPerSecond/5))
+		Expect(sender.minRTT).To(BeNumerically("~", linkRTT, 5*time.Millisecond))
+		// The congestion window is limited to twice the BDP (plus some extra packets).
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", 2*linkBDP*5/4))
+	})
+
+	It("cycles through the bandwidth probing phases", func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		defer mockCtrl.Finish()
+		tracer := mocklogging.NewMockConnectionTracer(mockCtrl)
+		var states []logging.CongestionState
+		tracer.EXPECT().UpdatedCongestionState(gomock.Any()).Do(func(s logging.CongestionState) {
+			states = append(states, s)
+		}).AnyTimes()
+		sender = newBBRSender(&clock, rttStats, packetSize, 10*packetSize, tracer)
+		simulate(4 * time.Second)
+		Expect(states).To(ContainElement(logging.CongestionStateDrain))
+		Expect(states).To(ContainElement(logging.CongestionStateProbeBandwidthDown))
+		Expect(states).To(ContainElement(logging.CongestionStateProbeBandwidthCruise))
+		Expect(states).To(ContainElement(logging.CongestionStateProbeBandwidthRefill))
+		Expect(states).To(ContainElement(logging.CongestionStateProbeBandwidthUp))
+		Expect(states[0]).To(Equal(logging.CongestionStateStartup))
+	})
+
+	It("enters ProbeRTT when the min RTT wasn't updated for a while", func() {
+		simulate(2 * time.Second)
+		Expect(sender.mode).To(Equal(bbrModeProbeBandwidth))
+		var enteredProbeRTT bool
+		for i := 0; i < 8000; i++ {
+			simulate(time.Millisecond)
+			if sender.mode == bbrModeProbeRTT {
+				enteredProbeRTT = true
+				break
+			}
+		}
+		Expect(enteredProbeRTT).To(BeTrue())
+		Expect(sender.GetCongestionWindow()).To(Equal(sender.probeRTTCongestionWindow()))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("~", linkBDP/2, linkBDP/10))
+		start := clock.Now()
+		for sender.mode == bbrModeProbeRTT {
+			simulate(time.Millisecond)
+		}
+		Expect(clock.Now().Sub(start)).To(BeNumerically(">=", bbrProbeRTTDuration))
+		Expect(sender.mode).To(Equal(bbrModeProbeBandwidth))
+	})
+
+	It("exits startup when encountering too much loss", func() {
+		bufferSize = linkBDP / 4
+		simulate(time.Second)
+		Expect(sender.fullBandwidthReached).To(BeTrue())
+		Expect(sender.inflightHi).To(BeNumerically("<", protocol.MaxByteCount))
+		Expect(sender.InSlowStart()).To(BeFalse())
+	})
+
+	It("limits the amount of data in flight after loss", func() {
+		bufferSize = linkBDP / 4
+		simulate(5 * time.Second)
+		// inflight_hi is close to the BDP, plus the bottleneck buffer.
+		Expect(sender.inflightHi).To(BeNumerically("<", 2*linkBDP))
+		Expect(sender.GetCongestionWindow()).To(BeNumerically("<=", sender.inflightHi))
+	})
+
+	It("reduces the congestion window on retransmission timeouts", func() {
+		simulate(time.Second)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">", 4*packetSize))
+		sender.OnRetransmissionTimeout(false)
+		Expect(sender.GetCongestionWindow()).To(BeNumerically(">", 4*packetSize))
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.GetCongestionWindow()).To(Equal(4 * packetSize))
+	})
+
+	It("paces packets", func() {
+		simulate(2 * time.Second)
+		// Send a burst of packets. The pacer should prevent sending all of them at once.
+		for i := 0; i < 20; i++ {
+			packetNumber++
+			sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, packetSize, true)
+		}
+		Expect(sender.HasPacingBudget()).To(BeFalse())
+		Expect(sender.TimeUntilSend(bytesInFlight)).To(BeTemporally(">", clock.Now()))
+	})
+
+	It("paces packets before the first RTT sample", func() {
+		Expect(rttStats.SmoothedRTT()).To(BeZero())
+		// 10 packets per 100ms, multiplied by the startup pacing gain
+		Expect(sender.pacingRate()).To(BeNumerically("~", bbrStartupPacingGain*float64(10*packetSize*10), 1))
+		for i := 0; i < 10; i++ {
+			packetNumber++
+			sender.OnPacketSent(clock.Now(), bytesInFlight, packetNumber, packetSize, true)
+			bytesInFlight += packetSize
+		}
+		Expect(sender.HasPacingBudget()).To(BeFalse())
+		// the budget refills over time
+		clock.Advance(10 * time.Millisecond)
+		Expect(sender.HasPacingBudget()).To(BeTrue())
+	})
+
+	It("only samples packets in the application data packet number space", func() {
+		now := clock.Now()
+		sender.OnPacketSentWithEncryptionLevel(now, packetSize, protocol.EncryptionInitial, 0, packetSize, true)
+		sender.OnPacketSentWithEncryptionLevel(now, 2*packetSize, protocol.Encryption1RTT, 0, packetSize, true)
+		Expect(sender.sampler.packets).To(HaveLen(1))
+		// an ACK for the Initial packet doesn't consume the state of the 1-RTT packet
+		sender.OnPacketAckedWithEncryptionLevel(protocol.EncryptionInitial, 0, packetSize, 2*packetSize, now.Add(linkRTT))
+		Expect(sender.sampler.packets).To(HaveKey(protocol.PacketNumber(0)))
+		Expect(sender.sampler.delivered).To(BeZero())
+		Expect(sender.bytesInFlight).To(Equal(packetSize))
+		sender.OnPacketAckedWithEncryptionLevel(protocol.Encryption1RTT, 0, packetSize, packetSize, now.Add(linkRTT))
+		Expect(sender.sampler.packets).To(BeEmpty())
+		Expect(sender.sampler.delivered).To(Equal(packetSize))
+	})
+
+	It("grows the congestion window when Initial and Handshake packets are acknowledged", func() {
+		now := clock.Now()
+		sender.OnPacketSentWithEncryptionLevel(now, packetSize, protocol.EncryptionInitial, 0, packetSize, true)
+		sender.OnPacketSentWithEncryptionLevel(now, 2*packetSize, protocol.EncryptionHandshake, 0, packetSize, true)
+		sender.OnPacketAckedWithEncryptionLevel(protocol.EncryptionInitial, 0, packetSize, 2*packetSize, now.Add(linkRTT))
+		Expect(sender.GetCongestionWindow()).To(Equal(11 * packetSize))
+		sender.OnPacketAckedWithEncryptionLevel(protocol.EncryptionHandshake, 0, packetSize, packetSize, now.Add(linkRTT))
+		Expect(sender.GetCongestionWindow()).To(Equal(12 * packetSize))
+		Expect(sender.sampler.delivered).To(BeZero())
+	})
+
+	It("doesn't allow reducing the max datagram size", func() {
+		sender.SetMaxDatagramSize(1400)
+		Expect(func() { sender.SetMaxDatagramSize(1399) }).To(Panic())
+	})
+
+	It("adjusts the minimum congestion window when the max datagram size increases", func() {
+		sender.OnRetransmissionTimeout(true)
+		Expect(sender.GetCongestionWindow()).To(Equal(4 * packetSize))
+		sender.SetMaxDatagramSize(1400)
+		Expect(sender.GetCongestionWindow()).To(Equal(4 * protocol.ByteCount(1400)))
+	})
+})
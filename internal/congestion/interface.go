@@ -19,6 +19,15 @@ type SendAlgorithm interface {
 	SetMaxDatagramSize(protocol.ByteCount)
 }
 
+// A SendAlgorithmWithEncryptionLevel is a SendAlgorithm that needs to know the encryption level of packets,
+// since packet numbers are only unique within a packet number space.
+// If a congestion controller implements it, these methods are called instead of OnPacketSent, OnPacketAcked and OnPacketLost.
+type SendAlgorithmWithEncryptionLevel interface {
+	OnPacketSentWithEncryptionLevel(sentTime time.Time, bytesInFlight protocol.ByteCount, encLevel protocol.EncryptionLevel, packetNumber protocol.PacketNumber, bytes protocol.ByteCount, isRetransmittable bool)
+	OnPacketAckedWithEncryptionLevel(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, ackedBytes protocol.ByteCount, priorInFlight protocol.ByteCount, eventTime time.Time)
+	OnPacketLostWithEncryptionLevel(encLevel protocol.EncryptionLevel, number protocol.PacketNumber, lostBytes protocol.ByteCount, priorInFlight protocol.ByteCount)
+}
+
 // A SendAlgorithmWithDebugInfos is a SendAlgorithm that exposes some debug infos
 type SendAlgorithmWithDebugInfos interface {
 	SendAlgorithm
@@ -26,3 +35,11 @@ type SendAlgorithmWithDebugInfos interface {
 	InRecovery() bool
 	GetCongestionWindow() protocol.ByteCount
 }
+
+// A Pacer paces the sending of packets
+type Pacer interface {
+	SentPacket(sendTime time.Time, size protocol.ByteCount)
+	Budget(now time.Time) protocol.ByteCount
+	TimeUntilSend() time.Time
+	SetMaxDatagramSize(protocol.ByteCount)
+}
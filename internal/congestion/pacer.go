@@ -18,18 +18,32 @@ type pacer struct {
 	getAdjustedBandwidth func() uint64 // in bytes/s
 }
 
+var _ Pacer = &pacer{}
+
+// NewPacer creates a new token bucket pacer.
+// The bandwidth estimate is used to determine the pacing rate.
+func NewPacer(getBandwidth func() Bandwidth) Pacer {
+	return newPacer(getBandwidth)
+}
+
 func newPacer(getBandwidth func() Bandwidth) *pacer {
+	return newPacerWithRate(func() uint64 {
+		// Bandwidth is in bits/s. We need the value in bytes/s.
+		bw := uint64(getBandwidth() / BytesPerSecond)
+		// Use a slightly higher value than the actual measured bandwidth.
+		// RTT variations then won't result in under-utilization of the congestion window.
+		// Ultimately, this will  result in sending packets as acknowledgments are received rather than when timers fire,
+		// provided the congestion window is fully utilized and acknowledgments arrive at regular intervals.
+		return bw * 5 / 4
+	})
+}
+
+// newPacerWithRate creates a pacer that paces at exactly the rate returned by getPacingRate (in bytes/s).
+// This is useful for congestion controllers that calculate the pacing rate themselves.
+func newPacerWithRate(getPacingRate func() uint64) *pacer {
 	p := &pacer{
-		maxDatagramSize: initialMaxDatagramSize,
-		getAdjustedBandwidth: func() uint64 {
-			// Bandwidth is in bits/s. We need the value in bytes/s.
-			bw := uint64(getBandwidth() / BytesPerSecond)
-			// Use a slightly higher value than the actual measured bandwidth.
-			// RTT variations then won't result in under-utilization of the congestion window.
-			// Ultimately, this will  result in sending packets as acknowledgments are received rather than when timers fire,
-			// provided the congestion window is fully utilized and acknowledgments arrive at regular intervals.
-			return bw * 5 / 4
-		},
+		maxDatagramSize:      initialMaxDatagramSize,
+		getAdjustedBandwidth: getPacingRate,
 	}
 	p.budgetAtLastSent = p.maxBurstSize()
 	return p
@@ -128,4 +128,11 @@ var _ = Describe("Pacer", func() {
 		Expect(p.TimeUntilSend()).To(Equal(t.Add(protocol.MinPacingDelay)))
 		Expect(p.Budget(t.Add(protocol.MinPacingDelay))).To(Equal(protocol.ByteCount(protocol.MinPacingDelay) * initialMaxDatagramSize * 1e6 / 1e9))
 	})
+
+	It("paces at exactly the rate, when the rate is set directly", func() {
+		p = newPacerWithRate(func() uint64 { return packetsPerSecond * uint64(initialMaxDatagramSize) })
+		t := time.Now()
+		sendBurst(t)
+		Expect(p.TimeUntilSend()).To(Equal(t.Add(time.Second / packetsPerSecond)))
+	})
 })
@@ -0,0 +1,80 @@
+package congestion
+
+// windowedMaxFilter tracks the maximum bandwidth sample seen within a window.
+// The window is measured in an arbitrary unit that increases monotonically,
+// e.g. the number of round trips or the number of bandwidth probing cycles.
+// It uses Kathleen Nichols' algorithm, keeping track of the best, second best
+// and third best sample, such that the filter doesn't need to store all samples.
+type windowedMaxFilter struct {
+	window    uint64
+	estimates [3]windowedSample
+}
+
+type windowedSample struct {
+	sample Bandwidth
+	time   uint64
+	valid  bool
+}
+
+func newWindowedMaxFilter(window uint64) *windowedMaxFilter {
+	return &windowedMaxFilter{window: window}
+}
+
+// Update updates the filter with a new sample.
+func (f *windowedMaxFilter) Update(sample Bandwidth, now uint64) {
+	// Reset all estimates if they have not yet been initialized,
+	// if the sample is a new best, or if the newest recorded estimate is too old.
+	if !f.estimates[0].valid || sample >= f.estimates[0].sample || now-f.estimates[2].time > f.window {
+		f.Reset(sample, now)
+		return
+	}
+
+	s := windowedSample{sample: sample, time: now, valid: true}
+	if sample >= f.estimates[1].sample {
+		f.estimates[1] = s
+		f.estimates[2] = s
+	} else if sample >= f.estimates[2].sample {
+		f.estimates[2] = s
+	}
+
+	// Expire and update estimates as necessary.
+	if now-f.estimates[0].time > f.window {
+		// The best estimate hasn't been updated for an entire window, so promote second and third best estimates.
+		f.estimates[0] = f.estimates[1]
+		f.estimates[1] = f.estimates[2]
+		f.estimates[2] = s
+		// Need to iterate one more time. Check if the new best estimate is outside the window as well,
+		// since it may also have been recorded a long time ago.
+		if now-f.estimates[0].time > f.window {
+			f.estimates[0] = f.estimates[1]
+			f.estimates[1] = f.estimates[2]
+		}
+		return
+	}
+	if f.estimates[1].sample == f.estimates[0].sample && now-f.estimates[1].time > f.window/4 {
+		// A quarter of the window has passed without a better sample,
+		// so the second best estimate is taken from the second quarter of the window.
+		f.estimates[1] = s
+		f.estimates[2] = s
+		return
+	}
+	if f.estimates[2].sample == f.estimates[1].sample && now-f.estimates[2].time > f.window/2 {
+		// We've passed half of the window without a better estimate,
+		// so take a third best estimate from the second half of the window.
+		f.estimates[2] = s
+	}
+}
+
+// Reset resets all estimates to the new sample.
+func (f *windowedMaxFilter) Reset(sample Bandwidth, now uint64) {
+	s := windowedSample{sample: sample, time: now, valid: true}
+	f.estimates[0] = s
+	f.estimates[1] = s
+	f.estimates[2] = s
+}
+
+// GetBest returns the maximum sample within the window.
+// It returns 0 if no sample was recorded yet.
+func (f *windowedMaxFilter) GetBest() Bandwidth {
+	return f.estimates[0].sample
+}
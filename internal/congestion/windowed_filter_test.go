@@ -0,0 +1,54 @@
+package congestion
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Windowed Max Filter", func() {
+	var f *windowedMaxFilter
+
+	BeforeEach(func() {
+		f = newWindowedMaxFilter(100)
+	})
+
+	It("returns 0 before any sample was recorded", func() {
+		Expect(f.GetBest()).To(BeZero())
+	})
+
+	It("returns the maximum", func() {
+		f.Update(10, 1)
+		Expect(f.GetBest()).To(Equal(Bandwidth(10)))
+		f.Update(5, 2)
+		Expect(f.GetBest()).To(Equal(Bandwidth(10)))
+		f.Update(20, 3)
+		Expect(f.GetBest()).To(Equal(Bandwidth(20)))
+		f.Update(15, 4)
+		Expect(f.GetBest()).To(Equal(Bandwidth(20)))
+	})
+
+	It("expires old samples", func() {
+		f.Update(100, 0)
+		f.Update(50, 30)
+		f.Update(30, 60)
+		Expect(f.GetBest()).To(Equal(Bandwidth(100)))
+		// The best sample expires. The second best one is used.
+		f.Update(10, 101)
+		Expect(f.GetBest()).To(Equal(Bandwidth(50)))
+		// Now the second best sample expires as well.
+		f.Update(10, 131)
+		Expect(f.GetBest()).To(Equal(Bandwidth(10)))
+	})
+
+	It("resets when all samples expire", func() {
+		f.Update(100, 0)
+		f.Update(10, 1000)
+		Expect(f.GetBest()).To(Equal(Bandwidth(10)))
+	})
+
+	It("resets", func() {
+		f.Update(100, 0)
+		f.Reset(42, 1)
+		Expect(f.GetBest()).To(Equal(Bandwidth(42)))
+	})
+})
@@ -22,6 +22,9 @@ type baseFlowController struct {
 	receiveWindow        protocol.ByteCount
 	receiveWindowSize    protocol.ByteCount
 	maxReceiveWindowSize protocol.ByteCount
+	// allowWindowIncrease is called before the receive window size is increased.
+	// If it is nil, the window size is always increased.
+	allowWindowIncrease func(size protocol.ByteCount) bool
 
 	epochStartTime   time.Time
 	epochStartOffset protocol.ByteCount
@@ -105,11 +108,24 @@ func (c *baseFlowController) maybeAdjustWindowSize() {
 	now := time.Now()
 	if now.Sub(c.epochStartTime) < time.Duration(4*fraction*float64(rtt)) {
 		// window is consumed too fast, try to increase the window size
-		c.receiveWindowSize = utils.MinByteCount(2*c.receiveWindowSize, c.maxReceiveWindowSize)
+		c.increaseWindowSize(utils.MinByteCount(2*c.receiveWindowSize, c.maxReceiveWindowSize))
 	}
 	c.startNewAutoTuningEpoch(now)
 }
 
+// increaseWindowSize increases the receiveWindowSize, if allowed.
+// It returns true if the window size was increased.
+func (c *baseFlowController) increaseWindowSize(newSize protocol.ByteCount) bool {
+	if newSize <= c.receiveWindowSize {
+		return false
+	}
+	if c.allowWindowIncrease != nil && !c.allowWindowIncrease(newSize-c.receiveWindowSize) {
+		return false
+	}
+	c.receiveWindowSize = newSize
+	return true
+}
+
 func (c *baseFlowController) startNewAutoTuningEpoch(now time.Time) {
 	c.epochStartTime = now
 	c.epochStartOffset = c.bytesRead
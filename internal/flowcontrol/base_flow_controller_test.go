@@ -172,6 +172,30 @@ var _ = Describe("Base Flow controller", func() {
 				Expect(offset).To(Equal(bytesRead + dataRead + newWindowSize))
 			})
 
+			It("asks if the window size may be increased", func() {
+				var increases []protocol.ByteCount
+				controller.allowWindowIncrease = func(size protocol.ByteCount) bool {
+					increases = append(increases, size)
+					return len(increases) > 1
+				}
+				rtt := scaleDuration(50 * time.Millisecond)
+				setRtt(rtt)
+				dataRead := receiveWindowSize*2/3 + 1
+				controller.epochStartOffset = controller.bytesRead
+				controller.epochStartTime = time.Now().Add(-rtt * 4 * 2 / 3)
+				controller.addBytesRead(dataRead)
+				Expect(controller.getWindowUpdate()).ToNot(BeZero())
+				// the first increase is denied
+				Expect(increases).To(Equal([]protocol.ByteCount{oldWindowSize}))
+				Expect(controller.receiveWindowSize).To(Equal(oldWindowSize))
+				// the second one is allowed
+				controller.epochStartTime = time.Now().Add(-rtt * 4 * 2 / 3)
+				controller.addBytesRead(dataRead)
+				Expect(controller.getWindowUpdate()).ToNot(BeZero())
+				Expect(increases).To(Equal([]protocol.ByteCount{oldWindowSize, oldWindowSize}))
+				Expect(controller.receiveWindowSize).To(Equal(2 * oldWindowSize))
+			})
+
 			It("doesn't increase the window size if data is read so fast that the window would be consumed in less than 4 RTTs, but less than half the window has been read", func() {
 				// this test only makes sense if a window update is triggered before half of the window has been consumed
 				Expect(protocol.WindowUpdateThreshold).To(BeNumerically(">", 1/3))
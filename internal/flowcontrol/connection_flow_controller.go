@@ -14,16 +14,23 @@ type connectionFlowController struct {
 	baseFlowController
 
 	queueWindowUpdate func()
+	// limitWindowSize is called before a window update is sent.
+	// It returns the maximum window size, allowing the window size to be reduced.
+	limitWindowSize func(size protocol.ByteCount) protocol.ByteCount
 }
 
 var _ ConnectionFlowController = &connectionFlowController{}
 
 // NewConnectionFlowController gets a new flow controller for the connection
 // It is created before we receive the peer's transport paramenters, thus it starts with a sendWindow of 0.
+// allowWindowIncrease is called before the receive window is increased, and may be nil.
+// limitWindowSize is called before a window update is sent, and may be nil.
 func NewConnectionFlowController(
 	receiveWindow protocol.ByteCount,
 	maxReceiveWindow protocol.ByteCount,
 	queueWindowUpdate func(),
+	allowWindowIncrease func(size protocol.ByteCount) bool,
+	limitWindowSize func(size protocol.ByteCount) protocol.ByteCount,
 	rttStats *utils.RTTStats,
 	logger utils.Logger,
 ) ConnectionFlowController {
@@ -33,9 +40,11 @@ func NewConnectionFlowController(
 			receiveWindow:        receiveWindow,
 			receiveWindowSize:    receiveWindow,
 			maxReceiveWindowSize: maxReceiveWindow,
+			allowWindowIncrease:  allowWindowIncrease,
 			logger:               logger,
 		},
 		queueWindowUpdate: queueWindowUpdate,
+		limitWindowSize:   limitWindowSize,
 	}
 }
 
@@ -67,6 +76,13 @@ func (c *connectionFlowController) AddBytesRead(n protocol.ByteCount) {
 
 func (c *connectionFlowController) GetWindowUpdate() protocol.ByteCount {
 	c.mutex.Lock()
+	if c.limitWindowSize != nil {
+		if limit := c.limitWindowSize(c.receiveWindowSize); limit < c.receiveWindowSize {
+			c.logger.Debugf("Reducing receive flow control window for the connection to %d kB", limit/(1<<10))
+			// The next window update will be smaller, but the window never shrinks.
+			c.receiveWindowSize = limit
+		}
+	}
 	oldWindowSize := c.receiveWindowSize
 	offset := c.baseFlowController.getWindowUpdate()
 	if oldWindowSize < c.receiveWindowSize {
@@ -76,13 +92,20 @@ func (c *connectionFlowController) GetWindowUpdate() protocol.ByteCount {
 	return offset
 }
 
+func (c *connectionFlowController) BufferedBytes() protocol.ByteCount {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.highestReceived - c.bytesRead
+}
+
 // EnsureMinimumWindowSize sets a minimum window size
 // it should make sure that the connection-level window is increased when a stream-level window grows
 func (c *connectionFlowController) EnsureMinimumWindowSize(inc protocol.ByteCount) {
 	c.mutex.Lock()
 	if inc > c.receiveWindowSize {
-		c.logger.Debugf("Increasing receive flow control window for the connection to %d kB, in response to stream flow control window increase", c.receiveWindowSize/(1<<10))
-		c.receiveWindowSize = utils.MinByteCount(inc, c.maxReceiveWindowSize)
+		if c.increaseWindowSize(utils.MinByteCount(inc, c.maxReceiveWindowSize)) {
+			c.logger.Debugf("Increasing receive flow control window for the connection to %d kB, in response to stream flow control window increase", c.receiveWindowSize/(1<<10))
+		}
 		c.startNewAutoTuningEpoch(time.Now())
 	}
 	c.mutex.Unlock()
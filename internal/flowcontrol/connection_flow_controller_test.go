@@ -36,7 +36,7 @@ var _ = Describe("Connection Flow controller", func() {
 			receiveWindow := protocol.ByteCount(2000)
 			maxReceiveWindow := protocol.ByteCount(3000)
 
-			fc := NewConnectionFlowController(receiveWindow, maxReceiveWindow, nil, rttStats, utils.DefaultLogger).(*connectionFlowController)
+			fc := NewConnectionFlowController(receiveWindow, maxReceiveWindow, nil, nil, nil, rttStats, utils.DefaultLogger).(*connectionFlowController)
 			Expect(fc.receiveWindow).To(Equal(receiveWindow))
 			Expect(fc.maxReceiveWindowSize).To(Equal(maxReceiveWindow))
 		})
@@ -91,6 +91,36 @@ var _ = Describe("Connection Flow controller", func() {
 				Expect(newWindowSize).To(Equal(2 * oldWindowSize))
 				Expect(offset).To(Equal(oldOffset + dataRead + newWindowSize))
 			})
+
+			It("reduces the window size, if limited", func() {
+				controller.receiveWindowSize = 80
+				controller.receiveWindow = 120
+				var limited protocol.ByteCount
+				controller.limitWindowSize = func(size protocol.ByteCount) protocol.ByteCount {
+					limited = size
+					return 50
+				}
+				controller.AddBytesRead(79)
+				Expect(queuedWindowUpdate).To(BeTrue())
+				offset := controller.GetWindowUpdate()
+				Expect(limited).To(Equal(protocol.ByteCount(80)))
+				Expect(controller.receiveWindowSize).To(Equal(protocol.ByteCount(50)))
+				Expect(offset).To(Equal(protocol.ByteCount(40 + 79 + 50)))
+			})
+
+			It("doesn't change the window size if the limit is larger", func() {
+				controller.limitWindowSize = func(protocol.ByteCount) protocol.ByteCount { return 1000 }
+				controller.AddBytesRead(30)
+				Expect(controller.GetWindowUpdate()).To(Equal(protocol.ByteCount(40 + 30 + 60)))
+				Expect(controller.receiveWindowSize).To(Equal(protocol.ByteCount(60)))
+			})
+		})
+
+		It("says how many bytes are buffered", func() {
+			controller.receiveWindow = 100
+			Expect(controller.IncrementHighestReceived(100)).To(Succeed())
+			controller.AddBytesRead(30)
+			Expect(controller.BufferedBytes()).To(Equal(protocol.ByteCount(70)))
 		})
 	})
 
@@ -124,6 +154,17 @@ var _ = Describe("Connection Flow controller", func() {
 			Expect(controller.receiveWindowSize).To(Equal(max))
 		})
 
+		It("doesn't increase the window size if not allowed", func() {
+			var increase protocol.ByteCount
+			controller.allowWindowIncrease = func(size protocol.ByteCount) bool {
+				increase = size
+				return false
+			}
+			controller.EnsureMinimumWindowSize(1800)
+			Expect(increase).To(Equal(protocol.ByteCount(800)))
+			Expect(controller.receiveWindowSize).To(Equal(oldWindowSize))
+		})
+
 		It("starts a new epoch after the window size was increased", func() {
 			controller.EnsureMinimumWindowSize(1912)
 			Expect(controller.epochStartTime).To(BeTemporally("~", time.Now(), 100*time.Millisecond))
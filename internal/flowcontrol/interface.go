@@ -30,6 +30,8 @@ type StreamFlowController interface {
 type ConnectionFlowController interface {
 	flowController
 	Reset() error
+	// BufferedBytes returns the number of bytes received, but not yet read by the application.
+	BufferedBytes() protocol.ByteCount
 }
 
 type connectionFlowControllerI interface {
@@ -0,0 +1,210 @@
+package handshake
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/qtls"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const storedSessionTicketRevision = 1
+
+// A SessionTicketStore stores serialized session tickets.
+type SessionTicketStore interface {
+	Get(key string) (ticket []byte, ok bool)
+	Put(key string, ticket []byte)
+}
+
+// clientSessionCache is a tls.ClientSessionCache that serializes the session tickets,
+// and saves them in a SessionTicketStore.
+type clientSessionCache struct {
+	store SessionTicketStore
+}
+
+var _ tls.ClientSessionCache = &clientSessionCache{}
+
+// NewClientSessionCache creates a new tls.ClientSessionCache that uses a SessionTicketStore.
+func NewClientSessionCache(store SessionTicketStore) tls.ClientSessionCache {
+	return &clientSessionCache{store: store}
+}
+
+func (c *clientSessionCache) Get(key string) (*tls.ClientSessionState, bool) {
+	data, ok := c.store.Get(key)
+	if !ok || data == nil {
+		return nil, false
+	}
+	state, err := unmarshalClientSessionState(data)
+	if err != nil {
+		c.store.Put(key, nil)
+		return nil, false
+	}
+	return state, true
+}
+
+func (c *clientSessionCache) Put(key string, state *tls.ClientSessionState) {
+	if state == nil {
+		c.store.Put(key, nil)
+		return
+	}
+	c.store.Put(key, marshalClientSessionState(state))
+}
+
+func marshalClientSessionState(state *qtls.ClientSessionState) []byte {
+	d := qtls.GetClientSessionStateData(state)
+	b := &bytes.Buffer{}
+	quicvarint.Write(b, storedSessionTicketRevision)
+	quicvarint.Write(b, uint64(d.Version))
+	quicvarint.Write(b, uint64(d.CipherSuite))
+	writeBytes(b, d.SessionTicket)
+	writeBytes(b, d.MasterSecret)
+	writeBytes(b, d.Nonce)
+	quicvarint.Write(b, uint64(d.ReceivedAt.UnixNano()))
+	quicvarint.Write(b, uint64(d.UseBy.UnixNano()))
+	quicvarint.Write(b, uint64(d.AgeAdd))
+	writeCertificates(b, d.ServerCertificates)
+	quicvarint.Write(b, uint64(len(d.VerifiedChains)))
+	for _, chain := range d.VerifiedChains {
+		writeCertificates(b, chain)
+	}
+	writeBytes(b, d.OCSPResponse)
+	quicvarint.Write(b, uint64(len(d.SCTs)))
+	for _, sct := range d.SCTs {
+		writeBytes(b, sct)
+	}
+	return b.Bytes()
+}
+
+func unmarshalClientSessionState(data []byte) (*qtls.ClientSessionState, error) {
+	r := bytes.NewReader(data)
+	rev, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if rev != storedSessionTicketRevision {
+		return nil, fmt.Errorf("unknown session ticket revision: %d", rev)
+	}
+	var d qtls.ClientSessionStateData
+	vers, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	d.Version = uint16(vers)
+	cipherSuite, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	d.CipherSuite = uint16(cipherSuite)
+	if d.SessionTicket, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if d.MasterSecret, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	if d.Nonce, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	receivedAt, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	d.ReceivedAt = time.Unix(0, int64(receivedAt))
+	useBy, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	d.UseBy = time.Unix(0, int64(useBy))
+	ageAdd, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	d.AgeAdd = uint32(ageAdd)
+	if d.ServerCertificates, err = readCertificates(r); err != nil {
+		return nil, err
+	}
+	numChains, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < numChains; i++ {
+		chain, err := readCertificates(r)
+		if err != nil {
+			return nil, err
+		}
+		d.VerifiedChains = append(d.VerifiedChains, chain)
+	}
+	if d.OCSPResponse, err = readBytes(r); err != nil {
+		return nil, err
+	}
+	numSCTs, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < numSCTs; i++ {
+		sct, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		d.SCTs = append(d.SCTs, sct)
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("session ticket has trailing data")
+	}
+	return qtls.NewClientSessionState(&d), nil
+}
+
+func writeBytes(b *bytes.Buffer, data []byte) {
+	quicvarint.Write(b, uint64(len(data)))
+	b.Write(data)
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	l, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if l > uint64(r.Len()) {
+		return nil, io.EOF
+	}
+	if l == 0 {
+		return nil, nil
+	}
+	data := make([]byte, l)
+	r.Read(data)
+	return data, nil
+}
+
+func writeCertificates(b *bytes.Buffer, certs []*x509.Certificate) {
+	quicvarint.Write(b, uint64(len(certs)))
+	for _, cert := range certs {
+		writeBytes(b, cert.Raw)
+	}
+}
+
+func readCertificates(r *bytes.Reader) ([]*x509.Certificate, error) {
+	num, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if num > uint64(r.Len()) {
+		return nil, io.EOF
+	}
+	certs := make([]*x509.Certificate, 0, num)
+	for i := uint64(0); i < num; i++ {
+		raw, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
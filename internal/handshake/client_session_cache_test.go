@@ -0,0 +1,142 @@
+package handshake
+
+import (
+	"bytes"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/qtls"
+	"github.com/lucas-clemente/quic-go/internal/testdata"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type mapSessionTicketStore struct {
+	mutex   sync.Mutex
+	tickets map[string][]byte
+}
+
+func (s *mapSessionTicketStore) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	t, ok := s.tickets[key]
+	return t, ok
+}
+
+func (s *mapSessionTicketStore) Put(key string, ticket []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if ticket == nil {
+		delete(s.tickets, key)
+		return
+	}
+	s.tickets[key] = ticket
+}
+
+func (s *mapSessionTicketStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.tickets)
+}
+
+var _ = Describe("Client Session Cache", func() {
+	var (
+		store *mapSessionTicketStore
+		cert  *x509.Certificate
+	)
+
+	BeforeEach(func() {
+		store = &mapSessionTicketStore{tickets: make(map[string][]byte)}
+		var err error
+		cert, err = x509.ParseCertificate(testdata.GetTLSConfig().Certificates[0].Certificate[0])
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	getState := func() *qtls.ClientSessionStateData {
+		return &qtls.ClientSessionStateData{
+			SessionTicket:      []byte("ticket"),
+			Version:            0x304,
+			CipherSuite:        0x1301,
+			MasterSecret:       []byte("secret"),
+			ServerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:     [][]*x509.Certificate{{cert}, {cert, cert}},
+			ReceivedAt:         time.Unix(1600000000, 1234),
+			OCSPResponse:       []byte("ocsp"),
+			SCTs:               [][]byte{[]byte("sct1"), []byte("sct2")},
+			Nonce:              []byte("nonce"),
+			UseBy:              time.Unix(1600086400, 0),
+			AgeAdd:             1337,
+		}
+	}
+
+	It("serializes session tickets", func() {
+		cache := NewClientSessionCache(store)
+		cache.Put("foo", qtls.NewClientSessionState(getState()))
+		Expect(store.tickets).To(HaveKey("foo"))
+		state, ok := cache.Get("foo")
+		Expect(ok).To(BeTrue())
+		d := qtls.GetClientSessionStateData(state)
+		expected := getState()
+		Expect(d.SessionTicket).To(Equal(expected.SessionTicket))
+		Expect(d.Version).To(Equal(expected.Version))
+		Expect(d.CipherSuite).To(Equal(expected.CipherSuite))
+		Expect(d.MasterSecret).To(Equal(expected.MasterSecret))
+		Expect(d.ServerCertificates).To(HaveLen(1))
+		Expect(d.ServerCertificates[0].Equal(cert)).To(BeTrue())
+		Expect(d.VerifiedChains).To(HaveLen(2))
+		Expect(d.VerifiedChains[0]).To(HaveLen(1))
+		Expect(d.VerifiedChains[1]).To(HaveLen(2))
+		Expect(d.ReceivedAt.Equal(expected.ReceivedAt)).To(BeTrue())
+		Expect(d.OCSPResponse).To(Equal(expected.OCSPResponse))
+		Expect(d.SCTs).To(Equal(expected.SCTs))
+		Expect(d.Nonce).To(Equal(expected.Nonce))
+		Expect(d.UseBy.Equal(expected.UseBy)).To(BeTrue())
+		Expect(d.AgeAdd).To(Equal(expected.AgeAdd))
+	})
+
+	It("doesn't return session tickets that don't exist", func() {
+		_, ok := NewClientSessionCache(store).Get("foo")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("deletes session tickets", func() {
+		cache := NewClientSessionCache(store)
+		cache.Put("foo", qtls.NewClientSessionState(getState()))
+		Expect(store.tickets).To(HaveKey("foo"))
+		cache.Put("foo", nil)
+		Expect(store.tickets).ToNot(HaveKey("foo"))
+	})
+
+	It("deletes session tickets that can't be parsed", func() {
+		cache := NewClientSessionCache(store)
+		cache.Put("foo", qtls.NewClientSessionState(getState()))
+		store.tickets["foo"] = store.tickets["foo"][:len(store.tickets["foo"])-1]
+		_, ok := cache.Get("foo")
+		Expect(ok).To(BeFalse())
+		Expect(store.tickets).ToNot(HaveKey("foo"))
+	})
+
+	It("rejects session tickets with an unknown revision", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, 1337)
+		_, err := unmarshalClientSessionState(b.Bytes())
+		Expect(err).To(MatchError("unknown session ticket revision: 1337"))
+	})
+
+	It("rejects session tickets with trailing data", func() {
+		data := marshalClientSessionState(qtls.NewClientSessionState(getState()))
+		_, err := unmarshalClientSessionState(append(data, 0))
+		Expect(err).To(MatchError("session ticket has trailing data"))
+	})
+
+	It("rejects truncated session tickets", func() {
+		data := marshalClientSessionState(qtls.NewClientSessionState(getState()))
+		for i := 0; i < len(data); i++ {
+			_, err := unmarshalClientSessionState(data[:i])
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
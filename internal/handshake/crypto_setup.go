@@ -2,6 +2,7 @@ package handshake
 
 import (
 	"bytes"
+	"crypto/rand"
 	"crypto/tls"
 	"errors"
 	"fmt"
@@ -112,6 +113,8 @@ type cryptoSetup struct {
 	closeChan chan struct{}
 
 	zeroRTTParameters      *wire.TransportParameters
+	accept0RTTAppData      func([]byte) bool                              // only set for the server
+	checkReplay            func(ticketID []byte, issuedAt time.Time) bool // only set for the server
 	clientHelloWritten     bool
 	clientHelloWrittenChan chan *wire.TransportParameters
 
@@ -161,6 +164,7 @@ func NewCryptoSetupClient(
 	runner handshakeRunner,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	keyUpdatePolicy KeyUpdatePolicy,
 	rttStats *utils.RTTStats,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
@@ -174,6 +178,7 @@ func NewCryptoSetupClient(
 		runner,
 		tlsConf,
 		enable0RTT,
+		keyUpdatePolicy,
 		rttStats,
 		tracer,
 		logger,
@@ -195,6 +200,9 @@ func NewCryptoSetupServer(
 	runner handshakeRunner,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	keyUpdatePolicy KeyUpdatePolicy,
+	accept0RTTAppData func([]byte) bool,
+	checkReplay func(ticketID []byte, issuedAt time.Time) bool,
 	rttStats *utils.RTTStats,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
@@ -208,12 +216,15 @@ func NewCryptoSetupServer(
 		runner,
 		tlsConf,
 		enable0RTT,
+		keyUpdatePolicy,
 		rttStats,
 		tracer,
 		logger,
 		protocol.PerspectiveServer,
 		version,
 	)
+	cs.accept0RTTAppData = accept0RTTAppData
+	cs.checkReplay = checkReplay
 	cs.conn = qtls.Server(newConn(localAddr, remoteAddr, version), cs.tlsConf, cs.extraConf)
 	return cs
 }
@@ -226,6 +237,7 @@ func newCryptoSetup(
 	runner handshakeRunner,
 	tlsConf *tls.Config,
 	enable0RTT bool,
+	keyUpdatePolicy KeyUpdatePolicy,
 	rttStats *utils.RTTStats,
 	tracer logging.ConnectionTracer,
 	logger utils.Logger,
@@ -237,19 +249,17 @@ func newCryptoSetup(
 		tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveClient)
 		tracer.UpdatedKeyFromTLS(protocol.EncryptionInitial, protocol.PerspectiveServer)
 	}
-	extHandler := newExtensionHandler(tp.Marshal(perspective), perspective, version)
 	cs := &cryptoSetup{
 		tlsConf:                   tlsConf,
 		initialStream:             initialStream,
 		initialSealer:             initialSealer,
 		initialOpener:             initialOpener,
 		handshakeStream:           handshakeStream,
-		aead:                      newUpdatableAEAD(rttStats, tracer, logger),
+		aead:                      newUpdatableAEAD(rttStats, keyUpdatePolicy, tracer, logger),
 		readEncLevel:              protocol.EncryptionInitial,
 		writeEncLevel:             protocol.EncryptionInitial,
 		runner:                    runner,
 		ourParams:                 tp,
-		paramsChan:                extHandler.TransportParameters(),
 		rttStats:                  rttStats,
 		tracer:                    tracer,
 		logger:                    logger,
@@ -262,6 +272,12 @@ func newCryptoSetup(
 		closeChan:                 make(chan struct{}),
 		version:                   version,
 	}
+	var negotiateVersion func([]byte) []byte
+	if perspective == protocol.PerspectiveServer && tp.VersionInformation != nil {
+		negotiateVersion = cs.negotiateVersion
+	}
+	extHandler := newExtensionHandler(tp.Marshal(perspective), perspective, version, negotiateVersion)
+	cs.paramsChan = extHandler.TransportParameters()
 	var maxEarlyData uint32
 	if enable0RTT {
 		maxEarlyData = 0xffffffff
@@ -281,6 +297,35 @@ func newCryptoSetup(
 	return cs, cs.clientHelloWrittenChan
 }
 
+// negotiateVersion performs compatible version negotiation, as defined in RFC 9368.
+// It is only used by the server, and called from the qtls go routine when the ClientHello is received.
+// If the client offered a compatible version that we prefer, we switch to that version.
+// The session makes the same decision when it processes the client's transport parameters.
+func (h *cryptoSetup) negotiateVersion(data []byte) []byte {
+	var tp wire.TransportParameters
+	// Invalid transport parameters are rejected when they're handled by the session.
+	if err := tp.Unmarshal(data, protocol.PerspectiveClient); err != nil || tp.VersionInformation == nil {
+		return h.ourParams.Marshal(h.perspective)
+	}
+	v := protocol.ChooseCompatibleVersion(h.ourParams.VersionInformation.AvailableVersions, h.version, tp.VersionInformation.AvailableVersions)
+	if v != h.ourParams.VersionInformation.ChosenVersion {
+		h.logger.Debugf("Switching to compatible version %s.", v)
+		params := *h.ourParams
+		params.VersionInformation = &wire.VersionInformation{
+			ChosenVersion:     v,
+			AvailableVersions: h.ourParams.VersionInformation.AvailableVersions,
+		}
+		h.ourParams = &params
+	}
+	return h.ourParams.Marshal(h.perspective)
+}
+
+// SetVersion is called when the session switches to a compatible version.
+// It must be called before the Initial keys are derived again.
+func (h *cryptoSetup) SetVersion(v protocol.VersionNumber) {
+	h.version = v
+}
+
 func (h *cryptoSetup) ChangeConnectionID(id protocol.ConnectionID) {
 	initialSealer, initialOpener := NewInitialAEAD(id, h.perspective, h.version)
 	h.initialSealer = initialSealer
@@ -449,16 +494,21 @@ func (h *cryptoSetup) handleDataFromSessionStateImpl(data []byte) (*wire.Transpo
 }
 
 // only valid for the server
-func (h *cryptoSetup) GetSessionTicket() ([]byte, error) {
-	var appData []byte
-	// Save transport parameters to the session ticket if we're allowing 0-RTT.
-	if h.extraConf.MaxEarlyData > 0 {
-		appData = (&sessionTicket{
-			Parameters: h.ourParams,
-			RTT:        h.rttStats.SmoothedRTT(),
-		}).Marshal()
+// The application data is saved in the session ticket, and passed to accept0RTTAppData when the ticket is used for 0-RTT.
+// The ticket data is saved even if 0-RTT is disabled, so that the application data is always stored.
+func (h *cryptoSetup) GetSessionTicket(appData []byte) ([]byte, error) {
+	id := make([]byte, sessionTicketIDLen)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
 	}
-	return h.conn.GetSessionTicket(appData)
+	ticketData := (&sessionTicket{
+		ID:         id,
+		IssuedAt:   time.Now(),
+		Parameters: h.ourParams,
+		RTT:        h.rttStats.SmoothedRTT(),
+		AppData:    appData,
+	}).Marshal()
+	return h.conn.GetSessionTicket(ticketData)
 }
 
 // accept0RTT is called for the server when receiving the client's session ticket.
@@ -469,14 +519,22 @@ func (h *cryptoSetup) accept0RTT(sessionTicketData []byte) bool {
 		h.logger.Debugf("Unmarshalling transport parameters from session ticket failed: %s", err.Error())
 		return false
 	}
-	valid := h.ourParams.ValidFor0RTT(t.Parameters)
-	if valid {
-		h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
-		h.rttStats.SetInitialRTT(t.RTT)
-	} else {
+	if !h.ourParams.ValidFor0RTT(t.Parameters) {
 		h.logger.Debugf("Transport parameters changed. Rejecting 0-RTT.")
+		return false
 	}
-	return valid
+	if h.accept0RTTAppData != nil && !h.accept0RTTAppData(t.AppData) {
+		h.logger.Debugf("Application rejected the data from the session ticket. Rejecting 0-RTT.")
+		return false
+	}
+	// Check for replays last, such that session tickets are only marked as used if 0-RTT is accepted.
+	if h.checkReplay != nil && !h.checkReplay(t.ID, t.IssuedAt) {
+		h.logger.Debugf("Session ticket was already used for 0-RTT, or is too old. Rejecting 0-RTT.")
+		return false
+	}
+	h.logger.Debugf("Accepting 0-RTT. Restoring RTT from session ticket: %s", t.RTT)
+	h.rttStats.SetInitialRTT(t.RTT)
+	return true
 }
 
 // rejected0RTT is called for the client when the server rejects 0-RTT.
@@ -693,6 +751,12 @@ func (h *cryptoSetup) SetHandshakeConfirmed() {
 	}
 }
 
+// ForceKeyUpdate requests an update of the 1-RTT keys.
+// It is safe to call from any go routine.
+func (h *cryptoSetup) ForceKeyUpdate() {
+	h.aead.ForceKeyUpdate()
+}
+
 func (h *cryptoSetup) GetInitialSealer() (LongHeaderSealer, error) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
@@ -733,6 +797,10 @@ func (h *cryptoSetup) Get1RTTSealer() (ShortHeaderSealer, error) {
 	if !h.has1RTTSealer {
 		return nil, ErrKeysNotYetAvailable
 	}
+	// RFC 9001, section 6.6: we must stop using the keys once the confidentiality limit is reached.
+	if h.aead.ConfidentialityLimitReached() {
+		return nil, qerr.AEADLimitReached
+	}
 	return h.aead, nil
 }
 
@@ -96,6 +96,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			testdata.GetTLSConfig(),
 			false,
+			KeyUpdatePolicy{},
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -137,6 +140,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			testdata.GetTLSConfig(),
 			false,
+			KeyUpdatePolicy{},
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -181,6 +187,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			runner,
 			serverConf,
 			false,
+			KeyUpdatePolicy{},
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -218,6 +227,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 			NewMockHandshakeRunner(mockCtrl),
 			serverConf,
 			false,
+			KeyUpdatePolicy{},
+			nil,
+			nil,
 			&utils.RTTStats{},
 			nil,
 			utils.DefaultLogger.WithPrefix("server"),
@@ -300,7 +312,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				defer GinkgoRecover()
 				defer close(done)
 				server.RunHandshake()
-				ticket, err := server.GetSessionTicket()
+				ticket, err := server.GetSessionTicket(nil)
 				Expect(err).ToNot(HaveOccurred())
 				if ticket != nil {
 					client.HandleMessage(ticket, protocol.Encryption1RTT)
@@ -335,6 +347,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				cRunner,
 				clientConf,
 				enable0RTT,
+				KeyUpdatePolicy{},
 				clientRTTStats,
 				nil,
 				utils.DefaultLogger.WithPrefix("client"),
@@ -362,6 +375,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sRunner,
 				serverConf,
 				enable0RTT,
+				KeyUpdatePolicy{},
+				nil,
+				nil,
 				serverRTTStats,
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -432,6 +448,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				runner,
 				&tls.Config{InsecureSkipVerify: true},
 				false,
+				KeyUpdatePolicy{},
 				&utils.RTTStats{},
 				nil,
 				utils.DefaultLogger.WithPrefix("client"),
@@ -475,6 +492,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 				cRunner,
 				clientConf,
 				false,
+				KeyUpdatePolicy{},
 				&utils.RTTStats{},
 				nil,
 				utils.DefaultLogger.WithPrefix("client"),
@@ -500,6 +518,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 				sRunner,
 				serverConf,
 				false,
+				KeyUpdatePolicy{},
+				nil,
+				nil,
 				&utils.RTTStats{},
 				nil,
 				utils.DefaultLogger.WithPrefix("server"),
@@ -518,6 +539,75 @@ var _ = Describe("Crypto Setup TLS", func() {
 			Expect(sTransportParametersRcvd.MaxIdleTimeout).To(Equal(sTransportParameters.MaxIdleTimeout))
 		})
 
+		It("switches to a compatible version", func() {
+			var sTransportParametersRcvd *wire.TransportParameters
+			cChunkChan, cInitialStream, cHandshakeStream := initStreams()
+			cRunner := NewMockHandshakeRunner(mockCtrl)
+			cRunner.EXPECT().OnReceivedParams(gomock.Any()).Do(func(tp *wire.TransportParameters) { sTransportParametersRcvd = tp })
+			cRunner.EXPECT().OnHandshakeComplete()
+			client, _ := NewCryptoSetupClient(
+				cInitialStream,
+				cHandshakeStream,
+				protocol.ConnectionID{},
+				nil,
+				nil,
+				&wire.TransportParameters{
+					VersionInformation: &wire.VersionInformation{
+						ChosenVersion:     protocol.VersionDraft34,
+						AvailableVersions: []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+					},
+				},
+				cRunner,
+				clientConf,
+				false,
+				KeyUpdatePolicy{},
+				&utils.RTTStats{},
+				nil,
+				utils.DefaultLogger.WithPrefix("client"),
+				protocol.VersionDraft34,
+			)
+
+			sChunkChan, sInitialStream, sHandshakeStream := initStreams()
+			sRunner := NewMockHandshakeRunner(mockCtrl)
+			sRunner.EXPECT().OnReceivedParams(gomock.Any())
+			sRunner.EXPECT().OnHandshakeComplete()
+			server := NewCryptoSetupServer(
+				sInitialStream,
+				sHandshakeStream,
+				protocol.ConnectionID{},
+				nil,
+				nil,
+				&wire.TransportParameters{
+					StatelessResetToken: &protocol.StatelessResetToken{},
+					VersionInformation: &wire.VersionInformation{
+						ChosenVersion:     protocol.VersionDraft34,
+						AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34},
+					},
+				},
+				sRunner,
+				serverConf,
+				false,
+				KeyUpdatePolicy{},
+				nil,
+				nil,
+				&utils.RTTStats{},
+				nil,
+				utils.DefaultLogger.WithPrefix("server"),
+				protocol.VersionDraft34,
+			)
+
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				handshake(client, cChunkChan, server, sChunkChan)
+				close(done)
+			}()
+			Eventually(done).Should(BeClosed())
+			Expect(sTransportParametersRcvd.VersionInformation).ToNot(BeNil())
+			Expect(sTransportParametersRcvd.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+			Expect(sTransportParametersRcvd.VersionInformation.AvailableVersions).To(Equal([]protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34}))
+		})
+
 		Context("with session tickets", func() {
 			It("errors when the NewSessionTicket is sent at the wrong encryption level", func() {
 				cChunkChan, cInitialStream, cHandshakeStream := initStreams()
@@ -534,6 +624,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 					cRunner,
 					clientConf,
 					false,
+					KeyUpdatePolicy{},
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("client"),
@@ -555,6 +646,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 					sRunner,
 					serverConf,
 					false,
+					KeyUpdatePolicy{},
+					nil,
+					nil,
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("server"),
@@ -596,6 +690,7 @@ var _ = Describe("Crypto Setup TLS", func() {
 					cRunner,
 					clientConf,
 					false,
+					KeyUpdatePolicy{},
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("client"),
@@ -617,6 +712,9 @@ var _ = Describe("Crypto Setup TLS", func() {
 					sRunner,
 					serverConf,
 					false,
+					KeyUpdatePolicy{},
+					nil,
+					nil,
 					&utils.RTTStats{},
 					nil,
 					utils.DefaultLogger.WithPrefix("server"),
@@ -684,6 +782,35 @@ var _ = Describe("Crypto Setup TLS", func() {
 				Expect(clientHelloWrittenChan).To(Receive(BeNil()))
 			})
 
+			It("uses session resumption with serialized session tickets", func() {
+				store := &mapSessionTicketStore{tickets: make(map[string][]byte)}
+				clientConf.ClientSessionCache = NewClientSessionCache(store)
+				_, client, clientErr, server, serverErr := handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(client.ConnectionState().DidResume).To(BeFalse())
+				Eventually(store.Len).Should(Equal(1))
+
+				// use a new tls.Config, to make sure that the ticket is restored from its serialized form
+				clientConf = clientConf.Clone()
+				clientConf.ClientSessionCache = NewClientSessionCache(store)
+				_, client, clientErr, server, serverErr = handshakeWithTLSConf(
+					clientConf, serverConf,
+					&utils.RTTStats{}, &utils.RTTStats{},
+					&wire.TransportParameters{}, &wire.TransportParameters{},
+					false,
+				)
+				Expect(clientErr).ToNot(HaveOccurred())
+				Expect(serverErr).ToNot(HaveOccurred())
+				Expect(server.ConnectionState().DidResume).To(BeTrue())
+				Expect(client.ConnectionState().DidResume).To(BeTrue())
+			})
+
 			It("doesn't use session resumption if the server disabled it", func() {
 				csc := mocktls.NewMockClientSessionCache(mockCtrl)
 				var state *tls.ClientSessionState
@@ -827,5 +954,93 @@ var _ = Describe("Crypto Setup TLS", func() {
 				Expect(client.ConnectionState().Used0RTT).To(BeFalse())
 			})
 		})
+
+		Context("accepting 0-RTT", func() {
+			var server *cryptoSetup
+			ticketID := bytes.Repeat([]byte{0x42}, sessionTicketIDLen)
+
+			BeforeEach(func() {
+				var token protocol.StatelessResetToken
+				server = NewCryptoSetupServer(
+					&bytes.Buffer{},
+					&bytes.Buffer{},
+					protocol.ConnectionID{},
+					nil,
+					nil,
+					&wire.TransportParameters{StatelessResetToken: &token, InitialMaxData: 1000},
+					NewMockHandshakeRunner(mockCtrl),
+					serverConf,
+					true,
+					KeyUpdatePolicy{},
+					nil,
+					nil,
+					&utils.RTTStats{},
+					nil,
+					utils.DefaultLogger.WithPrefix("server"),
+					protocol.VersionTLS,
+				).(*cryptoSetup)
+			})
+
+			It("accepts 0-RTT", func() {
+				ticket := &sessionTicket{ID: ticketID, IssuedAt: time.Now(), Parameters: &wire.TransportParameters{InitialMaxData: 1000}, RTT: time.Second}
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeTrue())
+				Expect(server.rttStats.SmoothedRTT()).To(Equal(time.Second))
+			})
+
+			It("passes the application data to the application", func() {
+				var appData []byte
+				server.accept0RTTAppData = func(data []byte) bool {
+					appData = data
+					return true
+				}
+				ticket := &sessionTicket{ID: ticketID, IssuedAt: time.Now(), Parameters: &wire.TransportParameters{InitialMaxData: 1000}, AppData: []byte("foobar")}
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeTrue())
+				Expect(appData).To(Equal([]byte("foobar")))
+			})
+
+			It("rejects 0-RTT if the application rejects the application data", func() {
+				server.accept0RTTAppData = func([]byte) bool { return false }
+				ticket := &sessionTicket{ID: ticketID, IssuedAt: time.Now(), Parameters: &wire.TransportParameters{InitialMaxData: 1000}, AppData: []byte("foobar")}
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeFalse())
+			})
+
+			It("doesn't call the application if the transport parameters changed", func() {
+				server.accept0RTTAppData = func([]byte) bool {
+					Fail("unexpected call")
+					return true
+				}
+				ticket := &sessionTicket{ID: ticketID, IssuedAt: time.Now(), Parameters: &wire.TransportParameters{InitialMaxData: 1001}}
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeFalse())
+			})
+
+			It("checks for replays", func() {
+				issuedAt := time.Now().Add(-time.Minute)
+				var seenIDs [][]byte
+				server.checkReplay = func(id []byte, t time.Time) bool {
+					Expect(t.Sub(issuedAt)).To(BeNumerically("<", time.Microsecond))
+					for _, seen := range seenIDs {
+						if bytes.Equal(seen, id) {
+							return false
+						}
+					}
+					seenIDs = append(seenIDs, id)
+					return true
+				}
+				ticket := &sessionTicket{ID: ticketID, IssuedAt: issuedAt, Parameters: &wire.TransportParameters{InitialMaxData: 1000}}
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeTrue())
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeFalse())
+				Expect(seenIDs).To(Equal([][]byte{ticketID}))
+			})
+
+			It("doesn't mark the session ticket as used if the application rejects the application data", func() {
+				server.accept0RTTAppData = func([]byte) bool { return false }
+				server.checkReplay = func([]byte, time.Time) bool {
+					Fail("unexpected call")
+					return true
+				}
+				ticket := &sessionTicket{ID: ticketID, IssuedAt: time.Now(), Parameters: &wire.TransportParameters{InitialMaxData: 1000}}
+				Expect(server.accept0RTT(ticket.Marshal())).To(BeFalse())
+			})
+		})
 	})
 })
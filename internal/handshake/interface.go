@@ -76,11 +76,13 @@ type CryptoSetup interface {
 	RunHandshake()
 	io.Closer
 	ChangeConnectionID(protocol.ConnectionID)
-	GetSessionTicket() ([]byte, error)
+	SetVersion(protocol.VersionNumber)
+	GetSessionTicket(appData []byte) ([]byte, error)
 
 	HandleMessage([]byte, protocol.EncryptionLevel) bool
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
+	ForceKeyUpdate()
 	ConnectionState() ConnectionState
 
 	GetInitialOpener() (LongHeaderOpener, error)
@@ -12,7 +12,7 @@ import (
 
 var (
 	oldRetryAEAD cipher.AEAD // used for QUIC draft versions up to 34
-	retryAEAD    cipher.AEAD // used for QUIC draft-34
+	retryAEAD    cipher.AEAD // used for QUIC draft-34 and QUIC v1
 )
 
 func init() {
@@ -48,7 +48,7 @@ func GetRetryIntegrityTag(retry []byte, origDestConnID protocol.ConnectionID, ve
 
 	var tag [16]byte
 	var sealed []byte
-	if version != protocol.VersionDraft34 {
+	if version != protocol.VersionDraft34 && version != protocol.Version1 {
 		sealed = oldRetryAEAD.Seal(tag[:0], oldRetryNonce[:], nil, retryBuf.Bytes())
 	} else {
 		sealed = retryAEAD.Seal(tag[:0], retryNonce[:], nil, retryBuf.Bytes())
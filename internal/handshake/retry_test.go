@@ -28,9 +28,10 @@ var _ = Describe("Retry Integrity Check", func() {
 		Expect(GetRetryIntegrityTag(data[:len(data)-16], connID, protocol.VersionDraft29)[:]).To(Equal(data[len(data)-16:]))
 	})
 
-	It("uses the test vector from the draft, for draft-34", func() {
+	It("uses the test vector from the RFC, for draft-34 and v1", func() {
 		connID := protocol.ConnectionID(splitHexString("0x8394c8f03e515708"))
 		data := splitHexString("ff000000010008f067a5502a4262b574 6f6b656e04a265ba2eff4d829058fb3f 0f2496ba")
 		Expect(GetRetryIntegrityTag(data[:len(data)-16], connID, protocol.VersionDraft34)[:]).To(Equal(data[len(data)-16:]))
+		Expect(GetRetryIntegrityTag(data[:len(data)-16], connID, protocol.Version1)[:]).To(Equal(data[len(data)-16:]))
 	})
 })
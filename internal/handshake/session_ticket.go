@@ -4,23 +4,34 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/lucas-clemente/quic-go/internal/wire"
 	"github.com/lucas-clemente/quic-go/quicvarint"
 )
 
-const sessionTicketRevision = 2
+const sessionTicketRevision = 4
+
+// sessionTicketIDLen is the length of the random ID that identifies a session ticket
+const sessionTicketIDLen = 16
 
 type sessionTicket struct {
+	ID         []byte    // uniquely identifies the session ticket, used for replay protection
+	IssuedAt   time.Time // to be encoded in mus
 	Parameters *wire.TransportParameters
 	RTT        time.Duration // to be encoded in mus
+	AppData    []byte        // opaque data provided by the application
 }
 
 func (t *sessionTicket) Marshal() []byte {
 	b := &bytes.Buffer{}
 	quicvarint.Write(b, sessionTicketRevision)
+	b.Write(t.ID)
+	quicvarint.Write(b, uint64(t.IssuedAt.UnixNano()/1000))
 	quicvarint.Write(b, uint64(t.RTT.Microseconds()))
+	quicvarint.Write(b, uint64(len(t.AppData)))
+	b.Write(t.AppData)
 	t.Parameters.MarshalForSessionTicket(b)
 	return b.Bytes()
 }
@@ -34,15 +45,35 @@ func (t *sessionTicket) Unmarshal(b []byte) error {
 	if rev != sessionTicketRevision {
 		return fmt.Errorf("unknown session ticket revision: %d", rev)
 	}
+	id := make([]byte, sessionTicketIDLen)
+	if _, err := io.ReadFull(r, id); err != nil {
+		return errors.New("failed to read session ticket ID")
+	}
+	issuedAt, err := quicvarint.Read(r)
+	if err != nil {
+		return errors.New("failed to read issue time")
+	}
 	rtt, err := quicvarint.Read(r)
 	if err != nil {
 		return errors.New("failed to read RTT")
 	}
+	appDataLen, err := quicvarint.Read(r)
+	if err != nil || appDataLen > uint64(r.Len()) {
+		return errors.New("failed to read application data")
+	}
+	var appData []byte
+	if appDataLen > 0 {
+		appData = make([]byte, appDataLen)
+		r.Read(appData)
+	}
 	var tp wire.TransportParameters
 	if err := tp.UnmarshalFromSessionTicket(r); err != nil {
 		return fmt.Errorf("unmarshaling transport parameters from session ticket failed: %s", err.Error())
 	}
+	t.ID = id
+	t.IssuedAt = time.Unix(0, int64(issuedAt)*1000)
 	t.Parameters = &tp
 	t.RTT = time.Duration(rtt) * time.Microsecond
+	t.AppData = appData
 	return nil
 }
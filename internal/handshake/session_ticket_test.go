@@ -14,17 +14,36 @@ import (
 var _ = Describe("Session Ticket", func() {
 	It("marshals and unmarshals a session ticket", func() {
 		ticket := &sessionTicket{
+			ID:       bytes.Repeat([]byte{0x42}, sessionTicketIDLen),
+			IssuedAt: time.Unix(1600000000, 123456000),
 			Parameters: &wire.TransportParameters{
 				InitialMaxStreamDataBidiLocal:  1,
 				InitialMaxStreamDataBidiRemote: 2,
 			},
-			RTT: 1337 * time.Microsecond,
+			RTT:     1337 * time.Microsecond,
+			AppData: []byte("foobar"),
 		}
 		var t sessionTicket
 		Expect(t.Unmarshal(ticket.Marshal())).To(Succeed())
+		Expect(t.ID).To(Equal(ticket.ID))
+		Expect(t.IssuedAt.Equal(ticket.IssuedAt)).To(BeTrue())
 		Expect(t.Parameters.InitialMaxStreamDataBidiLocal).To(BeEquivalentTo(1))
 		Expect(t.Parameters.InitialMaxStreamDataBidiRemote).To(BeEquivalentTo(2))
 		Expect(t.RTT).To(Equal(1337 * time.Microsecond))
+		Expect(t.AppData).To(Equal([]byte("foobar")))
+	})
+
+	It("marshals and unmarshals a session ticket without application data", func() {
+		ticket := &sessionTicket{
+			ID:         make([]byte, sessionTicketIDLen),
+			IssuedAt:   time.Now(),
+			Parameters: &wire.TransportParameters{InitialMaxData: 42},
+			RTT:        1337 * time.Microsecond,
+		}
+		var t sessionTicket
+		Expect(t.Unmarshal(ticket.Marshal())).To(Succeed())
+		Expect(t.Parameters.InitialMaxData).To(BeEquivalentTo(42))
+		Expect(t.AppData).To(BeNil())
 	})
 
 	It("refuses to unmarshal if the ticket is too short for the revision", func() {
@@ -37,15 +56,46 @@ var _ = Describe("Session Ticket", func() {
 		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("unknown session ticket revision: 1337"))
 	})
 
+	It("refuses to unmarshal if the ID cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		b.Write(make([]byte, sessionTicketIDLen-1))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read session ticket ID"))
+	})
+
+	It("refuses to unmarshal if the issue time cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		b.Write(make([]byte, sessionTicketIDLen))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read issue time"))
+	})
+
 	It("refuses to unmarshal if the RTT cannot be read", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, sessionTicketRevision)
+		b.Write(make([]byte, sessionTicketIDLen))
+		quicvarint.Write(b, 1234)
 		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read RTT"))
 	})
 
+	It("refuses to unmarshal if the application data cannot be read", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, sessionTicketRevision)
+		b.Write(make([]byte, sessionTicketIDLen))
+		quicvarint.Write(b, 1234)
+		quicvarint.Write(b, 1337)
+		quicvarint.Write(b, 10)
+		b.Write([]byte("foo"))
+		Expect((&sessionTicket{}).Unmarshal(b.Bytes())).To(MatchError("failed to read application data"))
+	})
+
 	It("refuses to unmarshal if unmarshaling the transport parameters fails", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, sessionTicketRevision)
+		b.Write(make([]byte, sessionTicketIDLen))
+		quicvarint.Write(b, 1234)
+		quicvarint.Write(b, 1337)
+		quicvarint.Write(b, 0)
 		b.Write([]byte("foobar"))
 		err := (&sessionTicket{}).Unmarshal(b.Bytes())
 		Expect(err).To(HaveOccurred())
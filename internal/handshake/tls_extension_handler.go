@@ -14,6 +14,11 @@ type extensionHandler struct {
 	ourParams  []byte
 	paramsChan chan []byte
 
+	// only used by the server
+	// Called with the client's transport parameters, before the EncryptedExtensions are sent.
+	// It returns our transport parameters, updated with the version chosen by compatible version negotiation.
+	negotiateVersion func(clientParams []byte) []byte
+
 	extensionType uint16
 
 	perspective protocol.Perspective
@@ -22,16 +27,22 @@ type extensionHandler struct {
 var _ tlsExtensionHandler = &extensionHandler{}
 
 // newExtensionHandler creates a new extension handler
-func newExtensionHandler(params []byte, pers protocol.Perspective, v protocol.VersionNumber) tlsExtensionHandler {
+func newExtensionHandler(
+	params []byte,
+	pers protocol.Perspective,
+	v protocol.VersionNumber,
+	negotiateVersion func(clientParams []byte) []byte,
+) tlsExtensionHandler {
 	et := uint16(quicTLSExtensionType)
-	if v != protocol.VersionDraft34 {
+	if v != protocol.VersionDraft34 && v != protocol.Version1 {
 		et = quicTLSExtensionTypeOldDrafts
 	}
 	return &extensionHandler{
-		ourParams:     params,
-		paramsChan:    make(chan []byte),
-		perspective:   pers,
-		extensionType: et,
+		ourParams:        params,
+		paramsChan:       make(chan []byte),
+		negotiateVersion: negotiateVersion,
+		perspective:      pers,
+		extensionType:    et,
 	}
 }
 
@@ -60,6 +71,9 @@ func (h *extensionHandler) ReceivedExtensions(msgType uint8, exts []qtls.Extensi
 		}
 	}
 
+	if h.perspective == protocol.PerspectiveServer && h.negotiateVersion != nil {
+		h.ourParams = h.negotiateVersion(data)
+	}
 	h.paramsChan <- data
 }
 
@@ -12,13 +12,15 @@ import (
 
 var _ = Describe("TLS Extension Handler, for the server", func() {
 	var (
-		handlerServer tlsExtensionHandler
-		handlerClient tlsExtensionHandler
-		version       protocol.VersionNumber
+		handlerServer    tlsExtensionHandler
+		handlerClient    tlsExtensionHandler
+		version          protocol.VersionNumber
+		negotiateVersion func([]byte) []byte
 	)
 
 	BeforeEach(func() {
 		version = protocol.VersionDraft29
+		negotiateVersion = nil
 	})
 
 	JustBeforeEach(func() {
@@ -26,16 +28,18 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 			[]byte("foobar"),
 			protocol.PerspectiveServer,
 			version,
+			negotiateVersion,
 		)
 		handlerClient = newExtensionHandler(
 			[]byte("raboof"),
 			protocol.PerspectiveClient,
 			version,
+			nil,
 		)
 	})
 
 	Context("for the server", func() {
-		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.VersionDraft34} {
+		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.VersionDraft34, protocol.Version1} {
 			v := ver
 
 			Context(fmt.Sprintf("sending, for version %s", v), func() {
@@ -122,7 +126,7 @@ var _ = Describe("TLS Extension Handler, for the server", func() {
 	})
 
 	Context("for the client", func() {
-		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.VersionDraft34} {
+		for _, ver := range []protocol.VersionNumber{protocol.VersionDraft29, protocol.VersionDraft34, protocol.Version1} {
 			v := ver
 
 			Context(fmt.Sprintf("sending, for version %s", v), func() {
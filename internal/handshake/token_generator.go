@@ -39,9 +39,11 @@ type TokenGenerator struct {
 	tokenProtector tokenProtector
 }
 
-// NewTokenGenerator initializes a new TookenGenerator
-func NewTokenGenerator(rand io.Reader) (*TokenGenerator, error) {
-	tokenProtector, err := newTokenProtector(rand)
+// NewTokenGenerator initializes a new TookenGenerator.
+// New tokens are protected using the first key, tokens protected by any of the keys are accepted.
+// If no keys are given, a random key is used.
+func NewTokenGenerator(rand io.Reader, keys []TokenProtectorKey) (*TokenGenerator, error) {
+	tokenProtector, err := newTokenProtector(rand, keys)
 	if err != nil {
 		return nil, err
 	}
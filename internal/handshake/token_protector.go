@@ -18,26 +18,34 @@ type tokenProtector interface {
 	DecodeToken([]byte) ([]byte, error)
 }
 
-const (
-	tokenSecretSize = 32
-	tokenNonceSize  = 32
-)
+const tokenNonceSize = 32
+
+// A TokenProtectorKey is the secret used to derive the keys that protect tokens.
+type TokenProtectorKey [32]byte
 
 // tokenProtector is used to create and verify a token
 type tokenProtectorImpl struct {
-	rand   io.Reader
-	secret []byte
+	rand io.Reader
+	// The first key is used to protect new tokens.
+	// All keys are used to decode tokens.
+	keys []TokenProtectorKey
 }
 
-// newTokenProtector creates a source for source address tokens
-func newTokenProtector(rand io.Reader) (tokenProtector, error) {
-	secret := make([]byte, tokenSecretSize)
-	if _, err := rand.Read(secret); err != nil {
-		return nil, err
+// newTokenProtector creates a source for source address tokens.
+// If no keys are given, a random key is generated.
+func newTokenProtector(rand io.Reader, keys []TokenProtectorKey) (tokenProtector, error) {
+	if len(keys) == 0 {
+		var key TokenProtectorKey
+		if _, err := io.ReadFull(rand, key[:]); err != nil {
+			return nil, err
+		}
+		keys = []TokenProtectorKey{key}
+	} else {
+		keys = append([]TokenProtectorKey(nil), keys...)
 	}
 	return &tokenProtectorImpl{
-		rand:   rand,
-		secret: secret,
+		rand: rand,
+		keys: keys,
 	}, nil
 }
 
@@ -47,7 +55,7 @@ func (s *tokenProtectorImpl) NewToken(data []byte) ([]byte, error) {
 	if _, err := s.rand.Read(nonce); err != nil {
 		return nil, err
 	}
-	aead, aeadNonce, err := s.createAEAD(nonce)
+	aead, aeadNonce, err := s.createAEAD(s.keys[0], nonce)
 	if err != nil {
 		return nil, err
 	}
@@ -60,24 +68,34 @@ func (s *tokenProtectorImpl) DecodeToken(p []byte) ([]byte, error) {
 		return nil, fmt.Errorf("token too short: %d", len(p))
 	}
 	nonce := p[:tokenNonceSize]
-	aead, aeadNonce, err := s.createAEAD(nonce)
-	if err != nil {
-		return nil, err
+	var err error
+	for _, key := range s.keys {
+		var aead cipher.AEAD
+		var aeadNonce []byte
+		aead, aeadNonce, err = s.createAEAD(key, nonce)
+		if err != nil {
+			return nil, err
+		}
+		var data []byte
+		data, err = aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+		if err == nil {
+			return data, nil
+		}
 	}
-	return aead.Open(nil, aeadNonce, p[tokenNonceSize:], nil)
+	return nil, err
 }
 
-func (s *tokenProtectorImpl) createAEAD(nonce []byte) (cipher.AEAD, []byte, error) {
-	h := hkdf.New(sha256.New, s.secret, nonce, []byte("quic-go token source"))
-	key := make([]byte, 32) // use a 32 byte key, in order to select AES-256
-	if _, err := io.ReadFull(h, key); err != nil {
+func (s *tokenProtectorImpl) createAEAD(key TokenProtectorKey, nonce []byte) (cipher.AEAD, []byte, error) {
+	h := hkdf.New(sha256.New, key[:], nonce, []byte("quic-go token source"))
+	aeadKey := make([]byte, 32) // use a 32 byte key, in order to select AES-256
+	if _, err := io.ReadFull(h, aeadKey); err != nil {
 		return nil, nil, err
 	}
 	aeadNonce := make([]byte, 12)
 	if _, err := io.ReadFull(h, aeadNonce); err != nil {
 		return nil, nil, err
 	}
-	c, err := aes.NewCipher(key)
+	c, err := aes.NewCipher(aeadKey)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -21,21 +21,21 @@ var _ = Describe("Token Protector", func() {
 
 	BeforeEach(func() {
 		var err error
-		tp, err = newTokenProtector(rand.Reader)
+		tp, err = newTokenProtector(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 	})
 
 	It("uses the random source", func() {
-		tp1, err := newTokenProtector(&zeroReader{})
+		tp1, err := newTokenProtector(&zeroReader{}, nil)
 		Expect(err).ToNot(HaveOccurred())
-		tp2, err := newTokenProtector(&zeroReader{})
+		tp2, err := newTokenProtector(&zeroReader{}, nil)
 		Expect(err).ToNot(HaveOccurred())
 		t1, err := tp1.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
 		t2, err := tp2.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
 		Expect(t1).To(Equal(t2))
-		tp3, err := newTokenProtector(rand.Reader)
+		tp3, err := newTokenProtector(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 		t3, err := tp3.NewToken([]byte("foo"))
 		Expect(err).ToNot(HaveOccurred())
@@ -60,6 +60,60 @@ var _ = Describe("Token Protector", func() {
 		Expect(err.Error()).To(ContainSubstring("message authentication failed"))
 	})
 
+	Context("using configured keys", func() {
+		var key1, key2 TokenProtectorKey
+
+		BeforeEach(func() {
+			rand.Read(key1[:])
+			rand.Read(key2[:])
+		})
+
+		It("accepts tokens created by a different protector using the same key", func() {
+			tp1, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key1})
+			Expect(err).ToNot(HaveOccurred())
+			tp2, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key1})
+			Expect(err).ToNot(HaveOccurred())
+			token, err := tp1.NewToken([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			decoded, err := tp2.DecodeToken(token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("foobar")))
+		})
+
+		It("rejects tokens created using a different key", func() {
+			tp1, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key1})
+			Expect(err).ToNot(HaveOccurred())
+			tp2, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key2})
+			Expect(err).ToNot(HaveOccurred())
+			token, err := tp1.NewToken([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = tp2.DecodeToken(token)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("message authentication failed"))
+		})
+
+		It("uses the first key for new tokens, and accepts tokens protected by any key", func() {
+			oldTP, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key1})
+			Expect(err).ToNot(HaveOccurred())
+			newTP, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key2})
+			Expect(err).ToNot(HaveOccurred())
+			tp, err := newTokenProtector(rand.Reader, []TokenProtectorKey{key2, key1})
+			Expect(err).ToNot(HaveOccurred())
+			oldToken, err := oldTP.NewToken([]byte("old"))
+			Expect(err).ToNot(HaveOccurred())
+			decoded, err := tp.DecodeToken(oldToken)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("old")))
+			token, err := tp.NewToken([]byte("new"))
+			Expect(err).ToNot(HaveOccurred())
+			decoded, err = newTP.DecodeToken(token)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(decoded).To(Equal([]byte("new")))
+			_, err = oldTP.DecodeToken(token)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	It("errors when decoding too short tokens", func() {
 		_, err := tp.DecodeToken([]byte("foobar"))
 		Expect(err).To(MatchError("token too short: 6"))
@@ -15,9 +15,15 @@ import (
 	"github.com/lucas-clemente/quic-go/logging"
 )
 
-// KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
-// It's a package-level variable to allow modifying it for testing purposes.
-var KeyUpdateInterval uint64 = protocol.KeyUpdateInterval
+// KeyUpdatePolicy defines when we initiate a key update.
+type KeyUpdatePolicy struct {
+	// PacketInterval is the maximum number of packets we send or receive before initiating a key update.
+	// If zero, protocol.KeyUpdateInterval is used.
+	PacketInterval uint64
+	// TimeInterval is the maximum time that we use the same keys.
+	// If zero, keys are only updated based on the number of packets.
+	TimeInterval time.Duration
+}
 
 type updatableAEAD struct {
 	suite *qtls.CipherSuiteTLS13
@@ -27,9 +33,17 @@ type updatableAEAD struct {
 	firstPacketNumber  protocol.PacketNumber
 	handshakeConfirmed bool
 
-	keyUpdateInterval  uint64
-	invalidPacketLimit uint64
-	invalidPacketCount uint64
+	keyUpdateInterval     uint64
+	keyUpdateTimeInterval time.Duration
+	keyUpdateRequested    utils.AtomicBool // set when a key update is requested by the application, accessed from multiple go routines
+	keyPhaseStartTime     time.Time        // the time when we started using the current key phase
+
+	confidentialityLimit   uint64
+	invalidPacketLimit     uint64
+	invalidPacketCount     uint64 // the integrity limit applies to all keys used on a connection
+	warnedConfidentiality  bool   // reset on every key update
+	reachedConfidentiality bool   // reset on every key update
+	warnedIntegrity        bool
 
 	// Time when the keys should be dropped. Keys are dropped on the next call to Open().
 	prevRcvAEADExpiry time.Time
@@ -67,13 +81,18 @@ var (
 	_ ShortHeaderSealer = &updatableAEAD{}
 )
 
-func newUpdatableAEAD(rttStats *utils.RTTStats, tracer logging.ConnectionTracer, logger utils.Logger) *updatableAEAD {
+func newUpdatableAEAD(rttStats *utils.RTTStats, keyUpdatePolicy KeyUpdatePolicy, tracer logging.ConnectionTracer, logger utils.Logger) *updatableAEAD {
+	keyUpdateInterval := keyUpdatePolicy.PacketInterval
+	if keyUpdateInterval == 0 {
+		keyUpdateInterval = protocol.KeyUpdateInterval
+	}
 	return &updatableAEAD{
 		firstPacketNumber:       protocol.InvalidPacketNumber,
 		largestAcked:            protocol.InvalidPacketNumber,
 		firstRcvdWithCurrentKey: protocol.InvalidPacketNumber,
 		firstSentWithCurrentKey: protocol.InvalidPacketNumber,
-		keyUpdateInterval:       KeyUpdateInterval,
+		keyUpdateInterval:       keyUpdateInterval,
+		keyUpdateTimeInterval:   keyUpdatePolicy.TimeInterval,
 		rttStats:                rttStats,
 		tracer:                  tracer,
 		logger:                  logger,
@@ -94,6 +113,9 @@ func (a *updatableAEAD) rollKeys() {
 	a.firstSentWithCurrentKey = protocol.InvalidPacketNumber
 	a.numRcvdWithCurrentKey = 0
 	a.numSentWithCurrentKey = 0
+	a.warnedConfidentiality = false
+	a.reachedConfidentiality = false
+	a.keyPhaseStartTime = time.Now()
 	a.prevRcvAEAD = a.rcvAEAD
 	a.rcvAEAD = a.nextRcvAEAD
 	a.sendAEAD = a.nextSendAEAD
@@ -130,6 +152,7 @@ func (a *updatableAEAD) SetReadKey(suite *qtls.CipherSuiteTLS13, trafficSecret [
 // For the client, this function is called after SetReadKey.
 // For the server, this function is called before SetWriteKey.
 func (a *updatableAEAD) SetWriteKey(suite *qtls.CipherSuiteTLS13, trafficSecret []byte) {
+	a.keyPhaseStartTime = time.Now()
 	a.sendAEAD = createAEAD(suite, trafficSecret)
 	a.headerEncrypter = newHeaderProtector(suite, trafficSecret, false)
 	if a.suite == nil {
@@ -146,8 +169,10 @@ func (a *updatableAEAD) setAEADParameters(aead cipher.AEAD, suite *qtls.CipherSu
 	a.suite = suite
 	switch suite.ID {
 	case tls.TLS_AES_128_GCM_SHA256, tls.TLS_AES_256_GCM_SHA384:
+		a.confidentialityLimit = protocol.ConfidentialityLimitAES
 		a.invalidPacketLimit = protocol.InvalidPacketLimitAES
 	case tls.TLS_CHACHA20_POLY1305_SHA256:
+		a.confidentialityLimit = protocol.ConfidentialityLimitChaCha
 		a.invalidPacketLimit = protocol.InvalidPacketLimitChaCha
 	default:
 		panic(fmt.Sprintf("unknown cipher suite %d", suite.ID))
@@ -163,8 +188,19 @@ func (a *updatableAEAD) Open(dst, src []byte, rcvTime time.Time, pn protocol.Pac
 	if err == ErrDecryptionFailed {
 		a.invalidPacketCount++
 		if a.invalidPacketCount >= a.invalidPacketLimit {
+			a.logger.Debugf("Failed to decrypt %d packets. Reached the integrity limit.", a.invalidPacketCount)
+			if a.tracer != nil {
+				a.tracer.ReachedAEADLimit(logging.AEADLimitIntegrity, a.keyPhase, a.invalidPacketCount)
+			}
 			return nil, qerr.AEADLimitReached
 		}
+		if !a.warnedIntegrity && a.invalidPacketCount >= aeadLimitWarningThreshold(a.invalidPacketLimit) {
+			a.warnedIntegrity = true
+			a.logger.Debugf("Failed to decrypt %d packets. The integrity limit is %d.", a.invalidPacketCount, a.invalidPacketLimit)
+			if a.tracer != nil {
+				a.tracer.ApproachingAEADLimit(logging.AEADLimitIntegrity, a.keyPhase, a.invalidPacketCount, a.invalidPacketLimit)
+			}
+		}
 	}
 	if err == nil {
 		a.highestRcvdPN = utils.MaxPacketNumber(a.highestRcvdPN, pn)
@@ -241,12 +277,37 @@ func (a *updatableAEAD) Seal(dst, src []byte, pn protocol.PacketNumber, ad []byt
 		a.firstPacketNumber = pn
 	}
 	a.numSentWithCurrentKey++
+	if !a.warnedConfidentiality && a.numSentWithCurrentKey >= aeadLimitWarningThreshold(a.confidentialityLimit) {
+		a.warnedConfidentiality = true
+		a.logger.Debugf("Sent %d packets with key phase %d. The confidentiality limit is %d.", a.numSentWithCurrentKey, a.keyPhase, a.confidentialityLimit)
+		if a.tracer != nil {
+			a.tracer.ApproachingAEADLimit(logging.AEADLimitConfidentiality, a.keyPhase, a.numSentWithCurrentKey, a.confidentialityLimit)
+		}
+	}
 	binary.BigEndian.PutUint64(a.nonceBuf[len(a.nonceBuf)-8:], uint64(pn))
 	// The AEAD we're using here will be the qtls.aeadAESGCM13.
 	// It uses the nonce provided here and XOR it with the IV.
 	return a.sendAEAD.Seal(dst, a.nonceBuf, src, ad)
 }
 
+// ConfidentialityLimitReached says if the current key has been used to seal as many packets as the confidentiality limit allows.
+// If a key update can be initiated, the next call to KeyPhase() rolls the keys, so the limit is not considered reached.
+// Once the limit is reached, no more packets must be sealed with the current key.
+// This is called for every packet sent, so the limit is only logged the first time it is reached.
+func (a *updatableAEAD) ConfidentialityLimitReached() bool {
+	if a.numSentWithCurrentKey < a.confidentialityLimit || a.updateAllowed() {
+		return false
+	}
+	if !a.reachedConfidentiality {
+		a.reachedConfidentiality = true
+		a.logger.Debugf("Sent %d packets with key phase %d. Reached the confidentiality limit.", a.numSentWithCurrentKey, a.keyPhase)
+		if a.tracer != nil {
+			a.tracer.ReachedAEADLimit(logging.AEADLimitConfidentiality, a.keyPhase, a.numSentWithCurrentKey)
+		}
+	}
+	return true
+}
+
 func (a *updatableAEAD) SetLargestAcked(pn protocol.PacketNumber) error {
 	if a.firstSentWithCurrentKey != protocol.InvalidPacketNumber &&
 		pn >= a.firstSentWithCurrentKey && a.numRcvdWithCurrentKey == 0 {
@@ -260,6 +321,13 @@ func (a *updatableAEAD) SetHandshakeConfirmed() {
 	a.handshakeConfirmed = true
 }
 
+// ForceKeyUpdate requests a key update.
+// The key update is initiated as soon as it is allowed.
+// It is safe to call this function from any go routine.
+func (a *updatableAEAD) ForceKeyUpdate() {
+	a.keyUpdateRequested.Set(true)
+}
+
 func (a *updatableAEAD) updateAllowed() bool {
 	if !a.handshakeConfirmed {
 		return false
@@ -276,6 +344,14 @@ func (a *updatableAEAD) shouldInitiateKeyUpdate() bool {
 	if !a.updateAllowed() {
 		return false
 	}
+	if a.keyUpdateRequested.Get() {
+		a.logger.Debugf("Key update requested. Initiating key update to the next key phase: %d", a.keyPhase+1)
+		return true
+	}
+	if a.keyUpdateTimeInterval > 0 && time.Since(a.keyPhaseStartTime) >= a.keyUpdateTimeInterval {
+		a.logger.Debugf("Used key phase %d for %s. Initiating key update to the next key phase: %d", a.keyPhase, time.Since(a.keyPhaseStartTime), a.keyPhase+1)
+		return true
+	}
 	if a.numRcvdWithCurrentKey >= a.keyUpdateInterval {
 		a.logger.Debugf("Received %d packets with current key phase. Initiating key update to the next key phase: %d", a.numRcvdWithCurrentKey, a.keyPhase+1)
 		return true
@@ -284,11 +360,18 @@ func (a *updatableAEAD) shouldInitiateKeyUpdate() bool {
 		a.logger.Debugf("Sent %d packets with current key phase. Initiating key update to the next key phase: %d", a.numSentWithCurrentKey, a.keyPhase+1)
 		return true
 	}
+	// Update the keys well before reaching the confidentiality limit,
+	// even if a larger key update interval was configured.
+	if a.confidentialityLimit > 0 && a.numSentWithCurrentKey >= aeadLimitWarningThreshold(a.confidentialityLimit) {
+		a.logger.Debugf("Approaching the confidentiality limit for key phase %d. Initiating key update to the next key phase: %d", a.keyPhase, a.keyPhase+1)
+		return true
+	}
 	return false
 }
 
 func (a *updatableAEAD) KeyPhase() protocol.KeyPhaseBit {
 	if a.shouldInitiateKeyUpdate() {
+		a.keyUpdateRequested.Set(false)
 		a.rollKeys()
 		a.logger.Debugf("Initiating key update to key phase %d", a.keyPhase)
 		if a.tracer != nil {
@@ -298,6 +381,11 @@ func (a *updatableAEAD) KeyPhase() protocol.KeyPhaseBit {
 	return a.keyPhase.Bit()
 }
 
+// aeadLimitWarningThreshold is the number of packets at which we warn that an AEAD limit is approached.
+func aeadLimitWarningThreshold(limit uint64) uint64 {
+	return limit / 4 * 3
+}
+
 func (a *updatableAEAD) Overhead() int {
 	return a.aeadOverhead
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/lucas-clemente/quic-go/internal/qerr"
 	"github.com/lucas-clemente/quic-go/internal/qtls"
 	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/logging"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -21,7 +22,7 @@ import (
 var _ = Describe("Updatable AEAD", func() {
 	It("ChaCha test vector from the draft", func() {
 		secret := splitHexString("9ac312a7f877468ebe69422748ad00a1 5443f18203a07d6060f688f30f21632b")
-		aead := newUpdatableAEAD(&utils.RTTStats{}, nil, nil)
+		aead := newUpdatableAEAD(&utils.RTTStats{}, KeyUpdatePolicy{}, nil, nil)
 		chacha := cipherSuites[2]
 		Expect(chacha.ID).To(Equal(tls.TLS_CHACHA20_POLY1305_SHA256))
 		aead.SetWriteKey(chacha, secret)
@@ -54,8 +55,8 @@ var _ = Describe("Updatable AEAD", func() {
 				rand.Read(trafficSecret2)
 
 				rttStats = utils.NewRTTStats()
-				client = newUpdatableAEAD(rttStats, nil, utils.DefaultLogger)
-				server = newUpdatableAEAD(rttStats, serverTracer, utils.DefaultLogger)
+				client = newUpdatableAEAD(rttStats, KeyUpdatePolicy{}, nil, utils.DefaultLogger)
+				server = newUpdatableAEAD(rttStats, KeyUpdatePolicy{}, serverTracer, utils.DefaultLogger)
 				client.SetReadKey(cs, trafficSecret2)
 				client.SetWriteKey(cs, trafficSecret1)
 				server.SetReadKey(cs, trafficSecret1)
@@ -141,6 +142,53 @@ var _ = Describe("Updatable AEAD", func() {
 					Expect(err).To(MatchError(qerr.AEADLimitReached))
 				})
 
+				It("counts invalid packets across key updates for the integrity limit", func() {
+					server.invalidPacketLimit = 10
+					serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitIntegrity, protocol.KeyPhase(0), uint64(6), uint64(10))
+					for i := 0; i < 6; i++ {
+						_, err := server.Open(nil, []byte("foobar"), time.Now(), protocol.PacketNumber(i), protocol.KeyPhaseZero, []byte("ad"))
+						Expect(err).To(MatchError(ErrDecryptionFailed))
+					}
+					server.rollKeys()
+					for i := 6; i < 9; i++ {
+						_, err := server.Open(nil, []byte("foobar"), time.Now(), protocol.PacketNumber(i), protocol.KeyPhaseOne, []byte("ad"))
+						Expect(err).To(MatchError(ErrDecryptionFailed))
+					}
+					serverTracer.EXPECT().ReachedAEADLimit(logging.AEADLimitIntegrity, protocol.KeyPhase(1), uint64(10))
+					_, err := server.Open(nil, []byte("foobar"), time.Now(), 9, protocol.KeyPhaseOne, []byte("ad"))
+					Expect(err).To(MatchError(qerr.AEADLimitReached))
+				})
+
+				It("traces when approaching the integrity limit", func() {
+					server.invalidPacketLimit = 8
+					for i := 0; i < 5; i++ {
+						_, err := server.Open(nil, []byte("foobar"), time.Now(), protocol.PacketNumber(i), protocol.KeyPhaseZero, []byte("ad"))
+						Expect(err).To(MatchError(ErrDecryptionFailed))
+					}
+					serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitIntegrity, protocol.KeyPhase(0), uint64(6), uint64(8))
+					for i := 5; i < 7; i++ {
+						_, err := server.Open(nil, []byte("foobar"), time.Now(), protocol.PacketNumber(i), protocol.KeyPhaseZero, []byte("ad"))
+						Expect(err).To(MatchError(ErrDecryptionFailed))
+					}
+				})
+
+				It("traces when approaching the confidentiality limit", func() {
+					server.confidentialityLimit = 8
+					for i := 0; i < 5; i++ {
+						server.Seal(nil, msg, protocol.PacketNumber(i), ad)
+					}
+					serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitConfidentiality, protocol.KeyPhase(0), uint64(6), uint64(8))
+					server.Seal(nil, msg, 5, ad)
+					server.Seal(nil, msg, 6, ad)
+					// the warning is traced again for the next key phase
+					server.rollKeys()
+					for i := 7; i < 12; i++ {
+						server.Seal(nil, msg, protocol.PacketNumber(i), ad)
+					}
+					serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitConfidentiality, protocol.KeyPhase(1), uint64(6), uint64(8))
+					server.Seal(nil, msg, 12, ad)
+				})
+
 				Context("key updates", func() {
 					Context("receiving key updates", func() {
 						It("updates keys", func() {
@@ -270,6 +318,80 @@ var _ = Describe("Updatable AEAD", func() {
 							server.SetHandshakeConfirmed()
 						})
 
+						It("uses the packet interval of the key update policy", func() {
+							a := newUpdatableAEAD(rttStats, KeyUpdatePolicy{PacketInterval: 42}, nil, utils.DefaultLogger)
+							Expect(a.keyUpdateInterval).To(BeEquivalentTo(42))
+						})
+
+						It("initiates a key update after the time interval of the key update policy", func() {
+							server.keyUpdateTimeInterval = time.Hour
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+							server.keyPhaseStartTime = time.Now().Add(-time.Hour)
+							serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), false)
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+							Expect(server.keyPhaseStartTime).To(BeTemporally("~", time.Now(), time.Second))
+						})
+
+						It("initiates a key update before reaching the confidentiality limit", func() {
+							server.keyUpdateInterval = 1000
+							server.confidentialityLimit = 8
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+							for i := 0; i < 5; i++ {
+								server.Seal(nil, msg, protocol.PacketNumber(i), ad)
+							}
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+							serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitConfidentiality, protocol.KeyPhase(0), uint64(6), uint64(8))
+							server.Seal(nil, msg, 5, ad)
+							serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), false)
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+						})
+
+						It("reaches the confidentiality limit if the keys can't be updated", func() {
+							server.keyUpdateInterval = 1000
+							server.confidentialityLimit = 8
+							server.rollKeys()
+							serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitConfidentiality, protocol.KeyPhase(1), uint64(6), uint64(8))
+							for i := 0; i < 7; i++ {
+								Expect(server.ConfidentialityLimitReached()).To(BeFalse())
+								Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+								server.Seal(nil, msg, protocol.PacketNumber(i), ad)
+							}
+							// no update allowed before receiving an acknowledgement for the current key phase
+							Expect(server.ConfidentialityLimitReached()).To(BeFalse())
+							server.Seal(nil, msg, 7, ad)
+							serverTracer.EXPECT().ReachedAEADLimit(logging.AEADLimitConfidentiality, protocol.KeyPhase(1), uint64(8))
+							Expect(server.ConfidentialityLimitReached()).To(BeTrue())
+							// the limit is only traced once
+							Expect(server.ConfidentialityLimitReached()).To(BeTrue())
+							Expect(server.ConfidentialityLimitReached()).To(BeTrue())
+						})
+
+						It("doesn't reach the confidentiality limit if the keys can be updated", func() {
+							server.keyUpdateInterval = 1000
+							server.confidentialityLimit = 8
+							serverTracer.EXPECT().ApproachingAEADLimit(logging.AEADLimitConfidentiality, protocol.KeyPhase(0), uint64(6), uint64(8))
+							for i := 0; i < 8; i++ {
+								server.Seal(nil, msg, protocol.PacketNumber(i), ad)
+							}
+							Expect(server.ConfidentialityLimitReached()).To(BeFalse())
+							serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), false)
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+						})
+
+						It("initiates a key update when requested", func() {
+							server.ForceKeyUpdate()
+							serverTracer.EXPECT().UpdatedKey(protocol.KeyPhase(1), false)
+							Expect(server.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+							Expect(server.keyUpdateRequested.Get()).To(BeFalse())
+						})
+
+						It("delays a requested key update until the handshake is confirmed", func() {
+							client.ForceKeyUpdate()
+							Expect(client.KeyPhase()).To(Equal(protocol.KeyPhaseZero))
+							client.SetHandshakeConfirmed()
+							Expect(client.KeyPhase()).To(Equal(protocol.KeyPhaseOne))
+						})
+
 						It("initiates a key update after sealing the maximum number of packets, for the first update", func() {
 							for i := 0; i < keyUpdateInterval; i++ {
 								pn := protocol.PacketNumber(i)
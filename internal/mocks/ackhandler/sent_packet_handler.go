@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	ackhandler "github.com/lucas-clemente/quic-go/internal/ackhandler"
+	congestion "github.com/lucas-clemente/quic-go/internal/congestion"
 	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 	wire "github.com/lucas-clemente/quic-go/internal/wire"
 )
@@ -49,6 +50,32 @@ func (mr *MockSentPacketHandlerMockRecorder) DropPackets(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropPackets", reflect.TypeOf((*MockSentPacketHandler)(nil).DropPackets), arg0)
 }
 
+// EnableAckFrequency mocks base method.
+func (m *MockSentPacketHandler) EnableAckFrequency() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "EnableAckFrequency")
+}
+
+// EnableAckFrequency indicates an expected call of EnableAckFrequency.
+func (mr *MockSentPacketHandlerMockRecorder) EnableAckFrequency() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableAckFrequency", reflect.TypeOf((*MockSentPacketHandler)(nil).EnableAckFrequency))
+}
+
+// GetAckFrequencyFrame mocks base method.
+func (m *MockSentPacketHandler) GetAckFrequencyFrame() *wire.AckFrequencyFrame {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAckFrequencyFrame")
+	ret0, _ := ret[0].(*wire.AckFrequencyFrame)
+	return ret0
+}
+
+// GetAckFrequencyFrame indicates an expected call of GetAckFrequencyFrame.
+func (mr *MockSentPacketHandlerMockRecorder) GetAckFrequencyFrame() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAckFrequencyFrame", reflect.TypeOf((*MockSentPacketHandler)(nil).GetAckFrequencyFrame))
+}
+
 // GetLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) GetLossDetectionTimeout() time.Time {
 	m.ctrl.T.Helper()
@@ -63,6 +90,20 @@ func (mr *MockSentPacketHandlerMockRecorder) GetLossDetectionTimeout() *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLossDetectionTimeout", reflect.TypeOf((*MockSentPacketHandler)(nil).GetLossDetectionTimeout))
 }
 
+// GetStats mocks base method.
+func (m *MockSentPacketHandler) GetStats() ackhandler.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStats")
+	ret0, _ := ret[0].(ackhandler.Stats)
+	return ret0
+}
+
+// GetStats indicates an expected call of GetStats.
+func (mr *MockSentPacketHandlerMockRecorder) GetStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStats", reflect.TypeOf((*MockSentPacketHandler)(nil).GetStats))
+}
+
 // HasPacingBudget mocks base method.
 func (m *MockSentPacketHandler) HasPacingBudget() bool {
 	m.ctrl.T.Helper()
@@ -77,6 +118,18 @@ func (mr *MockSentPacketHandlerMockRecorder) HasPacingBudget() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPacingBudget", reflect.TypeOf((*MockSentPacketHandler)(nil).HasPacingBudget))
 }
 
+// MigratedPath mocks base method.
+func (m *MockSentPacketHandler) MigratedPath(arg0 congestion.SendAlgorithmWithDebugInfos, arg1 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "MigratedPath", arg0, arg1)
+}
+
+// MigratedPath indicates an expected call of MigratedPath.
+func (mr *MockSentPacketHandlerMockRecorder) MigratedPath(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigratedPath", reflect.TypeOf((*MockSentPacketHandler)(nil).MigratedPath), arg0, arg1)
+}
+
 // OnLossDetectionTimeout mocks base method.
 func (m *MockSentPacketHandler) OnLossDetectionTimeout() error {
 	m.ctrl.T.Helper()
@@ -134,6 +187,18 @@ func (mr *MockSentPacketHandlerMockRecorder) QueueProbePacket(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueProbePacket", reflect.TypeOf((*MockSentPacketHandler)(nil).QueueProbePacket), arg0)
 }
 
+// ReboundPath mocks base method.
+func (m *MockSentPacketHandler) ReboundPath(arg0 bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReboundPath", arg0)
+}
+
+// ReboundPath indicates an expected call of ReboundPath.
+func (mr *MockSentPacketHandlerMockRecorder) ReboundPath(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReboundPath", reflect.TypeOf((*MockSentPacketHandler)(nil).ReboundPath), arg0)
+}
+
 // ReceivedAck mocks base method.
 func (m *MockSentPacketHandler) ReceivedAck(arg0 *wire.AckFrame, arg1 protocol.EncryptionLevel, arg2 time.Time) error {
 	m.ctrl.T.Helper()
@@ -237,3 +302,15 @@ func (mr *MockSentPacketHandlerMockRecorder) TimeUntilSend() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TimeUntilSend", reflect.TypeOf((*MockSentPacketHandler)(nil).TimeUntilSend))
 }
+
+// ValidatedPath mocks base method.
+func (m *MockSentPacketHandler) ValidatedPath() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ValidatedPath")
+}
+
+// ValidatedPath indicates an expected call of ValidatedPath.
+func (mr *MockSentPacketHandlerMockRecorder) ValidatedPath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatedPath", reflect.TypeOf((*MockSentPacketHandler)(nil).ValidatedPath))
+}
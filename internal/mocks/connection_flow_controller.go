@@ -58,6 +58,20 @@ func (mr *MockConnectionFlowControllerMockRecorder) AddBytesSent(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBytesSent", reflect.TypeOf((*MockConnectionFlowController)(nil).AddBytesSent), arg0)
 }
 
+// BufferedBytes mocks base method.
+func (m *MockConnectionFlowController) BufferedBytes() protocol.ByteCount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BufferedBytes")
+	ret0, _ := ret[0].(protocol.ByteCount)
+	return ret0
+}
+
+// BufferedBytes indicates an expected call of BufferedBytes.
+func (mr *MockConnectionFlowControllerMockRecorder) BufferedBytes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BufferedBytes", reflect.TypeOf((*MockConnectionFlowController)(nil).BufferedBytes))
+}
+
 // GetWindowUpdate mocks base method.
 func (m *MockConnectionFlowController) GetWindowUpdate() protocol.ByteCount {
 	m.ctrl.T.Helper()
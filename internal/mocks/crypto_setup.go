@@ -76,6 +76,18 @@ func (mr *MockCryptoSetupMockRecorder) ConnectionState() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectionState", reflect.TypeOf((*MockCryptoSetup)(nil).ConnectionState))
 }
 
+// ForceKeyUpdate mocks base method.
+func (m *MockCryptoSetup) ForceKeyUpdate() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForceKeyUpdate")
+}
+
+// ForceKeyUpdate indicates an expected call of ForceKeyUpdate.
+func (mr *MockCryptoSetupMockRecorder) ForceKeyUpdate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceKeyUpdate", reflect.TypeOf((*MockCryptoSetup)(nil).ForceKeyUpdate))
+}
+
 // Get0RTTOpener mocks base method.
 func (m *MockCryptoSetup) Get0RTTOpener() (handshake.LongHeaderOpener, error) {
 	m.ctrl.T.Helper()
@@ -197,18 +209,18 @@ func (mr *MockCryptoSetupMockRecorder) GetInitialSealer() *gomock.Call {
 }
 
 // GetSessionTicket mocks base method.
-func (m *MockCryptoSetup) GetSessionTicket() ([]byte, error) {
+func (m *MockCryptoSetup) GetSessionTicket(arg0 []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSessionTicket")
+	ret := m.ctrl.Call(m, "GetSessionTicket", arg0)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetSessionTicket indicates an expected call of GetSessionTicket.
-func (mr *MockCryptoSetupMockRecorder) GetSessionTicket() *gomock.Call {
+func (mr *MockCryptoSetupMockRecorder) GetSessionTicket(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionTicket", reflect.TypeOf((*MockCryptoSetup)(nil).GetSessionTicket))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionTicket", reflect.TypeOf((*MockCryptoSetup)(nil).GetSessionTicket), arg0)
 }
 
 // HandleMessage mocks base method.
@@ -262,3 +274,15 @@ func (mr *MockCryptoSetupMockRecorder) SetLargest1RTTAcked(arg0 interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLargest1RTTAcked", reflect.TypeOf((*MockCryptoSetup)(nil).SetLargest1RTTAcked), arg0)
 }
+
+// SetVersion mocks base method.
+func (m *MockCryptoSetup) SetVersion(arg0 protocol.VersionNumber) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetVersion", arg0)
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockCryptoSetupMockRecorder) SetVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockCryptoSetup)(nil).SetVersion), arg0)
+}
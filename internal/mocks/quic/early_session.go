@@ -109,6 +109,18 @@ func (mr *MockEarlySessionMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockEarlySession)(nil).Context))
 }
 
+// ForceKeyUpdate mocks base method.
+func (m *MockEarlySession) ForceKeyUpdate() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForceKeyUpdate")
+}
+
+// ForceKeyUpdate indicates an expected call of ForceKeyUpdate.
+func (mr *MockEarlySessionMockRecorder) ForceKeyUpdate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceKeyUpdate", reflect.TypeOf((*MockEarlySession)(nil).ForceKeyUpdate))
+}
+
 // HandshakeComplete mocks base method.
 func (m *MockEarlySession) HandshakeComplete() context.Context {
 	m.ctrl.T.Helper()
@@ -137,6 +149,34 @@ func (mr *MockEarlySessionMockRecorder) LocalAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockEarlySession)(nil).LocalAddr))
 }
 
+// MaxDatagramSize mocks base method.
+func (m *MockEarlySession) MaxDatagramSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockEarlySessionMockRecorder) MaxDatagramSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockEarlySession)(nil).MaxDatagramSize))
+}
+
+// MigrateTo mocks base method.
+func (m *MockEarlySession) MigrateTo(arg0 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockEarlySessionMockRecorder) MigrateTo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockEarlySession)(nil).MigrateTo), arg0)
+}
+
 // NextSession mocks base method.
 func (m *MockEarlySession) NextSession() quic.Session {
 	m.ctrl.T.Helper()
@@ -240,6 +280,20 @@ func (mr *MockEarlySessionMockRecorder) RemoteAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockEarlySession)(nil).RemoteAddr))
 }
 
+// SendDatagram mocks base method.
+func (m *MockEarlySession) SendDatagram(arg0 []byte, arg1 *quic.DatagramSendOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagram", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagram indicates an expected call of SendDatagram.
+func (mr *MockEarlySessionMockRecorder) SendDatagram(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagram", reflect.TypeOf((*MockEarlySession)(nil).SendDatagram), arg0, arg1)
+}
+
 // SendMessage mocks base method.
 func (m *MockEarlySession) SendMessage(arg0 []byte) error {
 	m.ctrl.T.Helper()
@@ -253,3 +307,17 @@ func (mr *MockEarlySessionMockRecorder) SendMessage(arg0 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockEarlySession)(nil).SendMessage), arg0)
 }
+
+// Stats mocks base method.
+func (m *MockEarlySession) Stats() quic.ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(quic.ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockEarlySessionMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockEarlySession)(nil).Stats))
+}
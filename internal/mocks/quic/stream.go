@@ -6,10 +6,12 @@ package mockquic
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	quic "github.com/lucas-clemente/quic-go"
 	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
@@ -60,6 +62,18 @@ func (mr *MockStreamMockRecorder) CancelWrite(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWrite", reflect.TypeOf((*MockStream)(nil).CancelWrite), arg0)
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStream) CancelWriteAt(arg0 protocol.ApplicationErrorCode, arg1 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelWriteAt", arg0, arg1)
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamMockRecorder) CancelWriteAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStream)(nil).CancelWriteAt), arg0, arg1)
+}
+
 // Close mocks base method.
 func (m *MockStream) Close() error {
 	m.ctrl.T.Helper()
@@ -88,6 +102,35 @@ func (mr *MockStreamMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockStream)(nil).Context))
 }
 
+// Peek mocks base method.
+func (m *MockStream) Peek() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peek")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Peek indicates an expected call of Peek.
+func (mr *MockStreamMockRecorder) Peek() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peek", reflect.TypeOf((*MockStream)(nil).Peek))
+}
+
+// Priority mocks base method.
+func (m *MockStream) Priority() protocol.Priority {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(protocol.Priority)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockStreamMockRecorder) Priority() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockStream)(nil).Priority))
+}
+
 // Read mocks base method.
 func (m *MockStream) Read(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +146,65 @@ func (mr *MockStreamMockRecorder) Read(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStream)(nil).Read), arg0)
 }
 
+// ReadBuffer mocks base method.
+func (m *MockStream) ReadBuffer() ([]byte, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBuffer")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadBuffer indicates an expected call of ReadBuffer.
+func (mr *MockStreamMockRecorder) ReadBuffer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBuffer", reflect.TypeOf((*MockStream)(nil).ReadBuffer))
+}
+
+// ReadFrom mocks base method.
+func (m *MockStream) ReadFrom(arg0 io.Reader) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFrom", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFrom indicates an expected call of ReadFrom.
+func (mr *MockStreamMockRecorder) ReadFrom(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFrom", reflect.TypeOf((*MockStream)(nil).ReadFrom), arg0)
+}
+
+// ReceiveStats mocks base method.
+func (m *MockStream) ReceiveStats() quic.ReceiveStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveStats")
+	ret0, _ := ret[0].(quic.ReceiveStreamStats)
+	return ret0
+}
+
+// ReceiveStats indicates an expected call of ReceiveStats.
+func (mr *MockStreamMockRecorder) ReceiveStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveStats", reflect.TypeOf((*MockStream)(nil).ReceiveStats))
+}
+
+// SendStats mocks base method.
+func (m *MockStream) SendStats() quic.SendStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendStats")
+	ret0, _ := ret[0].(quic.SendStreamStats)
+	return ret0
+}
+
+// SendStats indicates an expected call of SendStats.
+func (mr *MockStreamMockRecorder) SendStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendStats", reflect.TypeOf((*MockStream)(nil).SendStats))
+}
+
 // SetDeadline mocks base method.
 func (m *MockStream) SetDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -117,6 +219,18 @@ func (mr *MockStreamMockRecorder) SetDeadline(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeadline", reflect.TypeOf((*MockStream)(nil).SetDeadline), arg0)
 }
 
+// SetPriority mocks base method.
+func (m *MockStream) SetPriority(arg0 protocol.Priority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamMockRecorder) SetPriority(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStream)(nil).SetPriority), arg0)
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStream) SetReadDeadline(arg0 time.Time) error {
 	m.ctrl.T.Helper()
@@ -159,6 +273,34 @@ func (mr *MockStreamMockRecorder) StreamID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamID", reflect.TypeOf((*MockStream)(nil).StreamID))
 }
 
+// Used0RTT mocks base method.
+func (m *MockStream) Used0RTT() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Used0RTT")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Used0RTT indicates an expected call of Used0RTT.
+func (mr *MockStreamMockRecorder) Used0RTT() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Used0RTT", reflect.TypeOf((*MockStream)(nil).Used0RTT))
+}
+
+// WaitForAcks mocks base method.
+func (m *MockStream) WaitForAcks(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForAcks", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForAcks indicates an expected call of WaitForAcks.
+func (mr *MockStreamMockRecorder) WaitForAcks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForAcks", reflect.TypeOf((*MockStream)(nil).WaitForAcks), arg0)
+}
+
 // Write mocks base method.
 func (m *MockStream) Write(arg0 []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -173,3 +315,33 @@ func (mr *MockStreamMockRecorder) Write(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStream)(nil).Write), arg0)
 }
+
+// WriteBuffer mocks base method.
+func (m *MockStream) WriteBuffer(arg0 []byte, arg1 func()) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBuffer", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteBuffer indicates an expected call of WriteBuffer.
+func (mr *MockStreamMockRecorder) WriteBuffer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBuffer", reflect.TypeOf((*MockStream)(nil).WriteBuffer), arg0, arg1)
+}
+
+// WriteTo mocks base method.
+func (m *MockStream) WriteTo(arg0 io.Writer) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteTo", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteTo indicates an expected call of WriteTo.
+func (mr *MockStreamMockRecorder) WriteTo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTo", reflect.TypeOf((*MockStream)(nil).WriteTo), arg0)
+}
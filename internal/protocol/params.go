@@ -45,6 +45,10 @@ const DefaultMaxIncomingUniStreams = 100
 // MaxServerUnprocessedPackets is the max number of packets stored in the server that are not yet processed.
 const MaxServerUnprocessedPackets = 1024
 
+// MaxPacketBufferMemory is the maximum number of bytes used by packet buffers at any time.
+// Received packets are dropped when this limit is exceeded.
+const MaxPacketBufferMemory ByteCount = 64 * (1 << 20) // 64 MB
+
 // MaxSessionUnprocessedPackets is the max number of packets stored in each session that are not yet processed.
 const MaxSessionUnprocessedPackets = 256
 
@@ -59,6 +63,27 @@ const SkipPacketMaxPeriod PacketNumber = 128 * 1024
 // If the queue is full, new connection attempts will be rejected.
 const MaxAcceptQueueSize = 32
 
+// DefaultMaxUnvalidatedHandshakes is the default number of concurrent handshakes with clients that didn't validate their address,
+// above which the server requires address validation using a Retry.
+const DefaultMaxUnvalidatedHandshakes = 256
+
+// DefaultMaxUnvalidatedHandshakeRate is the default rate (per second) of new handshakes with clients that didn't validate their address,
+// above which the server requires address validation using a Retry.
+const DefaultMaxUnvalidatedHandshakeRate = 1000
+
+// DefaultMaxInitialRatePerSubnet is the default rate (per second) of Initial packets starting a new handshake accepted from a single subnet.
+const DefaultMaxInitialRatePerSubnet = 100
+
+// InitialRateLimitPrefixLenIPv4 and InitialRateLimitPrefixLenIPv6 are the prefix lengths of the subnets
+// that Initial packets are rate limited for.
+const (
+	InitialRateLimitPrefixLenIPv4 = 24
+	InitialRateLimitPrefixLenIPv6 = 48
+)
+
+// MaxRateLimitedSubnets is the maximum number of subnets that the server tracks the rate of Initial packets for.
+const MaxRateLimitedSubnets = 100 * 1000
+
 // TokenValidity is the duration that a (non-retry) token is considered valid
 const TokenValidity = 24 * time.Hour
 
@@ -108,6 +133,10 @@ const DefaultHandshakeIdleTimeout = 5 * time.Second
 // DefaultHandshakeTimeout is the default timeout for a connection until the crypto handshake succeeds.
 const DefaultHandshakeTimeout = 10 * time.Second
 
+// DefaultMaxZeroRTTTicketAge is the default maximum age of a session ticket that is used for 0-RTT.
+// Used session tickets are remembered for (at least) this long, in order to detect replays.
+const DefaultMaxZeroRTTTicketAge = 24 * time.Hour
+
 // MaxKeepAliveInterval is the maximum time until we send a packet to keep a connection alive.
 // It should be shorter than the time that NATs clear their mapping.
 const MaxKeepAliveInterval = 20 * time.Second
@@ -141,6 +170,9 @@ const MaxDatagramFrameSize ByteCount = 1220
 // See https://datatracker.ietf.org/doc/draft-pauly-quic-datagram/.
 const DatagramRcvQueueLen = 128
 
+// DefaultDatagramSendQueueLen is the default length of the send queue for DATAGRAM frames.
+const DefaultDatagramSendQueueLen = 32
+
 // MaxNumAckRanges is the maximum number of ACK ranges that we send in an ACK frame.
 // It also serves as a limit for the packet history.
 // If at any point we keep track of more ranges, old ranges are discarded.
@@ -168,6 +200,10 @@ const PacketsPerConnectionID = 10000
 // AckDelayExponent is the ack delay exponent used when sending ACKs.
 const AckDelayExponent = 3
 
+// DefaultInitialRTT is the RTT assumed for a new path, before an RTT sample was taken on that path.
+// This is kInitialRtt, see section 6.2.2 of RFC 9002.
+const DefaultInitialRTT = 333 * time.Millisecond
+
 // Estimated timer granularity.
 // The loss detection timer will not be set to a value smaller than granularity.
 const TimerGranularity = time.Millisecond
@@ -179,6 +215,10 @@ const MaxAckDelay = 25 * time.Millisecond
 // This is the value that should be advertised to the peer.
 const MaxAckDelayInclGranularity = MaxAckDelay + TimerGranularity
 
+// MinAckDelay is the min_ack_delay we advertise when using the ACK frequency extension.
+// It is the smallest max ack delay that the peer can request.
+const MinAckDelay = TimerGranularity
+
 // KeyUpdateInterval is the maximum number of packets we send or receive before initiating a key update.
 const KeyUpdateInterval = 100 * 1000
 
@@ -0,0 +1,22 @@
+package protocol
+
+const (
+	// MaxUrgency is the lowest urgency a stream can have, as defined in RFC 9218.
+	MaxUrgency = 7
+	// DefaultUrgency is the urgency of a stream that hasn't been prioritized.
+	DefaultUrgency = 3
+)
+
+// Priority is the priority of a stream, following the Extensible Prioritization Scheme (RFC 9218).
+type Priority struct {
+	// Urgency ranges from 0 (most urgent) to MaxUrgency (least urgent).
+	Urgency uint8
+	// Incremental streams of the same urgency share the available bandwidth in a round-robin fashion.
+	// Non-incremental streams are sent one after the other.
+	Incremental bool
+}
+
+// DefaultPriority is the priority of a newly opened stream.
+// Unlike the default priority of an HTTP request (RFC 9218, section 4), streams are incremental by default,
+// such that all streams that haven't been prioritized share the available bandwidth.
+var DefaultPriority = Priority{Urgency: DefaultUrgency, Incremental: true}
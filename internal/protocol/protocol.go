@@ -64,6 +64,10 @@ type StatelessResetToken [16]byte
 // Ethernet's max packet size is 1500 bytes,  1500 - 48 = 1452.
 const MaxPacketBufferSize ByteCount = 1452
 
+// MaxGSOBufferSize is the size of the buffers used to send multiple packets using Generic Segmentation Offload (GSO).
+// The kernel limits the number of segments to 64, and the total size to 64 KB.
+const MaxGSOBufferSize = 20 * MaxPacketBufferSize
+
 // MinInitialPacketSize is the minimum size an Initial packet is required to have.
 const MinInitialPacketSize = 1200
 
@@ -92,6 +96,14 @@ const MaxMaxAckDelay = (1<<14 - 1) * time.Millisecond
 // MaxConnIDLen is the maximum length of the connection ID
 const MaxConnIDLen = 20
 
+// ConfidentialityLimitAES is the maximum number of packets that we can encrypt with a single key when using
+// AEAD_AES_128_GCM or AEAD_AES_265_GCM.
+const ConfidentialityLimitAES = 1 << 23
+
+// ConfidentialityLimitChaCha is the maximum number of packets that we can encrypt with a single key when using AEAD_CHACHA20_POLY1305.
+// The limit is larger than the number of possible packets, so this is the largest packet number.
+const ConfidentialityLimitChaCha = 1 << 62
+
 // InvalidPacketLimitAES is the maximum number of packets that we can fail to decrypt when using
 // AEAD_AES_128_GCM or AEAD_AES_265_GCM.
 const InvalidPacketLimitAES = 1 << 52
@@ -18,17 +18,18 @@ const (
 
 // The version numbers, making grepping easier
 const (
-	VersionTLS      VersionNumber = 0x51474fff
-	VersionWhatever VersionNumber = 1 // for when the version doesn't matter
+	VersionTLS      VersionNumber = Version1
+	VersionWhatever VersionNumber = math.MaxUint32 - 1 // for when the version doesn't matter
 	VersionUnknown  VersionNumber = math.MaxUint32
 	VersionDraft29  VersionNumber = 0xff00001d
 	VersionDraft32  VersionNumber = 0xff000020
-	VersionDraft34  VersionNumber = 0xff000022 // If everything goes according to plan at the IETF, this will one day be QUIC v1.
+	VersionDraft34  VersionNumber = 0xff000022
+	Version1        VersionNumber = 0x1 // RFC 9000
 )
 
 // SupportedVersions lists the versions that the server supports
-// must be in sorted descending order
-var SupportedVersions = []VersionNumber{VersionTLS}
+// must be in order of preference (most preferred first)
+var SupportedVersions = []VersionNumber{Version1, VersionDraft34, VersionDraft32, VersionDraft29}
 
 // IsValidVersion says if the version is known to quic-go
 func IsValidVersion(v VersionNumber) bool {
@@ -36,11 +37,6 @@ func IsValidVersion(v VersionNumber) bool {
 }
 
 func (vn VersionNumber) String() string {
-	// For releases, VersionTLS will be set to a draft version.
-	// A switch statement can't contain duplicate cases.
-	if vn == VersionTLS && VersionTLS != VersionDraft29 && VersionTLS != VersionDraft32 {
-		return "TLS dev version (WIP)"
-	}
 	//nolint:exhaustive
 	switch vn {
 	case VersionWhatever:
@@ -53,6 +49,8 @@ func (vn VersionNumber) String() string {
 		return "draft-32"
 	case VersionDraft34:
 		return "draft-34"
+	case Version1:
+		return "v1"
 	default:
 		if vn.isGQUIC() {
 			return fmt.Sprintf("gQUIC %d", vn.toGQUICVersion())
@@ -85,6 +83,47 @@ func IsSupportedVersion(supported []VersionNumber, v VersionNumber) bool {
 	return false
 }
 
+// AreCompatibleVersions says if two versions are compatible, as defined in RFC 9368.
+// Compatible versions use the same Initial packet format and the same Initial and Retry keys,
+// so that the server can switch to the other version without an additional round trip.
+func AreCompatibleVersions(v1, v2 VersionNumber) bool {
+	if v1 == v2 {
+		return true
+	}
+	g1, ok1 := compatibilityGroup(v1)
+	g2, ok2 := compatibilityGroup(v2)
+	return ok1 && ok2 && g1 == g2
+}
+
+func compatibilityGroup(v VersionNumber) (int, bool) {
+	//nolint:exhaustive
+	switch v {
+	case VersionDraft29, VersionDraft32:
+		return 0, true
+	case VersionDraft34, Version1:
+		return 1, true
+	default:
+		return 0, false
+	}
+}
+
+// ChooseCompatibleVersion performs compatible version negotiation.
+// ours is a slice of versions that we support, sorted by our preference (descending).
+// chosen is the version used by the peer for its first packet, and theirs are the versions offered by the peer.
+// It returns the most preferred version that is compatible with the chosen version.
+// If no better version is found, the chosen version is returned.
+func ChooseCompatibleVersion(ours []VersionNumber, chosen VersionNumber, theirs []VersionNumber) VersionNumber {
+	for _, v := range ours {
+		if v == chosen {
+			return chosen
+		}
+		if AreCompatibleVersions(v, chosen) && IsSupportedVersion(theirs, v) {
+			return v
+		}
+	}
+	return chosen
+}
+
 // ChooseSupportedVersion finds the best version in the overlap of ours and theirs
 // ours is a slice of versions that we support, sorted by our preference (descending)
 // theirs is a slice of versions offered by the peer. The order does not matter.
@@ -14,18 +14,21 @@ var _ = Describe("Version", func() {
 		Expect(IsValidVersion(VersionTLS)).To(BeTrue())
 		Expect(IsValidVersion(VersionWhatever)).To(BeFalse())
 		Expect(IsValidVersion(VersionUnknown)).To(BeFalse())
-		Expect(IsValidVersion(VersionDraft29)).To(BeFalse())
-		Expect(IsValidVersion(VersionDraft32)).To(BeFalse())
-		Expect(IsValidVersion(VersionDraft34)).To(BeFalse())
+		Expect(IsValidVersion(VersionDraft29)).To(BeTrue())
+		Expect(IsValidVersion(VersionDraft32)).To(BeTrue())
+		Expect(IsValidVersion(VersionDraft34)).To(BeTrue())
+		Expect(IsValidVersion(Version1)).To(BeTrue())
 		Expect(IsValidVersion(1234)).To(BeFalse())
 	})
 
 	It("versions don't have reserved version numbers", func() {
-		Expect(isReservedVersion(VersionTLS)).To(BeFalse())
+		for _, v := range SupportedVersions {
+			Expect(isReservedVersion(v)).To(BeFalse())
+		}
 	})
 
 	It("has the right string representation", func() {
-		Expect(VersionTLS.String()).To(ContainSubstring("TLS"))
+		Expect(Version1.String()).To(Equal("v1"))
 		Expect(VersionWhatever.String()).To(Equal("whatever"))
 		Expect(VersionUnknown.String()).To(Equal("unknown"))
 		Expect(VersionDraft29.String()).To(Equal("draft-29"))
@@ -45,10 +48,41 @@ var _ = Describe("Version", func() {
 		Expect(IsSupportedVersion(SupportedVersions, SupportedVersions[len(SupportedVersions)-1])).To(BeTrue())
 	})
 
-	It("has supported versions in sorted order", func() {
-		for i := 0; i < len(SupportedVersions)-1; i++ {
-			Expect(SupportedVersions[i]).To(BeNumerically(">", SupportedVersions[i+1]))
-		}
+	It("prefers QUIC v1", func() {
+		Expect(SupportedVersions[0]).To(Equal(Version1))
+	})
+
+	It("says if versions are compatible", func() {
+		Expect(AreCompatibleVersions(Version1, Version1)).To(BeTrue())
+		Expect(AreCompatibleVersions(Version1, VersionDraft34)).To(BeTrue())
+		Expect(AreCompatibleVersions(VersionDraft34, Version1)).To(BeTrue())
+		Expect(AreCompatibleVersions(VersionDraft29, VersionDraft32)).To(BeTrue())
+		Expect(AreCompatibleVersions(Version1, VersionDraft29)).To(BeFalse())
+		Expect(AreCompatibleVersions(VersionDraft34, VersionDraft32)).To(BeFalse())
+		Expect(AreCompatibleVersions(Version1, 0x1234)).To(BeFalse())
+		Expect(AreCompatibleVersions(0x1234, 0x1234)).To(BeTrue())
+	})
+
+	Context("compatible version negotiation", func() {
+		It("upgrades to a preferred compatible version", func() {
+			v := ChooseCompatibleVersion([]VersionNumber{Version1, VersionDraft34}, VersionDraft34, []VersionNumber{VersionDraft34, Version1})
+			Expect(v).To(Equal(Version1))
+		})
+
+		It("keeps the chosen version if we prefer it", func() {
+			v := ChooseCompatibleVersion([]VersionNumber{VersionDraft34, Version1}, VersionDraft34, []VersionNumber{VersionDraft34, Version1})
+			Expect(v).To(Equal(VersionDraft34))
+		})
+
+		It("doesn't upgrade to a version that the peer doesn't support", func() {
+			v := ChooseCompatibleVersion([]VersionNumber{Version1, VersionDraft34}, VersionDraft34, []VersionNumber{VersionDraft34})
+			Expect(v).To(Equal(VersionDraft34))
+		})
+
+		It("doesn't upgrade to an incompatible version", func() {
+			v := ChooseCompatibleVersion([]VersionNumber{VersionDraft29, VersionDraft34}, VersionDraft34, []VersionNumber{VersionDraft34, VersionDraft29})
+			Expect(v).To(Equal(VersionDraft34))
+		})
 	})
 
 	It("says if backwards compatibility mode should be used", func() {
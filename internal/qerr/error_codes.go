@@ -28,6 +28,7 @@ const (
 	KeyUpdateError          ErrorCode = 0xe
 	AEADLimitReached        ErrorCode = 0xf
 	NoViablePathError       ErrorCode = 0x10
+	VersionNegotiationError ErrorCode = 0x11
 )
 
 func (e ErrorCode) isCryptoError() bool {
@@ -86,6 +87,8 @@ func (e ErrorCode) String() string {
 		return "AEAD_LIMIT_REACHED"
 	case NoViablePathError:
 		return "NO_VIABLE_PATH"
+	case VersionNegotiationError:
+		return "VERSION_NEGOTIATION_ERROR"
 	default:
 		if e.isCryptoError() {
 			return fmt.Sprintf("CRYPTO_ERROR (%#x)", uint16(e))
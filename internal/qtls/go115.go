@@ -7,7 +7,9 @@ import (
 	"crypto"
 	"crypto/cipher"
 	"crypto/tls"
+	"crypto/x509"
 	"net"
+	"time"
 	"unsafe"
 
 	qtls "github.com/marten-seemann/qtls-go1-15"
@@ -113,3 +115,75 @@ func CipherSuiteTLS13ByID(id uint16) *CipherSuiteTLS13 {
 		Hash:   cs.Hash,
 	}
 }
+
+// clientSessionState has the same memory layout as the clientSessionState used by qtls.
+// qtls converts it to a tls.ClientSessionState, which doesn't expose any of its fields.
+// The layout is checked against tls.ClientSessionState in the tests.
+type clientSessionState struct {
+	sessionTicket      []uint8
+	vers               uint16
+	cipherSuite        uint16
+	masterSecret       []byte
+	serverCertificates []*x509.Certificate
+	verifiedChains     [][]*x509.Certificate
+	receivedAt         time.Time
+	ocspResponse       []byte
+	scts               [][]byte
+	nonce              []byte
+	useBy              time.Time
+	ageAdd             uint32
+}
+
+// ClientSessionStateData contains the fields of a ClientSessionState.
+// It is used to serialize session tickets.
+type ClientSessionStateData struct {
+	SessionTicket      []byte
+	Version            uint16
+	CipherSuite        uint16
+	MasterSecret       []byte
+	ServerCertificates []*x509.Certificate
+	VerifiedChains     [][]*x509.Certificate
+	ReceivedAt         time.Time
+	OCSPResponse       []byte
+	SCTs               [][]byte
+	Nonce              []byte // contains the data saved using the GetAppDataForSessionState callback
+	UseBy              time.Time
+	AgeAdd             uint32
+}
+
+// GetClientSessionStateData extracts the fields of a ClientSessionState.
+func GetClientSessionStateData(s *ClientSessionState) *ClientSessionStateData {
+	cs := (*clientSessionState)(unsafe.Pointer(s))
+	return &ClientSessionStateData{
+		SessionTicket:      cs.sessionTicket,
+		Version:            cs.vers,
+		CipherSuite:        cs.cipherSuite,
+		MasterSecret:       cs.masterSecret,
+		ServerCertificates: cs.serverCertificates,
+		VerifiedChains:     cs.verifiedChains,
+		ReceivedAt:         cs.receivedAt,
+		OCSPResponse:       cs.ocspResponse,
+		SCTs:               cs.scts,
+		Nonce:              cs.nonce,
+		UseBy:              cs.useBy,
+		AgeAdd:             cs.ageAdd,
+	}
+}
+
+// NewClientSessionState creates a ClientSessionState from its fields.
+func NewClientSessionState(d *ClientSessionStateData) *ClientSessionState {
+	return (*ClientSessionState)(unsafe.Pointer(&clientSessionState{
+		sessionTicket:      d.SessionTicket,
+		vers:               d.Version,
+		cipherSuite:        d.CipherSuite,
+		masterSecret:       d.MasterSecret,
+		serverCertificates: d.ServerCertificates,
+		verifiedChains:     d.VerifiedChains,
+		receivedAt:         d.ReceivedAt,
+		ocspResponse:       d.OCSPResponse,
+		scts:               d.SCTs,
+		nonce:              d.Nonce,
+		useBy:              d.UseBy,
+		ageAdd:             d.AgeAdd,
+	}))
+}
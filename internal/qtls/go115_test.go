@@ -5,6 +5,7 @@ package qtls
 
 import (
 	"crypto/tls"
+	"reflect"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -17,4 +18,9 @@ var _ = Describe("qtls wrapper", func() {
 			Expect(cs.ID).To(Equal(id))
 		}
 	})
+
+	It("uses the same memory layout for the ClientSessionState as crypto/tls", func() {
+		// GetClientSessionStateData and NewClientSessionState convert between these types using unsafe.
+		Expect(structsEqual(reflect.TypeOf(tls.ClientSessionState{}), reflect.TypeOf(clientSessionState{}))).To(BeTrue())
+	})
 })
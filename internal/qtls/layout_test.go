@@ -0,0 +1,71 @@
+package qtls
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// structsEqual checks that two structs have the same memory layout,
+// i.e. that they have the same fields, with the same names, types and offsets.
+func structsEqual(a, b reflect.Type) bool {
+	if a.Size() != b.Size() || a.NumField() != b.NumField() {
+		return false
+	}
+	for i := 0; i < a.NumField(); i++ {
+		fa := a.Field(i)
+		fb := b.Field(i)
+		if fa.Name != fb.Name || fa.Type != fb.Type || fa.Offset != fb.Offset {
+			return false
+		}
+	}
+	return true
+}
+
+var _ = Describe("struct layout", func() {
+	It("detects structs with the same layout", func() {
+		type a struct {
+			foo uint16
+			bar []byte
+		}
+		type b struct {
+			foo uint16
+			bar []byte
+		}
+		Expect(structsEqual(reflect.TypeOf(a{}), reflect.TypeOf(b{}))).To(BeTrue())
+	})
+
+	It("detects different field names", func() {
+		type a struct{ foo uint16 }
+		type b struct{ bar uint16 }
+		Expect(structsEqual(reflect.TypeOf(a{}), reflect.TypeOf(b{}))).To(BeFalse())
+	})
+
+	It("detects different field types", func() {
+		type a struct{ foo uint16 }
+		type b struct{ foo int16 }
+		Expect(structsEqual(reflect.TypeOf(a{}), reflect.TypeOf(b{}))).To(BeFalse())
+	})
+
+	It("detects different field orders", func() {
+		type a struct {
+			foo uint16
+			bar uint32
+		}
+		type b struct {
+			bar uint32
+			foo uint16
+		}
+		Expect(structsEqual(reflect.TypeOf(a{}), reflect.TypeOf(b{}))).To(BeFalse())
+	})
+
+	It("detects additional fields", func() {
+		type a struct{ foo uint16 }
+		type b struct {
+			foo uint16
+			bar uint16
+		}
+		Expect(structsEqual(reflect.TypeOf(a{}), reflect.TypeOf(b{}))).To(BeFalse())
+	})
+})
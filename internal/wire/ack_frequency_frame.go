@@ -0,0 +1,76 @@
+package wire
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const ackFrequencyFrameType = 0xaf
+
+// An AckFrequencyFrame is an ACK_FREQUENCY frame,
+// as defined in https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/.
+type AckFrequencyFrame struct {
+	SequenceNumber uint64
+	// AckElicitingThreshold is the maximum number of ack-eliciting packets
+	// that the receiver may receive without sending an ACK.
+	AckElicitingThreshold uint64
+	RequestMaxAckDelay    time.Duration
+	// ReorderingThreshold is the number of out-of-order packets
+	// that the receiver tolerates before sending an ACK immediately.
+	// A value of 0 means that out-of-order packets don't trigger an ACK.
+	ReorderingThreshold uint64
+}
+
+func parseAckFrequencyFrame(r *bytes.Reader, _ protocol.VersionNumber) (*AckFrequencyFrame, error) {
+	if _, err := quicvarint.Read(r); err != nil {
+		return nil, err
+	}
+
+	frame := &AckFrequencyFrame{}
+	seq, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	frame.SequenceNumber = seq
+	threshold, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	frame.AckElicitingThreshold = threshold
+	delay, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	// prevent overflows when converting to a time.Duration
+	if delay > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+		delay = uint64(protocol.MaxMaxAckDelay / time.Microsecond)
+	}
+	frame.RequestMaxAckDelay = time.Duration(delay) * time.Microsecond
+	reorderingThreshold, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	frame.ReorderingThreshold = reorderingThreshold
+	return frame, nil
+}
+
+func (f *AckFrequencyFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	quicvarint.Write(b, ackFrequencyFrameType)
+	quicvarint.Write(b, f.SequenceNumber)
+	quicvarint.Write(b, f.AckElicitingThreshold)
+	quicvarint.Write(b, uint64(f.RequestMaxAckDelay/time.Microsecond))
+	quicvarint.Write(b, f.ReorderingThreshold)
+	return nil
+}
+
+// Length of a written frame
+func (f *AckFrequencyFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return quicvarint.Len(ackFrequencyFrameType) +
+		quicvarint.Len(f.SequenceNumber) +
+		quicvarint.Len(f.AckElicitingThreshold) +
+		quicvarint.Len(uint64(f.RequestMaxAckDelay/time.Microsecond)) +
+		quicvarint.Len(f.ReorderingThreshold)
+}
@@ -0,0 +1,76 @@
+package wire
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ACK_FREQUENCY frame", func() {
+	Context("when parsing", func() {
+		It("accepts sample frame", func() {
+			data := encodeVarInt(0xaf)
+			data = append(data, encodeVarInt(0xdeadbeef)...) // sequence number
+			data = append(data, encodeVarInt(10)...)         // ack-eliciting threshold
+			data = append(data, encodeVarInt(25000)...)      // request max ack delay, in microseconds
+			data = append(data, encodeVarInt(1)...)          // reordering threshold
+			b := bytes.NewReader(data)
+			frame, err := parseAckFrequencyFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.SequenceNumber).To(Equal(uint64(0xdeadbeef)))
+			Expect(frame.AckElicitingThreshold).To(Equal(uint64(10)))
+			Expect(frame.RequestMaxAckDelay).To(Equal(25 * time.Millisecond))
+			Expect(frame.ReorderingThreshold).To(Equal(uint64(1)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("limits the request max ack delay", func() {
+			data := encodeVarInt(0xaf)
+			data = append(data, encodeVarInt(1)...)
+			data = append(data, encodeVarInt(1)...)
+			data = append(data, encodeVarInt(1<<61)...)
+			data = append(data, encodeVarInt(1)...)
+			frame, err := parseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.RequestMaxAckDelay).To(Equal(protocol.MaxMaxAckDelay))
+		})
+
+		It("errors on EOFs", func() {
+			data := encodeVarInt(0xaf)
+			data = append(data, encodeVarInt(0xdeadbeef)...)
+			data = append(data, encodeVarInt(10)...)
+			data = append(data, encodeVarInt(25000)...)
+			data = append(data, encodeVarInt(1)...)
+			_, err := parseAckFrequencyFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseAckFrequencyFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("writing", func() {
+		It("writes a sample frame", func() {
+			b := &bytes.Buffer{}
+			frame := &AckFrequencyFrame{
+				SequenceNumber:        0x1337,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    25 * time.Millisecond,
+				ReorderingThreshold:   0,
+			}
+			Expect(frame.Write(b, versionIETFFrames)).To(Succeed())
+			expected := encodeVarInt(0xaf)
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(10)...)
+			expected = append(expected, encodeVarInt(25000)...)
+			expected = append(expected, encodeVarInt(0)...)
+			Expect(b.Bytes()).To(Equal(expected))
+			Expect(frame.Length(versionIETFFrames)).To(BeEquivalentTo(b.Len()))
+		})
+	})
+})
@@ -4,25 +4,31 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/quicvarint"
 )
 
 type frameParser struct {
 	ackDelayExponent uint8
 
-	supportsDatagrams bool
+	supportsDatagrams     bool
+	supportsAckFrequency  bool
+	supportsResetStreamAt bool
 
 	version protocol.VersionNumber
 }
 
 // NewFrameParser creates a new frame parser.
-func NewFrameParser(supportsDatagrams bool, v protocol.VersionNumber) FrameParser {
+func NewFrameParser(supportsDatagrams, supportsAckFrequency, supportsResetStreamAt bool, v protocol.VersionNumber) FrameParser {
 	return &frameParser{
-		supportsDatagrams: supportsDatagrams,
-		version:           v,
+		supportsDatagrams:     supportsDatagrams,
+		supportsAckFrequency:  supportsAckFrequency,
+		supportsResetStreamAt: supportsResetStreamAt,
+		version:               v,
 	}
 }
 
@@ -36,22 +42,35 @@ func (p *frameParser) ParseNext(r *bytes.Reader, encLevel protocol.EncryptionLev
 		}
 		r.UnreadByte()
 
-		f, err := p.parseFrame(r, typeByte, encLevel)
+		// Frame types are encoded as varints.
+		// The frame parsing functions read the frame type themselves, so we just peek at it here.
+		startLen := r.Len()
+		frameType, err := quicvarint.Read(r)
 		if err != nil {
-			return nil, qerr.NewErrorWithFrameType(qerr.FrameEncodingError, uint64(typeByte), err.Error())
+			return nil, qerr.NewError(qerr.FrameEncodingError, err.Error())
+		}
+		typeLen := startLen - r.Len()
+		r.Seek(-int64(typeLen), io.SeekCurrent)
+		if typeLen != int(quicvarint.Len(frameType)) {
+			return nil, qerr.NewErrorWithFrameType(qerr.FrameEncodingError, frameType, "frame type not minimally encoded")
+		}
+
+		f, err := p.parseFrame(r, frameType, encLevel)
+		if err != nil {
+			return nil, qerr.NewErrorWithFrameType(qerr.FrameEncodingError, frameType, err.Error())
 		}
 		return f, nil
 	}
 	return nil, nil
 }
 
-func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protocol.EncryptionLevel) (Frame, error) {
+func (p *frameParser) parseFrame(r *bytes.Reader, frameType uint64, encLevel protocol.EncryptionLevel) (Frame, error) {
 	var frame Frame
 	var err error
-	if typeByte&0xf8 == 0x8 {
+	if frameType&0xf8 == 0x8 {
 		frame, err = parseStreamFrame(r, p.version)
 	} else {
-		switch typeByte {
+		switch frameType {
 		case 0x1:
 			frame, err = parsePingFrame(r, p.version)
 		case 0x2, 0x3:
@@ -92,11 +111,29 @@ func (p *frameParser) parseFrame(r *bytes.Reader, typeByte byte, encLevel protoc
 			frame, err = parseConnectionCloseFrame(r, p.version)
 		case 0x1e:
 			frame, err = parseHandshakeDoneFrame(r, p.version)
+		case 0x1f:
+			if p.supportsAckFrequency {
+				frame, err = parseImmediateAckFrame(r, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
+		case resetStreamAtFrameType:
+			if p.supportsResetStreamAt {
+				frame, err = parseResetStreamAtFrame(r, p.version)
+				break
+			}
+			err = errors.New("unknown frame type")
 		case 0x30, 0x31:
 			if p.supportsDatagrams {
 				frame, err = parseDatagramFrame(r, p.version)
 				break
 			}
+			err = errors.New("unknown frame type")
+		case ackFrequencyFrameType:
+			if p.supportsAckFrequency {
+				frame, err = parseAckFrequencyFrame(r, p.version)
+				break
+			}
 			fallthrough
 		default:
 			err = errors.New("unknown frame type")
@@ -137,3 +174,7 @@ func (p *frameParser) isAllowedAtEncLevel(f Frame, encLevel protocol.EncryptionL
 func (p *frameParser) SetAckDelayExponent(exp uint8) {
 	p.ackDelayExponent = exp
 }
+
+func (p *frameParser) SetVersion(v protocol.VersionNumber) {
+	p.version = v
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/lucas-clemente/quic-go/internal/protocol"
 	"github.com/lucas-clemente/quic-go/internal/qerr"
+	"github.com/lucas-clemente/quic-go/quicvarint"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -18,7 +19,7 @@ var _ = Describe("Frame parsing", func() {
 
 	BeforeEach(func() {
 		buf = &bytes.Buffer{}
-		parser = NewFrameParser(true, versionIETFFrames)
+		parser = NewFrameParser(true, true, true, versionIETFFrames)
 	})
 
 	It("returns nil if there's nothing more to read", func() {
@@ -290,7 +291,7 @@ var _ = Describe("Frame parsing", func() {
 	})
 
 	It("errors when DATAGRAM frames are not supported", func() {
-		parser = NewFrameParser(false, versionIETFFrames)
+		parser = NewFrameParser(false, false, false, versionIETFFrames)
 		f := &DatagramFrame{Data: []byte("foobar")}
 		buf := &bytes.Buffer{}
 		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
@@ -298,9 +299,84 @@ var _ = Describe("Frame parsing", func() {
 		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x30): unknown frame type"))
 	})
 
+	It("unpacks RESET_STREAM_AT frames", func() {
+		f := &ResetStreamAtFrame{
+			StreamID:     0x1337,
+			ErrorCode:    0x42,
+			FinalSize:    0xdecafbad,
+			ReliableSize: 0x1234,
+		}
+		buf := &bytes.Buffer{}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+
+	It("errors when RESET_STREAM_AT frames are not supported", func() {
+		parser = NewFrameParser(false, false, false, versionIETFFrames)
+		buf := &bytes.Buffer{}
+		Expect((&ResetStreamAtFrame{}).Write(buf, versionIETFFrames)).To(Succeed())
+		_, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x24): unknown frame type"))
+	})
+
+	It("unpacks ACK_FREQUENCY frames", func() {
+		f := &AckFrequencyFrame{
+			SequenceNumber:        1337,
+			AckElicitingThreshold: 10,
+			RequestMaxAckDelay:    25 * time.Millisecond,
+			ReorderingThreshold:   1,
+		}
+		buf := &bytes.Buffer{}
+		Expect(f.Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(f))
+	})
+
+	It("unpacks IMMEDIATE_ACK frames", func() {
+		buf := &bytes.Buffer{}
+		Expect((&ImmediateAckFrame{}).Write(buf, versionIETFFrames)).To(Succeed())
+		frame, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(frame).To(Equal(&ImmediateAckFrame{}))
+	})
+
+	It("errors when the ACK frequency extension is not supported", func() {
+		parser = NewFrameParser(false, false, false, versionIETFFrames)
+		buf := &bytes.Buffer{}
+		Expect((&AckFrequencyFrame{}).Write(buf, versionIETFFrames)).To(Succeed())
+		_, err := parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0xaf): unknown frame type"))
+		buf.Reset()
+		Expect((&ImmediateAckFrame{}).Write(buf, versionIETFFrames)).To(Succeed())
+		_, err = parser.ParseNext(bytes.NewReader(buf.Bytes()), protocol.Encryption1RTT)
+		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x1f): unknown frame type"))
+	})
+
 	It("errors on invalid type", func() {
-		_, err := parser.ParseNext(bytes.NewReader([]byte{0x42}), protocol.Encryption1RTT)
-		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x42): unknown frame type"))
+		_, err := parser.ParseNext(bytes.NewReader([]byte{0x2f}), protocol.Encryption1RTT)
+		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x2f): unknown frame type"))
+	})
+
+	It("errors on invalid multi-byte frame types", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, 0x1337)
+		_, err := parser.ParseNext(bytes.NewReader(b.Bytes()), protocol.Encryption1RTT)
+		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x1337): unknown frame type"))
+	})
+
+	It("errors on frame types that are not minimally encoded", func() {
+		// a PING frame, encoded using 2 bytes
+		_, err := parser.ParseNext(bytes.NewReader([]byte{0x40, 0x01}), protocol.Encryption1RTT)
+		Expect(err).To(MatchError("FRAME_ENCODING_ERROR (frame type: 0x1): frame type not minimally encoded"))
+	})
+
+	It("errors on truncated frame types", func() {
+		_, err := parser.ParseNext(bytes.NewReader([]byte{0x40}), protocol.Encryption1RTT)
+		Expect(err).To(HaveOccurred())
+		Expect(err.(*qerr.QuicError).ErrorCode).To(Equal(qerr.FrameEncodingError))
 	})
 
 	It("errors on invalid frames", func() {
@@ -337,6 +413,9 @@ var _ = Describe("Frame parsing", func() {
 			&ConnectionCloseFrame{},
 			&HandshakeDoneFrame{},
 			&DatagramFrame{},
+			&AckFrequencyFrame{},
+			&ImmediateAckFrame{},
+			&ResetStreamAtFrame{},
 		}
 
 		var framesSerialized [][]byte
@@ -0,0 +1,28 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// An ImmediateAckFrame is an IMMEDIATE_ACK frame,
+// as defined in https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/.
+type ImmediateAckFrame struct{}
+
+func parseImmediateAckFrame(r *bytes.Reader, _ protocol.VersionNumber) (*ImmediateAckFrame, error) {
+	if _, err := r.ReadByte(); err != nil {
+		return nil, err
+	}
+	return &ImmediateAckFrame{}, nil
+}
+
+func (f *ImmediateAckFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	b.WriteByte(0x1f)
+	return nil
+}
+
+// Length of a written frame
+func (f *ImmediateAckFrame) Length(_ protocol.VersionNumber) protocol.ByteCount {
+	return 1
+}
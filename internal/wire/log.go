@@ -25,6 +25,8 @@ func LogFrame(logger utils.Logger, frame Frame, sent bool) {
 		logger.Debugf("\t%s &wire.StreamFrame{StreamID: %d, Fin: %t, Offset: %d, Data length: %d, Offset + Data length: %d}", dir, f.StreamID, f.Fin, f.Offset, f.DataLen(), f.Offset+f.DataLen())
 	case *ResetStreamFrame:
 		logger.Debugf("\t%s &wire.ResetStreamFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize)
+	case *ResetStreamAtFrame:
+		logger.Debugf("\t%s &wire.ResetStreamAtFrame{StreamID: %d, ErrorCode: %#x, FinalSize: %d, ReliableSize: %d}", dir, f.StreamID, f.ErrorCode, f.FinalSize, f.ReliableSize)
 	case *AckFrame:
 		hasECN := f.ECT0 > 0 || f.ECT1 > 0 || f.ECNCE > 0
 		var ecn string
@@ -46,6 +46,11 @@ var _ = Describe("Frame logging", func() {
 		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamFrame{StreamID: 0, ErrorCode: 0x0, FinalSize: 0}\n"))
 	})
 
+	It("logs RESET_STREAM_AT frames", func() {
+		LogFrame(logger, &ResetStreamAtFrame{StreamID: 4, ErrorCode: 0x42, FinalSize: 1337, ReliableSize: 100}, false)
+		Expect(buf.String()).To(ContainSubstring("\t<- &wire.ResetStreamAtFrame{StreamID: 4, ErrorCode: 0x42, FinalSize: 1337, ReliableSize: 100}\n"))
+	})
+
 	It("logs CRYPTO frames", func() {
 		frame := &CryptoFrame{
 			Offset: 42,
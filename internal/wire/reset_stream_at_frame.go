@@ -0,0 +1,69 @@
+package wire
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+)
+
+const resetStreamAtFrameType = 0x24
+
+// A ResetStreamAtFrame is a RESET_STREAM_AT frame,
+// as defined in https://datatracker.ietf.org/doc/draft-ietf-quic-reliable-stream-reset/.
+type ResetStreamAtFrame struct {
+	StreamID  protocol.StreamID
+	ErrorCode protocol.ApplicationErrorCode
+	FinalSize protocol.ByteCount
+	// ReliableSize is the amount of data that the sender delivers reliably,
+	// despite resetting the stream.
+	ReliableSize protocol.ByteCount
+}
+
+func parseResetStreamAtFrame(r *bytes.Reader, _ protocol.VersionNumber) (*ResetStreamAtFrame, error) {
+	if _, err := r.ReadByte(); err != nil { // read the TypeByte
+		return nil, err
+	}
+
+	sid, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	errorCode, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	finalSize, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	reliableSize, err := quicvarint.Read(r)
+	if err != nil {
+		return nil, err
+	}
+	if reliableSize > finalSize {
+		return nil, fmt.Errorf("reliable size (%d) larger than final size (%d)", reliableSize, finalSize)
+	}
+
+	return &ResetStreamAtFrame{
+		StreamID:     protocol.StreamID(sid),
+		ErrorCode:    protocol.ApplicationErrorCode(errorCode),
+		FinalSize:    protocol.ByteCount(finalSize),
+		ReliableSize: protocol.ByteCount(reliableSize),
+	}, nil
+}
+
+func (f *ResetStreamAtFrame) Write(b *bytes.Buffer, _ protocol.VersionNumber) error {
+	b.WriteByte(resetStreamAtFrameType)
+	quicvarint.Write(b, uint64(f.StreamID))
+	quicvarint.Write(b, uint64(f.ErrorCode))
+	quicvarint.Write(b, uint64(f.FinalSize))
+	quicvarint.Write(b, uint64(f.ReliableSize))
+	return nil
+}
+
+// Length of a written frame
+func (f *ResetStreamAtFrame) Length(version protocol.VersionNumber) protocol.ByteCount {
+	return 1 + quicvarint.Len(uint64(f.StreamID)) + quicvarint.Len(uint64(f.ErrorCode)) + quicvarint.Len(uint64(f.FinalSize)) + quicvarint.Len(uint64(f.ReliableSize))
+}
@@ -0,0 +1,85 @@
+package wire
+
+import (
+	"bytes"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/quicvarint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RESET_STREAM_AT frame", func() {
+	Context("when parsing", func() {
+		It("accepts sample frame", func() {
+			data := []byte{0x24}
+			data = append(data, encodeVarInt(0xdeadbeef)...)  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // final size
+			data = append(data, encodeVarInt(0x42)...)        // reliable size
+			b := bytes.NewReader(data)
+			frame, err := parseResetStreamAtFrame(b, versionIETFFrames)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(frame.StreamID).To(Equal(protocol.StreamID(0xdeadbeef)))
+			Expect(frame.ErrorCode).To(Equal(protocol.ApplicationErrorCode(0x1337)))
+			Expect(frame.FinalSize).To(Equal(protocol.ByteCount(0x987654321)))
+			Expect(frame.ReliableSize).To(Equal(protocol.ByteCount(0x42)))
+			Expect(b.Len()).To(BeZero())
+		})
+
+		It("errors when the reliable size is larger than the final size", func() {
+			data := []byte{0x24}
+			data = append(data, encodeVarInt(0xdeadbeef)...) // stream ID
+			data = append(data, encodeVarInt(0x1337)...)     // error code
+			data = append(data, encodeVarInt(100)...)        // final size
+			data = append(data, encodeVarInt(101)...)        // reliable size
+			_, err := parseResetStreamAtFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).To(MatchError("reliable size (101) larger than final size (100)"))
+		})
+
+		It("errors on EOFs", func() {
+			data := []byte{0x24}
+			data = append(data, encodeVarInt(0xdeadbeef)...)  // stream ID
+			data = append(data, encodeVarInt(0x1337)...)      // error code
+			data = append(data, encodeVarInt(0x987654321)...) // final size
+			data = append(data, encodeVarInt(0x42)...)        // reliable size
+			_, err := parseResetStreamAtFrame(bytes.NewReader(data), versionIETFFrames)
+			Expect(err).NotTo(HaveOccurred())
+			for i := range data {
+				_, err := parseResetStreamAtFrame(bytes.NewReader(data[0:i]), versionIETFFrames)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+
+	Context("when writing", func() {
+		It("writes a sample frame", func() {
+			frame := ResetStreamAtFrame{
+				StreamID:     0x1337,
+				ErrorCode:    0xcafe,
+				FinalSize:    0x11223344decafbad,
+				ReliableSize: 0xdecafbad,
+			}
+			b := &bytes.Buffer{}
+			Expect(frame.Write(b, versionIETFFrames)).To(Succeed())
+			expected := []byte{0x24}
+			expected = append(expected, encodeVarInt(0x1337)...)
+			expected = append(expected, encodeVarInt(0xcafe)...)
+			expected = append(expected, encodeVarInt(0x11223344decafbad)...)
+			expected = append(expected, encodeVarInt(0xdecafbad)...)
+			Expect(b.Bytes()).To(Equal(expected))
+		})
+
+		It("has the correct length", func() {
+			frame := ResetStreamAtFrame{
+				StreamID:     0x1337,
+				ErrorCode:    0xde,
+				FinalSize:    0x1234567,
+				ReliableSize: 0x1234,
+			}
+			expectedLen := 1 + quicvarint.Len(0x1337) + 2 + quicvarint.Len(0x1234567) + quicvarint.Len(0x1234)
+			Expect(frame.Length(versionIETFFrames)).To(Equal(expectedLen))
+		})
+	})
+})
@@ -40,6 +40,7 @@ var _ = Describe("Transport Parameters", func() {
 	}
 
 	It("has a string representation", func() {
+		minAckDelay := 5 * time.Millisecond
 		p := &TransportParameters{
 			InitialMaxStreamDataBidiLocal:   1234,
 			InitialMaxStreamDataBidiRemote:  2345,
@@ -56,8 +57,14 @@ var _ = Describe("Transport Parameters", func() {
 			StatelessResetToken:             &protocol.StatelessResetToken{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00},
 			ActiveConnectionIDLimit:         123,
 			MaxDatagramFrameSize:            876,
+			MinAckDelay:                     &minAckDelay,
+			EnableResetStreamAt:             true,
+			VersionInformation: &VersionInformation{
+				ChosenVersion:     protocol.VersionDraft34,
+				AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34},
+			},
 		}
-		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876}"))
+		Expect(p.String()).To(Equal("&wire.TransportParameters{OriginalDestinationConnectionID: deadbeef, InitialSourceConnectionID: decafbad, RetrySourceConnectionID: deadc0de, InitialMaxStreamDataBidiLocal: 1234, InitialMaxStreamDataBidiRemote: 2345, InitialMaxStreamDataUni: 3456, InitialMaxData: 4567, MaxBidiStreamNum: 1337, MaxUniStreamNum: 7331, MaxIdleTimeout: 42s, AckDelayExponent: 14, MaxAckDelay: 37ms, ActiveConnectionIDLimit: 123, StatelessResetToken: 0x112233445566778899aabbccddeeff00, MaxDatagramFrameSize: 876, MinAckDelay: 5ms, EnableResetStreamAt: true, VersionInformation: {ChosenVersion: draft-34, AvailableVersions: [v1 draft-34]}}"))
 	})
 
 	It("has a string representation, if there's no stateless reset token, no Retry source connection id and no datagram support", func() {
@@ -91,6 +98,7 @@ var _ = Describe("Transport Parameters", func() {
 			MaxBidiStreamNum:                protocol.StreamNum(getRandomValueUpTo(int64(protocol.MaxStreamCount))),
 			MaxUniStreamNum:                 protocol.StreamNum(getRandomValueUpTo(int64(protocol.MaxStreamCount))),
 			DisableActiveMigration:          true,
+			EnableResetStreamAt:             true,
 			StatelessResetToken:             &token,
 			OriginalDestinationConnectionID: protocol.ConnectionID{0xde, 0xad, 0xbe, 0xef},
 			InitialSourceConnectionID:       protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad},
@@ -112,6 +120,7 @@ var _ = Describe("Transport Parameters", func() {
 		Expect(p.MaxBidiStreamNum).To(Equal(params.MaxBidiStreamNum))
 		Expect(p.MaxIdleTimeout).To(Equal(params.MaxIdleTimeout))
 		Expect(p.DisableActiveMigration).To(Equal(params.DisableActiveMigration))
+		Expect(p.EnableResetStreamAt).To(BeTrue())
 		Expect(p.StatelessResetToken).To(Equal(params.StatelessResetToken))
 		Expect(p.OriginalDestinationConnectionID).To(Equal(protocol.ConnectionID{0xde, 0xad, 0xbe, 0xef}))
 		Expect(p.InitialSourceConnectionID).To(Equal(protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}))
@@ -166,6 +175,14 @@ var _ = Describe("Transport Parameters", func() {
 		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError("TRANSPORT_PARAMETER_ERROR: wrong length for disable_active_migration: 6 (expected empty)"))
 	})
 
+	It("errors when reset_stream_at has content", func() {
+		b := &bytes.Buffer{}
+		quicvarint.Write(b, uint64(resetStreamAtParameterID))
+		quicvarint.Write(b, 6)
+		b.Write([]byte("foobar"))
+		Expect((&TransportParameters{}).Unmarshal(b.Bytes(), protocol.PerspectiveServer)).To(MatchError("TRANSPORT_PARAMETER_ERROR: wrong length for reset_stream_at: 6 (expected empty)"))
+	})
+
 	It("errors when the server doesn't set the original_destination_connection_id", func() {
 		b := &bytes.Buffer{}
 		quicvarint.Write(b, uint64(statelessResetTokenParameterID))
@@ -179,6 +196,37 @@ var _ = Describe("Transport Parameters", func() {
 		Expect((&TransportParameters{}).Unmarshal([]byte{}, protocol.PerspectiveClient)).To(MatchError("TRANSPORT_PARAMETER_ERROR: missing initial_source_connection_id"))
 	})
 
+	Context("min_ack_delay", func() {
+		It("marshals and unmarshals", func() {
+			minAckDelay := 1337 * time.Microsecond
+			data := (&TransportParameters{
+				MaxAckDelay: protocol.DefaultMaxAckDelay,
+				MinAckDelay: &minAckDelay,
+			}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.MinAckDelay).ToNot(BeNil())
+			Expect(*p.MinAckDelay).To(Equal(minAckDelay))
+		})
+
+		It("doesn't marshal the min_ack_delay if not set", func() {
+			data := (&TransportParameters{MaxAckDelay: protocol.DefaultMaxAckDelay}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.MinAckDelay).To(BeNil())
+		})
+
+		It("errors if the min_ack_delay is larger than the max_ack_delay", func() {
+			minAckDelay := protocol.DefaultMaxAckDelay + time.Microsecond
+			data := (&TransportParameters{
+				MaxAckDelay: protocol.DefaultMaxAckDelay,
+				MinAckDelay: &minAckDelay,
+			}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(MatchError("TRANSPORT_PARAMETER_ERROR: min_ack_delay (25.001ms) larger than max_ack_delay (25ms)"))
+		})
+	})
+
 	It("errors when the max_ack_delay is too large", func() {
 		data := (&TransportParameters{
 			MaxAckDelay:         1 << 14 * time.Millisecond,
@@ -440,6 +488,72 @@ var _ = Describe("Transport Parameters", func() {
 		})
 	})
 
+	Context("version information", func() {
+		It("marshals and unmarshals", func() {
+			for _, pers := range []protocol.Perspective{protocol.PerspectiveClient, protocol.PerspectiveServer} {
+				vi := &VersionInformation{
+					ChosenVersion:     protocol.Version1,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft29},
+				}
+				data := (&TransportParameters{
+					VersionInformation:  vi,
+					StatelessResetToken: &protocol.StatelessResetToken{},
+				}).Marshal(pers)
+				p := &TransportParameters{}
+				Expect(p.Unmarshal(data, pers)).To(Succeed())
+				Expect(p.VersionInformation).To(Equal(vi))
+			}
+		})
+
+		It("doesn't marshal the version_information if not set", func() {
+			data := (&TransportParameters{}).Marshal(protocol.PerspectiveClient)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(data, protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.VersionInformation).To(BeNil())
+		})
+
+		It("accepts an empty list of available versions", func() {
+			b := &bytes.Buffer{}
+			quicvarint.Write(b, uint64(versionInformationParameterID))
+			quicvarint.Write(b, 4)
+			b.Write([]byte{0, 0, 0, 1})
+			addInitialSourceConnectionID(b)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(b.Bytes(), protocol.PerspectiveClient)).To(Succeed())
+			Expect(p.VersionInformation.ChosenVersion).To(Equal(protocol.Version1))
+			Expect(p.VersionInformation.AvailableVersions).To(BeEmpty())
+		})
+
+		It("errors if the length is not a multiple of 4", func() {
+			b := &bytes.Buffer{}
+			quicvarint.Write(b, uint64(versionInformationParameterID))
+			quicvarint.Write(b, 6)
+			b.Write([]byte{0, 0, 0, 1, 0, 0})
+			addInitialSourceConnectionID(b)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(b.Bytes(), protocol.PerspectiveClient)).To(MatchError("TRANSPORT_PARAMETER_ERROR: invalid length for version_information: 6"))
+		})
+
+		It("errors if it's empty", func() {
+			b := &bytes.Buffer{}
+			quicvarint.Write(b, uint64(versionInformationParameterID))
+			quicvarint.Write(b, 0)
+			addInitialSourceConnectionID(b)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(b.Bytes(), protocol.PerspectiveClient)).To(MatchError("TRANSPORT_PARAMETER_ERROR: invalid length for version_information: 0"))
+		})
+
+		It("errors if it contains version 0", func() {
+			b := &bytes.Buffer{}
+			quicvarint.Write(b, uint64(versionInformationParameterID))
+			quicvarint.Write(b, 8)
+			b.Write([]byte{0, 0, 0, 1, 0, 0, 0, 0})
+			addInitialSourceConnectionID(b)
+			p := &TransportParameters{}
+			Expect(p.Unmarshal(b.Bytes(), protocol.PerspectiveClient)).To(MatchError("TRANSPORT_PARAMETER_ERROR: version_information contains version 0"))
+		})
+	})
+
 	Context("saving and retrieving from a session ticket", func() {
 		It("saves and retrieves the parameters", func() {
 			params := &TransportParameters{
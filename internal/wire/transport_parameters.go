@@ -42,8 +42,14 @@ const (
 	activeConnectionIDLimitParameterID         transportParameterID = 0xe
 	initialSourceConnectionIDParameterID       transportParameterID = 0xf
 	retrySourceConnectionIDParameterID         transportParameterID = 0x10
+	// RFC 9368: Compatible Version Negotiation for QUIC
+	versionInformationParameterID transportParameterID = 0x11
 	// https://datatracker.ietf.org/doc/draft-ietf-quic-datagram/
 	maxDatagramFrameSizeParameterID transportParameterID = 0x20
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-ack-frequency/
+	minAckDelayParameterID transportParameterID = 0xff04de1b
+	// https://datatracker.ietf.org/doc/draft-ietf-quic-reliable-stream-reset/
+	resetStreamAtParameterID transportParameterID = 0x17f7586d2cb571
 )
 
 // PreferredAddress is the value encoding in the preferred_address transport parameter
@@ -56,6 +62,14 @@ type PreferredAddress struct {
 	StatelessResetToken protocol.StatelessResetToken
 }
 
+// VersionInformation is the value encoded in the version_information transport parameter
+type VersionInformation struct {
+	// ChosenVersion is the version that the endpoint is using for the connection
+	ChosenVersion protocol.VersionNumber
+	// AvailableVersions are the versions that the endpoint supports, in order of preference
+	AvailableVersions []protocol.VersionNumber
+}
+
 // TransportParameters are parameters sent to the peer during the handshake
 type TransportParameters struct {
 	InitialMaxStreamDataBidiLocal  protocol.ByteCount
@@ -65,9 +79,15 @@ type TransportParameters struct {
 
 	MaxAckDelay      time.Duration
 	AckDelayExponent uint8
+	// MinAckDelay is the min_ack_delay transport parameter.
+	// It is nil if the peer doesn't support the ACK frequency extension.
+	MinAckDelay *time.Duration
 
 	DisableActiveMigration bool
 
+	// EnableResetStreamAt is set if the endpoint supports receiving RESET_STREAM_AT frames.
+	EnableResetStreamAt bool
+
 	MaxUDPPayloadSize protocol.ByteCount
 
 	MaxUniStreamNum  protocol.StreamNum
@@ -85,6 +105,8 @@ type TransportParameters struct {
 	ActiveConnectionIDLimit uint64
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	VersionInformation *VersionInformation
 }
 
 // Unmarshal the transport parameters
@@ -134,6 +156,7 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			maxAckDelayParameterID,
 			activeConnectionIDLimitParameterID,
 			maxDatagramFrameSizeParameterID,
+			minAckDelayParameterID,
 			ackDelayExponentParameterID:
 			if err := p.readNumericTransportParameter(r, paramID, int(paramLen)); err != nil {
 				return err
@@ -150,6 +173,11 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 				return fmt.Errorf("wrong length for disable_active_migration: %d (expected empty)", paramLen)
 			}
 			p.DisableActiveMigration = true
+		case resetStreamAtParameterID:
+			if paramLen != 0 {
+				return fmt.Errorf("wrong length for reset_stream_at: %d (expected empty)", paramLen)
+			}
+			p.EnableResetStreamAt = true
 		case statelessResetTokenParameterID:
 			if sentBy == protocol.PerspectiveClient {
 				return errors.New("client sent a stateless_reset_token")
@@ -175,11 +203,19 @@ func (p *TransportParameters) unmarshal(r *bytes.Reader, sentBy protocol.Perspec
 			}
 			connID, _ := protocol.ReadConnectionID(r, int(paramLen))
 			p.RetrySourceConnectionID = &connID
+		case versionInformationParameterID:
+			if err := p.readVersionInformation(r, int(paramLen)); err != nil {
+				return err
+			}
 		default:
 			r.Seek(int64(paramLen), io.SeekCurrent)
 		}
 	}
 
+	if p.MinAckDelay != nil && *p.MinAckDelay > p.MaxAckDelay {
+		return fmt.Errorf("min_ack_delay (%s) larger than max_ack_delay (%s)", *p.MinAckDelay, p.MaxAckDelay)
+	}
+
 	if !fromSessionTicket {
 		if sentBy == protocol.PerspectiveServer && !readOriginalDestinationConnectionID {
 			return errors.New("missing original_destination_connection_id")
@@ -248,6 +284,29 @@ func (p *TransportParameters) readPreferredAddress(r *bytes.Reader, expectedLen
 	return nil
 }
 
+func (p *TransportParameters) readVersionInformation(r *bytes.Reader, length int) error {
+	if length < 4 || length%4 != 0 {
+		return fmt.Errorf("invalid length for version_information: %d", length)
+	}
+	vi := &VersionInformation{}
+	for i := 0; i < length/4; i++ {
+		v, err := utils.BigEndian.ReadUint32(r)
+		if err != nil {
+			return err
+		}
+		if v == 0 {
+			return errors.New("version_information contains version 0")
+		}
+		if i == 0 {
+			vi.ChosenVersion = protocol.VersionNumber(v)
+			continue
+		}
+		vi.AvailableVersions = append(vi.AvailableVersions, protocol.VersionNumber(v))
+	}
+	p.VersionInformation = vi
+	return nil
+}
+
 func (p *TransportParameters) readNumericTransportParameter(
 	r *bytes.Reader,
 	paramID transportParameterID,
@@ -302,6 +361,12 @@ func (p *TransportParameters) readNumericTransportParameter(
 		p.ActiveConnectionIDLimit = val
 	case maxDatagramFrameSizeParameterID:
 		p.MaxDatagramFrameSize = protocol.ByteCount(val)
+	case minAckDelayParameterID:
+		if val > uint64(protocol.MaxMaxAckDelay/time.Microsecond) {
+			return fmt.Errorf("invalid value for min_ack_delay: %dus (maximum %dus)", val, protocol.MaxMaxAckDelay/time.Microsecond)
+		}
+		minAckDelay := time.Duration(val) * time.Microsecond
+		p.MinAckDelay = &minAckDelay
 	default:
 		return fmt.Errorf("TransportParameter BUG: transport parameter %d not found", paramID)
 	}
@@ -391,6 +456,24 @@ func (p *TransportParameters) Marshal(pers protocol.Perspective) []byte {
 	if p.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		p.marshalVarintParam(b, maxDatagramFrameSizeParameterID, uint64(p.MaxDatagramFrameSize))
 	}
+	// min_ack_delay
+	if p.MinAckDelay != nil {
+		p.marshalVarintParam(b, minAckDelayParameterID, uint64(*p.MinAckDelay/time.Microsecond))
+	}
+	// reset_stream_at
+	if p.EnableResetStreamAt {
+		quicvarint.Write(b, uint64(resetStreamAtParameterID))
+		quicvarint.Write(b, 0)
+	}
+	// version_information
+	if p.VersionInformation != nil {
+		quicvarint.Write(b, uint64(versionInformationParameterID))
+		quicvarint.Write(b, uint64(4*(1+len(p.VersionInformation.AvailableVersions))))
+		utils.BigEndian.WriteUint32(b, uint32(p.VersionInformation.ChosenVersion))
+		for _, v := range p.VersionInformation.AvailableVersions {
+			utils.BigEndian.WriteUint32(b, uint32(v))
+		}
+	}
 	return b.Bytes()
 }
 
@@ -468,6 +551,17 @@ func (p *TransportParameters) String() string {
 		logString += ", MaxDatagramFrameSize: %d"
 		logParams = append(logParams, p.MaxDatagramFrameSize)
 	}
+	if p.MinAckDelay != nil {
+		logString += ", MinAckDelay: %s"
+		logParams = append(logParams, *p.MinAckDelay)
+	}
+	if p.EnableResetStreamAt {
+		logString += ", EnableResetStreamAt: true"
+	}
+	if p.VersionInformation != nil {
+		logString += ", VersionInformation: {ChosenVersion: %s, AvailableVersions: %s}"
+		logParams = append(logParams, p.VersionInformation.ChosenVersion, p.VersionInformation.AvailableVersions)
+	}
 	logString += "}"
 	return fmt.Sprintf(logString, logParams...)
 }
@@ -47,6 +47,8 @@ type (
 	TransportParameters = wire.TransportParameters
 	// The PreferredAddress is the preferred address sent in the transport parameters.
 	PreferredAddress = wire.PreferredAddress
+	// The VersionInformation is the version information sent in the transport parameters.
+	VersionInformation = wire.VersionInformation
 
 	// A TransportError is a transport-level error code.
 	TransportError = qerr.ErrorCode
@@ -99,6 +101,9 @@ type Tracer interface {
 
 	SentPacket(net.Addr, *Header, ByteCount, []Frame)
 	DroppedPacket(net.Addr, PacketType, ByteCount, PacketDropReason)
+	// UpdatedHandshakeMetrics is called by the server when it handles an Initial packet that starts a new handshake,
+	// and when a handshake with an unvalidated client completes.
+	UpdatedHandshakeMetrics(HandshakeMetrics)
 }
 
 // A ConnectionTracer records events.
@@ -110,6 +115,9 @@ type ConnectionTracer interface {
 	RestoredTransportParameters(parameters *TransportParameters) // for 0-RTT
 	SentPacket(hdr *ExtendedHeader, size ByteCount, ack *AckFrame, frames []Frame)
 	ReceivedVersionNegotiationPacket(*Header, []VersionNumber)
+	// NegotiatedVersion is called when compatible version negotiation switches the connection to a different version.
+	// The client doesn't learn the server's versions, so serverVersions is nil for the client.
+	NegotiatedVersion(chosen VersionNumber, clientVersions, serverVersions []VersionNumber)
 	ReceivedRetry(*Header)
 	ReceivedPacket(hdr *ExtendedHeader, size ByteCount, frames []Frame)
 	BufferedPacket(PacketType)
@@ -123,6 +131,10 @@ type ConnectionTracer interface {
 	UpdatedKey(generation KeyPhase, remote bool)
 	DroppedEncryptionLevel(EncryptionLevel)
 	DroppedKey(generation KeyPhase)
+	// ApproachingAEADLimit is called when the number of packets counted towards an AEAD limit reaches 3/4 of that limit.
+	ApproachingAEADLimit(limit AEADLimit, generation KeyPhase, count, max uint64)
+	// ReachedAEADLimit is called when an AEAD limit is reached, and the connection is closed.
+	ReachedAEADLimit(limit AEADLimit, generation KeyPhase, count uint64)
 	SetLossTimer(TimerType, EncryptionLevel, time.Time)
 	LossTimerExpired(TimerType, EncryptionLevel)
 	LossTimerCanceled()
@@ -50,6 +50,18 @@ func (mr *MockConnectionTracerMockRecorder) AcknowledgedPacket(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcknowledgedPacket", reflect.TypeOf((*MockConnectionTracer)(nil).AcknowledgedPacket), arg0, arg1)
 }
 
+// ApproachingAEADLimit mocks base method.
+func (m *MockConnectionTracer) ApproachingAEADLimit(arg0 AEADLimit, arg1 protocol.KeyPhase, arg2, arg3 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ApproachingAEADLimit", arg0, arg1, arg2, arg3)
+}
+
+// ApproachingAEADLimit indicates an expected call of ApproachingAEADLimit.
+func (mr *MockConnectionTracerMockRecorder) ApproachingAEADLimit(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproachingAEADLimit", reflect.TypeOf((*MockConnectionTracer)(nil).ApproachingAEADLimit), arg0, arg1, arg2, arg3)
+}
+
 // BufferedPacket mocks base method.
 func (m *MockConnectionTracer) BufferedPacket(arg0 PacketType) {
 	m.ctrl.T.Helper()
@@ -170,6 +182,30 @@ func (mr *MockConnectionTracerMockRecorder) LostPacket(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LostPacket", reflect.TypeOf((*MockConnectionTracer)(nil).LostPacket), arg0, arg1, arg2)
 }
 
+// NegotiatedVersion mocks base method.
+func (m *MockConnectionTracer) NegotiatedVersion(arg0 protocol.VersionNumber, arg1, arg2 []protocol.VersionNumber) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "NegotiatedVersion", arg0, arg1, arg2)
+}
+
+// NegotiatedVersion indicates an expected call of NegotiatedVersion.
+func (mr *MockConnectionTracerMockRecorder) NegotiatedVersion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NegotiatedVersion", reflect.TypeOf((*MockConnectionTracer)(nil).NegotiatedVersion), arg0, arg1, arg2)
+}
+
+// ReachedAEADLimit mocks base method.
+func (m *MockConnectionTracer) ReachedAEADLimit(arg0 AEADLimit, arg1 protocol.KeyPhase, arg2 uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReachedAEADLimit", arg0, arg1, arg2)
+}
+
+// ReachedAEADLimit indicates an expected call of ReachedAEADLimit.
+func (mr *MockConnectionTracerMockRecorder) ReachedAEADLimit(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReachedAEADLimit", reflect.TypeOf((*MockConnectionTracer)(nil).ReachedAEADLimit), arg0, arg1, arg2)
+}
+
 // ReceivedPacket mocks base method.
 func (m *MockConnectionTracer) ReceivedPacket(arg0 *wire.ExtendedHeader, arg1 protocol.ByteCount, arg2 []Frame) {
 	m.ctrl.T.Helper()
@@ -73,3 +73,15 @@ func (mr *MockTracerMockRecorder) TracerForConnection(arg0, arg1 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TracerForConnection", reflect.TypeOf((*MockTracer)(nil).TracerForConnection), arg0, arg1)
 }
+
+// UpdatedHandshakeMetrics mocks base method.
+func (m *MockTracer) UpdatedHandshakeMetrics(arg0 HandshakeMetrics) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "UpdatedHandshakeMetrics", arg0)
+}
+
+// UpdatedHandshakeMetrics indicates an expected call of UpdatedHandshakeMetrics.
+func (mr *MockTracerMockRecorder) UpdatedHandshakeMetrics(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatedHandshakeMetrics", reflect.TypeOf((*MockTracer)(nil).UpdatedHandshakeMetrics), arg0)
+}
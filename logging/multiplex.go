@@ -44,6 +44,12 @@ func (m *tracerMultiplexer) DroppedPacket(remote net.Addr, typ PacketType, size
 	}
 }
 
+func (m *tracerMultiplexer) UpdatedHandshakeMetrics(metrics HandshakeMetrics) {
+	for _, t := range m.tracers {
+		t.UpdatedHandshakeMetrics(metrics)
+	}
+}
+
 type connTracerMultiplexer struct {
 	tracers []ConnectionTracer
 }
@@ -103,6 +109,12 @@ func (m *connTracerMultiplexer) ReceivedVersionNegotiationPacket(hdr *Header, ve
 	}
 }
 
+func (m *connTracerMultiplexer) NegotiatedVersion(chosen VersionNumber, clientVersions, serverVersions []VersionNumber) {
+	for _, t := range m.tracers {
+		t.NegotiatedVersion(chosen, clientVersions, serverVersions)
+	}
+}
+
 func (m *connTracerMultiplexer) ReceivedRetry(hdr *Header) {
 	for _, t := range m.tracers {
 		t.ReceivedRetry(hdr)
@@ -181,6 +193,18 @@ func (m *connTracerMultiplexer) DroppedKey(generation KeyPhase) {
 	}
 }
 
+func (m *connTracerMultiplexer) ApproachingAEADLimit(limit AEADLimit, generation KeyPhase, count, max uint64) {
+	for _, t := range m.tracers {
+		t.ApproachingAEADLimit(limit, generation, count, max)
+	}
+}
+
+func (m *connTracerMultiplexer) ReachedAEADLimit(limit AEADLimit, generation KeyPhase, count uint64) {
+	for _, t := range m.tracers {
+		t.ReachedAEADLimit(limit, generation, count)
+	}
+}
+
 func (m *connTracerMultiplexer) SetLossTimer(typ TimerType, encLevel EncryptionLevel, exp time.Time) {
 	for _, t := range m.tracers {
 		t.SetLossTimer(typ, encLevel, exp)
@@ -81,6 +81,13 @@ var _ = Describe("Tracing", func() {
 				tr2.EXPECT().DroppedPacket(remote, PacketTypeRetry, ByteCount(1024), PacketDropDuplicate)
 				tracer.DroppedPacket(remote, PacketTypeRetry, 1024, PacketDropDuplicate)
 			})
+
+			It("traces the UpdatedHandshakeMetrics event", func() {
+				metrics := HandshakeMetrics{UnvalidatedHandshakes: 42, InitialsReceived: 1337}
+				tr1.EXPECT().UpdatedHandshakeMetrics(metrics)
+				tr2.EXPECT().UpdatedHandshakeMetrics(metrics)
+				tracer.UpdatedHandshakeMetrics(metrics)
+			})
 		})
 	})
 
@@ -149,6 +156,12 @@ var _ = Describe("Tracing", func() {
 			tracer.ReceivedVersionNegotiationPacket(hdr, []VersionNumber{1337})
 		})
 
+		It("traces the NegotiatedVersion event", func() {
+			tr1.EXPECT().NegotiatedVersion(VersionNumber(1), []VersionNumber{1, 2}, []VersionNumber{2, 1})
+			tr2.EXPECT().NegotiatedVersion(VersionNumber(1), []VersionNumber{1, 2}, []VersionNumber{2, 1})
+			tracer.NegotiatedVersion(1, []VersionNumber{1, 2}, []VersionNumber{2, 1})
+		})
+
 		It("traces the ReceivedRetry event", func() {
 			hdr := &Header{DestConnectionID: ConnectionID{1, 2, 3}}
 			tr1.EXPECT().ReceivedRetry(hdr)
@@ -232,6 +245,18 @@ var _ = Describe("Tracing", func() {
 			tracer.DroppedKey(123)
 		})
 
+		It("traces the ApproachingAEADLimit event", func() {
+			tr1.EXPECT().ApproachingAEADLimit(AEADLimitIntegrity, KeyPhase(3), uint64(100), uint64(128))
+			tr2.EXPECT().ApproachingAEADLimit(AEADLimitIntegrity, KeyPhase(3), uint64(100), uint64(128))
+			tracer.ApproachingAEADLimit(AEADLimitIntegrity, 3, 100, 128)
+		})
+
+		It("traces the ReachedAEADLimit event", func() {
+			tr1.EXPECT().ReachedAEADLimit(AEADLimitIntegrity, KeyPhase(3), uint64(128))
+			tr2.EXPECT().ReachedAEADLimit(AEADLimitIntegrity, KeyPhase(3), uint64(128))
+			tracer.ReachedAEADLimit(AEADLimitIntegrity, 3, 128)
+		})
+
 		It("traces the SetLossTimer event", func() {
 			now := time.Now()
 			tr1.EXPECT().SetLossTimer(TimerTypePTO, EncryptionHandshake, now)
@@ -91,4 +91,45 @@ const (
 	CongestionStateRecovery
 	// CongestionStateApplicationLimited means that the congestion controller is application limited
 	CongestionStateApplicationLimited
+	// CongestionStateStartup is the startup phase of BBR
+	CongestionStateStartup
+	// CongestionStateDrain is the drain phase of BBR
+	CongestionStateDrain
+	// CongestionStateProbeBandwidthDown is the phase of BBR's bandwidth probing cycle where the queue is drained
+	CongestionStateProbeBandwidthDown
+	// CongestionStateProbeBandwidthCruise is the phase of BBR's bandwidth probing cycle where it sends at the estimated bandwidth
+	CongestionStateProbeBandwidthCruise
+	// CongestionStateProbeBandwidthRefill is the phase of BBR's bandwidth probing cycle where the pipe is refilled before probing
+	CongestionStateProbeBandwidthRefill
+	// CongestionStateProbeBandwidthUp is the phase of BBR's bandwidth probing cycle where it probes for more bandwidth
+	CongestionStateProbeBandwidthUp
+	// CongestionStateProbeRTT is the phase of BBR where the amount of data in flight is reduced to measure the minimum RTT
+	CongestionStateProbeRTT
 )
+
+// AEADLimit is a limit on the usage of an AEAD, as defined in RFC 9001, Section 6.6.
+type AEADLimit uint8
+
+const (
+	// AEADLimitConfidentiality is the limit on the number of packets encrypted with the same key.
+	AEADLimitConfidentiality AEADLimit = iota
+	// AEADLimitIntegrity is the limit on the number of packets that fail authentication.
+	AEADLimitIntegrity
+)
+
+// HandshakeMetrics are counters maintained by the server.
+// They can be used to detect floods of Initial packets.
+type HandshakeMetrics struct {
+	// UnvalidatedHandshakes is the number of handshakes in progress with clients that didn't validate their address.
+	UnvalidatedHandshakes int
+	// InitialsReceived is the number of Initial packets received that started a new handshake.
+	InitialsReceived uint64
+	// InitialsRateLimited is the number of Initial packets dropped because of too many Initial packets from the same subnet.
+	InitialsRateLimited uint64
+	// RetriesSent is the number of Retry packets sent.
+	RetriesSent uint64
+	// RetriesSentUnderLoad is the number of Retry packets sent because the server was under load.
+	// This happens when the server requires address validation when too many unvalidated handshakes are in progress,
+	// or when too many unvalidated handshakes were started recently.
+	RetriesSentUnderLoad uint64
+}
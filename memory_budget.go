@@ -0,0 +1,182 @@
+package quic
+
+import (
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// sessionCryptoReservation is the flat amount of memory every session reserves for buffering crypto data.
+// It is the upper bound of the crypto data buffered for the Initial, the Handshake and the 1-RTT encryption level,
+// not the amount of crypto data actually buffered.
+const sessionCryptoReservation = 3 * protocol.MaxCryptoStreamOffset
+
+// A MemoryBudget limits the memory used for buffering received data, across all sessions that use it.
+// It can be shared between multiple Listeners and Dialers.
+//
+// Every session reserves memory for its connection-level flow control window,
+// and a flat amount for the maximum crypto data it may buffer.
+// The connection-level flow control window bounds the stream data a session buffers,
+// no matter if it was received in order or not.
+// Packet buffers are not accounted for: they are shared between all sessions using a pool.
+// The memory used by packet buffers is bounded by a constant, and received packets are dropped when it is exceeded.
+// When the budget is exhausted, the flow control window is not increased any more.
+// When the budget is exceeded (because new sessions were accepted),
+// sessions with a flow control window larger than their fair share of the budget reduce their window,
+// until the initial flow control window is reached.
+type MemoryBudget struct {
+	total      protocol.ByteCount
+	perSession protocol.ByteCount // 0 if not limited
+
+	mutex            sync.Mutex
+	reserved         protocol.ByteCount
+	reservations     map[*memoryReservation]struct{}
+	deniedIncreases  uint64
+	windowReductions uint64
+}
+
+// MemoryBudgetStats are the statistics of a MemoryBudget.
+type MemoryBudgetStats struct {
+	// Total is the total budget.
+	Total uint64
+	// PerSession is the maximum number of bytes reserved by a single session, 0 if not limited.
+	PerSession uint64
+	// Sessions is the number of sessions using the budget.
+	Sessions int
+	// Reserved is the number of bytes reserved by all sessions.
+	// It can exceed the total budget, since sessions always reserve their initial flow control window.
+	Reserved uint64
+	// Buffered is the number of bytes of stream data that were received, but not yet read by the application.
+	Buffered uint64
+	// DeniedWindowIncreases is the number of flow control window increases denied because of the budget.
+	DeniedWindowIncreases uint64
+	// WindowReductions is the number of times a flow control window was reduced because the budget was exceeded.
+	WindowReductions uint64
+}
+
+// NewMemoryBudget creates a new MemoryBudget.
+// total is the number of bytes that all sessions together may use for buffering received data.
+// perSession is the maximum number of bytes a single session may use. If it is 0, it is not limited.
+func NewMemoryBudget(total, perSession uint64) *MemoryBudget {
+	return &MemoryBudget{
+		total:        protocol.ByteCount(total),
+		perSession:   protocol.ByteCount(perSession),
+		reservations: make(map[*memoryReservation]struct{}),
+	}
+}
+
+// Stats returns the current memory usage.
+func (b *MemoryBudget) Stats() MemoryBudgetStats {
+	b.mutex.Lock()
+	stats := MemoryBudgetStats{
+		Total:                 uint64(b.total),
+		PerSession:            uint64(b.perSession),
+		Sessions:              len(b.reservations),
+		Reserved:              uint64(b.reserved),
+		DeniedWindowIncreases: b.deniedIncreases,
+		WindowReductions:      b.windowReductions,
+	}
+	reservations := make([]*memoryReservation, 0, len(b.reservations))
+	for r := range b.reservations {
+		reservations = append(reservations, r)
+	}
+	b.mutex.Unlock()
+
+	// Don't hold the mutex while querying the sessions.
+	// The flow controllers call into the budget while holding their own mutex.
+	for _, r := range reservations {
+		stats.Buffered += uint64(r.bufferedBytes())
+	}
+	return stats
+}
+
+// newReservation reserves memory for a new session.
+// The reservation must be released when the session is closed.
+func (b *MemoryBudget) newReservation(initialWindow protocol.ByteCount, bufferedBytes func() protocol.ByteCount) *memoryReservation {
+	r := &memoryReservation{
+		budget:        b,
+		initialWindow: initialWindow,
+		window:        initialWindow,
+		bufferedBytes: bufferedBytes,
+	}
+	b.mutex.Lock()
+	b.reservations[r] = struct{}{}
+	b.reserved += r.size()
+	b.mutex.Unlock()
+	return r
+}
+
+// A memoryReservation is the part of the MemoryBudget used by a single session.
+type memoryReservation struct {
+	budget        *MemoryBudget
+	initialWindow protocol.ByteCount
+	window        protocol.ByteCount // protected by budget.mutex
+	bufferedBytes func() protocol.ByteCount
+}
+
+func (r *memoryReservation) size() protocol.ByteCount {
+	return r.window + sessionCryptoReservation
+}
+
+// AllowWindowIncrease says if the flow control window may be increased by delta bytes.
+// If it returns true, the increase is reserved.
+func (r *memoryReservation) AllowWindowIncrease(delta protocol.ByteCount) bool {
+	b := r.budget
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.reserved+delta > b.total || (b.perSession > 0 && r.size()+delta > b.perSession) {
+		b.deniedIncreases++
+		return false
+	}
+	r.window += delta
+	b.reserved += delta
+	return true
+}
+
+// UndoWindowIncrease releases an increase of the flow control window by delta bytes,
+// that was allowed by AllowWindowIncrease, but not performed.
+func (r *memoryReservation) UndoWindowIncrease(delta protocol.ByteCount) {
+	b := r.budget
+	b.mutex.Lock()
+	r.window -= delta
+	b.reserved -= delta
+	b.mutex.Unlock()
+}
+
+// LimitWindowSize returns the maximum flow control window size.
+// If the budget is exceeded, it is the session's fair share of the budget, but never less than the initial window.
+// The reservation is reduced accordingly.
+func (r *memoryReservation) LimitWindowSize(size protocol.ByteCount) protocol.ByteCount {
+	b := r.budget
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.reserved <= b.total {
+		return size
+	}
+	var limit protocol.ByteCount
+	if fairShare := b.total / protocol.ByteCount(len(b.reservations)); fairShare > sessionCryptoReservation {
+		limit = fairShare - sessionCryptoReservation
+	}
+	limit = utils.MaxByteCount(limit, r.initialWindow)
+	if limit >= size || limit >= r.window {
+		return size
+	}
+	b.windowReductions++
+	b.reserved -= r.window - limit
+	r.window = limit
+	return limit
+}
+
+// Release releases the reservation.
+func (r *memoryReservation) Release() {
+	b := r.budget
+	b.mutex.Lock()
+	if _, ok := b.reservations[r]; ok {
+		delete(b.reservations, r)
+		b.reserved -= r.size()
+	}
+	b.mutex.Unlock()
+}
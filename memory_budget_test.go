@@ -0,0 +1,105 @@
+package quic
+
+import (
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory Budget", func() {
+	const initialWindow = 100 * 1000
+
+	buffered := func(n protocol.ByteCount) func() protocol.ByteCount {
+		return func() protocol.ByteCount { return n }
+	}
+
+	It("reserves the initial window and the crypto buffers", func() {
+		b := NewMemoryBudget(1e6, 0)
+		r1 := b.newReservation(initialWindow, buffered(10))
+		b.newReservation(initialWindow, buffered(20))
+		stats := b.Stats()
+		Expect(stats.Total).To(BeEquivalentTo(1e6))
+		Expect(stats.Sessions).To(Equal(2))
+		Expect(stats.Reserved).To(BeEquivalentTo(2 * (initialWindow + sessionCryptoReservation)))
+		Expect(stats.Buffered).To(BeEquivalentTo(30))
+		r1.Release()
+		r1.Release() // releasing twice is a no-op
+		stats = b.Stats()
+		Expect(stats.Sessions).To(Equal(1))
+		Expect(stats.Reserved).To(BeEquivalentTo(initialWindow + sessionCryptoReservation))
+		Expect(stats.Buffered).To(BeEquivalentTo(20))
+	})
+
+	It("allows window increases until the total budget is used", func() {
+		total := 2 * (initialWindow + sessionCryptoReservation)
+		b := NewMemoryBudget(uint64(total)+1000, 0)
+		r1 := b.newReservation(initialWindow, buffered(0))
+		r2 := b.newReservation(initialWindow, buffered(0))
+		Expect(r1.AllowWindowIncrease(600)).To(BeTrue())
+		Expect(r2.AllowWindowIncrease(401)).To(BeFalse())
+		Expect(r2.AllowWindowIncrease(400)).To(BeTrue())
+		Expect(r1.AllowWindowIncrease(1)).To(BeFalse())
+		stats := b.Stats()
+		Expect(stats.Reserved).To(BeEquivalentTo(total + 1000))
+		Expect(stats.DeniedWindowIncreases).To(BeEquivalentTo(2))
+		// releasing a session makes room for other sessions
+		r2.Release()
+		Expect(r1.AllowWindowIncrease(initialWindow)).To(BeTrue())
+	})
+
+	It("undoes window increases", func() {
+		b := NewMemoryBudget(1e6, 0)
+		r := b.newReservation(initialWindow, buffered(0))
+		Expect(r.AllowWindowIncrease(1000)).To(BeTrue())
+		Expect(b.Stats().Reserved).To(BeEquivalentTo(initialWindow + sessionCryptoReservation + 1000))
+		r.UndoWindowIncrease(1000)
+		Expect(b.Stats().Reserved).To(BeEquivalentTo(initialWindow + sessionCryptoReservation))
+		r.Release()
+		Expect(b.Stats().Reserved).To(BeZero())
+	})
+
+	It("limits the memory used by a single session", func() {
+		b := NewMemoryBudget(1e7, initialWindow+sessionCryptoReservation+1000)
+		r1 := b.newReservation(initialWindow, buffered(0))
+		r2 := b.newReservation(initialWindow, buffered(0))
+		Expect(r1.AllowWindowIncrease(1001)).To(BeFalse())
+		Expect(r1.AllowWindowIncrease(1000)).To(BeTrue())
+		Expect(r2.AllowWindowIncrease(1000)).To(BeTrue())
+		Expect(b.Stats().DeniedWindowIncreases).To(BeEquivalentTo(1))
+	})
+
+	It("doesn't limit the window size if the budget is not exceeded", func() {
+		b := NewMemoryBudget(1e7, 0)
+		r := b.newReservation(initialWindow, buffered(0))
+		Expect(r.AllowWindowIncrease(1e6)).To(BeTrue())
+		Expect(r.LimitWindowSize(initialWindow + 1e6)).To(BeEquivalentTo(initialWindow + 1e6))
+		Expect(b.Stats().WindowReductions).To(BeZero())
+	})
+
+	It("reduces the window size to the fair share if the budget is exceeded", func() {
+		const fairShare = 500 * 1000
+		b := NewMemoryBudget(2*fairShare, 0)
+		r1 := b.newReservation(initialWindow, buffered(0))
+		increase := protocol.ByteCount(2*fairShare - initialWindow - sessionCryptoReservation)
+		Expect(r1.AllowWindowIncrease(increase)).To(BeTrue())
+		// a new session is accepted, even though this exceeds the budget
+		r2 := b.newReservation(initialWindow, buffered(0))
+		Expect(b.Stats().Reserved).To(BeNumerically(">", 2*fairShare))
+		limit := r1.LimitWindowSize(initialWindow + increase)
+		Expect(limit).To(BeEquivalentTo(fairShare - sessionCryptoReservation))
+		// the second session is below its fair share
+		Expect(r2.LimitWindowSize(initialWindow)).To(BeEquivalentTo(initialWindow))
+		stats := b.Stats()
+		Expect(stats.WindowReductions).To(BeEquivalentTo(1))
+		Expect(stats.Reserved).To(BeEquivalentTo(fairShare + initialWindow + sessionCryptoReservation))
+	})
+
+	It("never reduces the window below the initial window", func() {
+		b := NewMemoryBudget(initialWindow, 0)
+		r1 := b.newReservation(initialWindow, buffered(0))
+		b.newReservation(initialWindow, buffered(0))
+		Expect(r1.LimitWindowSize(initialWindow)).To(BeEquivalentTo(initialWindow))
+		Expect(b.Stats().WindowReductions).To(BeZero())
+	})
+})
@@ -126,6 +126,18 @@ func (mr *MockPacketHandlerManagerMockRecorder) Remove(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockPacketHandlerManager)(nil).Remove), arg0)
 }
 
+// RemoveHandler mocks base method.
+func (m *MockPacketHandlerManager) RemoveHandler(arg0 packetHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveHandler", arg0)
+}
+
+// RemoveHandler indicates an expected call of RemoveHandler.
+func (mr *MockPacketHandlerManagerMockRecorder) RemoveHandler(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveHandler", reflect.TypeOf((*MockPacketHandlerManager)(nil).RemoveHandler), arg0)
+}
+
 // RemoveResetToken mocks base method.
 func (m *MockPacketHandlerManager) RemoveResetToken(arg0 protocol.StatelessResetToken) {
 	m.ctrl.T.Helper()
@@ -108,6 +108,18 @@ func (mr *MockQuicSessionMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockQuicSession)(nil).Context))
 }
 
+// ForceKeyUpdate mocks base method.
+func (m *MockQuicSession) ForceKeyUpdate() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ForceKeyUpdate")
+}
+
+// ForceKeyUpdate indicates an expected call of ForceKeyUpdate.
+func (mr *MockQuicSessionMockRecorder) ForceKeyUpdate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceKeyUpdate", reflect.TypeOf((*MockQuicSession)(nil).ForceKeyUpdate))
+}
+
 // GetVersion mocks base method.
 func (m *MockQuicSession) GetVersion() protocol.VersionNumber {
 	m.ctrl.T.Helper()
@@ -150,6 +162,34 @@ func (mr *MockQuicSessionMockRecorder) LocalAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LocalAddr", reflect.TypeOf((*MockQuicSession)(nil).LocalAddr))
 }
 
+// MaxDatagramSize mocks base method.
+func (m *MockQuicSession) MaxDatagramSize() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MaxDatagramSize")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// MaxDatagramSize indicates an expected call of MaxDatagramSize.
+func (mr *MockQuicSessionMockRecorder) MaxDatagramSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxDatagramSize", reflect.TypeOf((*MockQuicSession)(nil).MaxDatagramSize))
+}
+
+// MigrateTo mocks base method.
+func (m *MockQuicSession) MigrateTo(arg0 net.PacketConn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateTo", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MigrateTo indicates an expected call of MigrateTo.
+func (mr *MockQuicSessionMockRecorder) MigrateTo(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateTo", reflect.TypeOf((*MockQuicSession)(nil).MigrateTo), arg0)
+}
+
 // NextSession mocks base method.
 func (m *MockQuicSession) NextSession() Session {
 	m.ctrl.T.Helper()
@@ -253,6 +293,20 @@ func (mr *MockQuicSessionMockRecorder) RemoteAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockQuicSession)(nil).RemoteAddr))
 }
 
+// SendDatagram mocks base method.
+func (m *MockQuicSession) SendDatagram(arg0 []byte, arg1 *DatagramSendOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDatagram", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendDatagram indicates an expected call of SendDatagram.
+func (mr *MockQuicSessionMockRecorder) SendDatagram(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDatagram", reflect.TypeOf((*MockQuicSession)(nil).SendDatagram), arg0, arg1)
+}
+
 // SendMessage mocks base method.
 func (m *MockQuicSession) SendMessage(arg0 []byte) error {
 	m.ctrl.T.Helper()
@@ -267,6 +321,20 @@ func (mr *MockQuicSessionMockRecorder) SendMessage(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockQuicSession)(nil).SendMessage), arg0)
 }
 
+// Stats mocks base method.
+func (m *MockQuicSession) Stats() ConnectionStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(ConnectionStats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockQuicSessionMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockQuicSession)(nil).Stats))
+}
+
 // destroy mocks base method.
 func (m *MockQuicSession) destroy(arg0 error) {
 	m.ctrl.T.Helper()
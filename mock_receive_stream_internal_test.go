@@ -5,6 +5,7 @@
 package quic
 
 import (
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -48,6 +49,21 @@ func (mr *MockReceiveStreamIMockRecorder) CancelRead(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelRead", reflect.TypeOf((*MockReceiveStreamI)(nil).CancelRead), arg0)
 }
 
+// Peek mocks base method.
+func (m *MockReceiveStreamI) Peek() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peek")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Peek indicates an expected call of Peek.
+func (mr *MockReceiveStreamIMockRecorder) Peek() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peek", reflect.TypeOf((*MockReceiveStreamI)(nil).Peek))
+}
+
 // Read mocks base method.
 func (m *MockReceiveStreamI) Read(p []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +79,36 @@ func (mr *MockReceiveStreamIMockRecorder) Read(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockReceiveStreamI)(nil).Read), p)
 }
 
+// ReadBuffer mocks base method.
+func (m *MockReceiveStreamI) ReadBuffer() ([]byte, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBuffer")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadBuffer indicates an expected call of ReadBuffer.
+func (mr *MockReceiveStreamIMockRecorder) ReadBuffer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBuffer", reflect.TypeOf((*MockReceiveStreamI)(nil).ReadBuffer))
+}
+
+// ReceiveStats mocks base method.
+func (m *MockReceiveStreamI) ReceiveStats() ReceiveStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveStats")
+	ret0, _ := ret[0].(ReceiveStreamStats)
+	return ret0
+}
+
+// ReceiveStats indicates an expected call of ReceiveStats.
+func (mr *MockReceiveStreamIMockRecorder) ReceiveStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveStats", reflect.TypeOf((*MockReceiveStreamI)(nil).ReceiveStats))
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockReceiveStreamI) SetReadDeadline(t time.Time) error {
 	m.ctrl.T.Helper()
@@ -91,6 +137,35 @@ func (mr *MockReceiveStreamIMockRecorder) StreamID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamID", reflect.TypeOf((*MockReceiveStreamI)(nil).StreamID))
 }
 
+// Used0RTT mocks base method.
+func (m *MockReceiveStreamI) Used0RTT() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Used0RTT")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Used0RTT indicates an expected call of Used0RTT.
+func (mr *MockReceiveStreamIMockRecorder) Used0RTT() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Used0RTT", reflect.TypeOf((*MockReceiveStreamI)(nil).Used0RTT))
+}
+
+// WriteTo mocks base method.
+func (m *MockReceiveStreamI) WriteTo(w io.Writer) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteTo", w)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteTo indicates an expected call of WriteTo.
+func (mr *MockReceiveStreamIMockRecorder) WriteTo(w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTo", reflect.TypeOf((*MockReceiveStreamI)(nil).WriteTo), w)
+}
+
 // closeForShutdown mocks base method.
 func (m *MockReceiveStreamI) closeForShutdown(arg0 error) {
 	m.ctrl.T.Helper()
@@ -117,6 +192,20 @@ func (mr *MockReceiveStreamIMockRecorder) getWindowUpdate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getWindowUpdate", reflect.TypeOf((*MockReceiveStreamI)(nil).getWindowUpdate))
 }
 
+// handleResetStreamAtFrame mocks base method.
+func (m *MockReceiveStreamI) handleResetStreamAtFrame(arg0 *wire.ResetStreamAtFrame) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "handleResetStreamAtFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleResetStreamAtFrame indicates an expected call of handleResetStreamAtFrame.
+func (mr *MockReceiveStreamIMockRecorder) handleResetStreamAtFrame(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleResetStreamAtFrame", reflect.TypeOf((*MockReceiveStreamI)(nil).handleResetStreamAtFrame), arg0)
+}
+
 // handleResetStreamFrame mocks base method.
 func (m *MockReceiveStreamI) handleResetStreamFrame(arg0 *wire.ResetStreamFrame) error {
 	m.ctrl.T.Helper()
@@ -144,3 +233,15 @@ func (mr *MockReceiveStreamIMockRecorder) handleStreamFrame(arg0 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleStreamFrame", reflect.TypeOf((*MockReceiveStreamI)(nil).handleStreamFrame), arg0)
 }
+
+// markReceived0RTT mocks base method.
+func (m *MockReceiveStreamI) markReceived0RTT() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "markReceived0RTT")
+}
+
+// markReceived0RTT indicates an expected call of markReceived0RTT.
+func (mr *MockReceiveStreamIMockRecorder) markReceived0RTT() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "markReceived0RTT", reflect.TypeOf((*MockReceiveStreamI)(nil).markReceived0RTT))
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
 // MockSendConn is a mock of SendConn interface.
@@ -76,6 +77,34 @@ func (mr *MockSendConnMockRecorder) RemoteAddr() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteAddr", reflect.TypeOf((*MockSendConn)(nil).RemoteAddr))
 }
 
+// SupportsGSO mocks base method.
+func (m *MockSendConn) SupportsGSO() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SupportsGSO")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// SupportsGSO indicates an expected call of SupportsGSO.
+func (mr *MockSendConnMockRecorder) SupportsGSO() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SupportsGSO", reflect.TypeOf((*MockSendConn)(nil).SupportsGSO))
+}
+
+// WithRemoteAddr mocks base method.
+func (m *MockSendConn) WithRemoteAddr(arg0 net.Addr, arg1 *packetInfo) sendConn {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithRemoteAddr", arg0, arg1)
+	ret0, _ := ret[0].(sendConn)
+	return ret0
+}
+
+// WithRemoteAddr indicates an expected call of WithRemoteAddr.
+func (mr *MockSendConnMockRecorder) WithRemoteAddr(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRemoteAddr", reflect.TypeOf((*MockSendConn)(nil).WithRemoteAddr), arg0, arg1)
+}
+
 // Write mocks base method.
 func (m *MockSendConn) Write(arg0 []byte) error {
 	m.ctrl.T.Helper()
@@ -89,3 +118,17 @@ func (mr *MockSendConnMockRecorder) Write(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSendConn)(nil).Write), arg0)
 }
+
+// WriteBatch mocks base method.
+func (m *MockSendConn) WriteBatch(b []byte, segmentSize protocol.ByteCount) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBatch", b, segmentSize)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteBatch indicates an expected call of WriteBatch.
+func (mr *MockSendConnMockRecorder) WriteBatch(b, segmentSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBatch", reflect.TypeOf((*MockSendConn)(nil).WriteBatch), b, segmentSize)
+}
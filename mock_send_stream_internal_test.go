@@ -6,6 +6,7 @@ package quic
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -50,6 +51,18 @@ func (mr *MockSendStreamIMockRecorder) CancelWrite(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWrite", reflect.TypeOf((*MockSendStreamI)(nil).CancelWrite), arg0)
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockSendStreamI) CancelWriteAt(code ErrorCode, offset uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelWriteAt", code, offset)
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockSendStreamIMockRecorder) CancelWriteAt(code, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockSendStreamI)(nil).CancelWriteAt), code, offset)
+}
+
 // Close mocks base method.
 func (m *MockSendStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -78,6 +91,61 @@ func (mr *MockSendStreamIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockSendStreamI)(nil).Context))
 }
 
+// Priority mocks base method.
+func (m *MockSendStreamI) Priority() Priority {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(Priority)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockSendStreamIMockRecorder) Priority() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockSendStreamI)(nil).Priority))
+}
+
+// ReadFrom mocks base method.
+func (m *MockSendStreamI) ReadFrom(r io.Reader) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFrom", r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFrom indicates an expected call of ReadFrom.
+func (mr *MockSendStreamIMockRecorder) ReadFrom(r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFrom", reflect.TypeOf((*MockSendStreamI)(nil).ReadFrom), r)
+}
+
+// SendStats mocks base method.
+func (m *MockSendStreamI) SendStats() SendStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendStats")
+	ret0, _ := ret[0].(SendStreamStats)
+	return ret0
+}
+
+// SendStats indicates an expected call of SendStats.
+func (mr *MockSendStreamIMockRecorder) SendStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendStats", reflect.TypeOf((*MockSendStreamI)(nil).SendStats))
+}
+
+// SetPriority mocks base method.
+func (m *MockSendStreamI) SetPriority(arg0 Priority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockSendStreamIMockRecorder) SetPriority(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockSendStreamI)(nil).SetPriority), arg0)
+}
+
 // SetWriteDeadline mocks base method.
 func (m *MockSendStreamI) SetWriteDeadline(t time.Time) error {
 	m.ctrl.T.Helper()
@@ -106,6 +174,20 @@ func (mr *MockSendStreamIMockRecorder) StreamID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamID", reflect.TypeOf((*MockSendStreamI)(nil).StreamID))
 }
 
+// WaitForAcks mocks base method.
+func (m *MockSendStreamI) WaitForAcks(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForAcks", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForAcks indicates an expected call of WaitForAcks.
+func (mr *MockSendStreamIMockRecorder) WaitForAcks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForAcks", reflect.TypeOf((*MockSendStreamI)(nil).WaitForAcks), arg0)
+}
+
 // Write mocks base method.
 func (m *MockSendStreamI) Write(p []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -121,6 +203,21 @@ func (mr *MockSendStreamIMockRecorder) Write(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockSendStreamI)(nil).Write), p)
 }
 
+// WriteBuffer mocks base method.
+func (m *MockSendStreamI) WriteBuffer(b []byte, release func()) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBuffer", b, release)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteBuffer indicates an expected call of WriteBuffer.
+func (mr *MockSendStreamIMockRecorder) WriteBuffer(b, release interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBuffer", reflect.TypeOf((*MockSendStreamI)(nil).WriteBuffer), b, release)
+}
+
 // closeForShutdown mocks base method.
 func (m *MockSendStreamI) closeForShutdown(arg0 error) {
 	m.ctrl.T.Helper()
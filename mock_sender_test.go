@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	protocol "github.com/lucas-clemente/quic-go/internal/protocol"
 )
 
 // MockSender is a mock of Sender interface.
@@ -85,6 +86,18 @@ func (mr *MockSenderMockRecorder) Send(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSender)(nil).Send), p)
 }
 
+// SendBatch mocks base method.
+func (m *MockSender) SendBatch(p *packetBuffer, segmentSize protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SendBatch", p, segmentSize)
+}
+
+// SendBatch indicates an expected call of SendBatch.
+func (mr *MockSenderMockRecorder) SendBatch(p, segmentSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBatch", reflect.TypeOf((*MockSender)(nil).SendBatch), p, segmentSize)
+}
+
 // WouldBlock mocks base method.
 func (m *MockSender) WouldBlock() bool {
 	m.ctrl.T.Helper()
@@ -86,6 +86,18 @@ func (mr *MockSessionRunnerMockRecorder) Remove(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockSessionRunner)(nil).Remove), arg0)
 }
 
+// RemoveHandler mocks base method.
+func (m *MockSessionRunner) RemoveHandler(arg0 packetHandler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemoveHandler", arg0)
+}
+
+// RemoveHandler indicates an expected call of RemoveHandler.
+func (mr *MockSessionRunnerMockRecorder) RemoveHandler(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveHandler", reflect.TypeOf((*MockSessionRunner)(nil).RemoveHandler), arg0)
+}
+
 // RemoveResetToken mocks base method.
 func (m *MockSessionRunner) RemoveResetToken(arg0 protocol.StatelessResetToken) {
 	m.ctrl.T.Helper()
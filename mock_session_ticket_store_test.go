@@ -0,0 +1,61 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/lucas-clemente/quic-go (interfaces: SessionTicketStore)
+
+// Package quic is a generated GoMock package.
+package quic
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSessionTicketStore is a mock of SessionTicketStore interface.
+type MockSessionTicketStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionTicketStoreMockRecorder
+}
+
+// MockSessionTicketStoreMockRecorder is the mock recorder for MockSessionTicketStore.
+type MockSessionTicketStoreMockRecorder struct {
+	mock *MockSessionTicketStore
+}
+
+// NewMockSessionTicketStore creates a new mock instance.
+func NewMockSessionTicketStore(ctrl *gomock.Controller) *MockSessionTicketStore {
+	mock := &MockSessionTicketStore{ctrl: ctrl}
+	mock.recorder = &MockSessionTicketStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionTicketStore) EXPECT() *MockSessionTicketStoreMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockSessionTicketStore) Get(arg0 string) ([]byte, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSessionTicketStoreMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionTicketStore)(nil).Get), arg0)
+}
+
+// Put mocks base method.
+func (m *MockSessionTicketStore) Put(arg0 string, arg1 []byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Put", arg0, arg1)
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockSessionTicketStoreMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockSessionTicketStore)(nil).Put), arg0, arg1)
+}
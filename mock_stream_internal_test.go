@@ -6,6 +6,7 @@ package quic
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -62,6 +63,18 @@ func (mr *MockStreamIMockRecorder) CancelWrite(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWrite", reflect.TypeOf((*MockStreamI)(nil).CancelWrite), arg0)
 }
 
+// CancelWriteAt mocks base method.
+func (m *MockStreamI) CancelWriteAt(code ErrorCode, offset uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CancelWriteAt", code, offset)
+}
+
+// CancelWriteAt indicates an expected call of CancelWriteAt.
+func (mr *MockStreamIMockRecorder) CancelWriteAt(code, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelWriteAt", reflect.TypeOf((*MockStreamI)(nil).CancelWriteAt), code, offset)
+}
+
 // Close mocks base method.
 func (m *MockStreamI) Close() error {
 	m.ctrl.T.Helper()
@@ -90,6 +103,35 @@ func (mr *MockStreamIMockRecorder) Context() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockStreamI)(nil).Context))
 }
 
+// Peek mocks base method.
+func (m *MockStreamI) Peek() ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Peek")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Peek indicates an expected call of Peek.
+func (mr *MockStreamIMockRecorder) Peek() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Peek", reflect.TypeOf((*MockStreamI)(nil).Peek))
+}
+
+// Priority mocks base method.
+func (m *MockStreamI) Priority() Priority {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Priority")
+	ret0, _ := ret[0].(Priority)
+	return ret0
+}
+
+// Priority indicates an expected call of Priority.
+func (mr *MockStreamIMockRecorder) Priority() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Priority", reflect.TypeOf((*MockStreamI)(nil).Priority))
+}
+
 // Read mocks base method.
 func (m *MockStreamI) Read(p []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -105,6 +147,65 @@ func (mr *MockStreamIMockRecorder) Read(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStreamI)(nil).Read), p)
 }
 
+// ReadBuffer mocks base method.
+func (m *MockStreamI) ReadBuffer() ([]byte, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadBuffer")
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReadBuffer indicates an expected call of ReadBuffer.
+func (mr *MockStreamIMockRecorder) ReadBuffer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadBuffer", reflect.TypeOf((*MockStreamI)(nil).ReadBuffer))
+}
+
+// ReadFrom mocks base method.
+func (m *MockStreamI) ReadFrom(r io.Reader) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadFrom", r)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadFrom indicates an expected call of ReadFrom.
+func (mr *MockStreamIMockRecorder) ReadFrom(r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadFrom", reflect.TypeOf((*MockStreamI)(nil).ReadFrom), r)
+}
+
+// ReceiveStats mocks base method.
+func (m *MockStreamI) ReceiveStats() ReceiveStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveStats")
+	ret0, _ := ret[0].(ReceiveStreamStats)
+	return ret0
+}
+
+// ReceiveStats indicates an expected call of ReceiveStats.
+func (mr *MockStreamIMockRecorder) ReceiveStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveStats", reflect.TypeOf((*MockStreamI)(nil).ReceiveStats))
+}
+
+// SendStats mocks base method.
+func (m *MockStreamI) SendStats() SendStreamStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendStats")
+	ret0, _ := ret[0].(SendStreamStats)
+	return ret0
+}
+
+// SendStats indicates an expected call of SendStats.
+func (mr *MockStreamIMockRecorder) SendStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendStats", reflect.TypeOf((*MockStreamI)(nil).SendStats))
+}
+
 // SetDeadline mocks base method.
 func (m *MockStreamI) SetDeadline(t time.Time) error {
 	m.ctrl.T.Helper()
@@ -119,6 +220,18 @@ func (mr *MockStreamIMockRecorder) SetDeadline(t interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeadline", reflect.TypeOf((*MockStreamI)(nil).SetDeadline), t)
 }
 
+// SetPriority mocks base method.
+func (m *MockStreamI) SetPriority(arg0 Priority) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPriority", arg0)
+}
+
+// SetPriority indicates an expected call of SetPriority.
+func (mr *MockStreamIMockRecorder) SetPriority(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPriority", reflect.TypeOf((*MockStreamI)(nil).SetPriority), arg0)
+}
+
 // SetReadDeadline mocks base method.
 func (m *MockStreamI) SetReadDeadline(t time.Time) error {
 	m.ctrl.T.Helper()
@@ -161,6 +274,34 @@ func (mr *MockStreamIMockRecorder) StreamID() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamID", reflect.TypeOf((*MockStreamI)(nil).StreamID))
 }
 
+// Used0RTT mocks base method.
+func (m *MockStreamI) Used0RTT() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Used0RTT")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Used0RTT indicates an expected call of Used0RTT.
+func (mr *MockStreamIMockRecorder) Used0RTT() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Used0RTT", reflect.TypeOf((*MockStreamI)(nil).Used0RTT))
+}
+
+// WaitForAcks mocks base method.
+func (m *MockStreamI) WaitForAcks(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WaitForAcks", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WaitForAcks indicates an expected call of WaitForAcks.
+func (mr *MockStreamIMockRecorder) WaitForAcks(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitForAcks", reflect.TypeOf((*MockStreamI)(nil).WaitForAcks), arg0)
+}
+
 // Write mocks base method.
 func (m *MockStreamI) Write(p []byte) (int, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +317,36 @@ func (mr *MockStreamIMockRecorder) Write(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockStreamI)(nil).Write), p)
 }
 
+// WriteBuffer mocks base method.
+func (m *MockStreamI) WriteBuffer(b []byte, release func()) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteBuffer", b, release)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteBuffer indicates an expected call of WriteBuffer.
+func (mr *MockStreamIMockRecorder) WriteBuffer(b, release interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteBuffer", reflect.TypeOf((*MockStreamI)(nil).WriteBuffer), b, release)
+}
+
+// WriteTo mocks base method.
+func (m *MockStreamI) WriteTo(w io.Writer) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteTo", w)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteTo indicates an expected call of WriteTo.
+func (mr *MockStreamIMockRecorder) WriteTo(w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTo", reflect.TypeOf((*MockStreamI)(nil).WriteTo), w)
+}
+
 // closeForShutdown mocks base method.
 func (m *MockStreamI) closeForShutdown(arg0 error) {
 	m.ctrl.T.Helper()
@@ -202,6 +373,20 @@ func (mr *MockStreamIMockRecorder) getWindowUpdate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getWindowUpdate", reflect.TypeOf((*MockStreamI)(nil).getWindowUpdate))
 }
 
+// handleResetStreamAtFrame mocks base method.
+func (m *MockStreamI) handleResetStreamAtFrame(arg0 *wire.ResetStreamAtFrame) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "handleResetStreamAtFrame", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// handleResetStreamAtFrame indicates an expected call of handleResetStreamAtFrame.
+func (mr *MockStreamIMockRecorder) handleResetStreamAtFrame(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "handleResetStreamAtFrame", reflect.TypeOf((*MockStreamI)(nil).handleResetStreamAtFrame), arg0)
+}
+
 // handleResetStreamFrame mocks base method.
 func (m *MockStreamI) handleResetStreamFrame(arg0 *wire.ResetStreamFrame) error {
 	m.ctrl.T.Helper()
@@ -256,6 +441,18 @@ func (mr *MockStreamIMockRecorder) hasData() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "hasData", reflect.TypeOf((*MockStreamI)(nil).hasData))
 }
 
+// markReceived0RTT mocks base method.
+func (m *MockStreamI) markReceived0RTT() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "markReceived0RTT")
+}
+
+// markReceived0RTT indicates an expected call of markReceived0RTT.
+func (mr *MockStreamIMockRecorder) markReceived0RTT() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "markReceived0RTT", reflect.TypeOf((*MockStreamI)(nil).markReceived0RTT))
+}
+
 // popStreamFrame mocks base method.
 func (m *MockStreamI) popStreamFrame(maxBytes protocol.ByteCount) (*ackhandler.Frame, bool) {
 	m.ctrl.T.Helper()
@@ -47,6 +47,18 @@ func (mr *MockStreamSenderMockRecorder) onHasStreamData(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onHasStreamData", reflect.TypeOf((*MockStreamSender)(nil).onHasStreamData), arg0)
 }
 
+// onQueuedRetransmission mocks base method.
+func (m *MockStreamSender) onQueuedRetransmission(arg0 protocol.ByteCount) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "onQueuedRetransmission", arg0)
+}
+
+// onQueuedRetransmission indicates an expected call of onQueuedRetransmission.
+func (mr *MockStreamSenderMockRecorder) onQueuedRetransmission(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "onQueuedRetransmission", reflect.TypeOf((*MockStreamSender)(nil).onQueuedRetransmission), arg0)
+}
+
 // onStreamCompleted mocks base method.
 func (m *MockStreamSender) onStreamCompleted(arg0 protocol.StreamID) {
 	m.ctrl.T.Helper()
@@ -70,3 +82,17 @@ func (mr *MockStreamSenderMockRecorder) queueControlFrame(arg0 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "queueControlFrame", reflect.TypeOf((*MockStreamSender)(nil).queueControlFrame), arg0)
 }
+
+// supportsResetStreamAt mocks base method.
+func (m *MockStreamSender) supportsResetStreamAt() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "supportsResetStreamAt")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// supportsResetStreamAt indicates an expected call of supportsResetStreamAt.
+func (mr *MockStreamSenderMockRecorder) supportsResetStreamAt() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "supportsResetStreamAt", reflect.TypeOf((*MockStreamSender)(nil).supportsResetStreamAt))
+}
@@ -231,6 +231,24 @@ func (h *packetHandlerMap) Remove(id protocol.ConnectionID) {
 	h.logger.Debugf("Removing connection ID %s.", id)
 }
 
+// RemoveHandler removes all connection IDs and stateless reset tokens of a packet handler.
+// It is used when a session migrates to a new path.
+func (h *packetHandlerMap) RemoveHandler(handler packetHandler) {
+	h.mutex.Lock()
+	for id, entry := range h.handlers {
+		if entry.packetHandler == handler {
+			delete(h.handlers, id)
+		}
+	}
+	for token, ph := range h.resetTokens {
+		if ph == handler {
+			delete(h.resetTokens, token)
+		}
+	}
+	h.mutex.Unlock()
+	h.logger.Debugf("Removing all connection IDs of a session.")
+}
+
 func (h *packetHandlerMap) Retire(id protocol.ConnectionID) {
 	h.logger.Debugf("Retiring connection ID %s in %s.", id, h.deleteRetiredSessionsAfter)
 	time.AfterFunc(h.deleteRetiredSessionsAfter, func() {
@@ -343,11 +361,19 @@ func (h *packetHandlerMap) listen() {
 			h.close(err)
 			return
 		}
+		p.rcvConn = h.conn
 		h.handlePacket(p)
 	}
 }
 
 func (h *packetHandlerMap) handlePacket(p *receivedPacket) {
+	if packetBufferMemoryExhausted() {
+		if h.tracer != nil {
+			h.tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropDOSPrevention)
+		}
+		p.buffer.MaybeRelease()
+		return
+	}
 	connID, err := wire.ParseConnectionID(p.data, h.connIDLen)
 	if err != nil {
 		h.logger.Debugf("error parsing connection ID on packet from %s: %s", p.remoteAddr, err)
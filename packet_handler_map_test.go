@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"errors"
 	"net"
+	"sync/atomic"
 	"time"
 
 	mocklogging "github.com/lucas-clemente/quic-go/internal/mocks/logging"
@@ -160,6 +161,22 @@ var _ = Describe("Packet Handler Map", func() {
 				})
 			})
 
+			It("drops packets when the packet buffers use too much memory", func() {
+				atomic.AddInt64(&packetBufferMemory, int64(protocol.MaxPacketBufferMemory))
+				defer atomic.AddInt64(&packetBufferMemory, -int64(protocol.MaxPacketBufferMemory))
+				connID := protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}
+				handler.Add(connID, NewMockPacketHandler(mockCtrl))
+				addr := &net.UDPAddr{IP: net.IPv4(9, 8, 7, 6), Port: 1234}
+				data := getPacket(connID)
+				tracer.EXPECT().DroppedPacket(addr, logging.PacketTypeNotDetermined, protocol.ByteCount(len(data)), logging.PacketDropDOSPrevention)
+				handler.handlePacket(&receivedPacket{
+					buffer:     getPacketBuffer(),
+					remoteAddr: addr,
+					data:       data,
+				})
+				// don't EXPECT any calls to handlePacket of the MockPacketHandler
+			})
+
 			It("deletes removed sessions immediately", func() {
 				handler.deleteRetiredSessionsAfter = time.Hour
 				connID := protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}
@@ -169,6 +186,25 @@ var _ = Describe("Packet Handler Map", func() {
 				// don't EXPECT any calls to handlePacket of the MockPacketHandler
 			})
 
+			It("deletes all entries of a packet handler", func() {
+				handler.deleteRetiredSessionsAfter = time.Hour
+				connID1 := protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}
+				connID2 := protocol.ConnectionID{8, 7, 6, 5, 4, 3, 2, 1}
+				sess := NewMockPacketHandler(mockCtrl)
+				handler.Add(connID1, sess)
+				handler.Add(connID2, sess)
+				handler.Retire(connID2)
+				otherConnID := protocol.ConnectionID{1, 1, 1, 1, 1, 1, 1, 1}
+				otherSess := NewMockPacketHandler(mockCtrl)
+				handler.Add(otherConnID, otherSess)
+				handler.RemoveHandler(sess)
+				handler.handlePacket(&receivedPacket{data: getPacket(connID1)})
+				handler.handlePacket(&receivedPacket{data: getPacket(connID2)})
+				// don't EXPECT any calls to handlePacket of the removed MockPacketHandler
+				otherSess.EXPECT().handlePacket(gomock.Any())
+				handler.handlePacket(&receivedPacket{data: getPacket(otherConnID)})
+			})
+
 			It("deletes retired session entries after a wait time", func() {
 				handler.deleteRetiredSessionsAfter = scaleDuration(10 * time.Millisecond)
 				connID := protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}
@@ -18,15 +18,19 @@ import (
 type packer interface {
 	PackCoalescedPacket() (*coalescedPacket, error)
 	PackPacket() (*packedPacket, error)
+	AppendPacket(buffer *packetBuffer) (*packetContents, error)
 	MaybePackProbePacket(protocol.EncryptionLevel) (*packedPacket, error)
 	MaybePackAckPacket(handshakeConfirmed bool) (*packedPacket, error)
 	PackConnectionClose(*qerr.QuicError) (*coalescedPacket, error)
 
 	SetMaxPacketSize(protocol.ByteCount)
+	MaxPacketSize() protocol.ByteCount
 	PackMTUProbePacket(ping ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error)
+	PackPathProbePacket(destConnID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error)
 
 	HandleTransportParameters(*wire.TransportParameters)
 	SetToken([]byte)
+	SetVersion(protocol.VersionNumber)
 }
 
 type sealer interface {
@@ -401,7 +405,7 @@ func (p *packetPacker) PackCoalescedPacket() (*coalescedPacket, error) {
 	appDataEncLevel := protocol.Encryption1RTT
 	if size < maxPacketSize-protocol.MinCoalescedPacketSize {
 		var err error
-		appDataSealer, appDataHdr, appDataPayload = p.maybeGetAppDataPacket(maxPacketSize-size, size)
+		appDataSealer, appDataHdr, appDataPayload, err = p.maybeGetAppDataPacket(maxPacketSize-size, size)
 		if err != nil {
 			return nil, err
 		}
@@ -453,9 +457,9 @@ func (p *packetPacker) PackCoalescedPacket() (*coalescedPacket, error) {
 // PackPacket packs a packet in the application data packet number space.
 // It should be called after the handshake is confirmed.
 func (p *packetPacker) PackPacket() (*packedPacket, error) {
-	sealer, hdr, payload := p.maybeGetAppDataPacket(p.maxPacketSize, 0)
-	if payload == nil {
-		return nil, nil
+	sealer, hdr, payload, err := p.maybeGetAppDataPacket(p.maxPacketSize, 0)
+	if err != nil || payload == nil {
+		return nil, err
 	}
 	buffer := getPacketBuffer()
 	encLevel := protocol.Encryption1RTT
@@ -472,6 +476,34 @@ func (p *packetPacker) PackPacket() (*packedPacket, error) {
 	}, nil
 }
 
+// AppendPacket packs a packet in the application data packet number space,
+// and appends it to the buffer. It returns nil if there's nothing to send.
+// The buffer needs to have enough capacity left for a packet of the maximum packet size.
+// It should be called after the handshake is confirmed.
+func (p *packetPacker) AppendPacket(buffer *packetBuffer) (*packetContents, error) {
+	if protocol.ByteCount(cap(buffer.Data)-len(buffer.Data)) < p.maxPacketSize {
+		return nil, errors.New("packetPacker BUG: buffer too small")
+	}
+	sealer, hdr, payload, err := p.maybeGetAppDataPacket(p.maxPacketSize, 0)
+	if err != nil || payload == nil {
+		return nil, err
+	}
+	encLevel := protocol.Encryption1RTT
+	if hdr.IsLongHeader {
+		encLevel = protocol.Encryption0RTT
+	}
+	// Write the packet into the unused capacity of the buffer.
+	// This way, the packet size is checked for this packet only.
+	start := len(buffer.Data)
+	packetBuf := &packetBuffer{Data: buffer.Data[start:start]}
+	cont, err := p.appendPacket(packetBuf, hdr, payload, 0, encLevel, sealer, false)
+	if err != nil {
+		return nil, err
+	}
+	buffer.Data = buffer.Data[:start+len(packetBuf.Data)]
+	return cont, nil
+}
+
 func (p *packetPacker) maybeGetCryptoPacket(maxPacketSize, currentSize protocol.ByteCount, encLevel protocol.EncryptionLevel) (*wire.ExtendedHeader, *payload) {
 	var s cryptoStream
 	var hasRetransmission bool
@@ -529,31 +561,34 @@ func (p *packetPacker) maybeGetCryptoPacket(maxPacketSize, currentSize protocol.
 	return hdr, &payload
 }
 
-func (p *packetPacker) maybeGetAppDataPacket(maxPacketSize, currentSize protocol.ByteCount) (sealer, *wire.ExtendedHeader, *payload) {
+func (p *packetPacker) maybeGetAppDataPacket(maxPacketSize, currentSize protocol.ByteCount) (sealer, *wire.ExtendedHeader, *payload, error) {
 	var sealer sealer
 	var encLevel protocol.EncryptionLevel
 	var hdr *wire.ExtendedHeader
 	oneRTTSealer, err := p.cryptoSetup.Get1RTTSealer()
-	if err == nil {
+	switch err {
+	case nil:
 		encLevel = protocol.Encryption1RTT
 		sealer = oneRTTSealer
 		hdr = p.getShortHeader(oneRTTSealer.KeyPhase())
-	} else {
-		// 1-RTT sealer not yet available
+	case handshake.ErrKeysNotYetAvailable:
 		if p.perspective != protocol.PerspectiveClient {
-			return nil, nil, nil
+			return nil, nil, nil, nil
 		}
 		sealer, err = p.cryptoSetup.Get0RTTSealer()
 		if sealer == nil || err != nil {
-			return nil, nil, nil
+			return nil, nil, nil, nil
 		}
 		encLevel = protocol.Encryption0RTT
 		hdr = p.getLongHeader(protocol.Encryption0RTT)
+	default:
+		// For example, the confidentiality limit of the 1-RTT keys was reached.
+		return nil, nil, nil, err
 	}
 
 	maxPayloadSize := maxPacketSize - hdr.GetLength(p.version) - protocol.ByteCount(sealer.Overhead())
 	payload := p.maybeGetAppDataPacketWithEncLevel(maxPayloadSize, encLevel == protocol.Encryption1RTT && currentSize == 0)
-	return sealer, hdr, payload
+	return sealer, hdr, payload, nil
 }
 
 func (p *packetPacker) maybeGetAppDataPacketWithEncLevel(maxPayloadSize protocol.ByteCount, ackAllowed bool) *payload {
@@ -583,24 +618,11 @@ func (p *packetPacker) maybeGetAppDataPacketWithEncLevel(maxPayloadSize protocol
 func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, ackAllowed bool) *payload {
 	payload := &payload{frames: make([]ackhandler.Frame, 0, 1)}
 
-	var hasDatagram bool
-	if p.datagramQueue != nil {
-		if datagram := p.datagramQueue.Get(); datagram != nil {
-			payload.frames = append(payload.frames, ackhandler.Frame{
-				Frame: datagram,
-				// set it to a no-op. Then we won't set the default callback, which would retransmit the frame.
-				OnLost: func(wire.Frame) {},
-			})
-			payload.length += datagram.Length(p.version)
-			hasDatagram = true
-		}
-	}
-
 	var ack *wire.AckFrame
 	hasData := p.framer.HasData()
 	hasRetransmission := p.retransmissionQueue.HasAppData()
-	// TODO: make sure ACKs are sent when a lot of DATAGRAMs are queued
-	if !hasDatagram && ackAllowed {
+	// The ACK is added first, such that ACKs are sent even when a lot of DATAGRAMs are queued.
+	if ackAllowed {
 		ack = p.acks.GetAckFrame(protocol.Encryption1RTT, !hasRetransmission && !hasData)
 		if ack != nil {
 			payload.ack = ack
@@ -608,6 +630,23 @@ func (p *packetPacker) composeNextPacket(maxFrameSize protocol.ByteCount, ackAll
 		}
 	}
 
+	// If the DATAGRAM frame doesn't fit into the remaining space, it is sent in the next packet.
+	if p.datagramQueue != nil {
+		if datagram := p.datagramQueue.Get(maxFrameSize-payload.length, p.version); datagram != nil {
+			frame := ackhandler.Frame{
+				Frame: datagram.frame,
+				// set it to a no-op. Then we won't set the default callback, which would retransmit the frame.
+				OnLost: func(wire.Frame) {},
+			}
+			if datagram.onOutcome != nil {
+				frame.OnLost = func(wire.Frame) { p.datagramQueue.ReportOutcome(datagram, DatagramLost) }
+				frame.OnAcked = func(wire.Frame) { p.datagramQueue.ReportOutcome(datagram, DatagramAcked) }
+			}
+			payload.frames = append(payload.frames, frame)
+			payload.length += datagram.frame.Length(p.version)
+		}
+	}
+
 	if ack == nil && !hasData && !hasRetransmission {
 		return payload
 	}
@@ -711,6 +750,40 @@ func (p *packetPacker) PackMTUProbePacket(ping ackhandler.Frame, size protocol.B
 	}, nil
 }
 
+// PackPathProbePacket packs a 1-RTT packet that only contains the frames used for path validation.
+// The packet is sent using the connection ID for the new path,
+// and it is padded to the given size (see section 8.2.1 of RFC 9000).
+func (p *packetPacker) PackPathProbePacket(destConnID protocol.ConnectionID, frames []ackhandler.Frame, size protocol.ByteCount) (*packedPacket, error) {
+	payload := &payload{frames: frames}
+	for _, f := range frames {
+		payload.length += f.Length(p.version)
+	}
+	sealer, err := p.cryptoSetup.Get1RTTSealer()
+	if err != nil {
+		return nil, err
+	}
+	pn, pnLen := p.pnManager.PeekPacketNumber(protocol.Encryption1RTT)
+	hdr := &wire.ExtendedHeader{
+		PacketNumber:    pn,
+		PacketNumberLen: pnLen,
+		KeyPhase:        sealer.KeyPhase(),
+	}
+	hdr.DestConnectionID = destConnID
+	var padding protocol.ByteCount
+	if l := p.packetLength(hdr, payload) + protocol.ByteCount(sealer.Overhead()); l < size {
+		padding = size - l
+	}
+	buffer := getPacketBuffer()
+	contents, err := p.appendPacket(buffer, hdr, payload, padding, protocol.Encryption1RTT, sealer, false)
+	if err != nil {
+		return nil, err
+	}
+	return &packedPacket{
+		buffer:         buffer,
+		packetContents: contents,
+	}, nil
+}
+
 func (p *packetPacker) getSealerAndHeader(encLevel protocol.EncryptionLevel) (sealer, *wire.ExtendedHeader, error) {
 	switch encLevel {
 	case protocol.EncryptionInitial:
@@ -869,7 +942,17 @@ func (p *packetPacker) SetToken(token []byte) {
 	p.token = token
 }
 
+// SetVersion sets the version used in the long header.
+// It is used when switching to a compatible version.
+func (p *packetPacker) SetVersion(v protocol.VersionNumber) {
+	p.version = v
+}
+
 // When a higher MTU is discovered, use it.
+func (p *packetPacker) MaxPacketSize() protocol.ByteCount {
+	return p.maxPacketSize
+}
+
 func (p *packetPacker) SetMaxPacketSize(s protocol.ByteCount) {
 	p.maxPacketSize = s
 }
@@ -91,7 +91,7 @@ var _ = Describe("Packet packer", func() {
 		ackFramer = NewMockAckFrameSource(mockCtrl)
 		sealingManager = NewMockSealingManager(mockCtrl)
 		pnManager = mockackhandler.NewMockSentPacketHandler(mockCtrl)
-		datagramQueue = newDatagramQueue(func() {}, utils.DefaultLogger)
+		datagramQueue = newDatagramQueue(func() {}, 1, DatagramDropOldest, utils.DefaultLogger)
 
 		packer = newPacketPacker(
 			protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8},
@@ -139,6 +139,13 @@ var _ = Describe("Packet packer", func() {
 			Expect(h.Version).To(Equal(packer.version))
 		})
 
+		It("uses the version set when switching to a compatible version", func() {
+			pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionInitial).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+			packer.SetVersion(protocol.Version1)
+			h := packer.getLongHeader(protocol.EncryptionInitial)
+			Expect(h.Version).To(Equal(protocol.Version1))
+		})
+
 		It("sets source and destination connection ID", func() {
 			pnManager.EXPECT().PeekPacketNumber(protocol.EncryptionHandshake).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 			srcConnID := protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8}
@@ -472,6 +479,13 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("returns an error when the 1-RTT keys reached the confidentiality limit", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(nil, qerr.AEADLimitReached)
+				p, err := packer.PackPacket()
+				Expect(err).To(MatchError(qerr.AEADLimitReached))
+				Expect(p).To(BeNil())
+			})
+
 			It("packs single packets", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -509,6 +523,46 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.EncryptionLevel()).To(Equal(protocol.Encryption1RTT))
 			})
 
+			It("appends packets to a buffer", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil).Times(2)
+				framer.EXPECT().HasData().Return(true).Times(2)
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, false).Times(2)
+				expectAppendControlFrames()
+				f1 := &wire.StreamFrame{StreamID: 5, Data: []byte("foobar")}
+				expectAppendStreamFrames(ackhandler.Frame{Frame: f1})
+				buffer := getLargePacketBuffer()
+				p1, err := packer.AppendPacket(buffer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p1).ToNot(BeNil())
+				Expect(p1.header.PacketNumber).To(Equal(protocol.PacketNumber(0x42)))
+				Expect(buffer.Len()).To(Equal(p1.length))
+				expectAppendControlFrames()
+				f2 := &wire.StreamFrame{StreamID: 5, Data: []byte("raboof")}
+				expectAppendStreamFrames(ackhandler.Frame{Frame: f2})
+				p2, err := packer.AppendPacket(buffer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p2).ToNot(BeNil())
+				Expect(p2.header.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(buffer.Len()).To(Equal(p1.length + p2.length))
+				b := &bytes.Buffer{}
+				f1.Write(b, packer.version)
+				Expect(buffer.Data[:p1.length]).To(ContainSubstring(b.String()))
+				b.Reset()
+				f2.Write(b, packer.version)
+				Expect(buffer.Data[p1.length:]).To(ContainSubstring(b.String()))
+			})
+
+			It("refuses to append a packet if the buffer doesn't have enough capacity left", func() {
+				buffer := getLargePacketBuffer()
+				buffer.Data = buffer.Data[:cap(buffer.Data)-int(maxPacketSize)+1]
+				_, err := packer.AppendPacket(buffer)
+				Expect(err).To(MatchError("packetPacker BUG: buffer too small"))
+			})
+
 			It("packs a single ACK", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
@@ -559,6 +613,7 @@ var _ = Describe("Packet packer", func() {
 				time.Sleep(scaleDuration(20 * time.Millisecond))
 
 				framer.EXPECT().HasData()
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true)
 				p, err := packer.PackPacket()
 				Expect(p).ToNot(BeNil())
 				Expect(err).ToNot(HaveOccurred())
@@ -568,6 +623,84 @@ var _ = Describe("Packet packer", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("reports the outcome of DATAGRAM frames", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           []byte("foobar"),
+				}
+				var outcomes []DatagramOutcome
+				Expect(datagramQueue.Add(f, time.Time{}, func(o DatagramOutcome) { outcomes = append(outcomes, o) })).To(Succeed())
+				framer.EXPECT().HasData()
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true)
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.frames).To(HaveLen(1))
+				Expect(p.frames[0].Frame).To(Equal(f))
+				p.frames[0].OnLost(f)
+				Expect(outcomes).To(Equal([]DatagramOutcome{DatagramLost}))
+				// the outcome is only reported once
+				p.frames[0].OnAcked(f)
+				Expect(outcomes).To(Equal([]DatagramOutcome{DatagramLost}))
+			})
+
+			It("packs ACK frames together with DATAGRAM frames", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           []byte("foobar"),
+				}
+				Expect(datagramQueue.Add(f, time.Time{}, nil)).To(Succeed())
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 100}}}
+				framer.EXPECT().HasData()
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(ack)
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.ack).To(Equal(ack))
+				Expect(p.frames).To(HaveLen(1))
+				Expect(p.frames[0].Frame).To(Equal(f))
+			})
+
+			It("sends the ACK frame first, if the DATAGRAM frame doesn't fit into the same packet", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42))
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				ack := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 1, Largest: 100}}}
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           make([]byte, packer.maxPacketSize-protocol.ByteCount(getSealer().Overhead())-16),
+				}
+				Expect(datagramQueue.Add(f, time.Time{}, nil)).To(Succeed())
+				framer.EXPECT().HasData()
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true).Return(ack)
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.ack).To(Equal(ack))
+				Expect(p.frames).To(BeEmpty())
+				// the DATAGRAM frame is sent in the next packet
+				Expect(datagramQueue.Get(protocol.MaxByteCount, protocol.VersionTLS).frame).To(Equal(f))
+			})
+
+			It("doesn't pack DATAGRAM frames that don't fit into the packet", func() {
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				f := &wire.DatagramFrame{
+					DataLenPresent: true,
+					Data:           make([]byte, packer.maxPacketSize),
+				}
+				Expect(datagramQueue.Add(f, time.Time{}, nil)).To(Succeed())
+				framer.EXPECT().HasData()
+				ackFramer.EXPECT().GetAckFrame(protocol.Encryption1RTT, true)
+				p, err := packer.PackPacket()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p).To(BeNil())
+				Expect(datagramQueue.Get(protocol.MaxByteCount, protocol.VersionTLS).frame).To(Equal(f))
+			})
+
 			It("accounts for the space consumed by control frames", func() {
 				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x42), protocol.PacketNumberLen2)
 				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
@@ -619,7 +752,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false, packer.version)
+				frameParser := wire.NewFrameParser(false, false, false, packer.version)
 				frame, err := frameParser.ParseNext(r, protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -656,7 +789,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(firstPayloadByte).To(Equal(byte(0)))
 				// ... followed by the STREAM frame
-				frameParser := wire.NewFrameParser(true, packer.version)
+				frameParser := wire.NewFrameParser(true, true, true, packer.version)
 				frame, err := frameParser.ParseNext(r, protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.StreamFrame{}))
@@ -1209,7 +1342,7 @@ var _ = Describe("Packet packer", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(secondPayloadByte).To(Equal(byte(0)))
 				// ... followed by the PING
-				frameParser := wire.NewFrameParser(false, packer.version)
+				frameParser := wire.NewFrameParser(false, false, false, packer.version)
 				frame, err := frameParser.ParseNext(r, protocol.Encryption1RTT)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(frame).To(BeAssignableToTypeOf(&wire.PingFrame{}))
@@ -1482,6 +1615,23 @@ var _ = Describe("Packet packer", func() {
 				Expect(p.buffer.Data).To(HaveLen(int(probePacketSize)))
 				Expect(p.packetContents.isMTUProbePacket).To(BeTrue())
 			})
+
+			It("packs a path probe packet", func() {
+				sealingManager.EXPECT().Get1RTTSealer().Return(getSealer(), nil)
+				pnManager.EXPECT().PeekPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43), protocol.PacketNumberLen2)
+				pnManager.EXPECT().PopPacketNumber(protocol.Encryption1RTT).Return(protocol.PacketNumber(0x43))
+				connID := protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}
+				challenge := ackhandler.Frame{Frame: &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+				p, err := packer.PackPathProbePacket(connID, []ackhandler.Frame{challenge}, protocol.MinInitialPacketSize)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(p.header.IsLongHeader).To(BeFalse())
+				Expect(p.header.DestConnectionID).To(Equal(connID))
+				Expect(p.header.PacketNumber).To(Equal(protocol.PacketNumber(0x43)))
+				Expect(p.EncryptionLevel()).To(Equal(protocol.Encryption1RTT))
+				Expect(p.frames).To(Equal([]ackhandler.Frame{challenge}))
+				Expect(p.buffer.Data).To(HaveLen(protocol.MinInitialPacketSize))
+				Expect(p.packetContents.isMTUProbePacket).To(BeFalse())
+			})
 		})
 	})
 })
@@ -0,0 +1,92 @@
+package quic
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// The PTO used for a new path, for which we don't have an RTT estimate yet.
+// With an RTT of kInitialRtt, the RTT variation is half the RTT, and the PTO is three times the RTT
+// (see section 6.2.2 of RFC 9002).
+const pathValidationInitialPTO = 3 * protocol.DefaultInitialRTT
+
+// A pathValidator validates a network path, see section 8.2 of RFC 9000.
+// It sends a PATH_CHALLENGE every PTO, until a matching PATH_RESPONSE is received,
+// or until the validation times out.
+type pathValidator struct {
+	conn       sendConn
+	destConnID protocol.ConnectionID
+	// PATH_CHALLENGEs are padded to this size.
+	// This is smaller than 1200 bytes if the anti-amplification limit doesn't allow sending a full-size datagram.
+	datagramSize protocol.ByteCount
+
+	pto               time.Duration
+	deadline          time.Time
+	lastChallengeTime time.Time
+	challenges        [][8]byte
+}
+
+func newPathValidator(
+	conn sendConn,
+	destConnID protocol.ConnectionID,
+	datagramSize protocol.ByteCount,
+	rttStats *utils.RTTStats,
+	now time.Time,
+) *pathValidator {
+	// Use the larger of the current PTO and the PTO for the new path, see section 8.2.4 of RFC 9000.
+	pto := utils.MaxDuration(rttStats.PTO(true), pathValidationInitialPTO)
+	return &pathValidator{
+		conn:         conn,
+		destConnID:   destConnID,
+		datagramSize: datagramSize,
+		pto:          pto,
+		// Use three times the PTO as the timeout, see section 8.2.4 of RFC 9000.
+		deadline: now.Add(3 * pto),
+	}
+}
+
+// ShouldSendChallenge says if a new PATH_CHALLENGE should be sent.
+func (v *pathValidator) ShouldSendChallenge(now time.Time) bool {
+	return !now.Before(v.NextChallengeTime()) && !v.TimedOut(now)
+}
+
+// NextChallengeTime returns the time when the next PATH_CHALLENGE should be sent.
+func (v *pathValidator) NextChallengeTime() time.Time {
+	if v.lastChallengeTime.IsZero() {
+		return v.lastChallengeTime
+	}
+	return v.lastChallengeTime.Add(v.pto)
+}
+
+// Deadline is the time when the path validation times out.
+func (v *pathValidator) Deadline() time.Time {
+	return v.deadline
+}
+
+// TimedOut says if the path validation failed.
+func (v *pathValidator) TimedOut(now time.Time) bool {
+	return !now.Before(v.deadline)
+}
+
+// GetChallenge returns a new PATH_CHALLENGE frame.
+func (v *pathValidator) GetChallenge(now time.Time) *wire.PathChallengeFrame {
+	var data [8]byte
+	rand.Read(data[:])
+	v.challenges = append(v.challenges, data)
+	v.lastChallengeTime = now
+	return &wire.PathChallengeFrame{Data: data}
+}
+
+// HandlePathResponse says if the PATH_RESPONSE matches one of the PATH_CHALLENGEs sent on this path.
+func (v *pathValidator) HandlePathResponse(f *wire.PathResponseFrame) bool {
+	for _, c := range v.challenges {
+		if c == f.Data {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Path Validator", func() {
+	var (
+		v        *pathValidator
+		rttStats *utils.RTTStats
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		rttStats = &utils.RTTStats{}
+		now = time.Now()
+		v = newPathValidator(nil, protocol.ConnectionID{1, 2, 3, 4}, 1200, rttStats, now)
+	})
+
+	It("sends the first PATH_CHALLENGE immediately", func() {
+		Expect(v.NextChallengeTime()).To(BeZero())
+		Expect(v.ShouldSendChallenge(now)).To(BeTrue())
+	})
+
+	It("sends a new PATH_CHALLENGE every PTO", func() {
+		f1 := v.GetChallenge(now)
+		Expect(v.ShouldSendChallenge(now)).To(BeFalse())
+		Expect(v.NextChallengeTime()).To(Equal(now.Add(pathValidationInitialPTO)))
+		Expect(v.ShouldSendChallenge(v.NextChallengeTime())).To(BeTrue())
+		f2 := v.GetChallenge(v.NextChallengeTime())
+		Expect(f1.Data).ToNot(Equal(f2.Data))
+	})
+
+	It("uses the PTO of the connection, if it's larger than the initial PTO", func() {
+		rttStats.UpdateRTT(time.Second, 0, time.Time{})
+		v = newPathValidator(nil, protocol.ConnectionID{1, 2, 3, 4}, 1200, rttStats, now)
+		v.GetChallenge(now)
+		Expect(v.NextChallengeTime()).To(Equal(now.Add(rttStats.PTO(true))))
+	})
+
+	It("times out after three PTOs", func() {
+		Expect(v.Deadline()).To(Equal(now.Add(3 * pathValidationInitialPTO)))
+		Expect(v.TimedOut(v.Deadline().Add(-time.Nanosecond))).To(BeFalse())
+		Expect(v.TimedOut(v.Deadline())).To(BeTrue())
+		Expect(v.ShouldSendChallenge(v.Deadline())).To(BeFalse())
+	})
+
+	It("accepts PATH_RESPONSEs for all PATH_CHALLENGEs sent", func() {
+		f1 := v.GetChallenge(now)
+		f2 := v.GetChallenge(now.Add(pathValidationInitialPTO))
+		Expect(v.HandlePathResponse(&wire.PathResponseFrame{Data: f1.Data})).To(BeTrue())
+		Expect(v.HandlePathResponse(&wire.PathResponseFrame{Data: f2.Data})).To(BeTrue())
+		Expect(v.HandlePathResponse(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})).To(BeFalse())
+	})
+})
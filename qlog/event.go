@@ -197,6 +197,26 @@ func (e eventVersionNegotiationReceived) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.ArrayKey("supported_versions", versions(e.SupportedVersions))
 }
 
+type eventVersionInformation struct {
+	ChosenVersion  versionNumber
+	ClientVersions versions
+	ServerVersions versions
+}
+
+func (e eventVersionInformation) Category() category { return categoryTransport }
+func (e eventVersionInformation) Name() string       { return "version_information" }
+func (e eventVersionInformation) IsNil() bool        { return false }
+
+func (e eventVersionInformation) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("chosen_version", e.ChosenVersion.String())
+	if e.ClientVersions != nil {
+		enc.ArrayKey("client_versions", e.ClientVersions)
+	}
+	if e.ServerVersions != nil {
+		enc.ArrayKey("server_versions", e.ServerVersions)
+	}
+}
+
 type eventPacketBuffered struct {
 	PacketType logging.PacketType
 }
@@ -340,6 +360,40 @@ func (e eventKeyRetired) MarshalJSONObject(enc *gojay.Encoder) {
 	}
 }
 
+type eventAEADLimitApproached struct {
+	Limit      aeadLimit
+	Generation protocol.KeyPhase
+	Count      uint64
+	Max        uint64
+}
+
+func (e eventAEADLimitApproached) Category() category { return categorySecurity }
+func (e eventAEADLimitApproached) Name() string       { return "aead_limit_approached" }
+func (e eventAEADLimitApproached) IsNil() bool        { return false }
+
+func (e eventAEADLimitApproached) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("limit", e.Limit.String())
+	enc.Uint64Key("generation", uint64(e.Generation))
+	enc.Uint64Key("count", e.Count)
+	enc.Uint64Key("max", e.Max)
+}
+
+type eventAEADLimitReached struct {
+	Limit      aeadLimit
+	Generation protocol.KeyPhase
+	Count      uint64
+}
+
+func (e eventAEADLimitReached) Category() category { return categorySecurity }
+func (e eventAEADLimitReached) Name() string       { return "aead_limit_reached" }
+func (e eventAEADLimitReached) IsNil() bool        { return false }
+
+func (e eventAEADLimitReached) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("limit", e.Limit.String())
+	enc.Uint64Key("generation", uint64(e.Generation))
+	enc.Uint64Key("count", e.Count)
+}
+
 type eventTransportParameters struct {
 	Restore bool
 	Owner   owner
@@ -367,6 +421,12 @@ type eventTransportParameters struct {
 	PreferredAddress *preferredAddress
 
 	MaxDatagramFrameSize protocol.ByteCount
+
+	MinAckDelay *time.Duration
+
+	EnableResetStreamAt bool
+
+	VersionInformation *versionInformation
 }
 
 func (e eventTransportParameters) Category() category { return categoryTransport }
@@ -412,6 +472,15 @@ func (e eventTransportParameters) MarshalJSONObject(enc *gojay.Encoder) {
 	if e.MaxDatagramFrameSize != protocol.InvalidByteCount {
 		enc.Int64Key("max_datagram_frame_size", int64(e.MaxDatagramFrameSize))
 	}
+	if e.MinAckDelay != nil {
+		enc.FloatKey("min_ack_delay", milliseconds(*e.MinAckDelay))
+	}
+	if e.EnableResetStreamAt {
+		enc.BoolKey("reset_stream_at", true)
+	}
+	if e.VersionInformation != nil {
+		enc.ObjectKey("version_information", e.VersionInformation)
+	}
 }
 
 type preferredAddress struct {
@@ -433,6 +502,19 @@ func (a preferredAddress) MarshalJSONObject(enc *gojay.Encoder) {
 	enc.StringKey("stateless_reset_token", fmt.Sprintf("%x", a.StatelessResetToken))
 }
 
+type versionInformation struct {
+	ChosenVersion     versionNumber
+	AvailableVersions versions
+}
+
+var _ gojay.MarshalerJSONObject = &versionInformation{}
+
+func (i versionInformation) IsNil() bool { return false }
+func (i versionInformation) MarshalJSONObject(enc *gojay.Encoder) {
+	enc.StringKey("chosen_version", i.ChosenVersion.String())
+	enc.ArrayKey("available_versions", i.AvailableVersions)
+}
+
 type eventLossTimerSet struct {
 	TimerType timerType
 	EncLevel  protocol.EncryptionLevel
@@ -114,6 +114,24 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals RESET_STREAM_AT frames", func() {
+		check(
+			&logging.ResetStreamAtFrame{
+				StreamID:     987,
+				ErrorCode:    42,
+				FinalSize:    1234,
+				ReliableSize: 100,
+			},
+			map[string]interface{}{
+				"frame_type":    "reset_stream_at",
+				"stream_id":     987,
+				"error_code":    42,
+				"final_size":    1234,
+				"reliable_size": 100,
+			},
+		)
+	})
+
 	It("marshals STOP_SENDING frames", func() {
 		check(
 			&logging.StopSendingFrame{
@@ -365,6 +383,33 @@ var _ = Describe("Frames", func() {
 		)
 	})
 
+	It("marshals ACK_FREQUENCY frames", func() {
+		check(
+			&logging.AckFrequencyFrame{
+				SequenceNumber:        42,
+				AckElicitingThreshold: 10,
+				RequestMaxAckDelay:    25 * time.Millisecond,
+				ReorderingThreshold:   1,
+			},
+			map[string]interface{}{
+				"frame_type":              "ack_frequency",
+				"sequence_number":         42,
+				"ack_eliciting_threshold": 10,
+				"request_max_ack_delay":   25,
+				"reordering_threshold":    1,
+			},
+		)
+	})
+
+	It("marshals IMMEDIATE_ACK frames", func() {
+		check(
+			&logging.ImmediateAckFrame{},
+			map[string]interface{}{
+				"frame_type": "immediate_ack",
+			},
+		)
+	})
+
 	It("marshals DATAGRAM frames", func() {
 		check(
 			&logging.DatagramFrame{Length: 1337},
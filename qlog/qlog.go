@@ -69,6 +69,7 @@ func (t *tracer) TracerForConnection(p logging.Perspective, odcid protocol.Conne
 func (t *tracer) SentPacket(net.Addr, *logging.Header, protocol.ByteCount, []logging.Frame) {}
 func (t *tracer) DroppedPacket(net.Addr, logging.PacketType, protocol.ByteCount, logging.PacketDropReason) {
 }
+func (t *tracer) UpdatedHandshakeMetrics(logging.HandshakeMetrics) {}
 
 type connectionTracer struct {
 	mutex sync.Mutex
@@ -230,6 +231,16 @@ func (t *connectionTracer) toTransportParameters(tp *wire.TransportParameters) *
 			StatelessResetToken: tp.PreferredAddress.StatelessResetToken,
 		}
 	}
+	var vi *versionInformation
+	if tp.VersionInformation != nil {
+		vi = &versionInformation{
+			ChosenVersion:     versionNumber(tp.VersionInformation.ChosenVersion),
+			AvailableVersions: make(versions, len(tp.VersionInformation.AvailableVersions)),
+		}
+		for i, v := range tp.VersionInformation.AvailableVersions {
+			vi.AvailableVersions[i] = versionNumber(v)
+		}
+	}
 	return &eventTransportParameters{
 		OriginalDestinationConnectionID: tp.OriginalDestinationConnectionID,
 		InitialSourceConnectionID:       tp.InitialSourceConnectionID,
@@ -249,6 +260,9 @@ func (t *connectionTracer) toTransportParameters(tp *wire.TransportParameters) *
 		InitialMaxStreamsUni:            int64(tp.MaxUniStreamNum),
 		PreferredAddress:                pa,
 		MaxDatagramFrameSize:            tp.MaxDatagramFrameSize,
+		MinAckDelay:                     tp.MinAckDelay,
+		EnableResetStreamAt:             tp.EnableResetStreamAt,
+		VersionInformation:              vi,
 	}
 }
 
@@ -291,6 +305,26 @@ func (t *connectionTracer) ReceivedPacket(hdr *wire.ExtendedHeader, packetSize l
 	t.mutex.Unlock()
 }
 
+func (t *connectionTracer) NegotiatedVersion(chosen logging.VersionNumber, clientVersions, serverVersions []logging.VersionNumber) {
+	toVersions := func(vs []logging.VersionNumber) versions {
+		if vs == nil {
+			return nil
+		}
+		ver := make(versions, len(vs))
+		for i, v := range vs {
+			ver[i] = versionNumber(v)
+		}
+		return ver
+	}
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &eventVersionInformation{
+		ChosenVersion:  versionNumber(chosen),
+		ClientVersions: toVersions(clientVersions),
+		ServerVersions: toVersions(serverVersions),
+	})
+	t.mutex.Unlock()
+}
+
 func (t *connectionTracer) ReceivedRetry(hdr *wire.Header) {
 	t.mutex.Lock()
 	t.recordEvent(time.Now(), &eventRetryReceived{
@@ -426,6 +460,27 @@ func (t *connectionTracer) DroppedKey(generation protocol.KeyPhase) {
 	t.mutex.Unlock()
 }
 
+func (t *connectionTracer) ApproachingAEADLimit(limit logging.AEADLimit, generation protocol.KeyPhase, count, max uint64) {
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &eventAEADLimitApproached{
+		Limit:      aeadLimit(limit),
+		Generation: generation,
+		Count:      count,
+		Max:        max,
+	})
+	t.mutex.Unlock()
+}
+
+func (t *connectionTracer) ReachedAEADLimit(limit logging.AEADLimit, generation protocol.KeyPhase, count uint64) {
+	t.mutex.Lock()
+	t.recordEvent(time.Now(), &eventAEADLimitReached{
+		Limit:      aeadLimit(limit),
+		Generation: generation,
+		Count:      count,
+	})
+	t.mutex.Unlock()
+}
+
 func (t *connectionTracer) SetLossTimer(tt logging.TimerType, encLevel protocol.EncryptionLevel, timeout time.Time) {
 	t.mutex.Lock()
 	now := time.Now()
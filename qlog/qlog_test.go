@@ -277,6 +277,7 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("initial_max_streams_uni", float64(20)))
 				Expect(ev).ToNot(HaveKey("preferred_address"))
 				Expect(ev).ToNot(HaveKey("max_datagram_frame_size"))
+				Expect(ev).ToNot(HaveKey("version_information"))
 			})
 
 			It("records the server's transport parameters, without a stateless reset token", func() {
@@ -329,6 +330,22 @@ var _ = Describe("Tracing", func() {
 				Expect(pa).To(HaveKeyWithValue("stateless_reset_token", "0f0e0d0c0b0a09080706050403020100"))
 			})
 
+			It("records transport parameters with version information", func() {
+				tracer.SentTransportParameters(&logging.TransportParameters{
+					VersionInformation: &logging.VersionInformation{
+						ChosenVersion:     protocol.Version1,
+						AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft29},
+					},
+				})
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("transport:parameters_set"))
+				ev := entry.Event
+				Expect(ev).To(HaveKey("version_information"))
+				vi := ev["version_information"].(map[string]interface{})
+				Expect(vi).To(HaveKeyWithValue("chosen_version", "1"))
+				Expect(vi).To(HaveKeyWithValue("available_versions", []interface{}{"1", "ff00001d"}))
+			})
+
 			It("records transport parameters that enable the datagram extension", func() {
 				tracer.SentTransportParameters(&logging.TransportParameters{
 					MaxDatagramFrameSize: 1337,
@@ -340,6 +357,27 @@ var _ = Describe("Tracing", func() {
 				Expect(ev).To(HaveKeyWithValue("max_datagram_frame_size", float64(1337)))
 			})
 
+			It("records transport parameters that enable the ACK frequency extension", func() {
+				minAckDelay := 1500 * time.Microsecond
+				tracer.SentTransportParameters(&logging.TransportParameters{
+					MinAckDelay:          &minAckDelay,
+					MaxDatagramFrameSize: protocol.InvalidByteCount,
+				})
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("transport:parameters_set"))
+				Expect(entry.Event).To(HaveKeyWithValue("min_ack_delay", 1.5))
+			})
+
+			It("records transport parameters that enable RESET_STREAM_AT", func() {
+				tracer.SentTransportParameters(&logging.TransportParameters{
+					EnableResetStreamAt:  true,
+					MaxDatagramFrameSize: protocol.InvalidByteCount,
+				})
+				entry := exportAndParseSingle()
+				Expect(entry.Name).To(Equal("transport:parameters_set"))
+				Expect(entry.Event).To(HaveKeyWithValue("reset_stream_at", true))
+			})
+
 			It("records received transport parameters", func() {
 				tracer.ReceivedTransportParameters(&logging.TransportParameters{})
 				entry := exportAndParseSingle()
@@ -535,6 +573,17 @@ var _ = Describe("Tracing", func() {
 				Expect(header).To(HaveKey("scid"))
 			})
 
+			It("records the negotiated version", func() {
+				tracer.NegotiatedVersion(0xdecafbad, []logging.VersionNumber{0xdecafbad, 0xdeadbeef}, nil)
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("transport:version_information"))
+				ev := entry.Event
+				Expect(ev).To(HaveKeyWithValue("chosen_version", "decafbad"))
+				Expect(ev["client_versions"].([]interface{})).To(Equal([]interface{}{"decafbad", "deadbeef"}))
+				Expect(ev).ToNot(HaveKey("server_versions"))
+			})
+
 			It("records buffered packets", func() {
 				tracer.BufferedPacket(logging.PacketTypeHandshake)
 				entry := exportAndParseSingle()
@@ -760,6 +809,29 @@ var _ = Describe("Tracing", func() {
 				Expect(keyTypes).To(ContainElement("client_1rtt_secret"))
 			})
 
+			It("records when an AEAD limit is approached", func() {
+				tracer.ApproachingAEADLimit(logging.AEADLimitConfidentiality, 3, 6000000, 8388608)
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("security:aead_limit_approached"))
+				ev := entry.Event
+				Expect(ev).To(HaveKeyWithValue("limit", "confidentiality"))
+				Expect(ev).To(HaveKeyWithValue("generation", float64(3)))
+				Expect(ev).To(HaveKeyWithValue("count", float64(6000000)))
+				Expect(ev).To(HaveKeyWithValue("max", float64(8388608)))
+			})
+
+			It("records when an AEAD limit is reached", func() {
+				tracer.ReachedAEADLimit(logging.AEADLimitIntegrity, 2, 16777216)
+				entry := exportAndParseSingle()
+				Expect(entry.Time).To(BeTemporally("~", time.Now(), scaleDuration(10*time.Millisecond)))
+				Expect(entry.Name).To(Equal("security:aead_limit_reached"))
+				ev := entry.Event
+				Expect(ev).To(HaveKeyWithValue("limit", "integrity"))
+				Expect(ev).To(HaveKeyWithValue("generation", float64(2)))
+				Expect(ev).To(HaveKeyWithValue("count", float64(16777216)))
+			})
+
 			It("records when the timer is set", func() {
 				timeout := time.Now().Add(137 * time.Millisecond)
 				tracer.SetLossTimer(logging.TimerTypePTO, protocol.EncryptionHandshake, timeout)
@@ -215,6 +215,8 @@ func (e transportError) String() string {
 		return "aead_limit_reached"
 	case qerr.NoViablePathError:
 		return "no_viable_path"
+	case qerr.VersionNegotiationError:
+		return "version_negotiation_error"
 	default:
 		return ""
 	}
@@ -302,6 +304,19 @@ func (t timerType) String() string {
 	}
 }
 
+type aeadLimit logging.AEADLimit
+
+func (l aeadLimit) String() string {
+	switch logging.AEADLimit(l) {
+	case logging.AEADLimitConfidentiality:
+		return "confidentiality"
+	case logging.AEADLimitIntegrity:
+		return "integrity"
+	default:
+		return "unknown AEAD limit"
+	}
+}
+
 type timeoutReason logging.TimeoutReason
 
 func (r timeoutReason) String() string {
@@ -327,6 +342,20 @@ func (s congestionState) String() string {
 		return "recovery"
 	case logging.CongestionStateApplicationLimited:
 		return "application_limited"
+	case logging.CongestionStateStartup:
+		return "startup"
+	case logging.CongestionStateDrain:
+		return "drain"
+	case logging.CongestionStateProbeBandwidthDown:
+		return "probe_bw_down"
+	case logging.CongestionStateProbeBandwidthCruise:
+		return "probe_bw_cruise"
+	case logging.CongestionStateProbeBandwidthRefill:
+		return "probe_bw_refill"
+	case logging.CongestionStateProbeBandwidthUp:
+		return "probe_bw_up"
+	case logging.CongestionStateProbeRTT:
+		return "probe_rtt"
 	default:
 		return "unknown congestion state"
 	}
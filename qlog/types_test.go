@@ -58,6 +58,11 @@ var _ = Describe("Types", func() {
 		Expect(timerType(logging.TimerTypePTO).String()).To(Equal("pto"))
 	})
 
+	It("has a string representation for the AEAD limit", func() {
+		Expect(aeadLimit(logging.AEADLimitConfidentiality).String()).To(Equal("confidentiality"))
+		Expect(aeadLimit(logging.AEADLimitIntegrity).String()).To(Equal("integrity"))
+	})
+
 	It("has a string representation for the close reason", func() {
 		Expect(timeoutReason(logging.TimeoutReasonHandshake).String()).To(Equal("handshake_timeout"))
 		Expect(timeoutReason(logging.TimeoutReasonIdle).String()).To(Equal("idle_timeout"))
@@ -122,6 +127,7 @@ var _ = Describe("Types", func() {
 			Expect(transportError(qerr.ApplicationError).String()).To(Equal("application_error"))
 			Expect(transportError(qerr.CryptoBufferExceeded).String()).To(Equal("crypto_buffer_exceeded"))
 			Expect(transportError(qerr.NoViablePathError).String()).To(Equal("no_viable_path"))
+			Expect(transportError(qerr.VersionNegotiationError).String()).To(Equal("version_negotiation_error"))
 			Expect(transportError(1337).String()).To(BeEmpty())
 		})
 	})
@@ -131,5 +137,12 @@ var _ = Describe("Types", func() {
 		Expect(congestionState(logging.CongestionStateCongestionAvoidance).String()).To(Equal("congestion_avoidance"))
 		Expect(congestionState(logging.CongestionStateApplicationLimited).String()).To(Equal("application_limited"))
 		Expect(congestionState(logging.CongestionStateRecovery).String()).To(Equal("recovery"))
+		Expect(congestionState(logging.CongestionStateStartup).String()).To(Equal("startup"))
+		Expect(congestionState(logging.CongestionStateDrain).String()).To(Equal("drain"))
+		Expect(congestionState(logging.CongestionStateProbeBandwidthDown).String()).To(Equal("probe_bw_down"))
+		Expect(congestionState(logging.CongestionStateProbeBandwidthCruise).String()).To(Equal("probe_bw_cruise"))
+		Expect(congestionState(logging.CongestionStateProbeBandwidthRefill).String()).To(Equal("probe_bw_refill"))
+		Expect(congestionState(logging.CongestionStateProbeBandwidthUp).String()).To(Equal("probe_bw_up"))
+		Expect(congestionState(logging.CongestionStateProbeRTT).String()).To(Equal("probe_rtt"))
 	})
 })
@@ -0,0 +1,136 @@
+// Package quiclb implements QUIC-LB compatible connection IDs, following draft-ietf-quic-load-balancers-06.
+// Servers encode their server ID into the connection IDs they issue using a Generator.
+// Load balancers use a Decoder to extract the server ID from the connection ID of a packet,
+// so they can route all packets of a connection to the same server, even after the client's address changed.
+// This package should not be considered stable
+package quiclb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The Mode determines how the server ID is encoded into the connection ID.
+type Mode uint8
+
+const (
+	// ModePlaintext encodes the server ID in plaintext.
+	// The server ID is followed by NonceLen random bytes.
+	ModePlaintext Mode = iota
+	// ModeStreamCipher encrypts the server ID by XORing it with the AES-128 encryption of a random nonce.
+	// The connection ID consists of the first octet, the nonce and the encrypted server ID.
+	ModeStreamCipher
+	// ModeBlockCipher encrypts a single 16 byte block consisting of the server ID,
+	// ZeroPaddingLen zero bytes and random bytes, using AES-128.
+	// Connection IDs are always 17 bytes long.
+	ModeBlockCipher
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModePlaintext:
+		return "plaintext"
+	case ModeStreamCipher:
+		return "stream cipher"
+	case ModeBlockCipher:
+		return "block cipher"
+	default:
+		return fmt.Sprintf("unknown mode: %d", uint8(m))
+	}
+}
+
+const (
+	// MaxConfigRotation is the largest config rotation codepoint.
+	// The codepoint 0b11 is reserved for unroutable connection IDs.
+	MaxConfigRotation = 2
+
+	keyLen             = 16
+	blockLen           = 16
+	minPlaintextNonce  = 4
+	minStreamNonce     = 8
+	maxStreamNonce     = 16
+	minBlockServerUse  = 4
+	maxConnectionIDLen = 20
+)
+
+// ErrUnroutable is returned by the Decoder if a connection ID cannot be decoded.
+var ErrUnroutable = errors.New("quiclb: unroutable connection ID")
+
+// A Config is a QUIC-LB configuration.
+// The load balancer and all servers behind it must use the same configuration.
+type Config struct {
+	// ConfigRotation is encoded in the two most significant bits of the first octet of every connection ID.
+	// It allows multiple configurations to be used at the same time, e.g. while rotating keys.
+	// It must be between 0 and MaxConfigRotation.
+	ConfigRotation uint8
+	Mode           Mode
+	// ServerIDLen is the length of the server ID in bytes.
+	ServerIDLen int
+	// NonceLen is the number of random bytes following the server ID (plaintext mode, at least 4),
+	// or the length of the nonce (stream cipher mode, between 8 and 16).
+	// It is not used in block cipher mode.
+	NonceLen int
+	// ZeroPaddingLen is the number of zero bytes following the server ID (block cipher mode only).
+	// They are verified when decoding, which allows the load balancer to detect connection IDs
+	// that weren't generated using this configuration.
+	ZeroPaddingLen int
+	// Key is the 16 byte AES-128 key used in stream cipher and block cipher mode.
+	Key []byte
+	// If SelfEncodeLength is set, the length of the connection ID is encoded in the first octet.
+	// Otherwise, the remaining bits of the first octet are random.
+	SelfEncodeLength bool
+}
+
+// ConnectionIDLen returns the length of the connection IDs generated using this configuration.
+func (c *Config) ConnectionIDLen() int {
+	switch c.Mode {
+	case ModeStreamCipher:
+		return 1 + c.NonceLen + c.ServerIDLen
+	case ModeBlockCipher:
+		return 1 + blockLen
+	default:
+		return 1 + c.ServerIDLen + c.NonceLen
+	}
+}
+
+func (c *Config) validate() error {
+	if c.ConfigRotation > MaxConfigRotation {
+		return fmt.Errorf("quiclb: invalid config rotation codepoint: %d", c.ConfigRotation)
+	}
+	if c.ServerIDLen < 1 {
+		return errors.New("quiclb: server ID too short")
+	}
+	switch c.Mode {
+	case ModePlaintext:
+		if c.NonceLen < minPlaintextNonce {
+			return fmt.Errorf("quiclb: nonce must be at least %d bytes long", minPlaintextNonce)
+		}
+	case ModeStreamCipher:
+		if c.NonceLen < minStreamNonce || c.NonceLen > maxStreamNonce {
+			return fmt.Errorf("quiclb: nonce must be between %d and %d bytes long", minStreamNonce, maxStreamNonce)
+		}
+	case ModeBlockCipher:
+		if c.ServerIDLen+c.ZeroPaddingLen > blockLen-minBlockServerUse {
+			return fmt.Errorf("quiclb: server ID and zero padding must not be longer than %d bytes", blockLen-minBlockServerUse)
+		}
+	default:
+		return fmt.Errorf("quiclb: %s", c.Mode)
+	}
+	if c.Mode != ModePlaintext && len(c.Key) != keyLen {
+		return fmt.Errorf("quiclb: key must be %d bytes long", keyLen)
+	}
+	if l := c.ConnectionIDLen(); l > maxConnectionIDLen {
+		return fmt.Errorf("quiclb: connection ID too long: %d bytes", l)
+	}
+	return nil
+}
+
+// firstOctet calculates the first octet of a connection ID.
+// r is a random byte, used for the bits not used by the config rotation and the length.
+func (c *Config) firstOctet(r byte) byte {
+	b := c.ConfigRotation << 6
+	if c.SelfEncodeLength {
+		return b | byte(c.ConnectionIDLen()-1)
+	}
+	return b | r&0x3f
+}
@@ -0,0 +1,100 @@
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
+type decoderConfig struct {
+	Config
+	block cipher.Block // nil in plaintext mode
+}
+
+// A Decoder extracts the server ID from connection IDs.
+// It is used by the load balancer.
+// A Decoder is safe for concurrent use.
+type Decoder struct {
+	configs [MaxConfigRotation + 1]*decoderConfig
+}
+
+// NewDecoder creates a new Decoder.
+// Multiple configs can be used, as long as they use different config rotation codepoints.
+func NewDecoder(configs ...*Config) (*Decoder, error) {
+	d := &Decoder{}
+	for _, c := range configs {
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		if d.configs[c.ConfigRotation] != nil {
+			return nil, fmt.Errorf("quiclb: duplicate config rotation codepoint: %d", c.ConfigRotation)
+		}
+		dc := &decoderConfig{Config: *c}
+		if c.Mode != ModePlaintext {
+			block, err := aes.NewCipher(c.Key)
+			if err != nil {
+				return nil, err
+			}
+			dc.block = block
+		}
+		d.configs[c.ConfigRotation] = dc
+	}
+	return d, nil
+}
+
+func (d *Decoder) getConfig(firstOctet byte) (*decoderConfig, error) {
+	cr := firstOctet >> 6
+	if cr > MaxConfigRotation || d.configs[cr] == nil {
+		return nil, ErrUnroutable
+	}
+	return d.configs[cr], nil
+}
+
+// ConnectionIDLen returns the length of a connection ID, based on its first octet.
+// This is useful to determine the length of the connection ID of a short header packet.
+func (d *Decoder) ConnectionIDLen(firstOctet byte) (int, error) {
+	c, err := d.getConfig(firstOctet)
+	if err != nil {
+		return 0, err
+	}
+	return c.ConnectionIDLen(), nil
+}
+
+// ServerID decodes the server ID from a connection ID.
+// The connection ID may be followed by additional bytes, which are ignored.
+// This allows passing the remainder of a short header packet following the first byte.
+// It returns ErrUnroutable if the connection ID wasn't generated by any of the configs.
+func (d *Decoder) ServerID(connID []byte) ([]byte, error) {
+	if len(connID) == 0 {
+		return nil, ErrUnroutable
+	}
+	c, err := d.getConfig(connID[0])
+	if err != nil {
+		return nil, err
+	}
+	l := c.ConnectionIDLen()
+	if len(connID) < l {
+		return nil, ErrUnroutable
+	}
+	if c.SelfEncodeLength && int(connID[0]&0x3f) != l-1 {
+		return nil, ErrUnroutable
+	}
+	serverID := make([]byte, c.ServerIDLen)
+	switch c.Mode {
+	case ModePlaintext:
+		copy(serverID, connID[1:])
+	case ModeStreamCipher:
+		nonce := connID[1 : 1+c.NonceLen]
+		encryptStream(c.block, nonce, serverID, connID[1+c.NonceLen:l])
+	case ModeBlockCipher:
+		var block [blockLen]byte
+		c.block.Decrypt(block[:], connID[1:l])
+		for _, b := range block[c.ServerIDLen : c.ServerIDLen+c.ZeroPaddingLen] {
+			if b != 0 {
+				return nil, ErrUnroutable
+			}
+		}
+		copy(serverID, block[:])
+	}
+	return serverID, nil
+}
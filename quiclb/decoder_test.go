@@ -0,0 +1,138 @@
+package quiclb
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Decoder", func() {
+	key := bytes.Repeat([]byte{0x42}, 16)
+	serverID := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	for _, c := range []*Config{
+		{ServerIDLen: 4, NonceLen: 4},
+		{ServerIDLen: 4, NonceLen: 8, SelfEncodeLength: true},
+		{Mode: ModeStreamCipher, ServerIDLen: 4, NonceLen: 8, Key: key},
+		{Mode: ModeStreamCipher, ServerIDLen: 4, NonceLen: 12, Key: key, SelfEncodeLength: true},
+		{Mode: ModeBlockCipher, ServerIDLen: 4, ZeroPaddingLen: 4, Key: key},
+		{Mode: ModeBlockCipher, ServerIDLen: 4, Key: key, SelfEncodeLength: true},
+	} {
+		config := c
+
+		It("decodes the server ID, in "+config.Mode.String()+" mode", func() {
+			g, err := NewGenerator(config, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			d, err := NewDecoder(config)
+			Expect(err).ToNot(HaveOccurred())
+			for i := 0; i < 100; i++ {
+				connID, err := g.GenerateConnectionID()
+				Expect(err).ToNot(HaveOccurred())
+				l, err := d.ConnectionIDLen(connID[0])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(l).To(Equal(connID.Len()))
+				id, err := d.ServerID(connID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(id).To(Equal(serverID))
+			}
+		})
+	}
+
+	It("ignores bytes following the connection ID", func() {
+		config := &Config{Mode: ModeStreamCipher, ServerIDLen: 4, NonceLen: 8, Key: key}
+		g, err := NewGenerator(config, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		d, err := NewDecoder(config)
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		id, err := d.ServerID(append(connID, []byte("foobar")...))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal(serverID))
+	})
+
+	It("uses the config rotation codepoint to select the config", func() {
+		key2 := bytes.Repeat([]byte{0x13}, 16)
+		config1 := &Config{ConfigRotation: 0, Mode: ModeBlockCipher, ServerIDLen: 4, ZeroPaddingLen: 4, Key: key}
+		config2 := &Config{ConfigRotation: 1, Mode: ModeBlockCipher, ServerIDLen: 4, ZeroPaddingLen: 4, Key: key2}
+		g1, err := NewGenerator(config1, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		g2, err := NewGenerator(config2, []byte{1, 2, 3, 4})
+		Expect(err).ToNot(HaveOccurred())
+		d, err := NewDecoder(config1, config2)
+		Expect(err).ToNot(HaveOccurred())
+		c1, err := g1.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		c2, err := g2.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		id, err := d.ServerID(c1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal(serverID))
+		id, err = d.ServerID(c2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(id).To(Equal([]byte{1, 2, 3, 4}))
+	})
+
+	It("rejects duplicate config rotation codepoints", func() {
+		_, err := NewDecoder(
+			&Config{ConfigRotation: 1, ServerIDLen: 4, NonceLen: 4},
+			&Config{ConfigRotation: 1, ServerIDLen: 2, NonceLen: 4},
+		)
+		Expect(err).To(MatchError("quiclb: duplicate config rotation codepoint: 1"))
+	})
+
+	It("rejects invalid configs", func() {
+		_, err := NewDecoder(&Config{ServerIDLen: 4})
+		Expect(err).To(MatchError("quiclb: nonce must be at least 4 bytes long"))
+	})
+
+	Context("unroutable connection IDs", func() {
+		It("rejects empty connection IDs", func() {
+			d, err := NewDecoder(&Config{ServerIDLen: 4, NonceLen: 4})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = d.ServerID(nil)
+			Expect(err).To(MatchError(ErrUnroutable))
+		})
+
+		It("rejects connection IDs with an unknown config rotation codepoint", func() {
+			d, err := NewDecoder(&Config{ServerIDLen: 4, NonceLen: 4})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = d.ServerID([]byte{1 << 6, 1, 2, 3, 4, 5, 6, 7, 8})
+			Expect(err).To(MatchError(ErrUnroutable))
+			_, err = d.ServerID([]byte{3 << 6, 1, 2, 3, 4, 5, 6, 7, 8})
+			Expect(err).To(MatchError(ErrUnroutable))
+			_, err = d.ConnectionIDLen(3 << 6)
+			Expect(err).To(MatchError(ErrUnroutable))
+		})
+
+		It("rejects too short connection IDs", func() {
+			d, err := NewDecoder(&Config{ServerIDLen: 4, NonceLen: 4})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = d.ServerID([]byte{0, 1, 2, 3, 4, 5, 6, 7})
+			Expect(err).To(MatchError(ErrUnroutable))
+		})
+
+		It("rejects connection IDs with the wrong length encoded", func() {
+			d, err := NewDecoder(&Config{ServerIDLen: 4, NonceLen: 4, SelfEncodeLength: true})
+			Expect(err).ToNot(HaveOccurred())
+			_, err = d.ServerID([]byte{7, 1, 2, 3, 4, 5, 6, 7, 8})
+			Expect(err).To(MatchError(ErrUnroutable))
+			_, err = d.ServerID([]byte{8, 1, 2, 3, 4, 5, 6, 7, 8})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("rejects connection IDs with a non-zero padding, in block cipher mode", func() {
+			config := &Config{Mode: ModeBlockCipher, ServerIDLen: 4, ZeroPaddingLen: 4, Key: key}
+			g, err := NewGenerator(config, serverID)
+			Expect(err).ToNot(HaveOccurred())
+			d, err := NewDecoder(config)
+			Expect(err).ToNot(HaveOccurred())
+			connID, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			connID[5] ^= 0x1
+			_, err = d.ServerID(connID)
+			Expect(err).To(MatchError(ErrUnroutable))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package quiclb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/lucas-clemente/quic-go"
+)
+
+// A Generator generates connection IDs that encode the server ID.
+// It implements the quic.ConnectionIDGenerator interface.
+type Generator struct {
+	config   Config
+	serverID []byte
+	block    cipher.Block // nil in plaintext mode
+}
+
+var _ quic.ConnectionIDGenerator = &Generator{}
+
+// NewGenerator creates a new Generator.
+// The length of the server ID must match the ServerIDLen of the config.
+func NewGenerator(config *Config, serverID []byte) (*Generator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	if len(serverID) != config.ServerIDLen {
+		return nil, fmt.Errorf("quiclb: expected a %d byte server ID, got %d bytes", config.ServerIDLen, len(serverID))
+	}
+	g := &Generator{
+		config:   *config,
+		serverID: make([]byte, len(serverID)),
+	}
+	copy(g.serverID, serverID)
+	if config.Mode != ModePlaintext {
+		block, err := aes.NewCipher(config.Key)
+		if err != nil {
+			return nil, err
+		}
+		g.block = block
+	}
+	return g, nil
+}
+
+// GenerateConnectionID generates a new connection ID.
+func (g *Generator) GenerateConnectionID() (quic.ConnectionID, error) {
+	b := make([]byte, g.config.ConnectionIDLen())
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	b[0] = g.config.firstOctet(b[0])
+	switch g.config.Mode {
+	case ModePlaintext:
+		copy(b[1:], g.serverID)
+	case ModeStreamCipher:
+		nonce := b[1 : 1+g.config.NonceLen]
+		encryptStream(g.block, nonce, b[1+g.config.NonceLen:], g.serverID)
+	case ModeBlockCipher:
+		block := b[1:]
+		copy(block, g.serverID)
+		for i := 0; i < g.config.ZeroPaddingLen; i++ {
+			block[g.config.ServerIDLen+i] = 0
+		}
+		g.block.Encrypt(block, block)
+	}
+	return quic.ConnectionID(b), nil
+}
+
+// ConnectionIDLen returns the length of the connection IDs generated.
+func (g *Generator) ConnectionIDLen() int {
+	return g.config.ConnectionIDLen()
+}
+
+// encryptStream XORs src with the AES encryption of the zero-padded nonce, and writes the result to dst.
+// Since XOR is its own inverse, this is used for both encryption and decryption.
+func encryptStream(block cipher.Block, nonce, dst, src []byte) {
+	var keystream [blockLen]byte
+	copy(keystream[:], nonce)
+	block.Encrypt(keystream[:], keystream[:])
+	for i := range src {
+		dst[i] = src[i] ^ keystream[i]
+	}
+}
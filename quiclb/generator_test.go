@@ -0,0 +1,107 @@
+package quiclb
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generator", func() {
+	key := bytes.Repeat([]byte{0x42}, 16)
+
+	Context("validating the config", func() {
+		It("accepts valid configs", func() {
+			Expect((&Config{ServerIDLen: 3, NonceLen: 4}).validate()).To(Succeed())
+			Expect((&Config{Mode: ModeStreamCipher, ServerIDLen: 3, NonceLen: 8, Key: key}).validate()).To(Succeed())
+			Expect((&Config{Mode: ModeBlockCipher, ServerIDLen: 4, ZeroPaddingLen: 8, Key: key}).validate()).To(Succeed())
+		})
+
+		It("rejects invalid config rotation codepoints", func() {
+			Expect((&Config{ConfigRotation: 3, ServerIDLen: 3, NonceLen: 4}).validate()).To(MatchError("quiclb: invalid config rotation codepoint: 3"))
+		})
+
+		It("rejects empty server IDs", func() {
+			Expect((&Config{NonceLen: 4}).validate()).To(MatchError("quiclb: server ID too short"))
+		})
+
+		It("rejects short nonces", func() {
+			Expect((&Config{ServerIDLen: 3, NonceLen: 3}).validate()).To(MatchError("quiclb: nonce must be at least 4 bytes long"))
+			Expect((&Config{Mode: ModeStreamCipher, ServerIDLen: 3, NonceLen: 7, Key: key}).validate()).To(MatchError("quiclb: nonce must be between 8 and 16 bytes long"))
+			Expect((&Config{Mode: ModeStreamCipher, ServerIDLen: 3, NonceLen: 17, Key: key}).validate()).To(MatchError("quiclb: nonce must be between 8 and 16 bytes long"))
+		})
+
+		It("rejects too long server IDs in block cipher mode", func() {
+			Expect((&Config{Mode: ModeBlockCipher, ServerIDLen: 6, ZeroPaddingLen: 7, Key: key}).validate()).To(MatchError("quiclb: server ID and zero padding must not be longer than 12 bytes"))
+		})
+
+		It("rejects invalid keys", func() {
+			Expect((&Config{Mode: ModeStreamCipher, ServerIDLen: 3, NonceLen: 8}).validate()).To(MatchError("quiclb: key must be 16 bytes long"))
+			Expect((&Config{Mode: ModeBlockCipher, ServerIDLen: 3, Key: key[:15]}).validate()).To(MatchError("quiclb: key must be 16 bytes long"))
+		})
+
+		It("rejects too long connection IDs", func() {
+			Expect((&Config{ServerIDLen: 15, NonceLen: 5}).validate()).To(MatchError("quiclb: connection ID too long: 21 bytes"))
+			Expect((&Config{Mode: ModeStreamCipher, ServerIDLen: 8, NonceLen: 12, Key: key}).validate()).To(MatchError("quiclb: connection ID too long: 21 bytes"))
+		})
+
+		It("rejects unknown modes", func() {
+			Expect((&Config{Mode: 42, ServerIDLen: 3, NonceLen: 4}).validate()).To(MatchError("quiclb: unknown mode: 42"))
+		})
+	})
+
+	It("rejects server IDs of the wrong length", func() {
+		_, err := NewGenerator(&Config{ServerIDLen: 3, NonceLen: 4}, []byte{1, 2})
+		Expect(err).To(MatchError("quiclb: expected a 3 byte server ID, got 2 bytes"))
+	})
+
+	It("generates plaintext connection IDs", func() {
+		g, err := NewGenerator(&Config{ConfigRotation: 1, ServerIDLen: 3, NonceLen: 5}, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(9))
+		c1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1).To(HaveLen(9))
+		Expect(c1[0] >> 6).To(BeEquivalentTo(1))
+		Expect([]byte(c1[1:4])).To(Equal([]byte{0xa, 0xb, 0xc}))
+		c2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect([]byte(c2[1:4])).To(Equal([]byte{0xa, 0xb, 0xc}))
+		Expect(c2).ToNot(Equal(c1))
+	})
+
+	It("encodes the length", func() {
+		g, err := NewGenerator(&Config{ConfigRotation: 2, ServerIDLen: 3, NonceLen: 5, SelfEncodeLength: true}, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			c, err := g.GenerateConnectionID()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c[0]).To(Equal(byte(2<<6 | 8)))
+		}
+	})
+
+	It("encrypts the server ID in stream cipher mode", func() {
+		g, err := NewGenerator(&Config{Mode: ModeStreamCipher, ServerIDLen: 3, NonceLen: 8, Key: key}, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(12))
+		c1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1).To(HaveLen(12))
+		c2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1[9:]).ToNot(Equal(c2[9:]))
+	})
+
+	It("encrypts the server ID in block cipher mode", func() {
+		g, err := NewGenerator(&Config{Mode: ModeBlockCipher, ServerIDLen: 3, ZeroPaddingLen: 4, Key: key}, []byte{0xa, 0xb, 0xc})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(g.ConnectionIDLen()).To(Equal(17))
+		c1, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c1).To(HaveLen(17))
+		Expect([]byte(c1[1:4])).ToNot(Equal([]byte{0xa, 0xb, 0xc}))
+		c2, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c2).ToNot(Equal(c1))
+	})
+})
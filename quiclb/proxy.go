@@ -0,0 +1,176 @@
+package quiclb
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
+)
+
+// UpstreamIdleTimeout is the time after which the socket used to forward packets
+// from a client address to a backend is closed, if no packets were forwarded.
+// If the client sends another packet after that, a new socket is used,
+// which the backend handles like a NAT rebinding.
+const UpstreamIdleTimeout = time.Minute
+
+// MaxUpstreams is the maximum number of upstreams used by a Proxy at the same time.
+// Every upstream uses a separate socket, for every client address and backend.
+// When this limit is reached, packets from new client addresses are dropped,
+// until upstreams are closed because they were idle for UpstreamIdleTimeout.
+const MaxUpstreams = 10000
+
+var (
+	errProxyClosed      = errors.New("quiclb: proxy closed")
+	errTooManyUpstreams = errors.New("quiclb: too many upstreams")
+)
+
+// An upstream forwards packets from a single client address to a single backend.
+// Packets sent by the backend are forwarded to the client.
+type upstream struct {
+	conn       *net.UDPConn
+	clientAddr net.Addr
+}
+
+// A Proxy is a UDP proxy that forwards QUIC packets to the backends selected by a Router.
+// For every client address and backend, it uses a separate socket to send packets to the backend,
+// so that packets sent by the backend can be forwarded to the respective client.
+type Proxy struct {
+	conn   net.PacketConn
+	router *Router
+
+	mutex        sync.Mutex
+	closed       bool
+	upstreams    map[string]*upstream // the key is the client address and the backend address
+	maxUpstreams int
+
+	logger utils.Logger
+}
+
+// ListenProxy creates a new Proxy listening on the given UDP address.
+// It forwards packets until it is closed.
+func ListenProxy(addr string, router *Router) (*Proxy, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Proxy{
+		conn:         conn,
+		router:       router,
+		upstreams:    make(map[string]*upstream),
+		maxUpstreams: MaxUpstreams,
+		logger:       utils.DefaultLogger.WithPrefix("quiclb proxy"),
+	}
+	go p.run()
+	return p, nil
+}
+
+// LocalAddr returns the address the proxy is listening on.
+func (p *Proxy) LocalAddr() net.Addr {
+	return p.conn.LocalAddr()
+}
+
+// Close closes the proxy.
+func (p *Proxy) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	for _, u := range p.upstreams {
+		u.conn.Close()
+	}
+	return p.conn.Close()
+}
+
+func (p *Proxy) run() {
+	// The buffer can be reused, since packets are forwarded synchronously.
+	buffer := make([]byte, protocol.MaxPacketBufferSize)
+	for {
+		n, addr, err := p.conn.ReadFrom(buffer)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+				p.logger.Debugf("Temporary error reading from conn: %s", err)
+				continue
+			}
+			return
+		}
+		data := buffer[:n]
+		backend, err := p.router.Route(data)
+		if err != nil {
+			if p.logger.Debug() {
+				p.logger.Debugf("Dropping packet (%d bytes) from %s: %s", n, addr, err)
+			}
+			continue
+		}
+		u, err := p.getUpstream(addr, backend)
+		if err != nil {
+			if p.logger.Debug() {
+				p.logger.Debugf("Dropping packet (%d bytes) from %s: error creating upstream socket to %s: %s", n, addr, backend.Addr, err)
+			}
+			continue
+		}
+		u.conn.SetReadDeadline(time.Now().Add(UpstreamIdleTimeout))
+		if _, err := u.conn.Write(data); err != nil {
+			p.logger.Debugf("Error forwarding packet to %s: %s", backend.Addr, err)
+		}
+	}
+}
+
+func (p *Proxy) getUpstream(clientAddr net.Addr, backend *Backend) (*upstream, error) {
+	key := clientAddr.String() + "|" + backend.Addr.String()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil, errProxyClosed
+	}
+	if u, ok := p.upstreams[key]; ok {
+		return u, nil
+	}
+	if len(p.upstreams) >= p.maxUpstreams {
+		return nil, errTooManyUpstreams
+	}
+	conn, err := net.DialUDP("udp", nil, backend.Addr)
+	if err != nil {
+		return nil, err
+	}
+	u := &upstream{conn: conn, clientAddr: clientAddr}
+	p.upstreams[key] = u
+	go p.runUpstream(key, u)
+	return u, nil
+}
+
+func (p *Proxy) runUpstream(key string, u *upstream) {
+	defer func() {
+		p.mutex.Lock()
+		if p.upstreams[key] == u {
+			delete(p.upstreams, key)
+		}
+		p.mutex.Unlock()
+		u.conn.Close()
+	}()
+
+	buffer := make([]byte, protocol.MaxPacketBufferSize)
+	for {
+		n, err := u.conn.Read(buffer)
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Temporary() && !nerr.Timeout() {
+				continue
+			}
+			return
+		}
+		u.conn.SetReadDeadline(time.Now().Add(UpstreamIdleTimeout))
+		if _, err := p.conn.WriteTo(buffer[:n], u.clientAddr); err != nil {
+			p.logger.Debugf("Error forwarding packet to %s: %s", u.clientAddr, err)
+		}
+	}
+}
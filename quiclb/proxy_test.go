@@ -0,0 +1,148 @@
+package quiclb
+
+import (
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Proxy", func() {
+	config := &Config{ServerIDLen: 2, NonceLen: 6}
+
+	type receivedPacket struct {
+		data []byte
+		addr net.Addr
+	}
+
+	// runBackend runs a UDP server that echoes all packets
+	runBackend := func() (*net.UDPConn, <-chan receivedPacket) {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		received := make(chan receivedPacket, 100)
+		go func() {
+			for {
+				b := make([]byte, 1500)
+				n, addr, err := conn.ReadFrom(b)
+				if err != nil {
+					return
+				}
+				received <- receivedPacket{data: b[:n], addr: addr}
+				conn.WriteTo(b[:n], addr)
+			}
+		}()
+		return conn, received
+	}
+
+	It("forwards packets to the backend, and back to the client", func() {
+		backend1, received1 := runBackend()
+		defer backend1.Close()
+		backend2, received2 := runBackend()
+		defer backend2.Close()
+		decoder, err := NewDecoder(config)
+		Expect(err).ToNot(HaveOccurred())
+		router := NewRouter(decoder)
+		Expect(router.AddBackend([]byte{0, 1}, backend1.LocalAddr().(*net.UDPAddr))).To(Succeed())
+		Expect(router.AddBackend([]byte{0, 2}, backend2.LocalAddr().(*net.UDPAddr))).To(Succeed())
+		proxy, err := ListenProxy("localhost:0", router)
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		client, err := net.DialUDP("udp", nil, proxy.LocalAddr().(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+		g, err := NewGenerator(config, []byte{0, 2})
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		packet := shortHeaderPacket(connID)
+		_, err = client.Write(packet)
+		Expect(err).ToNot(HaveOccurred())
+		var p receivedPacket
+		Eventually(received2).Should(Receive(&p))
+		Expect(p.data).To(Equal(packet))
+		Expect(received1).ToNot(Receive())
+		// receive the echoed packet
+		client.SetReadDeadline(time.Now().Add(time.Second))
+		b := make([]byte, 1500)
+		n, err := client.Read(b)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b[:n]).To(Equal(packet))
+
+		// packets from the same client use the same upstream socket
+		_, err = client.Write(packet)
+		Expect(err).ToNot(HaveOccurred())
+		var p2 receivedPacket
+		Eventually(received2).Should(Receive(&p2))
+		Expect(p2.addr).To(Equal(p.addr))
+	})
+
+	It("drops unroutable packets", func() {
+		backend, received := runBackend()
+		defer backend.Close()
+		decoder, err := NewDecoder(config)
+		Expect(err).ToNot(HaveOccurred())
+		router := NewRouter(decoder)
+		Expect(router.AddBackend([]byte{0, 1}, backend.LocalAddr().(*net.UDPAddr))).To(Succeed())
+		proxy, err := ListenProxy("localhost:0", router)
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+
+		client, err := net.DialUDP("udp", nil, proxy.LocalAddr().(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer client.Close()
+		_, err = client.Write(shortHeaderPacket([]byte{0xff, 0, 1, 2, 3, 4, 5, 6, 7}))
+		Expect(err).ToNot(HaveOccurred())
+		Consistently(received, 50*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("limits the number of upstreams", func() {
+		backend, received := runBackend()
+		defer backend.Close()
+		decoder, err := NewDecoder(config)
+		Expect(err).ToNot(HaveOccurred())
+		router := NewRouter(decoder)
+		Expect(router.AddBackend([]byte{0, 1}, backend.LocalAddr().(*net.UDPAddr))).To(Succeed())
+		proxy, err := ListenProxy("localhost:0", router)
+		Expect(err).ToNot(HaveOccurred())
+		defer proxy.Close()
+		proxy.mutex.Lock()
+		proxy.maxUpstreams = 1
+		proxy.mutex.Unlock()
+
+		g, err := NewGenerator(config, []byte{0, 1})
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		packet := shortHeaderPacket(connID)
+		client1, err := net.DialUDP("udp", nil, proxy.LocalAddr().(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer client1.Close()
+		_, err = client1.Write(packet)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(received).Should(Receive())
+		// packets from a second client address are dropped
+		client2, err := net.DialUDP("udp", nil, proxy.LocalAddr().(*net.UDPAddr))
+		Expect(err).ToNot(HaveOccurred())
+		defer client2.Close()
+		_, err = client2.Write(packet)
+		Expect(err).ToNot(HaveOccurred())
+		Consistently(received, 50*time.Millisecond).ShouldNot(Receive())
+		// packets from the first client are still forwarded
+		_, err = client1.Write(packet)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(received).Should(Receive())
+	})
+
+	It("closes", func() {
+		decoder, err := NewDecoder(config)
+		Expect(err).ToNot(HaveOccurred())
+		proxy, err := ListenProxy("localhost:0", NewRouter(decoder))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(proxy.Close()).To(Succeed())
+		Expect(proxy.Close()).To(Succeed())
+		_, err = proxy.getUpstream(&net.UDPAddr{}, &Backend{Addr: &net.UDPAddr{}})
+		Expect(err).To(MatchError(errProxyClosed))
+	})
+})
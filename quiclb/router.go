@@ -0,0 +1,138 @@
+package quiclb
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"sync"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/wire"
+)
+
+// ErrNoBackend is returned by the Router if no backend is available.
+var ErrNoBackend = errors.New("quiclb: no backend available")
+
+// A Backend is a QUIC server behind the load balancer.
+type Backend struct {
+	// ServerID is the server ID encoded in the connection IDs issued by this backend (see NewGenerator).
+	ServerID []byte
+	Addr     *net.UDPAddr
+}
+
+// A Router determines which backend a QUIC packet is routed to.
+// Packets that use a connection ID issued by one of the backends are routed to that backend.
+// Long header packets that use a connection ID chosen by the client
+// (i.e. the Initial and 0-RTT packets sent at the beginning of a connection)
+// are routed using consistent hashing of the Destination Connection ID,
+// such that all of them are routed to the same backend.
+// A Router is safe for concurrent use.
+type Router struct {
+	decoder *Decoder
+
+	mutex    sync.RWMutex
+	backends map[string]*Backend // the key is the server ID
+}
+
+// NewRouter creates a new Router.
+// The decoder must use the same configs as the Generators used by the backends.
+func NewRouter(decoder *Decoder) *Router {
+	return &Router{
+		decoder:  decoder,
+		backends: make(map[string]*Backend),
+	}
+}
+
+// AddBackend adds a backend.
+// Adding a backend only changes the routing of connections that haven't completed the handshake yet,
+// for a fraction of 1/(number of backends) of these connections.
+func (r *Router) AddBackend(serverID []byte, addr *net.UDPAddr) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.backends[string(serverID)]; ok {
+		return fmt.Errorf("quiclb: backend with server ID %#x already exists", serverID)
+	}
+	b := &Backend{ServerID: make([]byte, len(serverID)), Addr: addr}
+	copy(b.ServerID, serverID)
+	r.backends[string(serverID)] = b
+	return nil
+}
+
+// RemoveBackend removes a backend.
+// Packets for connections handled by this backend won't be routed any more.
+func (r *Router) RemoveBackend(serverID []byte) {
+	r.mutex.Lock()
+	delete(r.backends, string(serverID))
+	r.mutex.Unlock()
+}
+
+// Route determines the backend a packet is routed to.
+// It returns ErrUnroutable if the packet can't be routed, and ErrNoBackend if there are no backends.
+func (r *Router) Route(packet []byte) (*Backend, error) {
+	if len(packet) == 0 {
+		return nil, ErrUnroutable
+	}
+	isLongHeader := packet[0]&0x80 > 0
+	var shortHeaderConnIDLen int
+	if !isLongHeader {
+		if len(packet) < 2 {
+			return nil, ErrUnroutable
+		}
+		l, err := r.decoder.ConnectionIDLen(packet[1])
+		if err != nil {
+			return nil, err
+		}
+		shortHeaderConnIDLen = l
+	}
+	connID, err := wire.ParseConnectionID(packet, shortHeaderConnIDLen)
+	if err != nil {
+		return nil, ErrUnroutable
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(r.backends) == 0 {
+		return nil, ErrNoBackend
+	}
+	if serverID, err := r.decoder.ServerID(connID); err == nil {
+		if b, ok := r.backends[string(serverID)]; ok {
+			return b, nil
+		}
+	}
+	if !isLongHeader {
+		return nil, ErrUnroutable
+	}
+	return r.hashConnectionID(connID), nil
+}
+
+// hashConnectionID selects a backend using rendezvous hashing.
+// Every backend is assigned a score based on its server ID and the connection ID,
+// and the backend with the highest score is selected.
+// Adding or removing a backend only changes the routing of the connection IDs that this backend scores highest for.
+func (r *Router) hashConnectionID(connID protocol.ConnectionID) *Backend {
+	var backend *Backend
+	var maxScore uint64
+	for _, b := range r.backends {
+		h := fnv.New64a()
+		h.Write(b.ServerID)
+		h.Write(connID)
+		if score := mix(h.Sum64()); backend == nil || score > maxScore {
+			backend = b
+			maxScore = score
+		}
+	}
+	return backend
+}
+
+// mix improves the distribution of the FNV hash, using the finalizer of MurmurHash3.
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
@@ -0,0 +1,149 @@
+package quiclb
+
+import (
+	"crypto/rand"
+	"net"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func longHeaderPacket(connID []byte) []byte {
+	b := []byte{0xc0, 0, 0, 0, 1, byte(len(connID))}
+	b = append(b, connID...)
+	b = append(b, 0) // empty Source Connection ID
+	return append(b, []byte("foobar")...)
+}
+
+func shortHeaderPacket(connID []byte) []byte {
+	b := append([]byte{0x40}, connID...)
+	return append(b, []byte("foobar")...)
+}
+
+func randomConnID(l int) []byte {
+	b := make([]byte, l)
+	rand.Read(b)
+	return b
+}
+
+var _ = Describe("Router", func() {
+	config := &Config{ServerIDLen: 2, NonceLen: 6}
+	addr1 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1001}
+	addr2 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1002}
+	addr3 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1003}
+
+	var router *Router
+
+	BeforeEach(func() {
+		decoder, err := NewDecoder(config)
+		Expect(err).ToNot(HaveOccurred())
+		router = NewRouter(decoder)
+		Expect(router.AddBackend([]byte{0, 1}, addr1)).To(Succeed())
+		Expect(router.AddBackend([]byte{0, 2}, addr2)).To(Succeed())
+	})
+
+	generateConnID := func(serverID []byte) []byte {
+		g, err := NewGenerator(config, serverID)
+		Expect(err).ToNot(HaveOccurred())
+		connID, err := g.GenerateConnectionID()
+		Expect(err).ToNot(HaveOccurred())
+		return connID
+	}
+
+	It("rejects duplicate backends", func() {
+		Expect(router.AddBackend([]byte{0, 1}, addr3)).To(MatchError("quiclb: backend with server ID 0x0001 already exists"))
+	})
+
+	It("routes packets using a connection ID issued by a backend", func() {
+		for i := 0; i < 10; i++ {
+			b, err := router.Route(shortHeaderPacket(generateConnID([]byte{0, 2})))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Addr).To(Equal(addr2))
+			b, err = router.Route(longHeaderPacket(generateConnID([]byte{0, 1})))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b.Addr).To(Equal(addr1))
+		}
+	})
+
+	It("routes long header packets with a connection ID chosen by the client consistently", func() {
+		connID := randomConnID(12)
+		b1, err := router.Route(longHeaderPacket(connID))
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			b, err := router.Route(longHeaderPacket(connID))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal(b1))
+		}
+	})
+
+	It("distributes connections between the backends", func() {
+		Expect(router.AddBackend([]byte{0, 3}, addr3)).To(Succeed())
+		counts := make(map[string]int)
+		for i := 0; i < 3000; i++ {
+			b, err := router.Route(longHeaderPacket(randomConnID(12)))
+			Expect(err).ToNot(HaveOccurred())
+			counts[b.Addr.String()]++
+		}
+		Expect(counts).To(HaveLen(3))
+		for _, c := range counts {
+			Expect(c).To(BeNumerically("~", 1000, 150))
+		}
+	})
+
+	It("only reroutes connections to a newly added backend", func() {
+		connIDs := make([][]byte, 1000)
+		routes := make([]*Backend, len(connIDs))
+		for i := range connIDs {
+			connIDs[i] = randomConnID(12)
+			b, err := router.Route(longHeaderPacket(connIDs[i]))
+			Expect(err).ToNot(HaveOccurred())
+			routes[i] = b
+		}
+		Expect(router.AddBackend([]byte{0, 3}, addr3)).To(Succeed())
+		var moved int
+		for i, connID := range connIDs {
+			b, err := router.Route(longHeaderPacket(connID))
+			Expect(err).ToNot(HaveOccurred())
+			if b != routes[i] {
+				Expect(b.Addr).To(Equal(addr3))
+				moved++
+			}
+		}
+		Expect(moved).To(BeNumerically("~", 333, 100))
+	})
+
+	It("doesn't route short header packets for removed backends", func() {
+		router.RemoveBackend([]byte{0, 2})
+		_, err := router.Route(shortHeaderPacket(generateConnID([]byte{0, 2})))
+		Expect(err).To(MatchError(ErrUnroutable))
+		// long header packets are routed to one of the remaining backends
+		b, err := router.Route(longHeaderPacket(generateConnID([]byte{0, 2})))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(b.Addr).To(Equal(addr1))
+	})
+
+	It("doesn't route short header packets with an unknown config rotation codepoint", func() {
+		connID := generateConnID([]byte{0, 1})
+		connID[0] |= 0xc0
+		_, err := router.Route(shortHeaderPacket(connID))
+		Expect(err).To(MatchError(ErrUnroutable))
+	})
+
+	It("doesn't route invalid packets", func() {
+		_, err := router.Route(nil)
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = router.Route([]byte{0x40})
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = router.Route(shortHeaderPacket(generateConnID([]byte{0, 1}))[:5])
+		Expect(err).To(MatchError(ErrUnroutable))
+		_, err = router.Route(longHeaderPacket(randomConnID(12))[:10])
+		Expect(err).To(MatchError(ErrUnroutable))
+	})
+
+	It("errors when there are no backends", func() {
+		router.RemoveBackend([]byte{0, 1})
+		router.RemoveBackend([]byte{0, 2})
+		_, err := router.Route(longHeaderPacket(randomConnID(12)))
+		Expect(err).To(MatchError(ErrNoBackend))
+	})
+})
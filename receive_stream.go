@@ -16,7 +16,9 @@ type receiveStreamI interface {
 	ReceiveStream
 
 	handleStreamFrame(*wire.StreamFrame) error
+	markReceived0RTT()
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
+	handleResetStreamAtFrame(*wire.ResetStreamAtFrame) error
 	closeForShutdown(error)
 	getWindowUpdate() protocol.ByteCount
 }
@@ -35,6 +37,7 @@ type receiveStream struct {
 	currentFrameDone   func()
 	currentFrameIsLast bool // is the currentFrame the last frame on this stream
 	readPosInFrame     int
+	readOffset         protocol.ByteCount // the number of bytes read by the application
 
 	closeForShutdownErr error
 	cancelReadErr       error
@@ -44,6 +47,10 @@ type receiveStream struct {
 	finRead           bool // set once we read a frame with a Fin
 	canceledRead      bool // set when CancelRead() is called
 	resetRemotely     bool // set when HandleResetStreamFrame() is called
+	resetAtPending    bool // set when a RESET_STREAM_AT frame is received, until the application has read reliableSize bytes
+	reliableSize      protocol.ByteCount
+	received0RTT      bool // set when a STREAM frame is received in a 0-RTT packet
+	abandoned         bool // set when the flow controller is abandoned
 
 	readChan chan struct{}
 	deadline time.Time
@@ -82,30 +89,51 @@ func (s *receiveStream) StreamID() protocol.StreamID {
 func (s *receiveStream) Read(p []byte) (int, error) {
 	s.mutex.Lock()
 	completed, n, err := s.readImpl(p)
+	// a stream reset using RESET_STREAM_AT completes once all the reliable data was read
+	abandon := completed && s.resetRemotely
+	if abandon {
+		s.abandoned = true
+	}
 	s.mutex.Unlock()
 
+	if abandon {
+		s.flowController.Abandon()
+	}
 	if completed {
 		s.sender.onStreamCompleted(s.streamID)
 	}
 	return n, err
 }
 
-func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, error) {
+// readErr returns the error that Read returns before it attempts to read any data.
+func (s *receiveStream) readErr() error {
 	if s.finRead {
-		return false, 0, io.EOF
+		return io.EOF
 	}
 	if s.canceledRead {
-		return false, 0, s.cancelReadErr
+		return s.cancelReadErr
 	}
 	if s.resetRemotely {
-		return false, 0, s.resetRemotelyErr
+		return s.resetRemotelyErr
 	}
 	if s.closedForShutdown {
-		return false, 0, s.closeForShutdownErr
+		return s.closeForShutdownErr
+	}
+	return nil
+}
+
+func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, error) {
+	if err := s.readErr(); err != nil {
+		return false, 0, err
 	}
 
 	bytesRead := 0
 	var deadlineTimer *utils.Timer
+	defer func() {
+		if deadlineTimer != nil {
+			deadlineTimer.Stop()
+		}
+	}()
 	for bytesRead < len(p) {
 		if s.currentFrame == nil || s.readPosInFrame >= len(s.currentFrame) {
 			s.dequeueNextFrame()
@@ -114,48 +142,8 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 			return false, bytesRead, s.closeForShutdownErr
 		}
 
-		for {
-			// Stop waiting on errors
-			if s.closedForShutdown {
-				return false, bytesRead, s.closeForShutdownErr
-			}
-			if s.canceledRead {
-				return false, bytesRead, s.cancelReadErr
-			}
-			if s.resetRemotely {
-				return false, bytesRead, s.resetRemotelyErr
-			}
-
-			deadline := s.deadline
-			if !deadline.IsZero() {
-				if !time.Now().Before(deadline) {
-					return false, bytesRead, errDeadline
-				}
-				if deadlineTimer == nil {
-					deadlineTimer = utils.NewTimer()
-					defer deadlineTimer.Stop()
-				}
-				deadlineTimer.Reset(deadline)
-			}
-
-			if s.currentFrame != nil || s.currentFrameIsLast {
-				break
-			}
-
-			s.mutex.Unlock()
-			if deadline.IsZero() {
-				<-s.readChan
-			} else {
-				select {
-				case <-s.readChan:
-				case <-deadlineTimer.Chan():
-					deadlineTimer.SetRead()
-				}
-			}
-			s.mutex.Lock()
-			if s.currentFrame == nil {
-				s.dequeueNextFrame()
-			}
+		if err := s.waitForData(&deadlineTimer); err != nil {
+			return false, bytesRead, err
 		}
 
 		if bytesRead > len(p) {
@@ -165,18 +153,29 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 			return false, bytesRead, fmt.Errorf("BUG: readPosInFrame (%d) > frame.DataLen (%d) in stream.Read", s.readPosInFrame, len(s.currentFrame))
 		}
 
+		data := s.currentFrame[s.readPosInFrame:]
+		// when a RESET_STREAM_AT frame was received, only deliver data up to the reliable size
+		if s.resetAtPending && protocol.ByteCount(len(data)) > s.reliableSize-s.readOffset {
+			data = data[:s.reliableSize-s.readOffset]
+		}
 		s.mutex.Unlock()
 
-		m := copy(p[bytesRead:], s.currentFrame[s.readPosInFrame:])
+		m := copy(p[bytesRead:], data)
 		s.readPosInFrame += m
 		bytesRead += m
 
 		s.mutex.Lock()
+		s.readOffset += protocol.ByteCount(m)
 		// when a RESET_STREAM was received, the was already informed about the final byteOffset for this stream
 		if !s.resetRemotely {
 			s.flowController.AddBytesRead(protocol.ByteCount(m))
 		}
 
+		if s.resetAtPending && s.readOffset >= s.reliableSize {
+			s.resetAtPending = false
+			s.resetRemotely = true
+			return true, bytesRead, s.resetRemotelyErr
+		}
 		if s.readPosInFrame >= len(s.currentFrame) && s.currentFrameIsLast {
 			s.finRead = true
 			return true, bytesRead, io.EOF
@@ -185,6 +184,193 @@ func (s *receiveStream) readImpl(p []byte) (bool /*stream completed */, int, err
 	return false, bytesRead, nil
 }
 
+// ReadBuffer returns the next chunk of data, without copying it out of the receive buffer.
+func (s *receiveStream) ReadBuffer() ([]byte, func(), error) {
+	s.mutex.Lock()
+	completed, data, release, err := s.readBufferImpl()
+	abandon := completed && s.resetRemotely
+	if abandon {
+		s.abandoned = true
+	}
+	s.mutex.Unlock()
+
+	if abandon {
+		s.flowController.Abandon()
+	}
+	if completed {
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return data, release, err
+}
+
+func (s *receiveStream) readBufferImpl() (bool /*stream completed */, []byte, func(), error) {
+	if err := s.readErr(); err != nil {
+		return false, nil, nil, err
+	}
+
+	if s.currentFrame == nil || s.readPosInFrame >= len(s.currentFrame) {
+		s.dequeueNextFrame()
+	}
+	var deadlineTimer *utils.Timer
+	defer func() {
+		if deadlineTimer != nil {
+			deadlineTimer.Stop()
+		}
+	}()
+	if err := s.waitForData(&deadlineTimer); err != nil {
+		return false, nil, nil, err
+	}
+
+	// Hand the frame to the application. It is now responsible for releasing the buffer.
+	data := s.currentFrame[s.readPosInFrame:]
+	frameDone := s.currentFrameDone
+	s.currentFrame = nil
+	s.currentFrameDone = nil
+	s.readPosInFrame = 0
+	// when a RESET_STREAM_AT frame was received, only deliver data up to the reliable size
+	if s.resetAtPending && protocol.ByteCount(len(data)) > s.reliableSize-s.readOffset {
+		data = data[:s.reliableSize-s.readOffset]
+	}
+	s.readOffset += protocol.ByteCount(len(data))
+	// The data only counts as read (and the flow control window is only increased) once the buffer was released.
+	// When a RESET_STREAM was received, the flow controller was already informed about the final offset.
+	countAsRead := !s.resetRemotely
+	n := protocol.ByteCount(len(data))
+	release := func() {
+		if frameDone != nil {
+			frameDone()
+		}
+		if !countAsRead {
+			return
+		}
+		s.mutex.Lock()
+		// If the flow controller was abandoned, it already accounted for this data.
+		if !s.abandoned {
+			s.flowController.AddBytesRead(n)
+		}
+		s.mutex.Unlock()
+	}
+
+	if s.resetAtPending && s.readOffset >= s.reliableSize {
+		s.resetAtPending = false
+		s.resetRemotely = true
+		return true, data, release, s.resetRemotelyErr
+	}
+	if s.currentFrameIsLast {
+		s.finRead = true
+		return true, data, release, io.EOF
+	}
+	return false, data, release, nil
+}
+
+// WriteTo implements io.WriterTo.
+// The received data is written to w directly from the receive buffers, without copying it.
+func (s *receiveStream) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for {
+		b, release, err := s.ReadBuffer()
+		if len(b) > 0 {
+			n, werr := w.Write(b)
+			written += int64(n)
+			if werr == nil && n < len(b) {
+				werr = io.ErrShortWrite
+			}
+			if werr != nil {
+				release()
+				return written, werr
+			}
+		}
+		if release != nil {
+			release()
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// Peek returns the data that can be read next, without consuming it.
+func (s *receiveStream) Peek() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.readErr(); err != nil {
+		return nil, err
+	}
+	if s.currentFrame == nil || s.readPosInFrame >= len(s.currentFrame) {
+		s.dequeueNextFrame()
+	}
+	var deadlineTimer *utils.Timer
+	defer func() {
+		if deadlineTimer != nil {
+			deadlineTimer.Stop()
+		}
+	}()
+	if err := s.waitForData(&deadlineTimer); err != nil {
+		return nil, err
+	}
+
+	data := s.currentFrame[s.readPosInFrame:]
+	if s.resetAtPending && protocol.ByteCount(len(data)) > s.reliableSize-s.readOffset {
+		data = data[:s.reliableSize-s.readOffset]
+	}
+	if len(data) == 0 {
+		// this is only possible for the last frame
+		return nil, io.EOF
+	}
+	return data, nil
+}
+
+// waitForData blocks until the current frame contains data (or is the last frame on this stream),
+// or until an error occurs. It must be called with the mutex held.
+func (s *receiveStream) waitForData(deadlineTimer **utils.Timer) error {
+	for {
+		// Stop waiting on errors
+		if s.closedForShutdown {
+			return s.closeForShutdownErr
+		}
+		if s.canceledRead {
+			return s.cancelReadErr
+		}
+		if s.resetRemotely {
+			return s.resetRemotelyErr
+		}
+
+		deadline := s.deadline
+		if !deadline.IsZero() {
+			if !time.Now().Before(deadline) {
+				return errDeadline
+			}
+			if *deadlineTimer == nil {
+				*deadlineTimer = utils.NewTimer()
+			}
+			(*deadlineTimer).Reset(deadline)
+		}
+
+		if s.currentFrame != nil || s.currentFrameIsLast {
+			return nil
+		}
+
+		s.mutex.Unlock()
+		if deadline.IsZero() {
+			<-s.readChan
+		} else {
+			select {
+			case <-s.readChan:
+			case <-(*deadlineTimer).Chan():
+				(*deadlineTimer).SetRead()
+			}
+		}
+		s.mutex.Lock()
+		if s.currentFrame == nil {
+			s.dequeueNextFrame()
+		}
+	}
+}
+
 func (s *receiveStream) dequeueNextFrame() {
 	var offset protocol.ByteCount
 	// We're done with the last frame. Release the buffer.
@@ -199,6 +385,9 @@ func (s *receiveStream) dequeueNextFrame() {
 func (s *receiveStream) CancelRead(errorCode protocol.ApplicationErrorCode) {
 	s.mutex.Lock()
 	completed := s.cancelReadImpl(errorCode)
+	if completed {
+		s.abandoned = true
+	}
 	s.mutex.Unlock()
 
 	if completed {
@@ -225,6 +414,9 @@ func (s *receiveStream) cancelReadImpl(errorCode protocol.ApplicationErrorCode)
 func (s *receiveStream) handleStreamFrame(frame *wire.StreamFrame) error {
 	s.mutex.Lock()
 	completed, err := s.handleStreamFrameImpl(frame)
+	if completed {
+		s.abandoned = true
+	}
 	s.mutex.Unlock()
 
 	if completed {
@@ -254,9 +446,40 @@ func (s *receiveStream) handleStreamFrameImpl(frame *wire.StreamFrame) (bool /*
 	return false, nil
 }
 
+func (s *receiveStream) markReceived0RTT() {
+	s.mutex.Lock()
+	s.received0RTT = true
+	s.mutex.Unlock()
+}
+
+func (s *receiveStream) Used0RTT() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.received0RTT
+}
+
+func (s *receiveStream) ReceiveStats() ReceiveStreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	buffered, outOfOrder := s.frameQueue.BufferedBytes()
+	if s.currentFrame != nil {
+		buffered += protocol.ByteCount(len(s.currentFrame) - s.readPosInFrame)
+	}
+	return ReceiveStreamStats{
+		BytesReceived:           uint64(s.readOffset + buffered),
+		BytesRead:               uint64(s.readOffset),
+		BytesBuffered:           uint64(buffered),
+		BytesBufferedOutOfOrder: uint64(outOfOrder),
+	}
+}
+
 func (s *receiveStream) handleResetStreamFrame(frame *wire.ResetStreamFrame) error {
 	s.mutex.Lock()
 	completed, err := s.handleResetStreamFrameImpl(frame)
+	if completed {
+		s.abandoned = true
+	}
 	s.mutex.Unlock()
 
 	if completed {
@@ -267,26 +490,59 @@ func (s *receiveStream) handleResetStreamFrame(frame *wire.ResetStreamFrame) err
 }
 
 func (s *receiveStream) handleResetStreamFrameImpl(frame *wire.ResetStreamFrame) (bool /*completed */, error) {
+	return s.handleResetImpl(frame.ErrorCode, frame.FinalSize, 0)
+}
+
+func (s *receiveStream) handleResetStreamAtFrame(frame *wire.ResetStreamAtFrame) error {
+	s.mutex.Lock()
+	completed, err := s.handleResetImpl(frame.ErrorCode, frame.FinalSize, frame.ReliableSize)
+	if completed {
+		s.abandoned = true
+	}
+	s.mutex.Unlock()
+
+	if completed {
+		s.flowController.Abandon()
+		s.sender.onStreamCompleted(s.streamID)
+	}
+	return err
+}
+
+func (s *receiveStream) handleResetImpl(errorCode protocol.ApplicationErrorCode, finalSize, reliableSize protocol.ByteCount) (bool /*completed */, error) {
 	if s.closedForShutdown {
 		return false, nil
 	}
-	if err := s.flowController.UpdateHighestReceived(frame.FinalSize, true); err != nil {
+	if err := s.flowController.UpdateHighestReceived(finalSize, true); err != nil {
 		return false, err
 	}
 	newlyRcvdFinalOffset := s.finalOffset == protocol.MaxByteCount
-	s.finalOffset = frame.FinalSize
+	s.finalOffset = finalSize
 
 	// ignore duplicate RESET_STREAM frames for this stream (after checking their final offset)
 	if s.resetRemotely {
 		return false, nil
 	}
-	s.resetRemotely = true
+	// the reliable size can be reduced by a subsequent RESET_STREAM_AT or RESET_STREAM frame, but never increased
+	if s.resetAtPending && reliableSize >= s.reliableSize {
+		return false, nil
+	}
+	wasPending := s.resetAtPending
 	s.resetRemotelyErr = streamCanceledError{
-		errorCode: frame.ErrorCode,
-		error:     fmt.Errorf("stream %d was reset with error code %d", s.streamID, frame.ErrorCode),
+		errorCode: errorCode,
+		error:     fmt.Errorf("stream %d was reset with error code %d", s.streamID, errorCode),
+	}
+	// Data up to the reliable size is still delivered to the application.
+	// The stream is reset once the application has read it.
+	if reliableSize > s.readOffset && !s.finRead && !s.canceledRead {
+		s.resetAtPending = true
+		s.reliableSize = reliableSize
+		s.signalRead()
+		return false, nil
 	}
+	s.resetAtPending = false
+	s.resetRemotely = true
 	s.signalRead()
-	return newlyRcvdFinalOffset, nil
+	return newlyRcvdFinalOffset || wasPending, nil
 }
 
 func (s *receiveStream) CloseRemote(offset protocol.ByteCount) {
@@ -1,6 +1,7 @@
 package quic
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"runtime"
@@ -39,6 +40,12 @@ var _ = Describe("Receive Stream", func() {
 		Expect(str.StreamID()).To(Equal(protocol.StreamID(1337)))
 	})
 
+	It("says if data was received in 0-RTT", func() {
+		Expect(str.Used0RTT()).To(BeFalse())
+		str.markReceived0RTT()
+		Expect(str.Used0RTT()).To(BeTrue())
+	})
+
 	Context("reading", func() {
 		It("reads a single STREAM frame", func() {
 			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(4), false)
@@ -446,6 +453,255 @@ var _ = Describe("Receive Stream", func() {
 		})
 	})
 
+	It("reports statistics", func() {
+		mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+		mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(14), false)
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+		Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 10, Data: []byte("abcd")})).To(Succeed())
+		Expect(str.ReceiveStats()).To(Equal(ReceiveStreamStats{
+			BytesReceived:           10,
+			BytesBuffered:           10,
+			BytesBufferedOutOfOrder: 4,
+		}))
+		mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+		n, err := strWithTimeout.Read(make([]byte, 4))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(4))
+		Expect(str.ReceiveStats()).To(Equal(ReceiveStreamStats{
+			BytesReceived:           10,
+			BytesRead:               4,
+			BytesBuffered:           6,
+			BytesBufferedOutOfOrder: 4,
+		}))
+	})
+
+	Context("writing to an io.Writer", func() {
+		It("writes all data to the writer", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), false)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), true)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foo")})).To(Succeed())
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Offset: 3, Data: []byte("bar"), Fin: true})).To(Succeed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3)).Times(2)
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			buf := &bytes.Buffer{}
+			n, err := str.WriteTo(buf)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(BeEquivalentTo(6))
+			Expect(buf.String()).To(Equal("foobar"))
+		})
+
+		It("returns errors from the writer", func() {
+			testErr := errors.New("write error")
+			pr, pw := io.Pipe()
+			pr.CloseWithError(testErr)
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foo")})).To(Succeed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+			n, err := str.WriteTo(pw)
+			Expect(err).To(MatchError(testErr))
+			Expect(n).To(BeZero())
+		})
+
+		It("returns stream errors", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(3), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foo")})).To(Succeed())
+			done := make(chan struct{})
+			buf := &bytes.Buffer{}
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				n, err := str.WriteTo(buf)
+				Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+				Expect(n).To(BeEquivalentTo(3))
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true)
+			mockFC.EXPECT().Abandon()
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+				StreamID:  streamID,
+				ErrorCode: 1234,
+				FinalSize: 10,
+			})).To(Succeed())
+			Eventually(done).Should(BeClosed())
+			Expect(buf.String()).To(Equal("foo"))
+		})
+	})
+
+	Context("peeking", func() {
+		It("returns data without consuming it", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			b, err := str.Peek()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("foobar")))
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+			n, err := strWithTimeout.Read(make([]byte, 3))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(3))
+			b, err = str.Peek()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("bar")))
+			Expect(str.ReceiveStats().BytesRead).To(BeEquivalentTo(3))
+		})
+
+		It("blocks until data is available", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				b, err := str.Peek()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b).To(Equal([]byte("foobar")))
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns io.EOF at the end of the stream", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(0), true)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Fin: true})).To(Succeed())
+			_, err := str.Peek()
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("respects the read deadline", func() {
+			Expect(str.SetReadDeadline(time.Now().Add(scaleDuration(20 * time.Millisecond)))).To(Succeed())
+			_, err := str.Peek()
+			Expect(err).To(MatchError(errDeadline))
+		})
+	})
+
+	Context("reading without copying", func() {
+		It("returns the received data", func() {
+			data := []byte("foobar")
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: data})).To(Succeed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			b, release, err := str.ReadBuffer()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("foobar")))
+			Expect(&b[0]).To(BeIdenticalTo(&data[0]))
+			Expect(release).ToNot(BeNil())
+			release()
+		})
+
+		It("hands the buffer to the application", func() {
+			var released bool
+			Expect(str.frameQueue.Push([]byte("foobar"), 0, func() { released = true })).To(Succeed())
+			b, release, err := str.ReadBuffer()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("foobar")))
+			Expect(released).To(BeFalse())
+			// the data only counts as read once it was released
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			release()
+			Expect(released).To(BeTrue())
+		})
+
+		It("doesn't count data released after the flow controller was abandoned", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			b, release, err := str.ReadBuffer()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("foobar")))
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true)
+			mockFC.EXPECT().Abandon()
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+				StreamID:  streamID,
+				ErrorCode: 1234,
+				FinalSize: 10,
+			})).To(Succeed())
+			release() // no call to AddBytesRead
+		})
+
+		It("returns the remaining data of a partially read frame", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4))
+			n, err := strWithTimeout.Read(make([]byte, 2))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(2))
+			b, release, err := str.ReadBuffer()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(b).To(Equal([]byte("obar")))
+			release()
+		})
+
+		It("blocks until data is received", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				b, release, err := str.ReadBuffer()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(b).To(Equal([]byte("foobar")))
+				release()
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("respects the read deadline", func() {
+			Expect(str.SetReadDeadline(time.Now().Add(scaleDuration(20 * time.Millisecond)))).To(Succeed())
+			b, _, err := str.ReadBuffer()
+			Expect(err).To(MatchError(errDeadline))
+			Expect(b).To(BeEmpty())
+		})
+
+		It("returns io.EOF with the last frame", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), true)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar"), Fin: true})).To(Succeed())
+			mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			b, release, err := str.ReadBuffer()
+			Expect(err).To(MatchError(io.EOF))
+			Expect(b).To(Equal([]byte("foobar")))
+			release()
+			_, _, err = str.ReadBuffer()
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		It("returns io.EOF for an empty frame with the FIN bit", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(0), true)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Fin: true})).To(Succeed())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			b, _, err := str.ReadBuffer()
+			Expect(err).To(MatchError(io.EOF))
+			Expect(b).To(BeEmpty())
+		})
+
+		It("only returns data up to the reliable size after receiving a RESET_STREAM_AT frame", func() {
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+			Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+			mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true)
+			Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{
+				StreamID:     streamID,
+				ErrorCode:    1234,
+				FinalSize:    10,
+				ReliableSize: 4,
+			})).To(Succeed())
+			// The flow controller is abandoned before the data is released.
+			// It accounts for the data that wasn't released yet.
+			gomock.InOrder(
+				mockFC.EXPECT().Abandon(),
+				mockSender.EXPECT().onStreamCompleted(streamID),
+			)
+			b, release, err := str.ReadBuffer()
+			Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+			Expect(b).To(Equal([]byte("foob")))
+			release()
+		})
+	})
+
 	Context("stream cancelations", func() {
 		Context("canceling read", func() {
 			It("unblocks Read", func() {
@@ -636,6 +892,120 @@ var _ = Describe("Receive Stream", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
+
+		Context("receiving RESET_STREAM_AT frames", func() {
+			It("delivers data up to the reliable size", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true)
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    10,
+					ReliableSize: 4,
+				})).To(Succeed())
+				gomock.InOrder(
+					mockFC.EXPECT().AddBytesRead(protocol.ByteCount(4)),
+					mockFC.EXPECT().Abandon(),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				b := make([]byte, 10)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+				Expect(n).To(Equal(4))
+				Expect(b[:n]).To(Equal([]byte("foob")))
+				_, err = strWithTimeout.Read(b)
+				Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+			})
+
+			It("unblocks Read when the reliable size is reached", func() {
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					b := make([]byte, 10)
+					n, err := strWithTimeout.Read(b)
+					Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+					Expect(b[:n]).To(Equal([]byte("foo")))
+					close(done)
+				}()
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true)
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    10,
+					ReliableSize: 3,
+				})).To(Succeed())
+				Consistently(done).ShouldNot(BeClosed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(3))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				Eventually(done).Should(BeClosed())
+			})
+
+			It("resets the stream immediately if the application already read the reliable data", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(6))
+				n, err := strWithTimeout.Read(make([]byte, 6))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(Equal(6))
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true)
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    10,
+					ReliableSize: 4,
+				})).To(Succeed())
+				_, err = strWithTimeout.Read([]byte{0})
+				Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+			})
+
+			It("reduces the reliable size, but never increases it", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true).Times(3)
+				for _, size := range []protocol.ByteCount{4, 5, 2} {
+					Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{
+						StreamID:     streamID,
+						ErrorCode:    1234,
+						FinalSize:    10,
+						ReliableSize: size,
+					})).To(Succeed())
+				}
+				mockFC.EXPECT().AddBytesRead(protocol.ByteCount(2))
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				b := make([]byte, 10)
+				n, err := strWithTimeout.Read(b)
+				Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+				Expect(b[:n]).To(Equal([]byte("fo")))
+			})
+
+			It("resets the stream immediately when a RESET_STREAM frame is received", func() {
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(6), false)
+				Expect(str.handleStreamFrame(&wire.StreamFrame{Data: []byte("foobar")})).To(Succeed())
+				mockFC.EXPECT().UpdateHighestReceived(protocol.ByteCount(10), true).Times(2)
+				Expect(str.handleResetStreamAtFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    10,
+					ReliableSize: 4,
+				})).To(Succeed())
+				mockFC.EXPECT().Abandon()
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				Expect(str.handleResetStreamFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+					FinalSize: 10,
+				})).To(Succeed())
+				_, err := strWithTimeout.Read([]byte{0})
+				Expect(err).To(MatchError("stream 1337 was reset with error code 1234"))
+			})
+		})
 	})
 
 	Context("flow control", func() {
@@ -16,6 +16,9 @@ type retransmissionQueue struct {
 
 	appData []wire.Frame
 
+	// the number of bytes of frames queued for retransmission
+	bytesQueued protocol.ByteCount
+
 	version protocol.VersionNumber
 }
 
@@ -24,6 +27,7 @@ func newRetransmissionQueue(ver protocol.VersionNumber) *retransmissionQueue {
 }
 
 func (q *retransmissionQueue) AddInitial(f wire.Frame) {
+	q.bytesQueued += f.Length(q.version)
 	if cf, ok := f.(*wire.CryptoFrame); ok {
 		q.initialCryptoData = append(q.initialCryptoData, cf)
 		return
@@ -32,6 +36,7 @@ func (q *retransmissionQueue) AddInitial(f wire.Frame) {
 }
 
 func (q *retransmissionQueue) AddHandshake(f wire.Frame) {
+	q.bytesQueued += f.Length(q.version)
 	if cf, ok := f.(*wire.CryptoFrame); ok {
 		q.handshakeCryptoData = append(q.handshakeCryptoData, cf)
 		return
@@ -55,9 +60,15 @@ func (q *retransmissionQueue) AddAppData(f wire.Frame) {
 	if _, ok := f.(*wire.StreamFrame); ok {
 		panic("STREAM frames are handled with their respective streams.")
 	}
+	q.bytesQueued += f.Length(q.version)
 	q.appData = append(q.appData, f)
 }
 
+// BytesQueued returns the number of bytes of frames that were queued for retransmission.
+func (q *retransmissionQueue) BytesQueued() protocol.ByteCount {
+	return q.bytesQueued
+}
+
 func (q *retransmissionQueue) GetInitialFrame(maxLen protocol.ByteCount) wire.Frame {
 	if len(q.initialCryptoData) > 0 {
 		f := q.initialCryptoData[0]
@@ -129,3 +140,7 @@ func (q *retransmissionQueue) DropPackets(encLevel protocol.EncryptionLevel) {
 		panic(fmt.Sprintf("unexpected encryption level: %s", encLevel))
 	}
 }
+
+func (q *retransmissionQueue) SetVersion(v protocol.VersionNumber) {
+	q.version = v
+}
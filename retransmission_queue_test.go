@@ -184,4 +184,19 @@ var _ = Describe("Retransmission queue", func() {
 			Expect(q.HasAppData()).To(BeFalse())
 		})
 	})
+
+	It("counts the bytes queued for retransmission", func() {
+		cf := &wire.CryptoFrame{Data: []byte("foobar")}
+		hf := &wire.CryptoFrame{Data: []byte("raboof")}
+		f := &wire.MaxDataFrame{MaximumData: 0x42}
+		Expect(q.BytesQueued()).To(BeZero())
+		q.AddInitial(cf)
+		q.AddHandshake(hf)
+		q.AddAppData(f)
+		Expect(q.BytesQueued()).To(Equal(cf.Length(version) + hf.Length(version) + f.Length(version)))
+		// dequeueing frames doesn't change the counter
+		Expect(q.GetAppDataFrame(protocol.MaxByteCount)).To(Equal(f))
+		q.DropPackets(protocol.EncryptionInitial)
+		Expect(q.BytesQueued()).To(Equal(cf.Length(version) + hf.Length(version) + f.Length(version)))
+	})
 })
@@ -2,14 +2,25 @@ package quic
 
 import (
 	"net"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+	"github.com/lucas-clemente/quic-go/internal/utils"
 )
 
 // A sendConn allows sending using a simple Write() on a non-connected packet conn.
 type sendConn interface {
 	Write([]byte) error
+	// WriteBatch writes multiple packets, each of them segmentSize bytes large.
+	// Only the last packet may be shorter.
+	WriteBatch(b []byte, segmentSize protocol.ByteCount) error
+	// SupportsGSO says if WriteBatch sends the packets using a single syscall.
+	SupportsGSO() bool
 	Close() error
 	LocalAddr() net.Addr
 	RemoteAddr() net.Addr
+	// WithRemoteAddr returns a sendConn that uses the same underlying connection,
+	// but sends to a different remote address.
+	WithRemoteAddr(net.Addr, *packetInfo) sendConn
 }
 
 type sconn struct {
@@ -36,10 +47,18 @@ func (c *sconn) Write(p []byte) error {
 	return err
 }
 
+func (c *sconn) WriteBatch(p []byte, segmentSize protocol.ByteCount) error {
+	return c.WritePackets(p, int(segmentSize), c.remoteAddr, c.oob)
+}
+
 func (c *sconn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
 
+func (c *sconn) WithRemoteAddr(remote net.Addr, info *packetInfo) sendConn {
+	return newSendConn(c.connection, remote, info)
+}
+
 func (c *sconn) LocalAddr() net.Addr {
 	addr := c.connection.LocalAddr()
 	if c.info != nil {
@@ -69,6 +88,23 @@ func (c *spconn) Write(p []byte) error {
 	return err
 }
 
+func (c *spconn) WriteBatch(p []byte, segmentSize protocol.ByteCount) error {
+	for len(p) > 0 {
+		l := utils.Min(len(p), int(segmentSize))
+		if err := c.Write(p[:l]); err != nil {
+			return err
+		}
+		p = p[l:]
+	}
+	return nil
+}
+
+func (c *spconn) SupportsGSO() bool { return false }
+
 func (c *spconn) RemoteAddr() net.Addr {
 	return c.remoteAddr
 }
+
+func (c *spconn) WithRemoteAddr(remote net.Addr, _ *packetInfo) sendConn {
+	return newSendPconn(c.PacketConn, remote)
+}
@@ -3,6 +3,8 @@ package quic
 import (
 	"net"
 
+	"github.com/golang/mock/gomock"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -25,6 +27,16 @@ var _ = Describe("Connection (for sending packets)", func() {
 		Expect(c.Write([]byte("foobar"))).To(Succeed())
 	})
 
+	It("writes batches of packets one by one", func() {
+		gomock.InOrder(
+			packetConn.EXPECT().WriteTo([]byte("foo"), addr),
+			packetConn.EXPECT().WriteTo([]byte("bar"), addr),
+			packetConn.EXPECT().WriteTo([]byte("ba"), addr),
+		)
+		Expect(c.WriteBatch([]byte("foobarba"), 3)).To(Succeed())
+		Expect(c.SupportsGSO()).To(BeFalse())
+	})
+
 	It("gets the remote address", func() {
 		Expect(c.RemoteAddr().String()).To(Equal("192.168.100.200:1337"))
 	})
@@ -38,6 +50,15 @@ var _ = Describe("Connection (for sending packets)", func() {
 		Expect(c.LocalAddr()).To(Equal(addr))
 	})
 
+	It("creates a connection for a different remote address", func() {
+		newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 100, 201), Port: 4242}
+		newConn := c.WithRemoteAddr(newAddr, nil)
+		Expect(newConn.RemoteAddr()).To(Equal(newAddr))
+		Expect(c.RemoteAddr()).To(Equal(addr))
+		packetConn.EXPECT().WriteTo([]byte("foobar"), newAddr)
+		Expect(newConn.Write([]byte("foobar"))).To(Succeed())
+	})
+
 	It("closes", func() {
 		packetConn.EXPECT().Close()
 		Expect(c.Close()).To(Succeed())
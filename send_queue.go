@@ -1,15 +1,24 @@
 package quic
 
+import "github.com/lucas-clemente/quic-go/internal/protocol"
+
 type sender interface {
 	Send(p *packetBuffer)
+	SendBatch(p *packetBuffer, segmentSize protocol.ByteCount)
 	Run() error
 	WouldBlock() bool
 	Available() <-chan struct{}
 	Close()
 }
 
+type queueEntry struct {
+	buf *packetBuffer
+	// segmentSize is set if buf contains multiple packets
+	segmentSize protocol.ByteCount
+}
+
 type sendQueue struct {
-	queue       chan *packetBuffer
+	queue       chan queueEntry
 	closeCalled chan struct{} // runStopped when Close() is called
 	runStopped  chan struct{} // runStopped when the run loop returns
 	available   chan struct{}
@@ -26,7 +35,7 @@ func newSendQueue(conn sendConn) sender {
 		runStopped:  make(chan struct{}),
 		closeCalled: make(chan struct{}),
 		available:   make(chan struct{}, 1),
-		queue:       make(chan *packetBuffer, sendQueueCapacity),
+		queue:       make(chan queueEntry, sendQueueCapacity),
 	}
 }
 
@@ -34,8 +43,19 @@ func newSendQueue(conn sendConn) sender {
 // Callers need to make sure that there's actually space in the send queue by calling WouldBlock.
 // Otherwise Send will panic.
 func (h *sendQueue) Send(p *packetBuffer) {
+	h.send(queueEntry{buf: p})
+}
+
+// SendBatch sends out multiple packets contained in a single buffer.
+// All packets are segmentSize bytes large, only the last one may be shorter.
+// The same restrictions as for Send apply.
+func (h *sendQueue) SendBatch(p *packetBuffer, segmentSize protocol.ByteCount) {
+	h.send(queueEntry{buf: p, segmentSize: segmentSize})
+}
+
+func (h *sendQueue) send(e queueEntry) {
 	select {
-	case h.queue <- p:
+	case h.queue <- e:
 	case <-h.runStopped:
 	default:
 		panic("sendQueue.Send would have blocked")
@@ -62,11 +82,15 @@ func (h *sendQueue) Run() error {
 			h.closeCalled = nil // prevent this case from being selected again
 			// make sure that all queued packets are actually sent out
 			shouldClose = true
-		case p := <-h.queue:
-			if err := h.conn.Write(p.Data); err != nil {
+		case e := <-h.queue:
+			if e.segmentSize == 0 {
+				if err := h.conn.Write(e.buf.Data); err != nil {
+					return err
+				}
+			} else if err := h.conn.WriteBatch(e.buf.Data, e.segmentSize); err != nil {
 				return err
 			}
-			p.Release()
+			e.buf.Release()
 			select {
 			case h.available <- struct{}{}:
 			default:
@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"github.com/golang/mock/gomock"
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -42,6 +44,25 @@ var _ = Describe("Send Queue", func() {
 		Eventually(done).Should(BeClosed())
 	})
 
+	It("sends a batch of packets", func() {
+		buf := getLargePacketBuffer()
+		buf.Data = append(buf.Data, []byte("foobarfoobarfoo")...)
+		q.SendBatch(buf, 6)
+
+		written := make(chan struct{})
+		c.EXPECT().WriteBatch([]byte("foobarfoobarfoo"), protocol.ByteCount(6)).Do(func([]byte, protocol.ByteCount) { close(written) })
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			q.Run()
+			close(done)
+		}()
+
+		Eventually(written).Should(BeClosed())
+		q.Close()
+		Eventually(done).Should(BeClosed())
+	})
+
 	It("panics when Send() is called although there's no space in the queue", func() {
 		for i := 0; i < sendQueueCapacity; i++ {
 			Expect(q.WouldBlock()).To(BeFalse())
@@ -3,6 +3,7 @@ package quic
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -23,6 +24,13 @@ type sendStreamI interface {
 	updateSendWindow(protocol.ByteCount)
 }
 
+// A sendBuffer is a buffer passed to WriteBuffer.
+// STREAM frames reference its memory directly, until they are acknowledged.
+type sendBuffer struct {
+	refs    int // the number of STREAM frames referencing the buffer, plus 1 while WriteBuffer is running
+	release func()
+}
+
 type sendStream struct {
 	mutex sync.Mutex
 
@@ -40,18 +48,35 @@ type sendStream struct {
 	cancelWriteErr      error
 	closeForShutdownErr error
 
-	closedForShutdown bool // set when CloseForShutdown() is called
-	finishedWriting   bool // set once Close() is called
-	canceledWrite     bool // set when CancelWrite() is called, or a STOP_SENDING frame is received
-	finSent           bool // set when a STREAM_FRAME with FIN bit has been sent
-	completed         bool // set when this stream has been reported to the streamSender as completed
+	closedForShutdown bool               // set when CloseForShutdown() is called
+	finishedWriting   bool               // set once Close() is called
+	canceledWrite     bool               // set when CancelWrite() is called, or a STOP_SENDING frame is received
+	reliableSize      protocol.ByteCount // when canceled using CancelWriteAt(), data up to this offset is still delivered
+	finalSize         protocol.ByteCount // the final size sent in the RESET_STREAM / RESET_STREAM_AT frame
+	finSent           bool               // set when a STREAM_FRAME with FIN bit has been sent
+	completed         bool               // set when this stream has been reported to the streamSender as completed
 
-	dataForWriting []byte // during a Write() call, this slice is the part of p that still needs to be sent out
+	dataForWriting []byte      // during a Write() call, this slice is the part of p that still needs to be sent out
+	writeBuffer    *sendBuffer // set during a WriteBuffer() call
 	nextFrame      *wire.StreamFrame
+	sendBuffers    map[*wire.StreamFrame]*sendBuffer // the buffers passed to WriteBuffer, referenced by STREAM frames
+
+	waitingForSendCredit bool               // set while ReadFrom waits for flow control credit
+	sendCredit           protocol.ByteCount // the send window, as seen the last time a STREAM frame was popped
 
 	writeChan chan struct{}
 	deadline  time.Time
 
+	ackChan chan struct{} // closed when a frame is acknowledged, to wake up WaitForAcks
+
+	bytesWritten       protocol.ByteCount
+	bytesRetransmitted protocol.ByteCount
+	bytesAcked         protocol.ByteCount
+	blockedSince       time.Time // set while the stream is blocked by flow control
+	blockedTime        time.Duration
+
+	priority protocol.Priority
+
 	flowController flowcontrol.StreamFlowController
 
 	version protocol.VersionNumber
@@ -73,6 +98,7 @@ func newSendStream(
 		sender:         sender,
 		flowController: flowController,
 		writeChan:      make(chan struct{}, 1),
+		priority:       protocol.DefaultPriority,
 		version:        version,
 	}
 	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
@@ -85,8 +111,33 @@ func (s *sendStream) StreamID() protocol.StreamID {
 
 func (s *sendStream) Write(p []byte) (int, error) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	n, err := s.writeImpl(p, nil)
+	s.bytesWritten += protocol.ByteCount(n)
+	s.mutex.Unlock()
+	return n, err
+}
 
+func (s *sendStream) WriteBuffer(b []byte, release func()) (int, error) {
+	buf := &sendBuffer{refs: 1, release: release}
+	s.mutex.Lock()
+	n, err := s.writeImpl(b, buf)
+	s.bytesWritten += protocol.ByteCount(n)
+	// data that wasn't sent when Write returned is never sent
+	s.dataForWriting = nil
+	s.writeBuffer = nil
+	buf.refs--
+	unreferenced := buf.refs == 0
+	s.mutex.Unlock()
+
+	if unreferenced {
+		release()
+	}
+	return n, err
+}
+
+// writeImpl writes p to the stream. If buf is set, p is sent without copying it.
+// It must be called with the mutex held.
+func (s *sendStream) writeImpl(p []byte, buf *sendBuffer) (int, error) {
 	if s.finishedWriting {
 		return 0, fmt.Errorf("write on closed stream %d", s.streamID)
 	}
@@ -104,6 +155,12 @@ func (s *sendStream) Write(p []byte) (int, error) {
 	}
 
 	s.dataForWriting = p
+	s.writeBuffer = buf
+	// the stream offset of the first byte of p
+	startOffset := s.writeOffset
+	if s.nextFrame != nil {
+		startOffset += s.nextFrame.DataLen()
+	}
 
 	var (
 		deadlineTimer  *utils.Timer
@@ -111,6 +168,10 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		notifiedSender bool
 	)
 	for {
+		// The data that wasn't sent yet was dropped when the stream was canceled.
+		if s.canceledWrite {
+			return int(utils.MaxByteCount(s.writeOffset, startOffset) - startOffset), s.cancelWriteErr
+		}
 		var copied bool
 		var deadline time.Time
 		// As soon as dataForWriting becomes smaller than a certain size x, we copy all the data to a STREAM frame (s.nextFrame),
@@ -118,7 +179,8 @@ func (s *sendStream) Write(p []byte) (int, error) {
 		// This allows us to return Write() when all data but x bytes have been sent out.
 		// When the user now calls Close(), this is much more likely to happen before we popped that last STREAM frame,
 		// allowing us to set the FIN bit on that frame (instead of sending an empty STREAM frame with FIN).
-		if s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
+		// Data passed to WriteBuffer is never copied into the nextFrame.
+		if buf == nil && s.canBufferStreamFrame() && len(s.dataForWriting) > 0 {
 			if s.nextFrame == nil {
 				f := wire.GetStreamFrame()
 				f.Offset = s.writeOffset
@@ -186,6 +248,99 @@ func (s *sendStream) Write(p []byte) (int, error) {
 	return bytesWritten, nil
 }
 
+// readFromBufferSize is the size of the buffers used by ReadFrom.
+const readFromBufferSize = 32 * 1024
+
+var readFromBufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, readFromBufferSize)
+		return &b
+	},
+}
+
+// ReadFrom implements io.ReaderFrom.
+// The data is read from r into buffers that are sent using WriteBuffer, i.e. without copying it again.
+// Data is only read from r when the stream has flow control credit, and never more than the credit allows.
+// Like Write, it blocks until the data has been sent, as flow control and congestion control allow.
+func (s *sendStream) ReadFrom(r io.Reader) (int64, error) {
+	var written int64
+	for {
+		credit, err := s.waitForSendCredit()
+		if err != nil {
+			return written, err
+		}
+		buf := readFromBufferPool.Get().(*[]byte)
+		n, err := r.Read((*buf)[:utils.MinByteCount(credit, readFromBufferSize)])
+		if n > 0 {
+			m, werr := s.WriteBuffer((*buf)[:n], func() { readFromBufferPool.Put(buf) })
+			written += int64(m)
+			if werr != nil {
+				return written, werr
+			}
+		} else {
+			readFromBufferPool.Put(buf)
+		}
+		if err == io.EOF {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+}
+
+// waitForSendCredit blocks until the stream has flow control credit.
+// It returns the number of bytes that can be sent.
+func (s *sendStream) waitForSendCredit() (protocol.ByteCount, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var deadlineTimer *utils.Timer
+	for {
+		s.waitingForSendCredit = false
+		if s.finishedWriting {
+			return 0, fmt.Errorf("write on closed stream %d", s.streamID)
+		}
+		if s.canceledWrite {
+			return 0, s.cancelWriteErr
+		}
+		if s.closeForShutdownErr != nil {
+			return 0, s.closeForShutdownErr
+		}
+		deadline := s.deadline
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return 0, errDeadline
+		}
+		// The flow controller is only accessed from the session's run loop.
+		// popStreamFrame updates the send credit, and signals the writeChan when it is available.
+		if credit := s.sendCredit; credit > 0 {
+			s.sendCredit = 0
+			return credit, nil
+		}
+		s.waitingForSendCredit = true
+		if !deadline.IsZero() {
+			if deadlineTimer == nil {
+				deadlineTimer = utils.NewTimer()
+				defer deadlineTimer.Stop()
+			}
+			deadlineTimer.Reset(deadline)
+		}
+
+		s.mutex.Unlock()
+		s.sender.onHasStreamData(s.streamID) // must be called without holding the mutex
+		if deadline.IsZero() {
+			<-s.writeChan
+		} else {
+			select {
+			case <-s.writeChan:
+			case <-deadlineTimer.Chan():
+				deadlineTimer.SetRead()
+			}
+		}
+		s.mutex.Lock()
+	}
+}
+
 func (s *sendStream) canBufferStreamFrame() bool {
 	var l protocol.ByteCount
 	if s.nextFrame != nil {
@@ -211,7 +366,7 @@ func (s *sendStream) popStreamFrame(maxBytes protocol.ByteCount) (*ackhandler.Fr
 }
 
 func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCount) (*wire.StreamFrame, bool /* has more data to send */) {
-	if s.canceledWrite || s.closeForShutdownErr != nil {
+	if (s.canceledWrite && s.reliableSize == 0) || s.closeForShutdownErr != nil {
 		return nil, false
 	}
 
@@ -221,12 +376,18 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 			if f == nil {
 				return nil, true
 			}
+			s.bytesRetransmitted += f.DataLen()
 			// We always claim that we have more data to send.
 			// This might be incorrect, in which case there'll be a spurious call to popStreamFrame in the future.
 			return f, true
 		}
 	}
 
+	// After CancelWriteAt, only the data that was buffered before canceling is sent.
+	if s.canceledWrite && s.nextFrame == nil {
+		return nil, false
+	}
+
 	if len(s.dataForWriting) == 0 && s.nextFrame == nil {
 		if s.finishedWriting && !s.finSent {
 			s.finSent = true
@@ -237,11 +398,17 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 				Fin:            true,
 			}, false
 		}
-		return nil, false
+		if !s.waitingForSendCredit {
+			return nil, false
+		}
 	}
 
 	sendWindow := s.flowController.SendWindowSize()
+	s.sendCredit = sendWindow
 	if sendWindow == 0 {
+		if s.blockedSince.IsZero() {
+			s.blockedSince = time.Now()
+		}
 		if isBlocked, offset := s.flowController.IsNewlyBlocked(); isBlocked {
 			s.sender.queueControlFrame(&wire.StreamDataBlockedFrame{
 				StreamID:          s.streamID,
@@ -252,12 +419,22 @@ func (s *sendStream) popNewOrRetransmittedStreamFrame(maxBytes protocol.ByteCoun
 		return nil, true
 	}
 
+	s.stopFlowControlBlocked()
+
+	// ReadFrom is waiting for flow control credit, and there's no data to send.
+	if s.waitingForSendCredit && len(s.dataForWriting) == 0 && s.nextFrame == nil {
+		s.waitingForSendCredit = false
+		s.signalWrite()
+		return nil, false
+	}
+
 	f, hasMoreData := s.popNewStreamFrame(maxBytes, sendWindow)
 	if dataLen := f.DataLen(); dataLen > 0 {
 		s.writeOffset += f.DataLen()
 		s.flowController.AddBytesSent(f.DataLen())
 	}
-	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent
+	s.sendCredit = sendWindow - f.DataLen()
+	f.Fin = s.finishedWriting && s.dataForWriting == nil && s.nextFrame == nil && !s.finSent && !s.canceledWrite
 	if f.Fin {
 		s.finSent = true
 	}
@@ -284,7 +461,13 @@ func (s *sendStream) popNewStreamFrame(maxBytes, sendWindow protocol.ByteCount)
 		return nextFrame, s.nextFrame != nil || s.dataForWriting != nil
 	}
 
-	f := wire.GetStreamFrame()
+	var f *wire.StreamFrame
+	if s.writeBuffer != nil {
+		// This frame will reference the buffer passed to WriteBuffer, so it must not be returned to the pool.
+		f = &wire.StreamFrame{}
+	} else {
+		f = wire.GetStreamFrame()
+	}
 	f.Fin = false
 	f.StreamID = s.streamID
 	f.Offset = s.writeOffset
@@ -313,6 +496,11 @@ func (s *sendStream) maybeGetRetransmission(maxBytes protocol.ByteCount) (*wire.
 	f := s.retransmissionQueue[0]
 	newFrame, needsSplit := f.MaybeSplitOffFrame(maxBytes, s.version)
 	if needsSplit {
+		// When splitting, the data slice of f is moved to newFrame.
+		if buf, ok := s.sendBuffers[f]; ok && newFrame != nil {
+			delete(s.sendBuffers, f)
+			s.sendBuffers[newFrame] = buf
+		}
 		return newFrame, true
 	}
 	s.retransmissionQueue = s.retransmissionQueue[1:]
@@ -327,6 +515,10 @@ func (s *sendStream) hasData() bool {
 }
 
 func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.ByteCount) {
+	if s.writeBuffer != nil {
+		s.getBufferForWriting(f, maxBytes)
+		return
+	}
 	if protocol.ByteCount(len(s.dataForWriting)) <= maxBytes {
 		f.Data = f.Data[:len(s.dataForWriting)]
 		copy(f.Data, s.dataForWriting)
@@ -342,11 +534,57 @@ func (s *sendStream) getDataForWriting(f *wire.StreamFrame, maxBytes protocol.By
 	}
 }
 
+// getBufferForWriting is like getDataForWriting, for data passed to WriteBuffer.
+// Instead of copying the data, the STREAM frame references the buffer.
+func (s *sendStream) getBufferForWriting(f *wire.StreamFrame, maxBytes protocol.ByteCount) {
+	n := utils.MinByteCount(protocol.ByteCount(len(s.dataForWriting)), maxBytes)
+	f.Data = s.dataForWriting[:n:n]
+	if s.sendBuffers == nil {
+		s.sendBuffers = make(map[*wire.StreamFrame]*sendBuffer)
+	}
+	s.sendBuffers[f] = s.writeBuffer
+	s.writeBuffer.refs++
+	if n == protocol.ByteCount(len(s.dataForWriting)) {
+		s.dataForWriting = nil
+		s.signalWrite()
+		return
+	}
+	s.dataForWriting = s.dataForWriting[n:]
+}
+
+// releaseSendBuffer removes the reference that a STREAM frame holds on a buffer passed to WriteBuffer.
+// If this was the last reference, it returns the release function of the buffer.
+// The release function must be called without holding the mutex.
+func (s *sendStream) releaseSendBuffer(f *wire.StreamFrame) func() {
+	buf, ok := s.sendBuffers[f]
+	if !ok {
+		return nil
+	}
+	delete(s.sendBuffers, f)
+	buf.refs--
+	if buf.refs > 0 {
+		return nil
+	}
+	return buf.release
+}
+
+// releaseAllSendBuffers removes the references that STREAM frames hold on buffers passed to WriteBuffer.
+func (s *sendStream) releaseAllSendBuffers() []func() {
+	var releases []func()
+	for f := range s.sendBuffers {
+		if release := s.releaseSendBuffer(f); release != nil {
+			releases = append(releases, release)
+		}
+	}
+	return releases
+}
+
 func (s *sendStream) frameAcked(f wire.Frame) {
+	dataLen := f.(*wire.StreamFrame).DataLen()
 	f.(*wire.StreamFrame).PutBack()
 
 	s.mutex.Lock()
-	if s.canceledWrite {
+	if s.canceledWrite && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
@@ -354,16 +592,22 @@ func (s *sendStream) frameAcked(f wire.Frame) {
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	s.bytesAcked += dataLen
+	s.signalAcked()
+	release := s.releaseSendBuffer(f.(*wire.StreamFrame))
 	newlyCompleted := s.isNewlyCompleted()
 	s.mutex.Unlock()
 
+	if release != nil {
+		release()
+	}
 	if newlyCompleted {
 		s.sender.onStreamCompleted(s.streamID)
 	}
 }
 
 func (s *sendStream) isNewlyCompleted() bool {
-	completed := (s.finSent || s.canceledWrite) && s.numOutstandingFrames == 0 && len(s.retransmissionQueue) == 0
+	completed := (s.finSent || s.canceledWrite) && s.numOutstandingFrames == 0 && len(s.retransmissionQueue) == 0 && s.nextFrame == nil
 	if completed && !s.completed {
 		s.completed = true
 		return true
@@ -375,20 +619,54 @@ func (s *sendStream) queueRetransmission(f wire.Frame) {
 	sf := f.(*wire.StreamFrame)
 	sf.DataLenPresent = true
 	s.mutex.Lock()
-	if s.canceledWrite {
+	if s.canceledWrite && s.reliableSize == 0 {
 		s.mutex.Unlock()
 		return
 	}
-	s.retransmissionQueue = append(s.retransmissionQueue, sf)
 	s.numOutstandingFrames--
 	if s.numOutstandingFrames < 0 {
 		panic("numOutStandingFrames negative")
 	}
+	if s.canceledWrite {
+		// only retransmit data below the reliable size
+		var release func()
+		sf, release = s.truncateToReliableSize(sf)
+		if sf == nil {
+			newlyCompleted := s.isNewlyCompleted()
+			s.mutex.Unlock()
+			if release != nil {
+				release()
+			}
+			if newlyCompleted {
+				s.sender.onStreamCompleted(s.streamID)
+			}
+			return
+		}
+	}
+	s.retransmissionQueue = append(s.retransmissionQueue, sf)
+	length := sf.Length(s.version)
 	s.mutex.Unlock()
 
+	s.sender.onQueuedRetransmission(length)
 	s.sender.onHasStreamData(s.streamID)
 }
 
+// truncateToReliableSize removes all data beyond the reliable size from a STREAM frame.
+// It returns nil if the frame doesn't contain any data below the reliable size.
+// In that case, the release function of a buffer passed to WriteBuffer might be returned.
+func (s *sendStream) truncateToReliableSize(f *wire.StreamFrame) (*wire.StreamFrame, func()) {
+	f.Fin = false
+	if f.Offset >= s.reliableSize {
+		release := s.releaseSendBuffer(f)
+		f.PutBack()
+		return nil, release
+	}
+	if f.Offset+f.DataLen() > s.reliableSize {
+		f.Data = f.Data[:s.reliableSize-f.Offset]
+	}
+	return f, nil
+}
+
 func (s *sendStream) Close() error {
 	s.mutex.Lock()
 	if s.closedForShutdown {
@@ -408,30 +686,94 @@ func (s *sendStream) Close() error {
 }
 
 func (s *sendStream) CancelWrite(errorCode protocol.ApplicationErrorCode) {
-	s.cancelWriteImpl(errorCode, fmt.Errorf("Write on stream %d canceled with error code %d", s.streamID, errorCode))
+	s.cancelWriteImpl(errorCode, 0, fmt.Errorf("Write on stream %d canceled with error code %d", s.streamID, errorCode))
 }
 
-// must be called after locking the mutex
-func (s *sendStream) cancelWriteImpl(errorCode protocol.ApplicationErrorCode, writeErr error) {
+func (s *sendStream) CancelWriteAt(errorCode protocol.ApplicationErrorCode, offset uint64) {
+	var reliableSize protocol.ByteCount
+	if s.sender.supportsResetStreamAt() {
+		reliableSize = protocol.ByteCount(utils.MinUint64(offset, uint64(protocol.MaxByteCount)))
+	}
+	s.cancelWriteImpl(errorCode, reliableSize, fmt.Errorf("Write on stream %d canceled with error code %d", s.streamID, errorCode))
+}
+
+func (s *sendStream) cancelWriteImpl(errorCode protocol.ApplicationErrorCode, reliableSize protocol.ByteCount, writeErr error) {
 	s.mutex.Lock()
-	if s.canceledWrite {
+	// A stream can be canceled again, in order to reduce the reliable size.
+	if s.canceledWrite && reliableSize >= s.reliableSize {
 		s.mutex.Unlock()
 		return
 	}
-	s.ctxCancel()
-	s.canceledWrite = true
-	s.cancelWriteErr = writeErr
-	s.numOutstandingFrames = 0
-	s.retransmissionQueue = nil
+	if !s.canceledWrite {
+		s.ctxCancel()
+		s.canceledWrite = true
+		s.cancelWriteErr = writeErr
+		// Data passed to a Write call that is still blocked is never sent.
+		s.dataForWriting = nil
+		// Data that is still buffered in the nextFrame has already been accepted by Write.
+		// It counts towards the data that can be delivered reliably.
+		written := s.writeOffset
+		if s.nextFrame != nil {
+			written += s.nextFrame.DataLen()
+		}
+		reliableSize = utils.MinByteCount(reliableSize, written)
+		s.finalSize = utils.MaxByteCount(s.writeOffset, reliableSize)
+	}
+	s.reliableSize = reliableSize
+	s.stopFlowControlBlocked()
+	s.signalAcked()
+	var releases []func()
+	if reliableSize == 0 {
+		s.numOutstandingFrames = 0
+		s.retransmissionQueue = nil
+		if s.nextFrame != nil {
+			s.nextFrame.PutBack()
+			s.nextFrame = nil
+		}
+		releases = s.releaseAllSendBuffers()
+	} else {
+		retransmissionQueue := s.retransmissionQueue[:0]
+		for _, f := range s.retransmissionQueue {
+			f, release := s.truncateToReliableSize(f)
+			if f != nil {
+				retransmissionQueue = append(retransmissionQueue, f)
+			}
+			if release != nil {
+				releases = append(releases, release)
+			}
+		}
+		s.retransmissionQueue = retransmissionQueue
+		if s.nextFrame != nil {
+			s.nextFrame, _ = s.truncateToReliableSize(s.nextFrame)
+		}
+	}
 	newlyCompleted := s.isNewlyCompleted()
+	hasStreamData := s.nextFrame != nil || len(s.retransmissionQueue) > 0
+	var f wire.Frame
+	if reliableSize > 0 {
+		f = &wire.ResetStreamAtFrame{
+			StreamID:     s.streamID,
+			ErrorCode:    errorCode,
+			FinalSize:    s.finalSize,
+			ReliableSize: reliableSize,
+		}
+	} else {
+		f = &wire.ResetStreamFrame{
+			StreamID:  s.streamID,
+			FinalSize: s.finalSize,
+			ErrorCode: errorCode,
+		}
+	}
 	s.mutex.Unlock()
 
+	for _, release := range releases {
+		release()
+	}
 	s.signalWrite()
-	s.sender.queueControlFrame(&wire.ResetStreamFrame{
-		StreamID:  s.streamID,
-		FinalSize: s.writeOffset,
-		ErrorCode: errorCode,
-	})
+	s.sender.queueControlFrame(f)
+	if hasStreamData {
+		s.sender.onHasStreamData(s.streamID)
+	}
 	if newlyCompleted {
 		s.sender.onStreamCompleted(s.streamID)
 	}
@@ -439,7 +781,7 @@ func (s *sendStream) cancelWriteImpl(errorCode protocol.ApplicationErrorCode, wr
 
 func (s *sendStream) updateSendWindow(limit protocol.ByteCount) {
 	s.mutex.Lock()
-	hasStreamData := s.dataForWriting != nil || s.nextFrame != nil
+	hasStreamData := s.dataForWriting != nil || s.nextFrame != nil || s.waitingForSendCredit
 	s.mutex.Unlock()
 
 	s.flowController.UpdateSendWindow(limit)
@@ -453,7 +795,7 @@ func (s *sendStream) handleStopSendingFrame(frame *wire.StopSendingFrame) {
 		errorCode: frame.ErrorCode,
 		error:     fmt.Errorf("stream %d was reset with error code %d", s.streamID, frame.ErrorCode),
 	}
-	s.cancelWriteImpl(frame.ErrorCode, writeErr)
+	s.cancelWriteImpl(frame.ErrorCode, 0, writeErr)
 }
 
 func (s *sendStream) Context() context.Context {
@@ -468,6 +810,101 @@ func (s *sendStream) SetWriteDeadline(t time.Time) error {
 	return nil
 }
 
+func (s *sendStream) SetPriority(prio protocol.Priority) {
+	if prio.Urgency > protocol.MaxUrgency {
+		prio.Urgency = protocol.MaxUrgency
+	}
+	s.mutex.Lock()
+	changed := s.priority != prio
+	s.priority = prio
+	hasStreamData := s.dataForWriting != nil || s.nextFrame != nil || len(s.retransmissionQueue) > 0 || (s.finishedWriting && !s.finSent)
+	s.mutex.Unlock()
+
+	// If the stream is already queued for sending, it needs to be rescheduled.
+	if changed && hasStreamData {
+		s.sender.onHasStreamData(s.streamID)
+	}
+}
+
+func (s *sendStream) Priority() protocol.Priority {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.priority
+}
+
+func (s *sendStream) SendStats() SendStreamStats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	blockedTime := s.blockedTime
+	if !s.blockedSince.IsZero() {
+		blockedTime += time.Since(s.blockedSince)
+	}
+	return SendStreamStats{
+		BytesWritten:           uint64(s.bytesWritten),
+		BytesSent:              uint64(s.writeOffset),
+		BytesRetransmitted:     uint64(s.bytesRetransmitted),
+		BytesAcked:             uint64(s.bytesAcked),
+		FlowControlBlockedTime: blockedTime,
+	}
+}
+
+// stopFlowControlBlocked is called when the stream is not blocked by flow control any more.
+func (s *sendStream) stopFlowControlBlocked() {
+	if s.blockedSince.IsZero() {
+		return
+	}
+	s.blockedTime += time.Since(s.blockedSince)
+	s.blockedSince = time.Time{}
+}
+
+func (s *sendStream) WaitForAcks(ctx context.Context) error {
+	s.mutex.Lock()
+	for {
+		if s.closeForShutdownErr != nil {
+			err := s.closeForShutdownErr
+			s.mutex.Unlock()
+			return err
+		}
+		if s.canceledWrite {
+			err := s.cancelWriteErr
+			s.mutex.Unlock()
+			return err
+		}
+		if s.allDataAcked() {
+			s.mutex.Unlock()
+			return nil
+		}
+		if s.ackChan == nil {
+			s.ackChan = make(chan struct{})
+		}
+		ackChan := s.ackChan
+		s.mutex.Unlock()
+
+		select {
+		case <-ackChan:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		s.mutex.Lock()
+	}
+}
+
+// allDataAcked says if all data written (and the FIN, if the stream was closed) was acknowledged.
+func (s *sendStream) allDataAcked() bool {
+	return s.dataForWriting == nil && s.nextFrame == nil && len(s.retransmissionQueue) == 0 &&
+		s.numOutstandingFrames == 0 && (!s.finishedWriting || s.finSent)
+}
+
+// signalAcked wakes up all WaitForAcks calls.
+// It must be called with the mutex held.
+func (s *sendStream) signalAcked() {
+	if s.ackChan != nil {
+		close(s.ackChan)
+		s.ackChan = nil
+	}
+}
+
 // CloseForShutdown closes a stream abruptly.
 // It makes Write unblock (and return the error) immediately.
 // The peer will NOT be informed about this: the stream is closed without sending a FIN or RST.
@@ -476,7 +913,13 @@ func (s *sendStream) closeForShutdown(err error) {
 	s.ctxCancel()
 	s.closedForShutdown = true
 	s.closeForShutdownErr = err
+	s.stopFlowControlBlocked()
+	s.signalAcked()
+	releases := s.releaseAllSendBuffers()
 	s.mutex.Unlock()
+	for _, release := range releases {
+		release()
+	}
 	s.signalWrite()
 }
 
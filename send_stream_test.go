@@ -2,6 +2,7 @@ package quic
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	mrand "math/rand"
@@ -649,6 +650,348 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("writing buffers without copying", func() {
+		var (
+			data     []byte
+			released chan struct{}
+			done     chan struct{}
+			written  int
+			writeErr error
+		)
+
+		// pops a STREAM frame containing 50 bytes
+		popFrame := func() *ackhandler.Frame {
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(50))
+			frame, _ := str.popStreamFrame(expectedFrameHeaderLen(str.writeOffset) + 50)
+			ExpectWithOffset(1, frame).ToNot(BeNil())
+			return frame
+		}
+
+		BeforeEach(func() {
+			data = getData(150)
+			released = make(chan struct{})
+			done = make(chan struct{})
+			mockSender.EXPECT().onHasStreamData(streamID)
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				written, writeErr = str.WriteBuffer(data, func() { close(released) })
+			}()
+			waitForWrite()
+		})
+
+		It("sends the data without copying it, and releases the buffer when all frames are acknowledged", func() {
+			var frames []*ackhandler.Frame
+			for i := 0; i < 3; i++ {
+				frame := popFrame()
+				f := frame.Frame.(*wire.StreamFrame)
+				Expect(f.Offset).To(BeEquivalentTo(i * 50))
+				Expect(f.Data).To(Equal(data[i*50 : (i+1)*50]))
+				Expect(&f.Data[0]).To(BeIdenticalTo(&data[i*50]))
+				frames = append(frames, frame)
+			}
+			Eventually(done).Should(BeClosed())
+			Expect(writeErr).ToNot(HaveOccurred())
+			Expect(written).To(Equal(150))
+			frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).To(BeNil())
+			Expect(hasMoreData).To(BeFalse())
+			frames[0].OnAcked(frames[0].Frame)
+			frames[2].OnAcked(frames[2].Frame)
+			Consistently(released).ShouldNot(BeClosed())
+			frames[1].OnAcked(frames[1].Frame)
+			Expect(released).To(BeClosed())
+		})
+
+		It("releases the buffer after retransmissions are acknowledged", func() {
+			frame1 := popFrame()
+			frame2 := popFrame()
+			frame3 := popFrame()
+			Eventually(done).Should(BeClosed())
+			frame2.OnAcked(frame2.Frame)
+			frame3.OnAcked(frame3.Frame)
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
+			mockSender.EXPECT().onHasStreamData(streamID)
+			frame1.OnLost(frame1.Frame)
+			// the retransmission is split into two frames
+			r1, _ := str.popStreamFrame(expectedFrameHeaderLen(0) + 30)
+			Expect(r1).ToNot(BeNil())
+			Expect(r1.Frame.(*wire.StreamFrame).Data).To(Equal(data[:30]))
+			r2, _ := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(r2).ToNot(BeNil())
+			Expect(r2.Frame.(*wire.StreamFrame).Offset).To(BeEquivalentTo(30))
+			Expect(r2.Frame.(*wire.StreamFrame).Data).To(Equal(data[30:50]))
+			r2.OnAcked(r2.Frame)
+			Consistently(released).ShouldNot(BeClosed())
+			r1.OnAcked(r1.Frame)
+			Expect(released).To(BeClosed())
+		})
+
+		It("releases the buffer when the stream is canceled", func() {
+			popFrame()
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			str.CancelWrite(1234)
+			Eventually(done).Should(BeClosed())
+			Expect(writeErr).To(MatchError("Write on stream 1337 canceled with error code 1234"))
+			Expect(written).To(Equal(50))
+			Expect(released).To(BeClosed())
+		})
+
+		It("releases the buffer when the stream is closed for shutdown", func() {
+			popFrame()
+			str.closeForShutdown(errors.New("shutdown"))
+			Eventually(done).Should(BeClosed())
+			Expect(writeErr).To(MatchError("shutdown"))
+			Expect(released).To(BeClosed())
+		})
+	})
+
+	It("releases the buffer passed to WriteBuffer when an error occurs", func() {
+		var released bool
+		mockSender.EXPECT().onHasStreamData(streamID)
+		Expect(str.Close()).To(Succeed())
+		_, err := str.WriteBuffer([]byte("foobar"), func() { released = true })
+		Expect(err).To(MatchError("write on closed stream 1337"))
+		Expect(released).To(BeTrue())
+	})
+
+	Context("reading from an io.Reader", func() {
+		waitForSendCredit := func() {
+			EventuallyWithOffset(1, func() bool {
+				str.mutex.Lock()
+				defer str.mutex.Unlock()
+				return str.waitingForSendCredit
+			}).Should(BeTrue())
+		}
+
+		It("sends the data read from the reader", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				n, err := str.ReadFrom(bytes.NewReader([]byte("foobar")))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(n).To(BeEquivalentTo(6))
+			}()
+			waitForSendCredit()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).To(BeNil())
+			Expect(hasMoreData).To(BeFalse())
+			waitForWrite()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal([]byte("foobar")))
+			Eventually(done).Should(BeClosed())
+			frame.OnAcked(frame.Frame)
+			Expect(str.SendStats().BytesAcked).To(BeEquivalentTo(6))
+		})
+
+		It("only reads from the reader when the stream has send credit", func() {
+			r := bytes.NewReader([]byte("foobar"))
+			mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				n, err := str.ReadFrom(r)
+				Expect(err).To(MatchError("shutdown"))
+				Expect(n).To(BeEquivalentTo(3))
+			}()
+			waitForSendCredit()
+			// the stream is blocked by flow control
+			mockFC.EXPECT().SendWindowSize()
+			mockFC.EXPECT().IsNewlyBlocked().Return(true, protocol.ByteCount(10))
+			mockSender.EXPECT().queueControlFrame(&wire.StreamDataBlockedFrame{StreamID: streamID, MaximumStreamData: 10})
+			frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).To(BeNil())
+			Expect(hasMoreData).To(BeFalse())
+			Consistently(r.Len).Should(Equal(6))
+			// receive a MAX_STREAM_DATA frame
+			mockFC.EXPECT().UpdateSendWindow(protocol.ByteCount(13))
+			str.updateSendWindow(13)
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(3))
+			frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).To(BeNil())
+			waitForWrite()
+			Expect(r.Len()).To(Equal(3))
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(3))
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(3))
+			frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal([]byte("foo")))
+			// the flow control credit is used up
+			waitForSendCredit()
+			Consistently(r.Len).Should(Equal(3))
+			str.closeForShutdown(errors.New("shutdown"))
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns errors from the reader", func() {
+			testErr := errors.New("read error")
+			pr, pw := io.Pipe()
+			go func() {
+				pw.Write([]byte("foobar"))
+				pw.CloseWithError(testErr)
+			}()
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				n, err := str.ReadFrom(pr)
+				Expect(err).To(MatchError(testErr))
+				Expect(n).To(BeEquivalentTo(6))
+			}()
+			waitForSendCredit()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).To(BeNil())
+			waitForWrite()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns errors when writing to the stream", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			Expect(str.Close()).To(Succeed())
+			n, err := str.ReadFrom(bytes.NewReader([]byte("foobar")))
+			Expect(err).To(MatchError("write on closed stream 1337"))
+			Expect(n).To(BeZero())
+		})
+	})
+
+	Context("statistics", func() {
+		BeforeEach(func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			n, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(n).To(Equal(6))
+		})
+
+		It("counts the bytes written, sent and acknowledged", func() {
+			Expect(str.SendStats().BytesWritten).To(BeEquivalentTo(6))
+			Expect(str.SendStats().BytesSent).To(BeZero())
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			Expect(str.SendStats().BytesSent).To(BeEquivalentTo(6))
+			Expect(str.SendStats().BytesAcked).To(BeZero())
+			frame.OnAcked(frame.Frame)
+			stats := str.SendStats()
+			Expect(stats.BytesAcked).To(BeEquivalentTo(6))
+			Expect(stats.BytesRetransmitted).To(BeZero())
+		})
+
+		It("counts retransmitted bytes", func() {
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			mockSender.EXPECT().onQueuedRetransmission((&wire.StreamFrame{StreamID: streamID, Data: []byte("foobar"), DataLenPresent: true}).Length(str.version))
+			mockSender.EXPECT().onHasStreamData(streamID)
+			frame.OnLost(frame.Frame)
+			frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			stats := str.SendStats()
+			Expect(stats.BytesSent).To(BeEquivalentTo(6))
+			Expect(stats.BytesRetransmitted).To(BeEquivalentTo(6))
+		})
+
+		It("measures the time the stream is blocked by flow control", func() {
+			Expect(str.SendStats().FlowControlBlockedTime).To(BeZero())
+			mockFC.EXPECT().SendWindowSize().Return(protocol.ByteCount(0))
+			mockFC.EXPECT().IsNewlyBlocked()
+			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).To(BeNil())
+			time.Sleep(scaleDuration(20 * time.Millisecond))
+			Expect(str.SendStats().FlowControlBlockedTime).To(BeNumerically(">=", scaleDuration(20*time.Millisecond)))
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(6))
+			frame, _ = str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame).ToNot(BeNil())
+			blockedTime := str.SendStats().FlowControlBlockedTime
+			time.Sleep(scaleDuration(5 * time.Millisecond))
+			Expect(str.SendStats().FlowControlBlockedTime).To(Equal(blockedTime))
+		})
+	})
+
+	Context("waiting for acknowledgements", func() {
+		It("returns immediately if no data was written", func() {
+			Expect(str.WaitForAcks(context.Background())).To(Succeed())
+		})
+
+		It("waits until all data and the FIN is acknowledged", func() {
+			mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(str.Close()).To(Succeed())
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(str.WaitForAcks(context.Background())).To(Succeed())
+			}()
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			mockFC.EXPECT().AddBytesSent(protocol.ByteCount(3)).Times(2)
+			frame1, _ := str.popStreamFrame(expectedFrameHeaderLen(0) + 3)
+			Expect(frame1).ToNot(BeNil())
+			mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+			frame2, _ := str.popStreamFrame(protocol.MaxByteCount)
+			Expect(frame2).ToNot(BeNil())
+			Expect(frame2.Frame.(*wire.StreamFrame).Fin).To(BeTrue())
+			frame1.OnAcked(frame1.Frame)
+			Consistently(done).ShouldNot(BeClosed())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			frame2.OnAcked(frame2.Frame)
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns an error when the stream is canceled", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				Expect(str.WaitForAcks(context.Background())).To(MatchError("Write on stream 1337 canceled with error code 1234"))
+			}()
+			Consistently(done).ShouldNot(BeClosed())
+			mockSender.EXPECT().queueControlFrame(gomock.Any())
+			mockSender.EXPECT().onStreamCompleted(streamID)
+			str.CancelWrite(1234)
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("returns an error when the stream is closed for shutdown", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			testErr := errors.New("shutdown")
+			str.closeForShutdown(testErr)
+			Expect(str.WaitForAcks(context.Background())).To(MatchError(testErr))
+		})
+
+		It("returns when the context is canceled", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			_, err := strWithTimeout.Write([]byte("foobar"))
+			Expect(err).ToNot(HaveOccurred())
+			ctx, cancel := context.WithTimeout(context.Background(), scaleDuration(20*time.Millisecond))
+			defer cancel()
+			Expect(str.WaitForAcks(ctx)).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+
 	Context("handling MAX_STREAM_DATA frames", func() {
 		It("informs the flow controller", func() {
 			mockFC.EXPECT().UpdateSendWindow(protocol.ByteCount(0x1337))
@@ -674,6 +1017,42 @@ var _ = Describe("Send Stream", func() {
 		})
 	})
 
+	Context("prioritization", func() {
+		It("has the default priority", func() {
+			Expect(str.Priority()).To(Equal(protocol.DefaultPriority))
+		})
+
+		It("sets the priority", func() {
+			str.SetPriority(protocol.Priority{Urgency: 1})
+			Expect(str.Priority()).To(Equal(protocol.Priority{Urgency: 1}))
+			mockSender.EXPECT().onHasStreamData(streamID)
+			Expect(str.Close()).To(Succeed())
+		})
+
+		It("limits the urgency", func() {
+			str.SetPriority(protocol.Priority{Urgency: 42, Incremental: true})
+			Expect(str.Priority()).To(Equal(protocol.Priority{Urgency: protocol.MaxUrgency, Incremental: true}))
+		})
+
+		It("reschedules the stream when the priority changes while it has data", func() {
+			mockSender.EXPECT().onHasStreamData(streamID)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				_, err := str.Write([]byte("foobar"))
+				Expect(err).ToNot(HaveOccurred())
+				close(done)
+			}()
+			waitForWrite()
+			mockSender.EXPECT().onHasStreamData(streamID)
+			str.SetPriority(protocol.Priority{Urgency: 6})
+			str.SetPriority(protocol.Priority{Urgency: 6}) // no change
+			// make sure the Write go routine returns
+			str.closeForShutdown(nil)
+			Eventually(done).Should(BeClosed())
+		})
+	})
+
 	Context("stream cancellations", func() {
 		Context("canceling writing", func() {
 			It("queues a RESET_STREAM frame", func() {
@@ -840,6 +1219,197 @@ var _ = Describe("Send Stream", func() {
 			})
 		})
 
+		Context("canceling writing at an offset", func() {
+			// pops a STREAM frame containing 40 bytes
+			popFrame := func() *ackhandler.Frame {
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(40))
+				frame, _ := str.popStreamFrame(expectedFrameHeaderLen(str.writeOffset) + 40)
+				ExpectWithOffset(1, frame).ToNot(BeNil())
+				return frame
+			}
+
+			BeforeEach(func() {
+				mockSender.EXPECT().onHasStreamData(streamID)
+				_, err := strWithTimeout.Write(getData(100))
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("behaves like CancelWrite if the peer doesn't support RESET_STREAM_AT", func() {
+				mockSender.EXPECT().supportsResetStreamAt().Return(false)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+					StreamID:  streamID,
+					ErrorCode: 1234,
+				})
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				str.CancelWriteAt(1234, 50)
+				frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+			})
+
+			It("queues a RESET_STREAM_AT frame, and sends the buffered data up to the reliable size", func() {
+				frame1 := popFrame()
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    60,
+					ReliableSize: 60,
+				})
+				mockSender.EXPECT().onHasStreamData(streamID)
+				str.CancelWriteAt(1234, 60)
+				_, err := strWithTimeout.Write([]byte("foobar"))
+				Expect(err).To(MatchError("Write on stream 1337 canceled with error code 1234"))
+				Expect(str.Context().Done()).To(BeClosed())
+
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(20))
+				frame2, _ := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame2).ToNot(BeNil())
+				f := frame2.Frame.(*wire.StreamFrame)
+				Expect(f.Offset).To(BeEquivalentTo(40))
+				Expect(f.Data).To(Equal(getDataAtOffset(40, 20)))
+				Expect(f.Fin).To(BeFalse())
+				frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+
+				// lost data below the reliable size is retransmitted
+				mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame1.OnLost(frame1.Frame)
+				frame1, _ = str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame1).ToNot(BeNil())
+				Expect(frame1.Frame.(*wire.StreamFrame).Offset).To(BeZero())
+				Expect(frame1.Frame.(*wire.StreamFrame).Data).To(Equal(getData(40)))
+				frame1.OnAcked(frame1.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame2.OnAcked(frame2.Frame)
+			})
+
+			It("doesn't send data from a blocked Write call after canceling", func() {
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					defer close(done)
+					// This doesn't fit into the nextFrame, which already contains 100 bytes.
+					// After canceling, the nextFrame only contains 50 bytes, and it would fit.
+					n, err := str.Write(getDataAtOffset(100, protocol.MaxPacketBufferSize-60))
+					Expect(err).To(MatchError("Write on stream 1337 canceled with error code 1234"))
+					Expect(n).To(BeZero())
+				}()
+				Consistently(done).ShouldNot(BeClosed())
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    50,
+					ReliableSize: 50,
+				})
+				str.CancelWriteAt(1234, 50)
+				Eventually(done).Should(BeClosed())
+
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(50))
+				frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).ToNot(BeNil())
+				Expect(frame.Frame.(*wire.StreamFrame).Offset).To(BeZero())
+				Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal(getData(50)))
+				frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+			})
+
+			It("only retransmits data below the reliable size", func() {
+				frame1 := popFrame()
+				frame2 := popFrame()
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    80,
+					ReliableSize: 50,
+				})
+				str.CancelWriteAt(1234, 50)
+				frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+
+				mockSender.EXPECT().onQueuedRetransmission((&wire.StreamFrame{StreamID: streamID, Offset: 40, Data: getDataAtOffset(40, 10), DataLenPresent: true}).Length(str.version))
+				mockSender.EXPECT().onHasStreamData(streamID)
+				frame2.OnLost(frame2.Frame)
+				frame2, _ = str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame2).ToNot(BeNil())
+				Expect(frame2.Frame.(*wire.StreamFrame).Offset).To(BeEquivalentTo(40))
+				Expect(frame2.Frame.(*wire.StreamFrame).Data).To(Equal(getDataAtOffset(40, 10)))
+				frame2.OnAcked(frame2.Frame)
+				mockSender.EXPECT().onStreamCompleted(streamID)
+				frame1.OnAcked(frame1.Frame)
+			})
+
+			It("limits the reliable size to the amount of data written", func() {
+				popFrame()
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    100,
+					ReliableSize: 100,
+				})
+				mockSender.EXPECT().onHasStreamData(streamID)
+				str.CancelWriteAt(1234, 1000)
+			})
+
+			It("reduces the reliable size, but never increases it", func() {
+				popFrame()
+				mockSender.EXPECT().supportsResetStreamAt().Return(true).Times(3)
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    60,
+					ReliableSize: 60,
+				})
+				mockSender.EXPECT().onHasStreamData(streamID).Times(2)
+				str.CancelWriteAt(1234, 60)
+				str.CancelWriteAt(1234, 80) // no-op
+				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamAtFrame{
+					StreamID:     streamID,
+					ErrorCode:    1234,
+					FinalSize:    60,
+					ReliableSize: 50,
+				})
+				str.CancelWriteAt(1234, 50)
+				mockFC.EXPECT().SendWindowSize().Return(protocol.MaxByteCount)
+				mockFC.EXPECT().AddBytesSent(protocol.ByteCount(10))
+				frame, _ := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).ToNot(BeNil())
+				Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal(getDataAtOffset(40, 10)))
+			})
+
+			It("stops sending data when a STOP_SENDING frame is received", func() {
+				frame1 := popFrame()
+				mockSender.EXPECT().supportsResetStreamAt().Return(true)
+				mockSender.EXPECT().queueControlFrame(gomock.Any())
+				mockSender.EXPECT().onHasStreamData(streamID)
+				str.CancelWriteAt(1234, 60)
+				gomock.InOrder(
+					mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
+						StreamID:  streamID,
+						ErrorCode: 101,
+						FinalSize: 60,
+					}),
+					mockSender.EXPECT().onStreamCompleted(streamID),
+				)
+				str.handleStopSendingFrame(&wire.StopSendingFrame{StreamID: streamID, ErrorCode: 101})
+				frame, hasMoreData := str.popStreamFrame(protocol.MaxByteCount)
+				Expect(frame).To(BeNil())
+				Expect(hasMoreData).To(BeFalse())
+				frame1.OnLost(frame1.Frame) // doesn't trigger a retransmission
+			})
+		})
+
 		Context("receiving STOP_SENDING frames", func() {
 			It("queues a RESET_STREAM frames, and copies the error code from the STOP_SENDING frame", func() {
 				mockSender.EXPECT().queueControlFrame(&wire.ResetStreamFrame{
@@ -900,6 +1470,7 @@ var _ = Describe("Send Stream", func() {
 				Offset:         0x42,
 				DataLenPresent: false,
 			}
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
 			mockSender.EXPECT().onHasStreamData(streamID)
 			str.queueRetransmission(f)
 			frame, _ := str.popStreamFrame(protocol.MaxByteCount)
@@ -917,6 +1488,7 @@ var _ = Describe("Send Stream", func() {
 				Offset:         0x42,
 				DataLenPresent: false,
 			}
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
 			mockSender.EXPECT().onHasStreamData(streamID)
 			str.queueRetransmission(sf)
 			frame, hasMoreData := str.popStreamFrame(sf.Length(str.version) - 3)
@@ -941,6 +1513,7 @@ var _ = Describe("Send Stream", func() {
 				Offset:         0x42,
 				DataLenPresent: false,
 			}
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
 			mockSender.EXPECT().onHasStreamData(streamID)
 			str.queueRetransmission(f)
 			frame, hasMoreData := str.popStreamFrame(2)
@@ -966,6 +1539,7 @@ var _ = Describe("Send Stream", func() {
 			Expect(frame.Frame.(*wire.StreamFrame).Data).To(Equal([]byte("foobar")))
 
 			// now lose the frame
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
 			mockSender.EXPECT().onHasStreamData(streamID)
 			frame.OnLost(frame.Frame)
 			newFrame, _ := str.popStreamFrame(protocol.MaxByteCount)
@@ -1097,6 +1671,7 @@ var _ = Describe("Send Stream", func() {
 			for _, f := range frames[1:] {
 				f.OnAcked(f.Frame)
 			}
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any())
 			mockSender.EXPECT().onHasStreamData(streamID)
 			frames[0].OnLost(frames[0].Frame)
 
@@ -1114,6 +1689,7 @@ var _ = Describe("Send Stream", func() {
 		It("retransmits data until everything has been acknowledged", func() {
 			const dataLen = 1 << 22 // 4 MB
 			mockSender.EXPECT().onHasStreamData(streamID).AnyTimes()
+			mockSender.EXPECT().onQueuedRetransmission(gomock.Any()).AnyTimes()
 			mockFC.EXPECT().SendWindowSize().DoAndReturn(func() protocol.ByteCount {
 				return protocol.ByteCount(mrand.Intn(500)) + 50
 			}).AnyTimes()
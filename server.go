@@ -66,9 +66,12 @@ type baseServer struct {
 	// If it is started with Listen, we take a packet conn as a parameter.
 	createdPacketConn bool
 
-	tokenGenerator *handshake.TokenGenerator
+	tokenGenerator   *handshake.TokenGenerator
+	handshakeLimiter *handshakeLimiter
 
 	sessionHandler packetHandlerManager
+	// The packet handler managers for the preferred addresses, if any.
+	preferredAddrHandlers []packetHandlerManager
 
 	receivedPackets chan *receivedPacket
 
@@ -186,31 +189,50 @@ func listen(conn net.PacketConn, tlsConf *tls.Config, config *Config, acceptEarl
 		}
 	}
 
+	var preferredAddrHandlers []packetHandlerManager
+	if config.PreferredAddress != nil {
+		var err error
+		config.PreferredAddress, preferredAddrHandlers, err = listenOnPreferredAddress(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// The packet conns for the preferred addresses are closed if creating the server fails.
+	destroyPreferredAddrHandlers := func() {
+		for _, h := range preferredAddrHandlers {
+			h.Destroy()
+		}
+	}
 	sessionHandler, err := getMultiplexer().AddConn(conn, config.ConnectionIDLength, config.StatelessResetKey, config.Tracer)
 	if err != nil {
+		destroyPreferredAddrHandlers()
 		return nil, err
 	}
-	tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+	tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader, config.TokenKeys)
 	if err != nil {
+		destroyPreferredAddrHandlers()
 		return nil, err
 	}
 	c, err := wrapConn(conn)
 	if err != nil {
+		destroyPreferredAddrHandlers()
 		return nil, err
 	}
 	s := &baseServer{
-		conn:                c,
-		tlsConf:             tlsConf,
-		config:              config,
-		tokenGenerator:      tokenGenerator,
-		sessionHandler:      sessionHandler,
-		sessionQueue:        make(chan quicSession),
-		errorChan:           make(chan struct{}),
-		running:             make(chan struct{}),
-		receivedPackets:     make(chan *receivedPacket, protocol.MaxServerUnprocessedPackets),
-		newSession:          newSession,
-		logger:              utils.DefaultLogger.WithPrefix("server"),
-		acceptEarlySessions: acceptEarly,
+		conn:                  c,
+		tlsConf:               tlsConf,
+		config:                config,
+		tokenGenerator:        tokenGenerator,
+		handshakeLimiter:      newHandshakeLimiter(config.HandshakeLimits, time.Now()),
+		sessionHandler:        sessionHandler,
+		preferredAddrHandlers: preferredAddrHandlers,
+		sessionQueue:          make(chan quicSession),
+		errorChan:             make(chan struct{}),
+		running:               make(chan struct{}),
+		receivedPackets:       make(chan *receivedPacket, protocol.MaxServerUnprocessedPackets),
+		newSession:            newSession,
+		logger:                utils.DefaultLogger.WithPrefix("server"),
+		acceptEarlySessions:   acceptEarly,
 	}
 	go s.run()
 	sessionHandler.SetServer(s)
@@ -218,6 +240,41 @@ func listen(conn net.PacketConn, tlsConf *tls.Config, config *Config, acceptEarl
 	return s, nil
 }
 
+// listenOnPreferredAddress creates the packet conns for the server's preferred addresses.
+// It returns the addresses that the server is listening on.
+// They differ from the configured addresses if a port of 0 was used.
+func listenOnPreferredAddress(config *Config) (*PreferredAddress, []packetHandlerManager, error) {
+	var handlers []packetHandlerManager
+	listen := func(addr *net.UDPAddr) (*net.UDPAddr, error) {
+		if addr == nil {
+			return nil, nil
+		}
+		conn, err := net.ListenUDP("udp", addr)
+		if err != nil {
+			return nil, err
+		}
+		handler, err := getMultiplexer().AddConn(conn, config.ConnectionIDLength, config.StatelessResetKey, config.Tracer)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+		return conn.LocalAddr().(*net.UDPAddr), nil
+	}
+	ipv4, err := listen(config.PreferredAddress.IPv4)
+	if err != nil {
+		return nil, nil, err
+	}
+	ipv6, err := listen(config.PreferredAddress.IPv6)
+	if err != nil {
+		for _, h := range handlers {
+			h.Destroy()
+		}
+		return nil, nil, err
+	}
+	return &PreferredAddress{IPv4: ipv4, IPv6: ipv6}, handlers, nil
+}
+
 func (s *baseServer) run() {
 	defer close(s.running)
 	for {
@@ -294,6 +351,9 @@ func (s *baseServer) Close() error {
 
 	<-s.running
 	s.sessionHandler.CloseServer()
+	for _, h := range s.preferredAddrHandlers {
+		h.Destroy()
+	}
 	if createdPacketConn {
 		return s.sessionHandler.Destroy()
 	}
@@ -398,6 +458,19 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		return errors.New("too short connection ID")
 	}
 
+	if s.config.Tracer != nil {
+		defer func() { s.config.Tracer.UpdatedHandshakeMetrics(s.handshakeLimiter.Metrics()) }()
+	}
+	now := time.Now()
+	if !s.handshakeLimiter.ReceivedInitial(p.remoteAddr, now) {
+		p.buffer.Release()
+		if s.config.Tracer != nil {
+			s.config.Tracer.DroppedPacket(p.remoteAddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention)
+		}
+		s.logger.Debugf("Dropping Initial packet from %s. Too many Initial packets from this subnet.", p.remoteAddr)
+		return nil
+	}
+
 	var (
 		token          *Token
 		retrySrcConnID *protocol.ConnectionID
@@ -417,20 +490,29 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 			}
 		}
 	}
-	if !s.config.AcceptToken(p.remoteAddr, token) {
-		go func() {
-			defer p.buffer.Release()
-			if token != nil && token.IsRetryToken {
+	addrValidated := s.config.AcceptToken(p.remoteAddr, token)
+	if !addrValidated {
+		if token != nil && token.IsRetryToken {
+			go func() {
+				defer p.buffer.Release()
 				if err := s.maybeSendInvalidToken(p, hdr); err != nil {
 					s.logger.Debugf("Error sending INVALID_TOKEN error: %s", err)
 				}
-				return
-			}
-			if err := s.sendRetry(p.remoteAddr, hdr, p.info); err != nil {
-				s.logger.Debugf("Error sending Retry: %s", err)
-			}
-		}()
-		return nil
+			}()
+			return nil
+		}
+		// Clients that presented a token that wasn't accepted always have to do a Retry.
+		// Clients that didn't present a token only have to do so if the server is under load.
+		if len(hdr.Token) > 0 || s.handshakeLimiter.RequireRetry(now) {
+			s.handshakeLimiter.SentRetry()
+			go func() {
+				defer p.buffer.Release()
+				if err := s.sendRetry(p.remoteAddr, hdr, p.info); err != nil {
+					s.logger.Debugf("Error sending Retry: %s", err)
+				}
+			}()
+			return nil
+		}
 	}
 
 	if queueLen := atomic.LoadInt32(&s.sessionQueueLen); queueLen >= protocol.MaxAcceptQueueSize {
@@ -444,7 +526,7 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		return nil
 	}
 
-	connID, err := protocol.GenerateConnectionID(s.config.ConnectionIDLength)
+	connID, err := s.config.ConnectionIDGenerator.GenerateConnectionID()
 	if err != nil {
 		return err
 	}
@@ -462,7 +544,7 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 		}
 		sess = s.newSession(
 			newSendConn(s.conn, p.remoteAddr, p.info),
-			s.sessionHandler,
+			s.sessionRunner(),
 			origDestConnID,
 			retrySrcConnID,
 			hdr.DestConnectionID,
@@ -482,6 +564,19 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 	}); !added {
 		return nil
 	}
+	if !addrValidated {
+		s.handshakeLimiter.StartedUnvalidatedHandshake(now)
+		go func() {
+			select {
+			case <-sess.HandshakeComplete().Done():
+			case <-sess.Context().Done():
+			}
+			s.handshakeLimiter.CompletedUnvalidatedHandshake()
+			if s.config.Tracer != nil {
+				s.config.Tracer.UpdatedHandshakeMetrics(s.handshakeLimiter.Metrics())
+			}
+		}()
+	}
 	go sess.run()
 	go s.handleNewSession(sess)
 	if sess == nil {
@@ -491,6 +586,76 @@ func (s *baseServer) handleInitialImpl(p *receivedPacket, hdr *wire.Header) erro
 	return nil
 }
 
+func (s *baseServer) sessionRunner() sessionRunner {
+	if len(s.preferredAddrHandlers) == 0 {
+		return s.sessionHandler
+	}
+	runners := multiSessionRunner{s.sessionHandler}
+	for _, h := range s.preferredAddrHandlers {
+		runners = append(runners, h)
+	}
+	return runners
+}
+
+// A multiSessionRunner is used for sessions of a server that listens on a preferred address.
+// Connection IDs and stateless reset tokens are registered with all packet handler managers,
+// such that the session receives packets on all of the server's addresses.
+type multiSessionRunner []sessionRunner
+
+var _ sessionRunner = multiSessionRunner{}
+
+func (r multiSessionRunner) Add(connID protocol.ConnectionID, handler packetHandler) bool {
+	added := true
+	for _, runner := range r {
+		if !runner.Add(connID, handler) {
+			added = false
+		}
+	}
+	return added
+}
+
+// GetStatelessResetToken uses the first runner.
+// If no stateless reset key is configured, every call generates a new random token.
+func (r multiSessionRunner) GetStatelessResetToken(connID protocol.ConnectionID) protocol.StatelessResetToken {
+	return r[0].GetStatelessResetToken(connID)
+}
+
+func (r multiSessionRunner) Retire(connID protocol.ConnectionID) {
+	for _, runner := range r {
+		runner.Retire(connID)
+	}
+}
+
+func (r multiSessionRunner) Remove(connID protocol.ConnectionID) {
+	for _, runner := range r {
+		runner.Remove(connID)
+	}
+}
+
+func (r multiSessionRunner) RemoveHandler(handler packetHandler) {
+	for _, runner := range r {
+		runner.RemoveHandler(handler)
+	}
+}
+
+func (r multiSessionRunner) ReplaceWithClosed(connID protocol.ConnectionID, handler packetHandler) {
+	for _, runner := range r {
+		runner.ReplaceWithClosed(connID, handler)
+	}
+}
+
+func (r multiSessionRunner) AddResetToken(token protocol.StatelessResetToken, handler packetHandler) {
+	for _, runner := range r {
+		runner.AddResetToken(token, handler)
+	}
+}
+
+func (r multiSessionRunner) RemoveResetToken(token protocol.StatelessResetToken) {
+	for _, runner := range r {
+		runner.RemoveResetToken(token)
+	}
+}
+
 func (s *baseServer) handleNewSession(sess quicSession) {
 	sessCtx := sess.Context()
 	if s.acceptEarlySessions {
@@ -523,7 +688,7 @@ func (s *baseServer) sendRetry(remoteAddr net.Addr, hdr *wire.Header, info *pack
 	// Log the Initial packet now.
 	// If no Retry is sent, the packet will be logged by the session.
 	(&wire.ExtendedHeader{Header: *hdr}).Log(s.logger)
-	srcConnID, err := protocol.GenerateConnectionID(s.config.ConnectionIDLength)
+	srcConnID, err := s.config.ConnectionIDGenerator.GenerateConnectionID()
 	if err != nil {
 		return err
 	}
@@ -157,6 +157,20 @@ var _ = Describe("Server", func() {
 		Expect(ln.Close()).To(Succeed())
 	})
 
+	It("uses the configured token keys", func() {
+		key := TokenKey{1, 2, 3, 4}
+		tokenGen, err := handshake.NewTokenGenerator(rand.Reader, []handshake.TokenProtectorKey{key})
+		Expect(err).ToNot(HaveOccurred())
+		token, err := tokenGen.NewToken(&net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1337})
+		Expect(err).ToNot(HaveOccurred())
+		ln, err := Listen(conn, tlsConf, &Config{TokenKeys: []TokenKey{key}})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		decoded, err := ln.(*baseServer).tokenGenerator.DecodeToken(token)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(decoded.RemoteAddr).To(Equal("192.168.0.1"))
+	})
+
 	It("listens on a given address", func() {
 		addr := "127.0.0.1:13579"
 		ln, err := ListenAddr(addr, tlsConf, &Config{})
@@ -166,6 +180,48 @@ var _ = Describe("Server", func() {
 		Expect(ln.Close()).To(Succeed())
 	})
 
+	It("listens on the preferred address", func() {
+		ln, err := ListenAddr("127.0.0.1:0", tlsConf, &Config{
+			PreferredAddress: &PreferredAddress{IPv4: &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		server := ln.(*baseServer)
+		Expect(server.preferredAddrHandlers).To(HaveLen(1))
+		// the port that the server is listening on is sent to the client
+		addr := server.config.PreferredAddress.IPv4
+		Expect(addr.Port).ToNot(BeZero())
+		Expect(addr.Port).ToNot(Equal(ln.Addr().(*net.UDPAddr).Port))
+		Expect(server.sessionRunner()).To(Equal(multiSessionRunner{server.sessionHandler, server.preferredAddrHandlers[0]}))
+		Expect(ln.Close()).To(Succeed())
+		// the preferred address is not in use any more
+		conn, err := net.ListenUDP("udp", addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("closes the preferred address if creating the server fails", func() {
+		udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		ln, err := Listen(udpConn, tlsConf, &Config{ConnectionIDLength: 4})
+		Expect(err).ToNot(HaveOccurred())
+		defer ln.Close()
+		// find a free port
+		c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		Expect(err).ToNot(HaveOccurred())
+		addr := c.LocalAddr().(*net.UDPAddr)
+		Expect(c.Close()).To(Succeed())
+		// adding the packet conn fails, since it is already used with a different connection ID length
+		_, err = Listen(udpConn, tlsConf, &Config{
+			ConnectionIDLength: 5,
+			PreferredAddress:   &PreferredAddress{IPv4: addr},
+		})
+		Expect(err).To(MatchError("cannot use 5 byte connection IDs on a connection that is already using 4 byte connction IDs"))
+		// the preferred address is not in use any more
+		c, err = net.ListenUDP("udp", addr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(c.Close()).To(Succeed())
+	})
+
 	It("errors if given an invalid address", func() {
 		addr := "127.0.0.1"
 		_, err := ListenAddr(addr, tlsConf, &Config{})
@@ -187,6 +243,7 @@ var _ = Describe("Server", func() {
 
 		BeforeEach(func() {
 			tracer = mocklogging.NewMockTracer(mockCtrl)
+			tracer.EXPECT().UpdatedHandshakeMetrics(gomock.Any()).AnyTimes()
 			ln, err := Listen(conn, tlsConf, &Config{Tracer: tracer})
 			Expect(err).ToNot(HaveOccurred())
 			serv = ln.(*baseServer)
@@ -483,6 +540,35 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			It("uses the ConnectionIDGenerator for the Retry", func() {
+				serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return false }
+				serv.config.ConnectionIDGenerator = &mockConnIDGenerator{
+					connIDLen: 6,
+					generate:  func() (protocol.ConnectionID, error) { return protocol.ConnectionID{6, 5, 4, 3, 2, 1}, nil },
+				}
+				hdr := &wire.Header{
+					IsLongHeader:     true,
+					Type:             protocol.PacketTypeInitial,
+					SrcConnectionID:  protocol.ConnectionID{5, 4, 3, 2, 1},
+					DestConnectionID: protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+					Version:          protocol.VersionTLS,
+				}
+				packet := getPacket(hdr, make([]byte, protocol.MinInitialPacketSize))
+				raddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+				packet.remoteAddr = raddr
+				tracer.EXPECT().SentPacket(packet.remoteAddr, gomock.Any(), gomock.Any(), nil)
+				done := make(chan struct{})
+				conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+					defer close(done)
+					replyHdr := parseHeader(b)
+					Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					Expect(replyHdr.SrcConnectionID).To(Equal(protocol.ConnectionID{6, 5, 4, 3, 2, 1}))
+					return len(b), nil
+				})
+				serv.handlePacket(packet)
+				Eventually(done).Should(BeClosed())
+			})
+
 			It("sends an INVALID_TOKEN error, if an invalid retry token is received", func() {
 				serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return false }
 				token, err := serv.tokenGenerator.NewRetryToken(&net.UDPAddr{}, nil, nil)
@@ -521,7 +607,7 @@ var _ = Describe("Server", func() {
 					Expect(err).ToNot(HaveOccurred())
 					data, err := opener.Open(nil, b[extHdr.ParsedLen():], extHdr.PacketNumber, b[:extHdr.ParsedLen()])
 					Expect(err).ToNot(HaveOccurred())
-					f, err := wire.NewFrameParser(false, hdr.Version).ParseNext(bytes.NewReader(data), protocol.EncryptionInitial)
+					f, err := wire.NewFrameParser(false, false, false, hdr.Version).ParseNext(bytes.NewReader(data), protocol.EncryptionInitial)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(f).To(BeAssignableToTypeOf(&wire.ConnectionCloseFrame{}))
 					ccf := f.(*wire.ConnectionCloseFrame)
@@ -629,6 +715,130 @@ var _ = Describe("Server", func() {
 				Eventually(done).Should(BeClosed())
 			})
 
+			Context("with handshake limits", func() {
+				var raddr *net.UDPAddr
+
+				BeforeEach(func() {
+					serv.config.AcceptToken = func(_ net.Addr, _ *Token) bool { return false }
+					raddr = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+				})
+
+				getInitial := func() *receivedPacket {
+					p := getPacket(&wire.Header{
+						IsLongHeader:     true,
+						Type:             protocol.PacketTypeInitial,
+						SrcConnectionID:  protocol.ConnectionID{5, 4, 3, 2, 1},
+						DestConnectionID: protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+						Version:          protocol.VersionTLS,
+					}, make([]byte, protocol.MinInitialPacketSize))
+					p.remoteAddr = raddr
+					return p
+				}
+
+				It("creates a session without a Retry, if the server is not under load", func() {
+					serv.handshakeLimiter = newHandshakeLimiter(&HandshakeLimits{}, time.Now())
+					p := getInitial()
+					phm.EXPECT().AddWithConnID(protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() packetHandler) bool {
+						phm.EXPECT().GetStatelessResetToken(gomock.Any())
+						fn()
+						return true
+					})
+					tracer.EXPECT().TracerForConnection(protocol.PerspectiveServer, gomock.Any())
+					handshakeCtx, handshakeComplete := context.WithCancel(context.Background())
+					run := make(chan struct{})
+					sess := NewMockQuicSession(mockCtrl)
+					serv.newSession = func(_ sendConn, _ sessionRunner, _ protocol.ConnectionID, retrySrcConnID *protocol.ConnectionID, _, _, _ protocol.ConnectionID, _ protocol.StatelessResetToken, _ *Config, _ *tls.Config, _ *handshake.TokenGenerator, _ bool, _ logging.ConnectionTracer, _ utils.Logger, _ protocol.VersionNumber) quicSession {
+						Expect(retrySrcConnID).To(BeNil())
+						sess.EXPECT().handlePacket(p)
+						sess.EXPECT().run().Do(func() { close(run) })
+						sess.EXPECT().Context().Return(context.Background()).AnyTimes()
+						sess.EXPECT().HandshakeComplete().Return(handshakeCtx).AnyTimes()
+						return sess
+					}
+					serv.handlePacket(p)
+					Eventually(run).Should(BeClosed())
+					Eventually(func() int { return serv.handshakeLimiter.Metrics().UnvalidatedHandshakes }).Should(Equal(1))
+					handshakeComplete()
+					Eventually(func() int { return serv.handshakeLimiter.Metrics().UnvalidatedHandshakes }).Should(BeZero())
+				})
+
+				It("replies with a Retry, if there are too many unvalidated handshakes", func() {
+					serv.handshakeLimiter = newHandshakeLimiter(&HandshakeLimits{MaxUnvalidatedHandshakes: 1}, time.Now())
+					serv.handshakeLimiter.StartedUnvalidatedHandshake(time.Now())
+					p := getInitial()
+					tracer.EXPECT().SentPacket(raddr, gomock.Any(), gomock.Any(), nil).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, _ []logging.Frame) {
+						Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					})
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						Expect(parseHeader(b).Type).To(Equal(protocol.PacketTypeRetry))
+						return len(b), nil
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(serv.handshakeLimiter.Metrics().RetriesSent).To(BeEquivalentTo(1))
+					Expect(serv.handshakeLimiter.Metrics().RetriesSentUnderLoad).To(BeEquivalentTo(1))
+				})
+
+				It("replies with a Retry, if the token was rejected", func() {
+					serv.handshakeLimiter = newHandshakeLimiter(&HandshakeLimits{}, time.Now())
+					token, err := serv.tokenGenerator.NewToken(raddr)
+					Expect(err).ToNot(HaveOccurred())
+					p := getPacket(&wire.Header{
+						IsLongHeader:     true,
+						Type:             protocol.PacketTypeInitial,
+						SrcConnectionID:  protocol.ConnectionID{5, 4, 3, 2, 1},
+						DestConnectionID: protocol.ConnectionID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+						Token:            token,
+						Version:          protocol.VersionTLS,
+					}, make([]byte, protocol.MinInitialPacketSize))
+					p.remoteAddr = raddr
+					tracer.EXPECT().SentPacket(raddr, gomock.Any(), gomock.Any(), nil).Do(func(_ net.Addr, replyHdr *logging.Header, _ logging.ByteCount, _ []logging.Frame) {
+						Expect(replyHdr.Type).To(Equal(protocol.PacketTypeRetry))
+					})
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						Expect(parseHeader(b).Type).To(Equal(protocol.PacketTypeRetry))
+						return len(b), nil
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(serv.handshakeLimiter.Metrics().RetriesSent).To(BeEquivalentTo(1))
+					Expect(serv.handshakeLimiter.Metrics().RetriesSentUnderLoad).To(BeZero())
+				})
+
+				It("doesn't count rejected sessions towards the rate of unvalidated handshakes", func() {
+					serv.handshakeLimiter = newHandshakeLimiter(&HandshakeLimits{MaxUnvalidatedHandshakeRate: 1}, time.Now())
+					atomic.StoreInt32(&serv.sessionQueueLen, protocol.MaxAcceptQueueSize)
+					p := getInitial()
+					tracer.EXPECT().SentPacket(raddr, gomock.Any(), gomock.Any(), gomock.Any())
+					done := make(chan struct{})
+					conn.EXPECT().WriteTo(gomock.Any(), raddr).DoAndReturn(func(b []byte, _ net.Addr) (int, error) {
+						defer close(done)
+						Expect(parseHeader(b).Type).To(Equal(protocol.PacketTypeInitial))
+						return len(b), nil
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(serv.handshakeLimiter.RequireRetry(time.Now())).To(BeFalse())
+				})
+
+				It("drops Initial packets, if too many Initials are received from the same subnet", func() {
+					serv.handshakeLimiter = newHandshakeLimiter(&HandshakeLimits{MaxInitialRatePerSubnet: 1}, time.Now())
+					Expect(serv.handshakeLimiter.ReceivedInitial(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 2), Port: 42}, time.Now())).To(BeTrue())
+					p := getInitial()
+					done := make(chan struct{})
+					tracer.EXPECT().DroppedPacket(raddr, logging.PacketTypeInitial, p.Size(), logging.PacketDropDOSPrevention).Do(func(net.Addr, logging.PacketType, protocol.ByteCount, logging.PacketDropReason) {
+						close(done)
+					})
+					serv.handlePacket(p)
+					Eventually(done).Should(BeClosed())
+					Expect(serv.handshakeLimiter.Metrics().InitialsRateLimited).To(BeEquivalentTo(1))
+				})
+			})
+
 			It("drops packets if the receive queue is full", func() {
 				phm.EXPECT().AddWithConnID(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_, _ protocol.ConnectionID, fn func() packetHandler) bool {
 					phm.EXPECT().GetStatelessResetToken(gomock.Any())
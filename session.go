@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/flowcontrol"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
@@ -25,6 +26,7 @@ import (
 
 type unpacker interface {
 	Unpack(hdr *wire.Header, rcvTime time.Time, data []byte) (*unpackedPacket, error)
+	SetVersion(protocol.VersionNumber)
 }
 
 type streamGetter interface {
@@ -52,9 +54,11 @@ type streamManager interface {
 type cryptoStreamHandler interface {
 	RunHandshake()
 	ChangeConnectionID(protocol.ConnectionID)
+	SetVersion(protocol.VersionNumber)
 	SetLargest1RTTAcked(protocol.PacketNumber) error
 	SetHandshakeConfirmed()
-	GetSessionTicket() ([]byte, error)
+	ForceKeyUpdate()
+	GetSessionTicket(appData []byte) ([]byte, error)
 	io.Closer
 	ConnectionState() handshake.ConnectionState
 }
@@ -74,6 +78,8 @@ type receivedPacket struct {
 	ecn protocol.ECN
 
 	info *packetInfo
+	// the connection this packet was received on
+	rcvConn connection
 }
 
 func (p *receivedPacket) Size() protocol.ByteCount { return protocol.ByteCount(len(p.data)) }
@@ -86,6 +92,7 @@ func (p *receivedPacket) Clone() *receivedPacket {
 		buffer:     p.buffer,
 		ecn:        p.ecn,
 		info:       p.info,
+		rcvConn:    p.rcvConn,
 	}
 }
 
@@ -94,6 +101,7 @@ type sessionRunner interface {
 	GetStatelessResetToken(protocol.ConnectionID) protocol.StatelessResetToken
 	Retire(protocol.ConnectionID)
 	Remove(protocol.ConnectionID)
+	RemoveHandler(packetHandler)
 	ReplaceWithClosed(protocol.ConnectionID, packetHandler)
 	AddResetToken(protocol.StatelessResetToken, packetHandler)
 	RemoveResetToken(protocol.StatelessResetToken)
@@ -153,16 +161,48 @@ type session struct {
 
 	perspective protocol.Perspective
 	version     protocol.VersionNumber
-	config      *Config
+	// The version the session was started with.
+	// This differs from version if we switched to a compatible version during the handshake.
+	initialVersion protocol.VersionNumber
+	config         *Config
 
+	runner sessionRunner
+
+	// connMutex protects conn, which is changed by the run loop when migrating to a new path
+	connMutex sync.Mutex
 	conn      sendConn
 	sendQueue sender
+	// Only used by the server: the connection that packets on the current path are received on.
+	// This is a different connection when the client migrates to the server's preferred address.
+	rcvConn connection
+
+	// set while a new path is being validated
+	pathValidator *pathValidator
+	// Only set for the client, while migrating to a new path.
+	migration         *pathMigration
+	migrationRequests chan *pathMigration
+	// Only set for the server, while the peer's new address is being validated.
+	// This is the path we fall back to if the path validation fails.
+	lastValidatedConn    sendConn
+	lastValidatedRcvConn connection
+	// Only set for the server, while handling a packet that was received on a new path.
+	// PATH_CHALLENGEs are answered on that path.
+	probePath *unvalidatedPath
+	// Used to detect NAT rebindings: only a packet with the largest packet number can cause a path change.
+	largestRcvd1RTTPacketNumber protocol.PacketNumber
 
 	streamsMap      streamManager
 	connIDManager   *connIDManager
 	connIDGenerator *connIDGenerator
 
 	rttStats *utils.RTTStats
+	// only accessed from the run loop, use Stats() to get a snapshot
+	packetsReceived uint64
+	bytesReceived   protocol.ByteCount
+	// the number of bytes of STREAM frames queued for retransmission,
+	// the retransmission queue counts all other frames
+	streamBytesRetransmitted protocol.ByteCount
+	statsRequests            chan chan<- ConnectionStats
 
 	cryptoStreamManager   *cryptoStreamManager
 	sentPacketHandler     ackhandler.SentPacketHandler
@@ -171,6 +211,7 @@ type session struct {
 	framer                framer
 	windowUpdateQueue     *windowUpdateQueue
 	connFlowController    flowcontrol.ConnectionFlowController
+	memoryReservation     *memoryReservation        // nil if no MemoryBudget is configured
 	tokenStoreKey         string                    // only set for the client
 	tokenGenerator        *handshake.TokenGenerator // only set for the server
 
@@ -217,6 +258,9 @@ type session struct {
 	pacingDeadline time.Time
 
 	peerParams *wire.TransportParameters
+	// peerSupportsResetStreamAt is set if both endpoints support RESET_STREAM_AT.
+	// It is accessed from the streams' go routines.
+	peerSupportsResetStreamAt utils.AtomicBool
 
 	timer *utils.Timer
 	// keepAlivePingSent stores whether a keep alive PING is in flight.
@@ -257,6 +301,7 @@ var newSession = func(
 ) quicSession {
 	s := &session{
 		conn:                  conn,
+		runner:                runner,
 		config:                conf,
 		handshakeDestConnID:   destConnID,
 		srcConnIDLen:          srcConnID.Len(),
@@ -267,6 +312,7 @@ var newSession = func(
 		tracer:                tracer,
 		logger:                logger,
 		version:               v,
+		initialVersion:        v,
 	}
 	if origDestConnID != nil {
 		s.logID = origDestConnID.String()
@@ -275,18 +321,21 @@ var newSession = func(
 	}
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { s.runner.AddResetToken(token, s) },
+		func(token protocol.StatelessResetToken) { s.runner.RemoveResetToken(token) },
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		clientDestConnID,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		s.config.ConnectionIDGenerator,
+		func(connID protocol.ConnectionID) { s.runner.Add(connID, s) },
+		func(connID protocol.ConnectionID) protocol.StatelessResetToken {
+			return s.runner.GetStatelessResetToken(connID)
+		},
+		func(connID protocol.ConnectionID) { s.runner.Remove(connID) },
+		func(connID protocol.ConnectionID) { s.runner.Retire(connID) },
+		func(connID protocol.ConnectionID, h packetHandler) { s.runner.ReplaceWithClosed(connID, h) },
 		s.queueControlFrame,
 		s.version,
 	)
@@ -295,6 +344,7 @@ var newSession = func(
 		0,
 		getMaxPacketSize(s.conn.RemoteAddr()),
 		s.rttStats,
+		s.newCongestionController(),
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -312,19 +362,32 @@ var newSession = func(
 		MaxUniStreamNum:                 protocol.StreamNum(s.config.MaxIncomingUniStreams),
 		MaxAckDelay:                     protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:                protocol.AckDelayExponent,
-		DisableActiveMigration:          true,
+		DisableActiveMigration:          s.config.DisableActiveMigration,
+		EnableResetStreamAt:             s.config.EnableResetStreamAt,
 		StatelessResetToken:             &statelessResetToken,
 		OriginalDestinationConnectionID: origDestConnID,
 		ActiveConnectionIDLimit:         protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID:       srcConnID,
 		RetrySourceConnectionID:         retrySrcConnID,
+		VersionInformation:              &wire.VersionInformation{ChosenVersion: s.version, AvailableVersions: s.config.Versions},
+	}
+	if s.config.PreferredAddress != nil {
+		params.PreferredAddress = s.newPreferredAddress()
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
 	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
 	if s.tracer != nil {
 		s.tracer.SentTransportParameters(params)
 	}
+	var checkReplay func([]byte, time.Time) bool
+	if s.config.ZeroRTTReplayProtector != nil {
+		checkReplay = s.config.ZeroRTTReplayProtector.Accept0RTT
+	}
 	cs := handshake.NewCryptoSetupServer(
 		initialStream,
 		handshakeStream,
@@ -337,12 +400,15 @@ var newSession = func(
 			onError:          s.closeLocal,
 			dropKeys:         s.dropEncryptionLevel,
 			onHandshakeComplete: func() {
-				runner.Retire(clientDestConnID)
+				s.runner.Retire(clientDestConnID)
 				close(s.handshakeCompleteChan)
 			},
 		},
 		tlsConf,
 		enable0RTT,
+		s.keyUpdatePolicy(),
+		s.config.Accept0RTTWithAppData,
+		checkReplay,
 		s.rttStats,
 		tracer,
 		logger,
@@ -386,6 +452,7 @@ var newClientSession = func(
 ) quicSession {
 	s := &session{
 		conn:                  conn,
+		runner:                runner,
 		config:                conf,
 		origDestConnID:        destConnID,
 		handshakeDestConnID:   destConnID,
@@ -397,21 +464,25 @@ var newClientSession = func(
 		tracer:                tracer,
 		versionNegotiated:     hasNegotiatedVersion,
 		version:               v,
+		initialVersion:        v,
 	}
 	s.connIDManager = newConnIDManager(
 		destConnID,
-		func(token protocol.StatelessResetToken) { runner.AddResetToken(token, s) },
-		runner.RemoveResetToken,
+		func(token protocol.StatelessResetToken) { s.runner.AddResetToken(token, s) },
+		func(token protocol.StatelessResetToken) { s.runner.RemoveResetToken(token) },
 		s.queueControlFrame,
 	)
 	s.connIDGenerator = newConnIDGenerator(
 		srcConnID,
 		nil,
-		func(connID protocol.ConnectionID) { runner.Add(connID, s) },
-		runner.GetStatelessResetToken,
-		runner.Remove,
-		runner.Retire,
-		runner.ReplaceWithClosed,
+		s.config.ConnectionIDGenerator,
+		func(connID protocol.ConnectionID) { s.runner.Add(connID, s) },
+		func(connID protocol.ConnectionID) protocol.StatelessResetToken {
+			return s.runner.GetStatelessResetToken(connID)
+		},
+		func(connID protocol.ConnectionID) { s.runner.Remove(connID) },
+		func(connID protocol.ConnectionID) { s.runner.Retire(connID) },
+		func(connID protocol.ConnectionID, h packetHandler) { s.runner.ReplaceWithClosed(connID, h) },
 		s.queueControlFrame,
 		s.version,
 	)
@@ -420,6 +491,7 @@ var newClientSession = func(
 		initialPacketNumber,
 		getMaxPacketSize(s.conn.RemoteAddr()),
 		s.rttStats,
+		s.newCongestionController(),
 		s.perspective,
 		s.tracer,
 		s.logger,
@@ -438,12 +510,18 @@ var newClientSession = func(
 		MaxAckDelay:                    protocol.MaxAckDelayInclGranularity,
 		AckDelayExponent:               protocol.AckDelayExponent,
 		DisableActiveMigration:         true,
+		EnableResetStreamAt:            s.config.EnableResetStreamAt,
 		ActiveConnectionIDLimit:        protocol.MaxActiveConnectionIDs,
 		InitialSourceConnectionID:      srcConnID,
+		VersionInformation:             &wire.VersionInformation{ChosenVersion: s.version, AvailableVersions: s.config.Versions},
 	}
 	if s.config.EnableDatagrams {
 		params.MaxDatagramFrameSize = protocol.MaxDatagramFrameSize
 	}
+	if s.config.EnableAckFrequency {
+		minAckDelay := protocol.MinAckDelay
+		params.MinAckDelay = &minAckDelay
+	}
 	if s.tracer != nil {
 		s.tracer.SentTransportParameters(params)
 	}
@@ -462,6 +540,7 @@ var newClientSession = func(
 		},
 		tlsConf,
 		enable0RTT,
+		s.keyUpdatePolicy(),
 		s.rttStats,
 		tracer,
 		logger,
@@ -499,18 +578,69 @@ var newClientSession = func(
 	return s
 }
 
+// newPreferredAddress creates the preferred_address transport parameter, see section 9.6 of RFC 9000.
+func (s *session) newPreferredAddress() *wire.PreferredAddress {
+	connID, token, err := s.connIDGenerator.GeneratePreferredAddressConnectionID()
+	if err != nil {
+		s.logger.Errorf("Not sending the preferred_address: %s", err)
+		return nil
+	}
+	addr := &wire.PreferredAddress{
+		IPv4:                net.IPv4zero,
+		IPv6:                net.IPv6zero,
+		ConnectionID:        connID,
+		StatelessResetToken: token,
+	}
+	if ipv4 := s.config.PreferredAddress.IPv4; ipv4 != nil {
+		addr.IPv4 = ipv4.IP
+		addr.IPv4Port = uint16(ipv4.Port)
+	}
+	if ipv6 := s.config.PreferredAddress.IPv6; ipv6 != nil {
+		addr.IPv6 = ipv6.IP.To16()
+		addr.IPv6Port = uint16(ipv6.Port)
+	}
+	return addr
+}
+
 func (s *session) preSetup() {
 	s.sendQueue = newSendQueue(s.conn)
+	s.migrationRequests = make(chan *pathMigration)
+	s.statsRequests = make(chan chan<- ConnectionStats)
+	s.largestRcvd1RTTPacketNumber = protocol.InvalidPacketNumber
 	s.retransmissionQueue = newRetransmissionQueue(s.version)
-	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams, s.version)
+	s.frameParser = wire.NewFrameParser(s.config.EnableDatagrams, s.config.EnableAckFrequency, s.config.EnableResetStreamAt, s.version)
 	s.rttStats = &utils.RTTStats{}
 	s.connFlowController = flowcontrol.NewConnectionFlowController(
 		protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
 		protocol.ByteCount(s.config.MaxConnectionReceiveWindow),
 		s.onHasConnectionWindowUpdate,
+		func(size protocol.ByteCount) bool {
+			if s.memoryReservation != nil && !s.memoryReservation.AllowWindowIncrease(size) {
+				return false
+			}
+			if s.config.AllowConnectionWindowIncrease != nil && !s.config.AllowConnectionWindowIncrease(s, uint64(size)) {
+				if s.memoryReservation != nil {
+					s.memoryReservation.UndoWindowIncrease(size)
+				}
+				return false
+			}
+			return true
+		},
+		func(size protocol.ByteCount) protocol.ByteCount {
+			if s.memoryReservation == nil {
+				return size
+			}
+			return s.memoryReservation.LimitWindowSize(size)
+		},
 		s.rttStats,
 		s.logger,
 	)
+	if s.config.MemoryBudget != nil {
+		s.memoryReservation = s.config.MemoryBudget.newReservation(
+			protocol.ByteCount(s.config.InitialConnectionReceiveWindow),
+			s.connFlowController.BufferedBytes,
+		)
+	}
 	s.earlySessionReadyChan = make(chan struct{})
 	s.streamsMap = newStreamsMap(
 		s,
@@ -533,22 +663,43 @@ func (s *session) preSetup() {
 
 	s.windowUpdateQueue = newWindowUpdateQueue(s.streamsMap, s.connFlowController, s.framer.QueueControlFrame)
 	if s.config.EnableDatagrams {
-		s.datagramQueue = newDatagramQueue(s.scheduleSending, s.logger)
+		s.datagramQueue = newDatagramQueue(s.scheduleSending, s.config.DatagramSendQueueLen, s.config.DatagramDropPolicy, s.logger)
 	}
 }
 
+// newCongestionController creates the congestion controller configured in the Config.
+// It returns nil if none is configured, in which case the default congestion controller is used.
+func (s *session) newCongestionController() congestion.SendAlgorithmWithDebugInfos {
+	if s.config.CongestionControl == nil {
+		return nil
+	}
+	return s.config.CongestionControl(&congestion.ConnectionInfo{
+		Perspective:            s.perspective,
+		RTTStats:               s.rttStats,
+		InitialMaxDatagramSize: getMaxPacketSize(s.conn.RemoteAddr()),
+		Tracer:                 s.tracer,
+	})
+}
+
+func (s *session) runSendQueue(q sender) {
+	go func() {
+		if err := q.Run(); err != nil {
+			s.destroyImpl(err)
+		}
+	}()
+}
+
 // run the session main loop
 func (s *session) run() error {
 	defer s.ctxCancel()
+	if s.memoryReservation != nil {
+		defer s.memoryReservation.Release()
+	}
 
 	s.timer = utils.NewTimer()
 
 	go s.cryptoStreamHandler.RunHandshake()
-	go func() {
-		if err := s.sendQueue.Run(); err != nil {
-			s.destroyImpl(err)
-		}
-	}()
+	s.runSendQueue(s.sendQueue)
 
 	if s.perspective == protocol.PerspectiveClient {
 		select {
@@ -609,6 +760,10 @@ runLoop:
 				// We do all the interesting stuff after the switch statement, so
 				// nothing to see here.
 			case <-sendQueueAvailable:
+			case m := <-s.migrationRequests:
+				s.startMigration(m)
+			case c := <-s.statsRequests:
+				c <- s.getStats()
 			case firstPacket := <-s.receivedPackets:
 				wasProcessed := s.handlePacketImpl(firstPacket)
 				// Don't set timers and send packets if the packet made us close the session.
@@ -676,6 +831,10 @@ runLoop:
 			}
 		}
 
+		if s.pathValidator != nil {
+			s.maybeProbePath(now)
+		}
+
 		if s.sendQueue.WouldBlock() {
 			// The send queue is still busy sending out packets.
 			// Wait until there's space to enqueue new packets.
@@ -703,6 +862,52 @@ runLoop:
 	return closeErr.err
 }
 
+func (s *session) ForceKeyUpdate() {
+	s.cryptoStreamHandler.ForceKeyUpdate()
+	// Send a packet, so that the new keys are used right away.
+	s.framer.QueueControlFrame(&wire.PingFrame{})
+	s.scheduleSending()
+}
+
+func (s *session) keyUpdatePolicy() handshake.KeyUpdatePolicy {
+	return handshake.KeyUpdatePolicy{
+		PacketInterval: s.config.KeyUpdateInterval,
+		TimeInterval:   s.config.KeyUpdateTimeInterval,
+	}
+}
+
+func (s *session) Stats() ConnectionStats {
+	c := make(chan ConnectionStats, 1)
+	select {
+	case s.statsRequests <- c:
+		return <-c
+	case <-s.ctx.Done():
+		// The run loop has returned, so the session state won't be modified any more.
+		return s.getStats()
+	}
+}
+
+func (s *session) getStats() ConnectionStats {
+	stats := s.sentPacketHandler.GetStats()
+	return ConnectionStats{
+		MinRTT:             s.rttStats.MinRTT(),
+		LatestRTT:          s.rttStats.LatestRTT(),
+		SmoothedRTT:        s.rttStats.SmoothedRTT(),
+		MeanDeviation:      s.rttStats.MeanDeviation(),
+		CongestionWindow:   uint64(stats.CongestionWindow),
+		BytesInFlight:      uint64(stats.BytesInFlight),
+		MTU:                uint64(s.packer.MaxPacketSize()),
+		PacketsSent:        stats.PacketsSent,
+		BytesSent:          uint64(stats.BytesSent),
+		PacketsReceived:    s.packetsReceived,
+		BytesReceived:      uint64(s.bytesReceived),
+		PacketsLost:        stats.PacketsLost,
+		BytesLost:          uint64(stats.BytesLost),
+		BytesRetransmitted: uint64(s.retransmissionQueue.BytesQueued() + s.streamBytesRetransmitted),
+		PTOCount:           stats.PTOCount,
+	}
+}
+
 // blocks until the early session can be used
 func (s *session) earlySessionReady() <-chan struct{} {
 	return s.earlySessionReadyChan
@@ -765,6 +970,9 @@ func (s *session) maybeResetTimer() {
 	if !s.pacingDeadline.IsZero() {
 		deadline = utils.MinTime(deadline, s.pacingDeadline)
 	}
+	if s.pathValidator != nil {
+		deadline = utils.MinTime(deadline, utils.MinTime(s.pathValidator.NextChallengeTime(), s.pathValidator.Deadline()))
+	}
 
 	s.timer.Reset(deadline)
 }
@@ -791,7 +999,11 @@ func (s *session) handleHandshakeComplete() {
 
 	s.handleHandshakeConfirmed()
 
-	ticket, err := s.cryptoStreamHandler.GetSessionTicket()
+	var appData []byte
+	if s.config.GetAppDataForSessionTicket != nil {
+		appData = s.config.GetAppDataForSessionTicket()
+	}
+	ticket, err := s.cryptoStreamHandler.GetSessionTicket(appData)
 	if err != nil {
 		s.closeLocal(err)
 	}
@@ -815,25 +1027,37 @@ func (s *session) handleHandshakeConfirmed() {
 	s.cryptoStreamHandler.SetHandshakeConfirmed()
 
 	if !s.config.DisablePathMTUDiscovery {
-		maxPacketSize := s.peerParams.MaxUDPPayloadSize
-		if maxPacketSize == 0 {
-			maxPacketSize = protocol.MaxByteCount
-		}
-		maxPacketSize = utils.MinByteCount(maxPacketSize, protocol.MaxPacketBufferSize)
-		s.mtuDiscoverer = newMTUDiscoverer(
-			s.rttStats,
-			getMaxPacketSize(s.conn.RemoteAddr()),
-			maxPacketSize,
-			func(size protocol.ByteCount) {
-				s.sentPacketHandler.SetMaxDatagramSize(size)
-				s.packer.SetMaxPacketSize(size)
-			},
-		)
+		s.startPathMTUDiscovery()
+	}
+	if s.perspective == protocol.PerspectiveClient && s.peerParams.PreferredAddress != nil {
+		s.migrateToPreferredAddress()
 	}
 }
 
+func (s *session) startPathMTUDiscovery() {
+	maxPacketSize := s.peerParams.MaxUDPPayloadSize
+	if maxPacketSize == 0 {
+		maxPacketSize = protocol.MaxByteCount
+	}
+	maxPacketSize = utils.MinByteCount(maxPacketSize, protocol.MaxPacketBufferSize)
+	s.mtuDiscoverer = newMTUDiscoverer(
+		s.rttStats,
+		getMaxPacketSize(s.conn.RemoteAddr()),
+		maxPacketSize,
+		func(size protocol.ByteCount) {
+			s.sentPacketHandler.SetMaxDatagramSize(size)
+			s.packer.SetMaxPacketSize(size)
+			s.updateMaxDatagramSize()
+		},
+	)
+}
+
 func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 	s.sentPacketHandler.ReceivedBytes(rp.Size())
+	// Until the handshake completes, all packets are received on the connection the first packet was received on.
+	if s.perspective == protocol.PerspectiveServer && s.rcvConn == nil {
+		s.rcvConn = rp.rcvConn
+	}
 
 	if wire.IsVersionNegotiationPacket(rp.data) {
 		s.handleVersionNegotiationPacket(rp)
@@ -864,7 +1088,7 @@ func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 			break
 		}
 
-		if hdr.IsLongHeader && hdr.Version != s.version {
+		if hdr.IsLongHeader && hdr.Version != s.version && !s.isCompatibleVersion(hdr.Version) {
 			if s.tracer != nil {
 				s.tracer.DroppedPacket(logging.PacketTypeFromHeader(hdr), protocol.ByteCount(len(data)), logging.PacketDropUnexpectedVersion)
 			}
@@ -893,6 +1117,8 @@ func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 		p.data = packetData
 		if wasProcessed := s.handleSinglePacket(p, hdr); wasProcessed {
 			processed = true
+			s.packetsReceived++
+			s.bytesReceived += protocol.ByteCount(len(packetData))
 		}
 		data = rest
 	}
@@ -900,6 +1126,17 @@ func (s *session) handlePacketImpl(rp *receivedPacket) bool {
 	return processed
 }
 
+// isCompatibleVersion says if a long header packet sent with a version different from the one in use is accepted.
+// The server switches to a compatible version during the handshake, so it accepts packets sent with the version
+// that the client started the handshake with.
+// The client accepts the compatible version chosen by the server until it receives the first packet.
+func (s *session) isCompatibleVersion(v protocol.VersionNumber) bool {
+	if s.perspective == protocol.PerspectiveServer {
+		return v == s.initialVersion
+	}
+	return !s.receivedFirstPacket && protocol.IsSupportedVersion(s.config.Versions, v) && protocol.AreCompatibleVersions(v, s.version)
+}
+
 func (s *session) handleSinglePacket(p *receivedPacket, hdr *wire.Header) bool /* was the packet successfully processed */ {
 	var wasQueued bool
 
@@ -962,7 +1199,8 @@ func (s *session) handleSinglePacket(p *receivedPacket, hdr *wire.Header) bool /
 				s.logger.Debugf("Dropping %s packet (%d bytes) for which we couldn't unpack the header. Error: %s", hdr.PacketType(), p.Size(), err)
 			} else {
 				// This is an error returned by the AEAD (other than ErrDecryptionFailed).
-				// For example, a PROTOCOL_VIOLATION due to key updates.
+				// For example, a PROTOCOL_VIOLATION due to key updates,
+				// or an AEAD_LIMIT_REACHED when the integrity limit is reached.
 				s.closeLocal(err)
 			}
 		}
@@ -982,13 +1220,46 @@ func (s *session) handleSinglePacket(p *receivedPacket, hdr *wire.Header) bool /
 		return false
 	}
 
-	if err := s.handleUnpackedPacket(packet, p.ecn, p.rcvTime, p.Size()); err != nil {
+	// The server detects if the client migrated to a new address, see section 9.3 of RFC 9000.
+	if s.perspective == protocol.PerspectiveServer && s.handshakeConfirmed && packet.encryptionLevel == protocol.Encryption1RTT && s.isNewPath(p) {
+		var conn sendConn
+		if p.rcvConn != nil {
+			conn = newSendConn(p.rcvConn, p.remoteAddr, p.info)
+		} else {
+			conn = s.conn.WithRemoteAddr(p.remoteAddr, p.info)
+		}
+		s.probePath = &unvalidatedPath{
+			conn:          conn,
+			rcvConn:       p.rcvConn,
+			bytesReceived: p.Size(),
+		}
+		defer func() { s.probePath = nil }()
+	}
+
+	isNonProbing, err := s.handleUnpackedPacket(packet, p.ecn, p.rcvTime, p.Size())
+	if err != nil {
 		s.closeLocal(err)
 		return false
 	}
+	if packet.encryptionLevel == protocol.Encryption1RTT && packet.packetNumber > s.largestRcvd1RTTPacketNumber {
+		s.largestRcvd1RTTPacketNumber = packet.packetNumber
+		if s.probePath != nil && isNonProbing {
+			s.handlePeerMigration(s.probePath)
+		}
+	}
 	return true
 }
 
+// isNewPath says if a packet was received on a path other than the current path.
+// This is the case if it was sent from a different remote address,
+// or if it was received on a different local address (i.e. on the server's preferred address).
+func (s *session) isNewPath(p *receivedPacket) bool {
+	if p.rcvConn != nil && p.rcvConn != s.rcvConn {
+		return true
+	}
+	return p.remoteAddr != nil && !addrsEqual(p.remoteAddr, s.conn.RemoteAddr())
+}
+
 func (s *session) handleRetryPacket(hdr *wire.Header, data []byte) bool /* was this a valid Retry */ {
 	if s.perspective == protocol.PerspectiveServer {
 		if s.tracer != nil {
@@ -1107,13 +1378,18 @@ func (s *session) handleUnpackedPacket(
 	ecn protocol.ECN,
 	rcvTime time.Time,
 	packetSize protocol.ByteCount, // only for logging
-) error {
+) (bool /* is non-probing */, error) {
 	if len(packet.data) == 0 {
-		return qerr.NewError(qerr.ProtocolViolation, "empty packet")
+		return false, qerr.NewError(qerr.ProtocolViolation, "empty packet")
 	}
 
 	if !s.receivedFirstPacket {
 		s.receivedFirstPacket = true
+		// The server can switch to a compatible version.
+		if s.perspective == protocol.PerspectiveClient && packet.hdr.IsLongHeader && packet.hdr.Version != s.version {
+			s.logger.Debugf("Server switched to compatible version %s.", packet.hdr.Version)
+			s.switchVersion(packet.hdr.Version, s.config.Versions, nil)
+		}
 		// The server can change the source connection ID with the first Handshake packet.
 		if s.perspective == protocol.PerspectiveClient && packet.hdr.IsLongHeader && !packet.hdr.SrcConnectionID.Equal(s.handshakeDestConnID) {
 			cid := packet.hdr.SrcConnectionID
@@ -1150,11 +1426,11 @@ func (s *session) handleUnpackedPacket(
 	// If we're not tracing, this slice will always remain empty.
 	var frames []wire.Frame
 	r := bytes.NewReader(packet.data)
-	var isAckEliciting bool
+	var isAckEliciting, isNonProbing bool
 	for {
 		frame, err := s.frameParser.ParseNext(r, packet.encryptionLevel)
 		if err != nil {
-			return err
+			return false, err
 		}
 		if frame == nil {
 			break
@@ -1162,11 +1438,14 @@ func (s *session) handleUnpackedPacket(
 		if ackhandler.IsFrameAckEliciting(frame) {
 			isAckEliciting = true
 		}
+		if !isProbingFrame(frame) {
+			isNonProbing = true
+		}
 		// Only process frames now if we're not logging.
 		// If we're logging, we need to make sure that the packet_received event is logged first.
 		if s.tracer == nil {
 			if err := s.handleFrame(frame, packet.encryptionLevel, packet.hdr.DestConnectionID); err != nil {
-				return err
+				return false, err
 			}
 		} else {
 			frames = append(frames, frame)
@@ -1181,12 +1460,23 @@ func (s *session) handleUnpackedPacket(
 		s.tracer.ReceivedPacket(packet.hdr, packetSize, fs)
 		for _, frame := range frames {
 			if err := s.handleFrame(frame, packet.encryptionLevel, packet.hdr.DestConnectionID); err != nil {
-				return err
+				return false, err
 			}
 		}
 	}
 
-	return s.receivedPacketHandler.ReceivedPacket(packet.packetNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
+	return isNonProbing, s.receivedPacketHandler.ReceivedPacket(packet.packetNumber, ecn, packet.encryptionLevel, rcvTime, isAckEliciting)
+}
+
+// PATH_CHALLENGE, PATH_RESPONSE, NEW_CONNECTION_ID and PADDING frames are probing frames,
+// see section 9.1 of RFC 9000.
+func isProbingFrame(f wire.Frame) bool {
+	switch f.(type) {
+	case *wire.PathChallengeFrame, *wire.PathResponseFrame, *wire.NewConnectionIDFrame:
+		return true
+	default:
+		return false
+	}
 }
 
 func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, destConnID protocol.ConnectionID) error {
@@ -1196,13 +1486,15 @@ func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, d
 	case *wire.CryptoFrame:
 		err = s.handleCryptoFrame(frame, encLevel)
 	case *wire.StreamFrame:
-		err = s.handleStreamFrame(frame)
+		err = s.handleStreamFrame(frame, encLevel)
 	case *wire.AckFrame:
 		err = s.handleAckFrame(frame, encLevel)
 	case *wire.ConnectionCloseFrame:
 		s.handleConnectionCloseFrame(frame)
 	case *wire.ResetStreamFrame:
 		err = s.handleResetStreamFrame(frame)
+	case *wire.ResetStreamAtFrame:
+		err = s.handleResetStreamAtFrame(frame)
 	case *wire.MaxDataFrame:
 		s.handleMaxDataFrame(frame)
 	case *wire.MaxStreamDataFrame:
@@ -1218,8 +1510,7 @@ func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, d
 	case *wire.PathChallengeFrame:
 		s.handlePathChallengeFrame(frame)
 	case *wire.PathResponseFrame:
-		// since we don't send PATH_CHALLENGEs, we don't expect PATH_RESPONSEs
-		err = errors.New("unexpected PATH_RESPONSE frame")
+		s.handlePathResponseFrame(frame)
 	case *wire.NewTokenFrame:
 		err = s.handleNewTokenFrame(frame)
 	case *wire.NewConnectionIDFrame:
@@ -1230,6 +1521,10 @@ func (s *session) handleFrame(f wire.Frame, encLevel protocol.EncryptionLevel, d
 		err = s.handleHandshakeDoneFrame()
 	case *wire.DatagramFrame:
 		err = s.handleDatagramFrame(frame)
+	case *wire.AckFrequencyFrame:
+		err = s.handleAckFrequencyFrame(frame)
+	case *wire.ImmediateAckFrame:
+		s.receivedPacketHandler.QueueImmediateAck()
 	default:
 		err = fmt.Errorf("unexpected frame type: %s", reflect.ValueOf(&frame).Elem().Type().Name())
 	}
@@ -1272,7 +1567,7 @@ func (s *session) handleCryptoFrame(frame *wire.CryptoFrame, encLevel protocol.E
 	return nil
 }
 
-func (s *session) handleStreamFrame(frame *wire.StreamFrame) error {
+func (s *session) handleStreamFrame(frame *wire.StreamFrame, encLevel protocol.EncryptionLevel) error {
 	str, err := s.streamsMap.GetOrOpenReceiveStream(frame.StreamID)
 	if err != nil {
 		return err
@@ -1282,6 +1577,9 @@ func (s *session) handleStreamFrame(frame *wire.StreamFrame) error {
 		// ignore this StreamFrame
 		return nil
 	}
+	if encLevel == protocol.Encryption0RTT {
+		str.markReceived0RTT()
+	}
 	return str.handleStreamFrame(frame)
 }
 
@@ -1318,6 +1616,18 @@ func (s *session) handleResetStreamFrame(frame *wire.ResetStreamFrame) error {
 	return str.handleResetStreamFrame(frame)
 }
 
+func (s *session) handleResetStreamAtFrame(frame *wire.ResetStreamAtFrame) error {
+	str, err := s.streamsMap.GetOrOpenReceiveStream(frame.StreamID)
+	if err != nil {
+		return err
+	}
+	if str == nil {
+		// stream is closed and already garbage collected
+		return nil
+	}
+	return str.handleResetStreamAtFrame(frame)
+}
+
 func (s *session) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 	str, err := s.streamsMap.GetOrOpenSendStream(frame.StreamID)
 	if err != nil {
@@ -1332,7 +1642,321 @@ func (s *session) handleStopSendingFrame(frame *wire.StopSendingFrame) error {
 }
 
 func (s *session) handlePathChallengeFrame(frame *wire.PathChallengeFrame) {
-	s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
+	if s.probePath == nil {
+		s.queueControlFrame(&wire.PathResponseFrame{Data: frame.Data})
+		return
+	}
+	// The PATH_RESPONSE has to be sent on the path that the PATH_CHALLENGE was received on.
+	// The datagram is padded to 1200 bytes, unless the anti-amplification limit doesn't allow that.
+	size := utils.MinByteCount(protocol.MinInitialPacketSize, 3*s.probePath.bytesReceived)
+	s.sendPathProbe(s.probePath.conn, s.connIDManager.Get(), &wire.PathResponseFrame{Data: frame.Data}, size)
+}
+
+func (s *session) handlePathResponseFrame(frame *wire.PathResponseFrame) {
+	// A PATH_RESPONSE might arrive after the path validation already completed or failed.
+	if s.pathValidator == nil || !s.pathValidator.HandlePathResponse(frame) {
+		s.logger.Debugf("Ignoring PATH_RESPONSE that doesn't match any PATH_CHALLENGE.")
+		return
+	}
+	s.logger.Debugf("Path validation for %s succeeded.", s.pathValidator.conn.RemoteAddr())
+	s.pathValidator = nil
+	if s.perspective == protocol.PerspectiveServer {
+		// We already switched to the new path when the client migrated.
+		s.lastValidatedConn = nil
+		s.lastValidatedRcvConn = nil
+		s.sentPacketHandler.ValidatedPath()
+		return
+	}
+	m := s.migration
+	s.migration = nil
+	if !s.connIDManager.ActivateReservedConnectionID() {
+		s.abortMigration(m, errors.New("migration failed: connection ID was retired"))
+		return
+	}
+	if m.runner != nil {
+		s.moveToRunner(m.runner)
+	}
+	s.switchConn(m.conn)
+	s.sentPacketHandler.MigratedPath(s.newCongestionController(), true)
+	s.resetPathMTU()
+	m.result <- nil
+}
+
+// A pathMigration is a request to migrate the session to a new local address (see section 9.2 of RFC 9000),
+// or to the server's preferred address (see section 9.6 of RFC 9000).
+type pathMigration struct {
+	conn sendConn
+	// The packet conn for the new local address, passed to MigrateTo.
+	// It is nil when migrating to the server's preferred address.
+	pconn net.PacketConn
+	// The runner for the new local address.
+	// It is nil when migrating to the server's preferred address, since the local address doesn't change.
+	runner             sessionRunner
+	toPreferredAddress bool
+	result             chan error
+}
+
+// An unvalidatedPath is a path other than the current path that the peer sent a packet on.
+type unvalidatedPath struct {
+	conn          sendConn
+	rcvConn       connection
+	bytesReceived protocol.ByteCount
+}
+
+func (s *session) MigrateTo(conn net.PacketConn) error {
+	if s.perspective == protocol.PerspectiveServer {
+		return errors.New("only clients can migrate")
+	}
+	m := &pathMigration{
+		conn:   newSendPconn(conn, s.RemoteAddr()),
+		pconn:  conn,
+		result: make(chan error, 1),
+	}
+	select {
+	case s.migrationRequests <- m:
+	case <-s.ctx.Done():
+		return errors.New("session closed")
+	}
+	// The run loop either completes the migration, or aborts it when the session is closed.
+	return <-m.result
+}
+
+func (s *session) startMigration(m *pathMigration) {
+	if !s.handshakeConfirmed {
+		m.result <- errors.New("can't migrate before the handshake is confirmed")
+		return
+	}
+	// The disable_active_migration transport parameter doesn't apply to the server's preferred address.
+	if s.peerParams.DisableActiveMigration && !m.toPreferredAddress {
+		m.result <- errors.New("the server disabled active migration")
+		return
+	}
+	if s.migration != nil {
+		m.result <- errors.New("already migrating")
+		return
+	}
+	connID, ok := s.connIDManager.ReserveConnectionID()
+	if !ok {
+		m.result <- errors.New("no unused connection ID available")
+		return
+	}
+	// Only start listening on the new local address once we know that we can migrate.
+	if m.pconn != nil {
+		runner, err := getMultiplexer().AddConn(m.pconn, s.srcConnIDLen, s.config.StatelessResetKey, s.config.Tracer)
+		if err != nil {
+			s.connIDManager.RetireReservedConnectionID()
+			m.result <- err
+			return
+		}
+		m.runner = runner
+	}
+	// The server replies to our probe packets on the new path.
+	if m.runner != nil {
+		for _, connID := range s.connIDGenerator.ConnectionIDs() {
+			m.runner.Add(connID, s)
+		}
+	}
+	s.logger.Debugf("Probing new path from %s to %s.", m.conn.LocalAddr(), m.conn.RemoteAddr())
+	s.migration = m
+	s.pathValidator = newPathValidator(m.conn, connID, protocol.MinInitialPacketSize, s.rttStats, time.Now())
+}
+
+// migrateToPreferredAddress starts the migration to the server's preferred address, see section 9.6 of RFC 9000.
+// We keep using the same local address, only the server's address changes.
+func (s *session) migrateToPreferredAddress() {
+	addr := s.selectPreferredAddress()
+	if addr == nil {
+		s.logger.Debugf("Server sent a preferred_address, but no address matches the address family of the current path.")
+		s.connIDManager.RetireReservedConnectionID()
+		return
+	}
+	s.startMigration(&pathMigration{
+		conn:               s.conn.WithRemoteAddr(addr, nil),
+		toPreferredAddress: true,
+		result:             make(chan error, 1),
+	})
+}
+
+// selectPreferredAddress selects the address from the preferred_address transport parameter
+// that uses the same address family as the current path.
+// It returns nil if the server didn't send an address of that address family.
+func (s *session) selectPreferredAddress() *net.UDPAddr {
+	remoteAddr, ok := s.conn.RemoteAddr().(*net.UDPAddr)
+	if !ok {
+		return nil
+	}
+	pa := s.peerParams.PreferredAddress
+	ip, port := pa.IPv6, pa.IPv6Port
+	if remoteAddr.IP.To4() != nil {
+		ip, port = pa.IPv4, pa.IPv4Port
+	}
+	if ip.IsUnspecified() || port == 0 {
+		return nil
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
+
+// abortMigration is called when the migration to a new path fails, or when the session is closed during the migration.
+func (s *session) abortMigration(m *pathMigration, err error) {
+	if m.runner != nil {
+		m.runner.RemoveHandler(s)
+	}
+	s.connIDManager.RetireReservedConnectionID()
+	m.result <- err
+}
+
+func (s *session) maybeProbePath(now time.Time) {
+	if s.pathValidator.TimedOut(now) {
+		s.handlePathValidationTimeout()
+		return
+	}
+	if !s.pathValidator.ShouldSendChallenge(now) {
+		return
+	}
+	s.sendPathProbe(
+		s.pathValidator.conn,
+		s.pathValidator.destConnID,
+		s.pathValidator.GetChallenge(now),
+		s.pathValidator.datagramSize,
+	)
+}
+
+func (s *session) handlePathValidationTimeout() {
+	s.logger.Debugf("Path validation for %s timed out.", s.pathValidator.conn.RemoteAddr())
+	s.pathValidator = nil
+	if s.perspective == protocol.PerspectiveClient {
+		m := s.migration
+		s.migration = nil
+		s.abortMigration(m, errors.New("path validation timed out"))
+		return
+	}
+	// The client's new address couldn't be validated. Go back to the last validated path.
+	oldAddr, oldRcvConn := s.conn.RemoteAddr(), s.rcvConn
+	s.switchConn(s.lastValidatedConn)
+	s.rcvConn = s.lastValidatedRcvConn
+	s.lastValidatedConn = nil
+	s.lastValidatedRcvConn = nil
+	s.switchedPath(oldAddr, oldRcvConn, true)
+	s.resetPathMTU()
+}
+
+// handlePeerMigration is called by the server when the client sent a non-probing packet from a new address.
+func (s *session) handlePeerMigration(path *unvalidatedPath) {
+	s.logger.Debugf("Peer migrated to %s.", path.conn.RemoteAddr())
+	if s.lastValidatedConn == nil {
+		s.lastValidatedConn = s.conn
+		s.lastValidatedRcvConn = s.rcvConn
+	}
+	oldAddr, oldRcvConn := s.conn.RemoteAddr(), s.rcvConn
+	s.switchConn(path.conn)
+	if path.rcvConn != nil {
+		s.rcvConn = path.rcvConn
+	}
+	s.resetPathMTU()
+	if addrsEqual(path.conn.RemoteAddr(), s.lastValidatedConn.RemoteAddr()) {
+		// The peer's address didn't change, so it's still validated.
+		// This happens when the peer migrated back to the last validated path,
+		// or when it migrated to our preferred address.
+		s.lastValidatedConn = nil
+		s.lastValidatedRcvConn = nil
+		s.pathValidator = nil
+		s.switchedPath(oldAddr, oldRcvConn, true)
+		return
+	}
+	// Until the new path is validated, the anti-amplification limit applies.
+	// The packet that caused the migration counts towards the limit.
+	s.switchedPath(oldAddr, oldRcvConn, false)
+	s.sentPacketHandler.ReceivedBytes(path.bytesReceived)
+	s.pathValidator = newPathValidator(
+		path.conn,
+		s.connIDManager.Get(),
+		utils.MinByteCount(protocol.MinInitialPacketSize, 3*path.bytesReceived),
+		s.rttStats,
+		time.Now(),
+	)
+}
+
+// switchedPath is called by the server when it switched to a new path.
+// If only the peer's port changed, this is most likely a NAT rebinding, and the RTT estimate and the
+// congestion controller are kept (see section 9.4 of RFC 9000).
+func (s *session) switchedPath(oldAddr net.Addr, oldRcvConn connection, peerAddressValidated bool) {
+	if s.rcvConn == oldRcvConn && ipsEqual(oldAddr, s.conn.RemoteAddr()) {
+		s.sentPacketHandler.ReboundPath(peerAddressValidated)
+		return
+	}
+	s.sentPacketHandler.MigratedPath(s.newCongestionController(), peerAddressValidated)
+}
+
+// sendPathProbe sends a packet containing a PATH_CHALLENGE or a PATH_RESPONSE frame.
+// It is sent directly on the path, bypassing the send queue.
+func (s *session) sendPathProbe(conn sendConn, destConnID protocol.ConnectionID, f wire.Frame, size protocol.ByteCount) {
+	// Path probing frames are not retransmitted when lost.
+	// If necessary, a new PATH_CHALLENGE is sent.
+	frames := []ackhandler.Frame{{Frame: f, OnLost: func(wire.Frame) {}}}
+	packet, err := s.packer.PackPathProbePacket(destConnID, frames, size)
+	if err != nil {
+		s.closeLocal(err)
+		return
+	}
+	s.logPacket(packet.packetContents)
+	s.sentPacketHandler.SentPacket(packet.ToAckHandlerPacket(time.Now(), s.retransmissionQueue))
+	if err := conn.Write(packet.buffer.Data); err != nil {
+		s.logger.Debugf("Error sending path probe packet to %s: %s", conn.RemoteAddr(), err)
+	}
+	packet.buffer.Release()
+}
+
+// switchConn switches to sending packets on a new path.
+func (s *session) switchConn(conn sendConn) {
+	s.sendQueue.Close()
+	s.connMutex.Lock()
+	s.conn = conn
+	s.connMutex.Unlock()
+	s.sendQueue = newSendQueue(conn)
+	s.runSendQueue(s.sendQueue)
+}
+
+// moveToRunner moves all connection IDs and the stateless reset token to the packet handler map of a new path.
+// Retired connection IDs are removed from the old packet handler map right away,
+// such that closing the old net.PacketConn doesn't close this session.
+func (s *session) moveToRunner(runner sessionRunner) {
+	s.runner.RemoveHandler(s)
+	s.runner = runner
+	for _, connID := range s.connIDGenerator.ConnectionIDs() {
+		runner.Add(connID, s)
+	}
+	if token := s.connIDManager.StatelessResetToken(); token != nil {
+		runner.AddResetToken(*token, s)
+	}
+}
+
+// resetPathMTU is called after switching to a new path, for which the MTU is not known yet.
+func (s *session) resetPathMTU() {
+	maxPacketSize := getMaxPacketSize(s.conn.RemoteAddr())
+	if s.peerParams.MaxUDPPayloadSize != 0 {
+		maxPacketSize = utils.MinByteCount(maxPacketSize, s.peerParams.MaxUDPPayloadSize)
+	}
+	s.packer.SetMaxPacketSize(maxPacketSize)
+	s.updateMaxDatagramSize()
+	if !s.config.DisablePathMTUDiscovery {
+		s.startPathMTUDiscovery()
+	}
+}
+
+func addrsEqual(addr1, addr2 net.Addr) bool {
+	if udpAddr1, ok := addr1.(*net.UDPAddr); ok {
+		if udpAddr2, ok := addr2.(*net.UDPAddr); ok {
+			return udpAddr1.IP.Equal(udpAddr2.IP) && udpAddr1.Port == udpAddr2.Port
+		}
+	}
+	return addr1.String() == addr2.String()
+}
+
+// ipsEqual says if two addresses have the same IP address, i.e. if they only differ in the port.
+func ipsEqual(addr1, addr2 net.Addr) bool {
+	udpAddr1, ok1 := addr1.(*net.UDPAddr)
+	udpAddr2, ok2 := addr2.(*net.UDPAddr)
+	return ok1 && ok2 && udpAddr1.IP.Equal(udpAddr2.IP)
 }
 
 func (s *session) handleNewTokenFrame(frame *wire.NewTokenFrame) error {
@@ -1370,6 +1994,10 @@ func (s *session) handleAckFrame(frame *wire.AckFrame, encLevel protocol.Encrypt
 	if encLevel != protocol.Encryption1RTT {
 		return nil
 	}
+	// The congestion window might have changed.
+	if f := s.sentPacketHandler.GetAckFrequencyFrame(); f != nil {
+		s.framer.QueueControlFrame(f)
+	}
 	return s.cryptoStreamHandler.SetLargest1RTTAcked(frame.LargestAcked())
 }
 
@@ -1381,6 +2009,14 @@ func (s *session) handleDatagramFrame(f *wire.DatagramFrame) error {
 	return nil
 }
 
+func (s *session) handleAckFrequencyFrame(f *wire.AckFrequencyFrame) error {
+	if f.RequestMaxAckDelay < protocol.MinAckDelay {
+		return qerr.NewError(qerr.ProtocolViolation, fmt.Sprintf("requested max ack delay (%s) smaller than min_ack_delay (%s)", f.RequestMaxAckDelay, protocol.MinAckDelay))
+	}
+	s.receivedPacketHandler.SetAckFrequency(f)
+	return nil
+}
+
 // closeLocal closes the session and send a CONNECTION_CLOSE containing the error
 func (s *session) closeLocal(e error) {
 	s.closeOnce.Do(func() {
@@ -1442,6 +2078,10 @@ func (s *session) handleCloseError(closeErr closeError) {
 	}
 
 	s.streamsMap.CloseWithError(quicErr)
+	if s.migration != nil {
+		s.abortMigration(s.migration, quicErr)
+		s.migration = nil
+	}
 	s.connIDManager.Close()
 	if s.datagramQueue != nil {
 		s.datagramQueue.CloseWithError(quicErr)
@@ -1510,6 +2150,7 @@ func (s *session) restoreTransportParameters(params *wire.TransportParameters) {
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.streamsMap.UpdateLimits(params)
+	s.updateMaxDatagramSize()
 }
 
 func (s *session) handleTransportParameters(params *wire.TransportParameters) {
@@ -1520,6 +2161,13 @@ func (s *session) handleTransportParameters(params *wire.TransportParameters) {
 	// On the client side we have to wait for handshake completion.
 	// During a 0-RTT connection, we are only allowed to use the new transport parameters for 1-RTT packets.
 	if s.perspective == protocol.PerspectiveServer {
+		// The crypto setup makes the same decision when sending our transport parameters.
+		if params.VersionInformation != nil {
+			if v := protocol.ChooseCompatibleVersion(s.config.Versions, s.initialVersion, params.VersionInformation.AvailableVersions); v != s.version {
+				s.logger.Debugf("Switching to compatible version %s.", v)
+				s.switchVersion(v, params.VersionInformation.AvailableVersions, s.config.Versions)
+			}
+		}
 		s.applyTransportParameters()
 		// On the server side, the early session is ready as soon as we processed
 		// the client's transport parameters.
@@ -1541,8 +2189,14 @@ func (s *session) checkTransportParameters(params *wire.TransportParameters) err
 	}
 
 	if s.perspective == protocol.PerspectiveServer {
+		if params.VersionInformation != nil && params.VersionInformation.ChosenVersion != s.initialVersion {
+			return qerr.NewError(qerr.VersionNegotiationError, fmt.Sprintf("expected chosen version to equal %s, is %s", s.initialVersion, params.VersionInformation.ChosenVersion))
+		}
 		return nil
 	}
+	if err := s.checkVersionInformation(params.VersionInformation); err != nil {
+		return err
+	}
 	// check the original_destination_connection_id
 	if !params.OriginalDestinationConnectionID.Equal(s.origDestConnID) {
 		return qerr.NewError(qerr.TransportParameterError, fmt.Sprintf("expected original_destination_connection_id to equal %s, is %s", s.origDestConnID, params.OriginalDestinationConnectionID))
@@ -1560,6 +2214,42 @@ func (s *session) checkTransportParameters(params *wire.TransportParameters) err
 	return nil
 }
 
+// checkVersionInformation checks the version_information sent by the server, see RFC 9368.
+func (s *session) checkVersionInformation(vi *wire.VersionInformation) error {
+	if vi == nil {
+		return nil
+	}
+	if vi.ChosenVersion != s.version {
+		return qerr.NewError(qerr.VersionNegotiationError, fmt.Sprintf("expected chosen version to equal %s, is %s", s.version, vi.ChosenVersion))
+	}
+	// Prevent version downgrade attacks:
+	// If we performed incompatible version negotiation, we must have picked the same version
+	// based on the versions that the server lists in its transport parameters.
+	if s.versionNegotiated {
+		if v, ok := protocol.ChooseSupportedVersion(s.config.Versions, vi.AvailableVersions); !ok || v != s.initialVersion {
+			return qerr.NewError(qerr.VersionNegotiationError, fmt.Sprintf("version downgrade detected (server supports %s)", vi.AvailableVersions))
+		}
+	}
+	return nil
+}
+
+// switchVersion switches to a compatible version.
+// The server learns the versions supported by the client from the client's transport parameters,
+// the client doesn't learn the versions supported by the server, so serverVersions is nil for the client.
+// Streams keep using the version they were created with, since compatible versions use the same frame encodings.
+func (s *session) switchVersion(v protocol.VersionNumber, clientVersions, serverVersions []protocol.VersionNumber) {
+	s.version = v
+	s.cryptoStreamHandler.SetVersion(v)
+	s.unpacker.SetVersion(v)
+	s.frameParser.SetVersion(v)
+	s.retransmissionQueue.SetVersion(v)
+	s.framer.SetVersion(v)
+	s.packer.SetVersion(v)
+	if s.tracer != nil {
+		s.tracer.NegotiatedVersion(v, clientVersions, serverVersions)
+	}
+}
+
 func (s *session) applyTransportParameters() {
 	params := s.peerParams
 	// Our local idle timeout will always be > 0.
@@ -1567,16 +2257,19 @@ func (s *session) applyTransportParameters() {
 	s.keepAliveInterval = utils.MinDuration(s.idleTimeout/2, protocol.MaxKeepAliveInterval)
 	s.streamsMap.UpdateLimits(params)
 	s.packer.HandleTransportParameters(params)
+	s.updateMaxDatagramSize()
 	s.frameParser.SetAckDelayExponent(params.AckDelayExponent)
 	s.connFlowController.UpdateSendWindow(params.InitialMaxData)
 	s.rttStats.SetMaxAckDelay(params.MaxAckDelay)
+	if s.config.EnableAckFrequency && params.MinAckDelay != nil {
+		s.sentPacketHandler.EnableAckFrequency()
+	}
+	s.peerSupportsResetStreamAt.Set(s.config.EnableResetStreamAt && params.EnableResetStreamAt)
 	s.connIDGenerator.SetMaxActiveConnIDs(params.ActiveConnectionIDLimit)
 	if params.StatelessResetToken != nil {
 		s.connIDManager.SetStatelessResetToken(*params.StatelessResetToken)
 	}
-	// We don't support connection migration yet, so we don't have any use for the preferred_address.
 	if params.PreferredAddress != nil {
-		// Retire the connection ID.
 		s.connIDManager.AddFromPreferredAddress(params.PreferredAddress.ConnectionID, params.PreferredAddress.StatelessResetToken)
 	}
 }
@@ -1684,7 +2377,12 @@ func (s *session) sendProbePacket(encLevel protocol.EncryptionLevel) error {
 		case protocol.EncryptionHandshake:
 			s.retransmissionQueue.AddHandshake(&wire.PingFrame{})
 		case protocol.Encryption1RTT:
-			s.retransmissionQueue.AddAppData(&wire.PingFrame{})
+			// If the peer supports it, elicit an ACK without waiting for the (potentially increased) ack threshold.
+			if s.config.EnableAckFrequency && s.peerParams.MinAckDelay != nil {
+				s.retransmissionQueue.AddAppData(&wire.ImmediateAckFrame{})
+			} else {
+				s.retransmissionQueue.AddAppData(&wire.PingFrame{})
+			}
 		default:
 			panic("unexpected encryption level")
 		}
@@ -1732,6 +2430,9 @@ func (s *session) sendPacket() (bool, error) {
 		s.sendPackedPacket(packet, now)
 		return true, nil
 	}
+	if s.conn.SupportsGSO() {
+		return s.sendPacketBatch(now)
+	}
 	packet, err := s.packer.PackPacket()
 	if err != nil || packet == nil {
 		return false, err
@@ -1740,14 +2441,50 @@ func (s *session) sendPacket() (bool, error) {
 	return true, nil
 }
 
+// sendPacketBatch packs multiple packets into a single buffer,
+// such that they can be sent using a single syscall (using GSO).
+// All packets except for the last one have the maximum packet size.
+func (s *session) sendPacketBatch(now time.Time) (bool, error) {
+	buffer := getLargePacketBuffer()
+	maxPacketSize := s.packer.MaxPacketSize()
+	for {
+		packet, err := s.packer.AppendPacket(buffer)
+		if err != nil {
+			buffer.Release()
+			return false, err
+		}
+		if packet == nil {
+			break
+		}
+		s.registerSentPacket(packet, now)
+		// Only full-size packets can be followed by another packet in the same batch.
+		if packet.length != maxPacketSize || buffer.Len()+maxPacketSize > protocol.ByteCount(cap(buffer.Data)) {
+			break
+		}
+		if s.sentPacketHandler.SendMode() != ackhandler.SendAny || !s.sentPacketHandler.HasPacingBudget() {
+			break
+		}
+	}
+	if buffer.Len() == 0 {
+		buffer.Release()
+		return false, nil
+	}
+	s.sendQueue.SendBatch(buffer, maxPacketSize)
+	return true, nil
+}
+
 func (s *session) sendPackedPacket(packet *packedPacket, now time.Time) {
+	s.registerSentPacket(packet.packetContents, now)
+	s.sendQueue.Send(packet.buffer)
+}
+
+func (s *session) registerSentPacket(packet *packetContents, now time.Time) {
 	if s.firstAckElicitingPacketAfterIdleSentTime.IsZero() && packet.IsAckEliciting() {
 		s.firstAckElicitingPacketAfterIdleSentTime = now
 	}
 	s.logPacket(packet)
 	s.sentPacketHandler.SentPacket(packet.ToAckHandlerPacket(now, s.retransmissionQueue))
 	s.connIDManager.SentPacket()
-	s.sendQueue.Send(packet.buffer)
 }
 
 func (s *session) sendConnectionClose(quicErr *qerr.QuicError) ([]byte, error) {
@@ -1795,11 +2532,11 @@ func (s *session) logCoalescedPacket(packet *coalescedPacket) {
 	}
 }
 
-func (s *session) logPacket(packet *packedPacket) {
+func (s *session) logPacket(packet *packetContents) {
 	if s.logger.Debug() {
-		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, %s", packet.header.PacketNumber, packet.buffer.Len(), s.logID, packet.EncryptionLevel())
+		s.logger.Debugf("-> Sending packet %d (%d bytes) for connection %s, %s", packet.header.PacketNumber, packet.length, s.logID, packet.EncryptionLevel())
 	}
-	s.logPacketContents(packet.packetContents)
+	s.logPacketContents(packet)
 }
 
 // AcceptStream returns the next stream openend by the peer
@@ -1895,6 +2632,15 @@ func (s *session) onHasStreamData(id protocol.StreamID) {
 	s.scheduleSending()
 }
 
+// onQueuedRetransmission is called from the run loop, when a lost STREAM frame is queued for retransmission.
+func (s *session) onQueuedRetransmission(l protocol.ByteCount) {
+	s.streamBytesRetransmitted += l
+}
+
+func (s *session) supportsResetStreamAt() bool {
+	return s.peerSupportsResetStreamAt.Get()
+}
+
 func (s *session) onStreamCompleted(id protocol.StreamID) {
 	if err := s.streamsMap.DeleteStream(id); err != nil {
 		s.closeLocal(err)
@@ -1902,14 +2648,61 @@ func (s *session) onStreamCompleted(id protocol.StreamID) {
 }
 
 func (s *session) SendMessage(p []byte) error {
-	f := &wire.DatagramFrame{DataLenPresent: true}
-	if protocol.ByteCount(len(p)) > f.MaxDataLen(s.peerParams.MaxDatagramFrameSize, s.version) {
-		return errors.New("message too large")
+	f, err := s.newDatagramFrame(p)
+	if err != nil {
+		return err
+	}
+	return s.datagramQueue.AddAndWait(f)
+}
+
+func (s *session) SendDatagram(p []byte, opts *DatagramSendOptions) error {
+	f, err := s.newDatagramFrame(p)
+	if err != nil {
+		return err
 	}
-	f.Data = make([]byte, len(p))
+	var deadline time.Time
+	var onOutcome func(DatagramOutcome)
+	if opts != nil {
+		deadline = opts.Deadline
+		onOutcome = opts.OnOutcome
+	}
+	return s.datagramQueue.Add(f, deadline, onOutcome)
+}
+
+func (s *session) newDatagramFrame(p []byte) (*wire.DatagramFrame, error) {
+	if s.datagramQueue == nil {
+		return nil, errors.New("datagram support disabled")
+	}
+	if protocol.ByteCount(len(p)) > s.datagramQueue.MaxDataLen() {
+		return nil, errors.New("message too large")
+	}
+	f := &wire.DatagramFrame{DataLenPresent: true, Data: make([]byte, len(p))}
 	copy(f.Data, p)
-	s.datagramQueue.AddAndWait(f)
-	return nil
+	return f, nil
+}
+
+func (s *session) MaxDatagramSize() int {
+	if s.datagramQueue == nil {
+		return 0
+	}
+	return int(s.datagramQueue.MaxDataLen())
+}
+
+// updateMaxDatagramSize updates the maximum size of a datagram that can be sent.
+// It is limited by the peer's max_datagram_frame_size and by the size of a 1-RTT packet.
+func (s *session) updateMaxDatagramSize() {
+	if s.datagramQueue == nil {
+		return
+	}
+	if s.peerParams == nil || !s.supportsDatagrams() {
+		s.datagramQueue.SetMaxDataLen(0)
+		return
+	}
+	// Assume the largest possible short header and a 16 byte AEAD tag.
+	maxFrameSize := s.packer.MaxPacketSize() - (1 + protocol.MaxConnIDLen + protocol.ByteCount(protocol.PacketNumberLen4)) - 16
+	maxFrameSize = utils.MinByteCount(maxFrameSize, s.peerParams.MaxDatagramFrameSize)
+	f := &wire.DatagramFrame{DataLenPresent: true}
+	s.datagramQueue.SetMaxDataLen(f.MaxDataLen(maxFrameSize, s.version))
 }
 
 func (s *session) ReceiveMessage() ([]byte, error) {
@@ -1917,10 +2710,14 @@ func (s *session) ReceiveMessage() ([]byte, error) {
 }
 
 func (s *session) LocalAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.LocalAddr()
 }
 
 func (s *session) RemoteAddr() net.Addr {
+	s.connMutex.Lock()
+	defer s.connMutex.Unlock()
 	return s.conn.RemoteAddr()
 }
 
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lucas-clemente/quic-go/congestion"
 	"github.com/lucas-clemente/quic-go/internal/ackhandler"
 	"github.com/lucas-clemente/quic-go/internal/handshake"
 	"github.com/lucas-clemente/quic-go/internal/mocks"
@@ -87,7 +88,8 @@ var _ = Describe("Session", func() {
 		mconn = NewMockSendConn(mockCtrl)
 		mconn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(localAddr).AnyTimes()
-		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader)
+		mconn.EXPECT().SupportsGSO().AnyTimes()
+		tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader, nil)
 		Expect(err).ToNot(HaveOccurred())
 		tracer = mocklogging.NewMockConnectionTracer(mockCtrl)
 		tracer.EXPECT().SentTransportParameters(gomock.Any())
@@ -134,7 +136,21 @@ var _ = Describe("Session", func() {
 				str := NewMockReceiveStreamI(mockCtrl)
 				str.EXPECT().handleStreamFrame(f)
 				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(5)).Return(str, nil)
-				Expect(sess.handleStreamFrame(f)).To(Succeed())
+				Expect(sess.handleStreamFrame(f, protocol.Encryption1RTT)).To(Succeed())
+			})
+
+			It("tells the stream about STREAM frames received in 0-RTT packets", func() {
+				f := &wire.StreamFrame{
+					StreamID: 5,
+					Data:     []byte{0xde, 0xca, 0xfb, 0xad},
+				}
+				str := NewMockReceiveStreamI(mockCtrl)
+				gomock.InOrder(
+					str.EXPECT().markReceived0RTT(),
+					str.EXPECT().handleStreamFrame(f),
+				)
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(5)).Return(str, nil)
+				Expect(sess.handleStreamFrame(f, protocol.Encryption0RTT)).To(Succeed())
 			})
 
 			It("returns errors", func() {
@@ -146,7 +162,7 @@ var _ = Describe("Session", func() {
 				str := NewMockReceiveStreamI(mockCtrl)
 				str.EXPECT().handleStreamFrame(f).Return(testErr)
 				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(5)).Return(str, nil)
-				Expect(sess.handleStreamFrame(f)).To(MatchError(testErr))
+				Expect(sess.handleStreamFrame(f, protocol.Encryption1RTT)).To(MatchError(testErr))
 			})
 
 			It("ignores STREAM frames for closed streams", func() {
@@ -154,7 +170,7 @@ var _ = Describe("Session", func() {
 				Expect(sess.handleStreamFrame(&wire.StreamFrame{
 					StreamID: 5,
 					Data:     []byte("foobar"),
-				})).To(Succeed())
+				}, protocol.Encryption1RTT)).To(Succeed())
 			})
 		})
 
@@ -167,6 +183,50 @@ var _ = Describe("Session", func() {
 				err := sess.handleAckFrame(f, protocol.EncryptionHandshake)
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("requests a new ack frequency when receiving a 1-RTT ACK", func() {
+				f := &wire.AckFrame{AckRanges: []wire.AckRange{{Smallest: 2, Largest: 3}}}
+				sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedAck(f, protocol.Encryption1RTT, gomock.Any())
+				ackFrequencyFrame := &wire.AckFrequencyFrame{AckElicitingThreshold: 5}
+				sph.EXPECT().GetAckFrequencyFrame().Return(ackFrequencyFrame)
+				cryptoSetup.EXPECT().SetLargest1RTTAcked(protocol.PacketNumber(3))
+				sess.sentPacketHandler = sph
+				Expect(sess.handleAckFrame(f, protocol.Encryption1RTT)).To(Succeed())
+				frames, _ := sess.framer.AppendControlFrames(nil, 1000)
+				Expect(frames).To(Equal([]ackhandler.Frame{{Frame: ackFrequencyFrame}}))
+			})
+		})
+
+		Context("handling ACK_FREQUENCY and IMMEDIATE_ACK frames", func() {
+			var rph *mockackhandler.MockReceivedPacketHandler
+
+			BeforeEach(func() {
+				rph = mockackhandler.NewMockReceivedPacketHandler(mockCtrl)
+				sess.receivedPacketHandler = rph
+			})
+
+			It("passes ACK_FREQUENCY frames to the ReceivedPacketHandler", func() {
+				f := &wire.AckFrequencyFrame{
+					SequenceNumber:        1,
+					AckElicitingThreshold: 10,
+					RequestMaxAckDelay:    protocol.MinAckDelay,
+				}
+				rph.EXPECT().SetAckFrequency(f)
+				Expect(sess.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("rejects ACK_FREQUENCY frames that request a max ack delay smaller than the min_ack_delay", func() {
+				err := sess.handleFrame(&wire.AckFrequencyFrame{RequestMaxAckDelay: protocol.MinAckDelay - 1}, protocol.Encryption1RTT, protocol.ConnectionID{})
+				Expect(err).To(HaveOccurred())
+				Expect(err).To(BeAssignableToTypeOf(&qerr.QuicError{}))
+				Expect(err.(*qerr.QuicError).ErrorCode).To(Equal(qerr.ProtocolViolation))
+			})
+
+			It("queues an ACK when receiving an IMMEDIATE_ACK frame", func() {
+				rph.EXPECT().QueueImmediateAck()
+				Expect(sess.handleFrame(&wire.ImmediateAckFrame{}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
 		})
 
 		Context("handling RESET_STREAM frames", func() {
@@ -205,6 +265,41 @@ var _ = Describe("Session", func() {
 			})
 		})
 
+		Context("handling RESET_STREAM_AT frames", func() {
+			It("passes the frame to the stream", func() {
+				f := &wire.ResetStreamAtFrame{
+					StreamID:     555,
+					ErrorCode:    42,
+					FinalSize:    0x1337,
+					ReliableSize: 0x42,
+				}
+				str := NewMockReceiveStreamI(mockCtrl)
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(555)).Return(str, nil)
+				str.EXPECT().handleResetStreamAtFrame(f)
+				Expect(sess.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+
+			It("returns errors", func() {
+				f := &wire.ResetStreamAtFrame{
+					StreamID:  7,
+					FinalSize: 0x1337,
+				}
+				testErr := errors.New("flow control violation")
+				str := NewMockReceiveStreamI(mockCtrl)
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(7)).Return(str, nil)
+				str.EXPECT().handleResetStreamAtFrame(f).Return(testErr)
+				Expect(sess.handleFrame(f, protocol.Encryption1RTT, protocol.ConnectionID{})).To(MatchError(testErr))
+			})
+
+			It("ignores RESET_STREAM_AT frames for closed streams", func() {
+				streamManager.EXPECT().GetOrOpenReceiveStream(protocol.StreamID(3)).Return(nil, nil)
+				Expect(sess.handleFrame(&wire.ResetStreamAtFrame{
+					StreamID:  3,
+					ErrorCode: 42,
+				}, protocol.Encryption1RTT, protocol.ConnectionID{})).To(Succeed())
+			})
+		})
+
 		Context("handling MAX_DATA and MAX_STREAM_DATA frames", func() {
 			var connFC *mocks.MockConnectionFlowController
 
@@ -285,9 +380,9 @@ var _ = Describe("Session", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		It("rejects PATH_RESPONSE frames", func() {
+		It("ignores PATH_RESPONSE frames that don't match a PATH_CHALLENGE", func() {
 			err := sess.handleFrame(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}}, protocol.Encryption1RTT, protocol.ConnectionID{})
-			Expect(err).To(MatchError("unexpected PATH_RESPONSE frame"))
+			Expect(err).ToNot(HaveOccurred())
 		})
 
 		It("handles PATH_CHALLENGE frames", func() {
@@ -596,7 +691,7 @@ var _ = Describe("Session", func() {
 			// don't EXPECT any calls to packer.PackPacket()
 			sess.handlePacket(&receivedPacket{
 				rcvTime:    time.Now(),
-				remoteAddr: &net.UDPAddr{},
+				remoteAddr: remoteAddr,
 				buffer:     getPacketBuffer(),
 				data:       buf.Bytes(),
 			})
@@ -702,6 +797,8 @@ var _ = Describe("Session", func() {
 			p.data[0] ^= 0x40 // unset the QUIC bit
 			tracer.EXPECT().DroppedPacket(logging.PacketTypeNotDetermined, p.Size(), logging.PacketDropHeaderParseError)
 			Expect(sess.handlePacketImpl(p)).To(BeFalse())
+			Expect(sess.packetsReceived).To(BeZero())
+			Expect(sess.bytesReceived).To(BeZero())
 		})
 
 		It("drops packets for which the version is unsupported", func() {
@@ -764,6 +861,8 @@ var _ = Describe("Session", func() {
 			tracer.EXPECT().StartedConnection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
 			tracer.EXPECT().ReceivedPacket(hdr, protocol.ByteCount(len(packet.data)), []logging.Frame{})
 			Expect(sess.handlePacketImpl(packet)).To(BeTrue())
+			Expect(sess.packetsReceived).To(BeEquivalentTo(1))
+			Expect(sess.bytesReceived).To(Equal(packet.Size()))
 		})
 
 		It("informs the ReceivedPacketHandler about ack-eliciting packets", func() {
@@ -1016,6 +1115,62 @@ var _ = Describe("Session", func() {
 			Eventually(sess.Context().Done()).Should(BeClosed())
 		})
 
+		It("closes the session when the integrity limit is reached", func() {
+			unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, qerr.AEADLimitReached)
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			cryptoSetup.EXPECT().Close()
+			packer.EXPECT().PackConnectionClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
+				err := sess.run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.(qerr.ErrorCode)).To(Equal(qerr.AEADLimitReached))
+				close(done)
+			}()
+			expectReplaceWithClosed()
+			mconn.EXPECT().Write(gomock.Any())
+			packet := getPacket(&wire.ExtendedHeader{
+				Header:          wire.Header{DestConnectionID: srcConnID},
+				PacketNumberLen: protocol.PacketNumberLen1,
+			}, nil)
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			sess.handlePacket(packet)
+			Eventually(sess.Context().Done()).Should(BeClosed())
+		})
+
+		It("closes the session when the confidentiality limit is reached", func() {
+			sess.handshakeConfirmed = true
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+			sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+			sess.sentPacketHandler = sph
+			packer.EXPECT().PackPacket().Return(nil, qerr.AEADLimitReached)
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			cryptoSetup.EXPECT().Close()
+			// the 1-RTT keys can't be used to send a CONNECTION_CLOSE any more
+			packer.EXPECT().PackConnectionClose(gomock.Any()).Return(nil, qerr.AEADLimitReached)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
+				err := sess.run()
+				Expect(err).To(HaveOccurred())
+				Expect(err.(qerr.ErrorCode)).To(Equal(qerr.AEADLimitReached))
+				close(done)
+			}()
+			expectReplaceWithClosed()
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			sess.scheduleSending()
+			Eventually(sess.Context().Done()).Should(BeClosed())
+			Eventually(done).Should(BeClosed())
+		})
+
 		It("rejects packets with empty payload", func() {
 			unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).Return(&unpackedPacket{
 				hdr:             &wire.ExtendedHeader{},
@@ -1107,21 +1262,192 @@ var _ = Describe("Session", func() {
 			Expect(sess.undecryptablePackets).To(Equal([]*receivedPacket{packet}))
 		})
 
-		Context("updating the remote address", func() {
-			It("doesn't support connection migration", func() {
+		It("sends the preferred_address", func() {
+			var params *wire.TransportParameters
+			tr := mocklogging.NewMockConnectionTracer(mockCtrl)
+			tr.EXPECT().SentTransportParameters(gomock.Any()).Do(func(p *wire.TransportParameters) { params = p })
+			tr.EXPECT().UpdatedKeyFromTLS(gomock.Any(), gomock.Any()).AnyTimes()
+			tr.EXPECT().UpdatedCongestionState(gomock.Any())
+			token := protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).Return(token)
+			tokenGenerator, err := handshake.NewTokenGenerator(rand.Reader, nil)
+			Expect(err).ToNot(HaveOccurred())
+			conf := populateServerConfig(&Config{
+				ConnectionIDLength: srcConnID.Len(),
+				PreferredAddress:   &PreferredAddress{IPv4: &net.UDPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}},
+			})
+			s := newSession(mconn, sessionRunner, nil, nil, clientDestConnID, destConnID, srcConnID, protocol.StatelessResetToken{}, conf, nil, tokenGenerator, false, tr, utils.DefaultLogger, protocol.VersionTLS).(*session)
+			Expect(params.PreferredAddress).ToNot(BeNil())
+			Expect(params.PreferredAddress.IPv4.Equal(net.IPv4(1, 2, 3, 4))).To(BeTrue())
+			Expect(params.PreferredAddress.IPv4Port).To(BeEquivalentTo(1234))
+			Expect(params.PreferredAddress.IPv6.Equal(net.IPv6zero)).To(BeTrue())
+			Expect(params.PreferredAddress.IPv6Port).To(BeZero())
+			Expect(params.PreferredAddress.ConnectionID.Len()).To(Equal(srcConnID.Len()))
+			Expect(params.PreferredAddress.StatelessResetToken).To(Equal(token))
+			// The connection ID is registered when the handshake completes.
+			sessionRunner.EXPECT().Retire(clientDestConnID)
+			sessionRunner.EXPECT().Add(params.PreferredAddress.ConnectionID, s)
+			s.connIDGenerator.SetHandshakeComplete()
+		})
+
+		Context("connection migration", func() {
+			newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 4321}
+			var (
+				sph     *mockackhandler.MockSentPacketHandler
+				newConn *MockSendConn
+			)
+
+			BeforeEach(func() {
+				sess.handshakeConfirmed = true
+				sess.peerParams = &wire.TransportParameters{}
+				sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().ReceivedBytes(gomock.Any()).AnyTimes()
+				sess.sentPacketHandler = sph
+				newConn = NewMockSendConn(mockCtrl)
+				newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+				newConn.EXPECT().SupportsGSO().AnyTimes()
+				sess.receivedFirstPacket = true
+			})
+
+			getPacketWithFrames := func(from net.Addr, pn protocol.PacketNumber, frames ...wire.Frame) *receivedPacket {
+				buf := &bytes.Buffer{}
+				for _, f := range frames {
+					Expect(f.Write(buf, sess.version)).To(Succeed())
+				}
 				unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).Return(&unpackedPacket{
 					encryptionLevel: protocol.Encryption1RTT,
-					hdr:             &wire.ExtendedHeader{},
-					data:            []byte{0}, // one PADDING frame
+					packetNumber:    pn,
+					hdr:             &wire.ExtendedHeader{PacketNumber: pn},
+					data:            buf.Bytes(),
 				}, nil)
-				packet := getPacket(&wire.ExtendedHeader{
+				p := getPacket(&wire.ExtendedHeader{
 					Header:          wire.Header{DestConnectionID: srcConnID},
-					PacketNumberLen: protocol.PacketNumberLen1,
+					PacketNumber:    pn,
+					PacketNumberLen: protocol.PacketNumberLen2,
 				}, nil)
-				packet.remoteAddr = &net.IPAddr{IP: net.IPv4(192, 168, 0, 100)}
-				tracer.EXPECT().StartedConnection(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
-				tracer.EXPECT().ReceivedPacket(gomock.Any(), protocol.ByteCount(len(packet.data)), gomock.Any())
-				Expect(sess.handlePacketImpl(packet)).To(BeTrue())
+				p.remoteAddr = from
+				tracer.EXPECT().ReceivedPacket(gomock.Any(), p.Size(), gomock.Any())
+				return p
+			}
+
+			expectPathProbe := func(conn *MockSendConn, size protocol.ByteCount) <-chan wire.Frame {
+				sent := make(chan wire.Frame, 1)
+				packer.EXPECT().PackPathProbePacket(destConnID, gomock.Any(), size).DoAndReturn(func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+					Expect(frames).To(HaveLen(1))
+					sent <- frames[0].Frame
+					return &packedPacket{
+						buffer: getPacketBuffer(),
+						packetContents: &packetContents{
+							header: &wire.ExtendedHeader{PacketNumber: 1},
+							frames: frames,
+						},
+					}, nil
+				})
+				sph.EXPECT().SentPacket(gomock.Any())
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+				conn.EXPECT().Write(gomock.Any())
+				return sent
+			}
+
+			It("answers PATH_CHALLENGEs on the path they were received on", func() {
+				mconn.EXPECT().WithRemoteAddr(newAddr, nil).Return(newConn)
+				p := getPacketWithFrames(newAddr, 10, &wire.PathChallengeFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})
+				// The response is limited by the anti-amplification limit.
+				sent := expectPathProbe(newConn, 3*p.Size())
+				Expect(sess.handlePacketImpl(p)).To(BeTrue())
+				Expect(sent).To(Receive(Equal(&wire.PathResponseFrame{Data: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}})))
+				// Probing packets don't cause a path change.
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+				Expect(sess.pathValidator).To(BeNil())
+			})
+
+			It("doesn't switch to a new path for reordered packets", func() {
+				Expect(sess.handlePacketImpl(getPacketWithFrames(remoteAddr, 10, &wire.PingFrame{}))).To(BeTrue())
+				mconn.EXPECT().WithRemoteAddr(newAddr, nil).Return(newConn)
+				Expect(sess.handlePacketImpl(getPacketWithFrames(newAddr, 9, &wire.PingFrame{}))).To(BeTrue())
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+				Expect(sess.pathValidator).To(BeNil())
+			})
+
+			It("switches to the preferred address, without validating the client's address", func() {
+				sender := NewMockSender(mockCtrl)
+				sender.EXPECT().Close()
+				sess.sendQueue = sender
+				sess.rcvConn = &basicConn{PacketConn: NewMockPacketConn(mockCtrl)}
+				preferredAddrConn := &basicConn{PacketConn: NewMockPacketConn(mockCtrl)}
+				p := getPacketWithFrames(remoteAddr, 10, &wire.PingFrame{})
+				p.rcvConn = preferredAddrConn
+				packer.EXPECT().SetMaxPacketSize(getMaxPacketSize(remoteAddr))
+				sph.EXPECT().MigratedPath(nil, true)
+				Expect(sess.handlePacketImpl(p)).To(BeTrue())
+				defer sess.sendQueue.Close()
+				Expect(sess.rcvConn).To(BeIdenticalTo(preferredAddrConn))
+				Expect(sess.conn.(*sconn).connection).To(BeIdenticalTo(preferredAddrConn))
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+				Expect(sess.pathValidator).To(BeNil())
+				Expect(sess.lastValidatedConn).To(BeNil())
+			})
+
+			Context("when the peer migrates", func() {
+				var packetSize protocol.ByteCount
+
+				BeforeEach(func() {
+					sender := NewMockSender(mockCtrl)
+					sender.EXPECT().Close()
+					sess.sendQueue = sender
+					mconn.EXPECT().WithRemoteAddr(newAddr, nil).Return(newConn)
+					p := getPacketWithFrames(newAddr, 10, &wire.PingFrame{})
+					packetSize = p.Size()
+					packer.EXPECT().SetMaxPacketSize(getMaxPacketSize(newAddr))
+					sph.EXPECT().MigratedPath(nil, false)
+					Expect(sess.handlePacketImpl(p)).To(BeTrue())
+				})
+
+				AfterEach(func() {
+					sess.sendQueue.Close()
+				})
+
+				It("switches to the new path, and validates it", func() {
+					Expect(sess.RemoteAddr()).To(Equal(newAddr))
+					Expect(sess.lastValidatedConn).To(Equal(mconn))
+					sent := expectPathProbe(newConn, 3*packetSize)
+					sess.maybeProbePath(time.Now())
+					var challenge wire.Frame
+					Eventually(sent).Should(Receive(&challenge))
+					Expect(challenge).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+					// only send one PATH_CHALLENGE per PTO
+					sess.maybeProbePath(time.Now())
+					sph.EXPECT().ValidatedPath()
+					Expect(sess.handleFrame(&wire.PathResponseFrame{Data: challenge.(*wire.PathChallengeFrame).Data}, protocol.Encryption1RTT, srcConnID)).To(Succeed())
+					Expect(sess.pathValidator).To(BeNil())
+					Expect(sess.lastValidatedConn).To(BeNil())
+				})
+
+				It("goes back to the last validated path if path validation fails", func() {
+					sph.EXPECT().MigratedPath(nil, true)
+					packer.EXPECT().SetMaxPacketSize(getMaxPacketSize(remoteAddr))
+					sess.maybeProbePath(sess.pathValidator.Deadline())
+					Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+					Expect(sess.pathValidator).To(BeNil())
+					Expect(sess.lastValidatedConn).To(BeNil())
+				})
+			})
+
+			It("keeps the congestion state if only the peer's port changed", func() {
+				sender := NewMockSender(mockCtrl)
+				sender.EXPECT().Close()
+				sess.sendQueue = sender
+				reboundAddr := &net.UDPAddr{IP: remoteAddr.IP, Port: remoteAddr.Port + 1}
+				reboundConn := NewMockSendConn(mockCtrl)
+				reboundConn.EXPECT().RemoteAddr().Return(reboundAddr).AnyTimes()
+				reboundConn.EXPECT().SupportsGSO().AnyTimes()
+				mconn.EXPECT().WithRemoteAddr(reboundAddr, nil).Return(reboundConn)
+				packer.EXPECT().SetMaxPacketSize(getMaxPacketSize(reboundAddr))
+				sph.EXPECT().ReboundPath(false)
+				Expect(sess.handlePacketImpl(getPacketWithFrames(reboundAddr, 10, &wire.PingFrame{}))).To(BeTrue())
+				defer sess.sendQueue.Close()
+				Expect(sess.RemoteAddr()).To(Equal(reboundAddr))
+				Expect(sess.pathValidator).ToNot(BeNil())
 			})
 		})
 
@@ -1426,6 +1752,122 @@ var _ = Describe("Session", func() {
 				})
 			})
 		}
+
+		It("sends an IMMEDIATE_ACK as a 1-RTT probe packet, if the peer supports the ACK frequency extension", func() {
+			sess.config.EnableAckFrequency = true
+			minAckDelay := time.Millisecond
+			sess.peerParams = &wire.TransportParameters{MinAckDelay: &minAckDelay}
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().TimeUntilSend().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendPTOAppData)
+			sph.EXPECT().SendMode().Return(ackhandler.SendNone)
+			sph.EXPECT().QueueProbePacket(protocol.Encryption1RTT).Return(false)
+			p := getPacket(123)
+			packer.EXPECT().MaybePackProbePacket(protocol.Encryption1RTT).Return(p, nil)
+			sph.EXPECT().SentPacket(gomock.Any())
+			sess.sentPacketHandler = sph
+			runSession()
+			sent := make(chan struct{})
+			sender.EXPECT().Send(gomock.Any()).Do(func(packet *packetBuffer) { close(sent) })
+			tracer.EXPECT().SentPacket(p.header, p.length, gomock.Any(), gomock.Any())
+			sess.scheduleSending()
+			Eventually(sent).Should(BeClosed())
+			Expect(sess.retransmissionQueue.GetAppDataFrame(1000)).To(BeAssignableToTypeOf(&wire.ImmediateAckFrame{}))
+		})
+
+		Context("using GSO", func() {
+			var sph *mockackhandler.MockSentPacketHandler
+
+			BeforeEach(func() {
+				mconn = NewMockSendConn(mockCtrl)
+				mconn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
+				mconn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+				mconn.EXPECT().SupportsGSO().Return(true).AnyTimes()
+				sess.conn = mconn
+				sess.handshakeConfirmed = true
+				sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+				sph.EXPECT().TimeUntilSend().AnyTimes()
+				sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+				sph.EXPECT().SendMode().Return(ackhandler.SendAny).AnyTimes()
+				sess.sentPacketHandler = sph
+				packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1000)).AnyTimes()
+			})
+
+			appendPacket := func(pn protocol.PacketNumber, length protocol.ByteCount) func(*packetBuffer) (*packetContents, error) {
+				return func(buf *packetBuffer) (*packetContents, error) {
+					buf.Data = append(buf.Data, bytes.Repeat([]byte{byte(pn)}, int(length))...)
+					return &packetContents{
+						header: &wire.ExtendedHeader{PacketNumber: pn},
+						length: length,
+					}, nil
+				}
+			}
+
+			It("sends multiple packets in a single batch", func() {
+				sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+				sph.EXPECT().SentPacket(gomock.Any()).Times(3)
+				gomock.InOrder(
+					packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(1, 1000)),
+					packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(2, 1000)),
+					packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(3, 500)),
+					packer.EXPECT().AppendPacket(gomock.Any()).AnyTimes(),
+				)
+				tracer.EXPECT().SentPacket(gomock.Any(), protocol.ByteCount(1000), nil, []logging.Frame{}).Times(2)
+				tracer.EXPECT().SentPacket(gomock.Any(), protocol.ByteCount(500), nil, []logging.Frame{})
+				sent := make(chan []byte)
+				sender.EXPECT().SendBatch(gomock.Any(), protocol.ByteCount(1000)).Do(func(p *packetBuffer, _ protocol.ByteCount) { sent <- p.Data })
+				runSession()
+				sess.scheduleSending()
+				var data []byte
+				Eventually(sent).Should(Receive(&data))
+				Expect(data).To(HaveLen(2500))
+				Expect(data[:1000]).To(Equal(bytes.Repeat([]byte{1}, 1000)))
+				Expect(data[1000:2000]).To(Equal(bytes.Repeat([]byte{2}, 1000)))
+				Expect(data[2000:]).To(Equal(bytes.Repeat([]byte{3}, 500)))
+			})
+
+			It("doesn't append packets after a packet that's smaller than the maximum packet size", func() {
+				sph.EXPECT().HasPacingBudget().Return(true).AnyTimes()
+				sph.EXPECT().SentPacket(gomock.Any()).Times(2)
+				gomock.InOrder(
+					packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(1, 999)),
+					packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(2, 1000)),
+					packer.EXPECT().AppendPacket(gomock.Any()).AnyTimes(),
+				)
+				tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), nil, []logging.Frame{}).Times(2)
+				sent := make(chan []byte, 2)
+				sender.EXPECT().SendBatch(gomock.Any(), protocol.ByteCount(1000)).Do(func(p *packetBuffer, _ protocol.ByteCount) { sent <- p.Data }).Times(2)
+				runSession()
+				sess.scheduleSending()
+				var data []byte
+				Eventually(sent).Should(Receive(&data))
+				Expect(data).To(HaveLen(999))
+				Eventually(sent).Should(Receive(&data))
+				Expect(data).To(HaveLen(1000))
+			})
+
+			It("stops appending packets when pacing limited", func() {
+				gomock.InOrder(
+					sph.EXPECT().HasPacingBudget().Return(true),
+					sph.EXPECT().HasPacingBudget().Return(false).AnyTimes(),
+				)
+				sph.EXPECT().SentPacket(gomock.Any())
+				packer.EXPECT().MaybePackAckPacket(gomock.Any()).AnyTimes()
+				gomock.InOrder(
+					packer.EXPECT().AppendPacket(gomock.Any()).DoAndReturn(appendPacket(1, 1000)),
+					packer.EXPECT().AppendPacket(gomock.Any()).AnyTimes(),
+				)
+				tracer.EXPECT().SentPacket(gomock.Any(), protocol.ByteCount(1000), nil, []logging.Frame{})
+				sent := make(chan []byte)
+				sender.EXPECT().SendBatch(gomock.Any(), protocol.ByteCount(1000)).Do(func(p *packetBuffer, _ protocol.ByteCount) { sent <- p.Data })
+				runSession()
+				sess.scheduleSending()
+				var data []byte
+				Eventually(sent).Should(Receive(&data))
+				Expect(data).To(HaveLen(1000))
+			})
+		})
 	})
 
 	Context("packet pacing", func() {
@@ -1889,7 +2331,7 @@ var _ = Describe("Session", func() {
 			<-finishHandshake
 			cryptoSetup.EXPECT().RunHandshake()
 			cryptoSetup.EXPECT().SetHandshakeConfirmed()
-			cryptoSetup.EXPECT().GetSessionTicket()
+			cryptoSetup.EXPECT().GetSessionTicket(gomock.Any())
 			close(sess.handshakeCompleteChan)
 			sess.run()
 		}()
@@ -1912,6 +2354,7 @@ var _ = Describe("Session", func() {
 	It("sends a session ticket when the handshake completes", func() {
 		const size = protocol.MaxPostHandshakeCryptoFrameSize * 3 / 2
 		packer.EXPECT().PackCoalescedPacket().AnyTimes()
+		sess.config.GetAppDataForSessionTicket = func() []byte { return []byte("foobar") }
 		finishHandshake := make(chan struct{})
 		sessionRunner.EXPECT().Retire(clientDestConnID)
 		go func() {
@@ -1919,7 +2362,7 @@ var _ = Describe("Session", func() {
 			<-finishHandshake
 			cryptoSetup.EXPECT().RunHandshake()
 			cryptoSetup.EXPECT().SetHandshakeConfirmed()
-			cryptoSetup.EXPECT().GetSessionTicket().Return(make([]byte, size), nil)
+			cryptoSetup.EXPECT().GetSessionTicket([]byte("foobar")).Return(make([]byte, size), nil)
 			close(sess.handshakeCompleteChan)
 			sess.run()
 		}()
@@ -2005,7 +2448,7 @@ var _ = Describe("Session", func() {
 			defer GinkgoRecover()
 			cryptoSetup.EXPECT().RunHandshake()
 			cryptoSetup.EXPECT().SetHandshakeConfirmed()
-			cryptoSetup.EXPECT().GetSessionTicket()
+			cryptoSetup.EXPECT().GetSessionTicket(gomock.Any())
 			mconn.EXPECT().Write(gomock.Any())
 			close(sess.handshakeCompleteChan)
 			sess.run()
@@ -2083,6 +2526,190 @@ var _ = Describe("Session", func() {
 			sess.handleTransportParameters(params)
 			Expect(sess.earlySessionReady()).To(BeClosed())
 		})
+
+		It("switches to a compatible version", func() {
+			sess.version = protocol.VersionDraft34
+			sess.initialVersion = protocol.VersionDraft34
+			sess.config.Versions = []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34}
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.VersionDraft34,
+					AvailableVersions: []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+				},
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			packer.EXPECT().SetVersion(protocol.Version1)
+			cryptoSetup.EXPECT().SetVersion(protocol.Version1)
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			sessionRunner.EXPECT().Add(gomock.Any(), sess).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			tracer.EXPECT().NegotiatedVersion(
+				protocol.Version1,
+				[]protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1},
+				[]protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34},
+			)
+			sess.handleTransportParameters(params)
+			Expect(sess.version).To(Equal(protocol.Version1))
+			Expect(sess.retransmissionQueue.version).To(Equal(protocol.Version1))
+			Expect(sess.framer.(*framerI).version).To(Equal(protocol.Version1))
+		})
+
+		It("enables the ACK frequency extension if both peers support it", func() {
+			sess.config.EnableAckFrequency = true
+			minAckDelay := time.Millisecond
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				MaxAckDelay:               protocol.DefaultMaxAckDelay,
+				MinAckDelay:               &minAckDelay,
+			}
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().EnableAckFrequency()
+			sess.sentPacketHandler = sph
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			sessionRunner.EXPECT().Add(gomock.Any(), sess).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+		})
+
+		It("enables RESET_STREAM_AT if both peers support it", func() {
+			sess.config.EnableResetStreamAt = true
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				EnableResetStreamAt:       true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			sessionRunner.EXPECT().Add(gomock.Any(), sess).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			Expect(sess.supportsResetStreamAt()).To(BeFalse())
+			sess.handleTransportParameters(params)
+			Expect(sess.supportsResetStreamAt()).To(BeTrue())
+		})
+
+		It("doesn't enable RESET_STREAM_AT if it's disabled in the config", func() {
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				EnableResetStreamAt:       true,
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			sessionRunner.EXPECT().Add(gomock.Any(), sess).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+			Expect(sess.supportsResetStreamAt()).To(BeFalse())
+		})
+
+		It("doesn't enable the ACK frequency extension if it's disabled in the config", func() {
+			minAckDelay := time.Millisecond
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				MaxAckDelay:               protocol.DefaultMaxAckDelay,
+				MinAckDelay:               &minAckDelay,
+			}
+			sess.sentPacketHandler = mockackhandler.NewMockSentPacketHandler(mockCtrl) // no calls expected
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			sessionRunner.EXPECT().Add(gomock.Any(), sess).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+		})
+
+		It("errors if the client's chosen version doesn't match the version it used", func() {
+			params := &wire.TransportParameters{
+				InitialSourceConnectionID: destConnID,
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     0x1234,
+					AvailableVersions: []protocol.VersionNumber{0x1234},
+				},
+			}
+			streamManager.EXPECT().UpdateLimits(params)
+			packer.EXPECT().HandleTransportParameters(params)
+			sessionRunner.EXPECT().GetStatelessResetToken(gomock.Any()).AnyTimes()
+			sessionRunner.EXPECT().Add(gomock.Any(), sess).AnyTimes()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+			Expect(sess.version).To(Equal(sess.initialVersion))
+			var closeErr closeError
+			Expect(sess.closeChan).To(Receive(&closeErr))
+			Expect(closeErr.err).To(MatchError(fmt.Sprintf("VERSION_NEGOTIATION_ERROR: expected chosen version to equal %s, is 0x1234", sess.initialVersion)))
+		})
+	})
+
+	It("forces a key update", func() {
+		cryptoSetup.EXPECT().ForceKeyUpdate()
+		sess.ForceKeyUpdate()
+		frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+		Expect(frames).To(Equal([]ackhandler.Frame{{Frame: &wire.PingFrame{}}}))
+	})
+
+	Context("sending datagrams", func() {
+		BeforeEach(func() {
+			sess.datagramQueue = newDatagramQueue(func() {}, 10, DatagramDropOldest, utils.DefaultLogger)
+		})
+
+		It("errors when datagram support is disabled", func() {
+			sess.datagramQueue = nil
+			Expect(sess.MaxDatagramSize()).To(BeZero())
+			Expect(sess.SendDatagram([]byte("foobar"), nil)).To(MatchError("datagram support disabled"))
+		})
+
+		It("doesn't send datagrams if the peer doesn't support them", func() {
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: protocol.InvalidByteCount}
+			sess.updateMaxDatagramSize()
+			Expect(sess.MaxDatagramSize()).To(BeZero())
+			Expect(sess.SendDatagram([]byte("foobar"), nil)).To(MatchError("message too large"))
+		})
+
+		It("limits the datagram size by the peer's max_datagram_frame_size", func() {
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1200))
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 100}
+			sess.updateMaxDatagramSize()
+			maxSize := sess.MaxDatagramSize()
+			Expect(maxSize).To(Equal(int((&wire.DatagramFrame{DataLenPresent: true}).MaxDataLen(100, sess.version))))
+			Expect(sess.SendDatagram(make([]byte, maxSize), nil)).To(Succeed())
+			Expect(sess.SendDatagram(make([]byte, maxSize+1), nil)).To(MatchError("message too large"))
+		})
+
+		It("limits the datagram size by the packet size", func() {
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1200))
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 65536}
+			sess.updateMaxDatagramSize()
+			Expect(sess.MaxDatagramSize()).To(BeNumerically("<", 1200-16))
+			Expect(sess.MaxDatagramSize()).To(BeNumerically(">", 1100))
+			// the size changes when the MTU changes
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1400))
+			size := sess.MaxDatagramSize()
+			sess.updateMaxDatagramSize()
+			Expect(sess.MaxDatagramSize()).To(Equal(size + 200))
+		})
+
+		It("queues datagrams with options", func() {
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1200))
+			sess.peerParams = &wire.TransportParameters{MaxDatagramFrameSize: 1000}
+			sess.updateMaxDatagramSize()
+			deadline := time.Now().Add(time.Hour)
+			var outcomes []DatagramOutcome
+			b := []byte("foobar")
+			Expect(sess.SendDatagram(b, &DatagramSendOptions{
+				Deadline:  deadline,
+				OnOutcome: func(o DatagramOutcome) { outcomes = append(outcomes, o) },
+			})).To(Succeed())
+			b[0] = 'x' // the data is copied
+			d := sess.datagramQueue.Get(protocol.MaxByteCount, sess.version)
+			Expect(d).ToNot(BeNil())
+			Expect(d.frame.Data).To(Equal([]byte("foobar")))
+			Expect(d.frame.DataLenPresent).To(BeTrue())
+			Expect(d.deadline).To(Equal(deadline))
+			d.onOutcome(DatagramAcked)
+			Expect(outcomes).To(Equal([]DatagramOutcome{DatagramAcked}))
+		})
 	})
 
 	Context("keep-alives", func() {
@@ -2277,7 +2904,7 @@ var _ = Describe("Session", func() {
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
-				cryptoSetup.EXPECT().GetSessionTicket().MaxTimes(1)
+				cryptoSetup.EXPECT().GetSessionTicket(gomock.Any()).MaxTimes(1)
 				err := sess.run()
 				nerr, ok := err.(net.Error)
 				Expect(ok).To(BeTrue())
@@ -2308,7 +2935,7 @@ var _ = Describe("Session", func() {
 			go func() {
 				defer GinkgoRecover()
 				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
-				cryptoSetup.EXPECT().GetSessionTicket().MaxTimes(1)
+				cryptoSetup.EXPECT().GetSessionTicket(gomock.Any()).MaxTimes(1)
 				cryptoSetup.EXPECT().SetHandshakeConfirmed().MaxTimes(1)
 				close(sess.handshakeCompleteChan)
 				err := sess.run()
@@ -2355,6 +2982,67 @@ var _ = Describe("Session", func() {
 		Eventually(done).Should(BeClosed())
 	})
 
+	Context("statistics", func() {
+		It("returns statistics, while the session is running and after it was closed", func() {
+			sess.rttStats.UpdateRTT(100*time.Millisecond, 0, time.Now())
+			sess.rttStats.UpdateRTT(50*time.Millisecond, 0, time.Now())
+			sess.packetsReceived = 42
+			sess.bytesReceived = 4200
+			sess.streamBytesRetransmitted = 100
+			sess.retransmissionQueue.bytesQueued = 50
+			sph := mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sph.EXPECT().GetLossDetectionTimeout().AnyTimes()
+			sph.EXPECT().SendMode().Return(ackhandler.SendNone).AnyTimes()
+			sph.EXPECT().GetStats().Return(ackhandler.Stats{
+				PacketsSent:      10,
+				BytesSent:        1000,
+				PacketsLost:      2,
+				BytesLost:        200,
+				PTOCount:         3,
+				CongestionWindow: 12345,
+				BytesInFlight:    678,
+			}).Times(2)
+			sess.sentPacketHandler = sph
+			packer.EXPECT().MaxPacketSize().Return(protocol.ByteCount(1337)).Times(2)
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				cryptoSetup.EXPECT().RunHandshake().MaxTimes(1)
+				sess.run()
+				close(done)
+			}()
+			expectedStats := ConnectionStats{
+				MinRTT:             50 * time.Millisecond,
+				LatestRTT:          50 * time.Millisecond,
+				SmoothedRTT:        sess.rttStats.SmoothedRTT(),
+				MeanDeviation:      sess.rttStats.MeanDeviation(),
+				CongestionWindow:   12345,
+				BytesInFlight:      678,
+				MTU:                1337,
+				PacketsSent:        10,
+				BytesSent:          1000,
+				PacketsReceived:    42,
+				BytesReceived:      4200,
+				PacketsLost:        2,
+				BytesLost:          200,
+				BytesRetransmitted: 150,
+				PTOCount:           3,
+			}
+			Expect(sess.Stats()).To(Equal(expectedStats))
+
+			streamManager.EXPECT().CloseWithError(gomock.Any())
+			expectReplaceWithClosed()
+			cryptoSetup.EXPECT().Close()
+			packer.EXPECT().PackConnectionClose(gomock.Any()).Return(&coalescedPacket{buffer: getPacketBuffer()}, nil)
+			mconn.EXPECT().Write(gomock.Any())
+			tracer.EXPECT().ClosedConnection(gomock.Any())
+			tracer.EXPECT().Close()
+			sess.shutdown()
+			Eventually(done).Should(BeClosed())
+			Expect(sess.Stats()).To(Equal(expectedStats))
+		})
+	})
+
 	Context("getting streams", func() {
 		It("opens streams", func() {
 			mstr := NewMockStreamI(mockCtrl)
@@ -2416,6 +3104,24 @@ var _ = Describe("Session", func() {
 	It("returns the remote address", func() {
 		Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
 	})
+
+	It("uses the default congestion controller if none is configured", func() {
+		Expect(sess.newCongestionController()).To(BeNil())
+	})
+
+	It("creates the congestion controller from the config", func() {
+		cc := mocks.NewMockSendAlgorithmWithDebugInfos(mockCtrl)
+		var info *congestion.ConnectionInfo
+		sess.config.CongestionControl = func(i *congestion.ConnectionInfo) congestion.SendAlgorithmWithDebugInfos {
+			info = i
+			return cc
+		}
+		Expect(sess.newCongestionController()).To(Equal(cc))
+		Expect(info.Perspective).To(Equal(protocol.PerspectiveServer))
+		Expect(info.RTTStats).To(Equal(sess.rttStats))
+		Expect(info.InitialMaxDatagramSize).To(Equal(getMaxPacketSize(remoteAddr)))
+		Expect(info.Tracer).To(Equal(tracer))
+	})
 })
 
 var _ = Describe("Client Session", func() {
@@ -2459,6 +3165,7 @@ var _ = Describe("Client Session", func() {
 		mconn = NewMockSendConn(mockCtrl)
 		mconn.EXPECT().RemoteAddr().Return(&net.UDPAddr{}).AnyTimes()
 		mconn.EXPECT().LocalAddr().Return(&net.UDPAddr{}).AnyTimes()
+		mconn.EXPECT().SupportsGSO().AnyTimes()
 		if tlsConf == nil {
 			tlsConf = &tls.Config{}
 		}
@@ -2527,6 +3234,56 @@ var _ = Describe("Client Session", func() {
 		Eventually(sess.Context().Done()).Should(BeClosed())
 	})
 
+	It("switches to the compatible version chosen by the server", func() {
+		sess.config.Versions = []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34}
+		unpacker := NewMockUnpacker(mockCtrl)
+		unpacker.EXPECT().Unpack(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(hdr *wire.Header, _ time.Time, data []byte) (*unpackedPacket, error) {
+			return &unpackedPacket{
+				encryptionLevel: protocol.EncryptionInitial,
+				hdr:             &wire.ExtendedHeader{Header: *hdr},
+				data:            []byte{0}, // one PADDING frame
+			}, nil
+		})
+		sess.unpacker = unpacker
+		p := getPacket(&wire.ExtendedHeader{
+			Header: wire.Header{
+				IsLongHeader:     true,
+				Type:             protocol.PacketTypeInitial,
+				SrcConnectionID:  destConnID,
+				DestConnectionID: srcConnID,
+				Length:           2 + 6,
+				Version:          protocol.VersionDraft34,
+			},
+			PacketNumberLen: protocol.PacketNumberLen2,
+		}, []byte("foobar"))
+		packer.EXPECT().SetVersion(protocol.VersionDraft34)
+		unpacker.EXPECT().SetVersion(protocol.VersionDraft34)
+		cryptoSetup.EXPECT().SetVersion(protocol.VersionDraft34)
+		tracer.EXPECT().NegotiatedVersion(protocol.VersionDraft34, []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34}, nil)
+		tracer.EXPECT().ReceivedPacket(gomock.Any(), p.Size(), []logging.Frame{})
+		Expect(sess.handlePacketImpl(p)).To(BeTrue())
+		Expect(sess.version).To(Equal(protocol.VersionDraft34))
+		Expect(sess.retransmissionQueue.version).To(Equal(protocol.VersionDraft34))
+	})
+
+	It("doesn't switch to a version that it didn't offer", func() {
+		sess.config.Versions = []protocol.VersionNumber{protocol.Version1}
+		p := getPacket(&wire.ExtendedHeader{
+			Header: wire.Header{
+				IsLongHeader:     true,
+				Type:             protocol.PacketTypeInitial,
+				SrcConnectionID:  destConnID,
+				DestConnectionID: srcConnID,
+				Length:           2 + 6,
+				Version:          protocol.VersionDraft34,
+			},
+			PacketNumberLen: protocol.PacketNumberLen2,
+		}, []byte("foobar"))
+		tracer.EXPECT().DroppedPacket(logging.PacketTypeInitial, p.Size(), logging.PacketDropUnexpectedVersion)
+		Expect(sess.handlePacketImpl(p)).To(BeFalse())
+		Expect(sess.version).To(Equal(protocol.Version1))
+	})
+
 	It("continues accepting Long Header packets after using a new connection ID", func() {
 		unpacker := NewMockUnpacker(mockCtrl)
 		sess.unpacker = unpacker
@@ -2550,6 +3307,7 @@ var _ = Describe("Client Session", func() {
 			Type:             protocol.PacketTypeHandshake,
 			DestConnectionID: srcConnID,
 			SrcConnectionID:  destConnID,
+			Version:          sess.version,
 		}
 		tracer.EXPECT().ReceivedPacket(gomock.Any(), gomock.Any(), gomock.Any())
 		Expect(sess.handleSinglePacket(&receivedPacket{buffer: getPacketBuffer()}, hdr)).To(BeTrue())
@@ -2564,6 +3322,232 @@ var _ = Describe("Client Session", func() {
 		Expect(sess.handleHandshakeDoneFrame()).To(Succeed())
 	})
 
+	Context("migrating to a new path", func() {
+		newAddr := &net.UDPAddr{IP: net.IPv4(192, 168, 0, 100), Port: 4321}
+		newConnID := protocol.ConnectionID{0xde, 0xca, 0xfb, 0xad}
+		newResetToken := protocol.StatelessResetToken{0xd, 0xe, 0xc, 0xa, 0xf, 0xb, 0xa, 0xd}
+		var (
+			sph       *mockackhandler.MockSentPacketHandler
+			newConn   *MockSendConn
+			newRunner *MockSessionRunner
+			migration *pathMigration
+		)
+
+		JustBeforeEach(func() {
+			sess.handshakeConfirmed = true
+			sess.peerParams = &wire.TransportParameters{}
+			sph = mockackhandler.NewMockSentPacketHandler(mockCtrl)
+			sess.sentPacketHandler = sph
+			newConn = NewMockSendConn(mockCtrl)
+			newConn.EXPECT().RemoteAddr().Return(newAddr).AnyTimes()
+			newConn.EXPECT().LocalAddr().Return(newAddr).AnyTimes()
+			newConn.EXPECT().SupportsGSO().AnyTimes()
+			newRunner = NewMockSessionRunner(mockCtrl)
+			migration = &pathMigration{conn: newConn, runner: newRunner, result: make(chan error, 1)}
+		})
+
+		addConnectionID := func() {
+			Expect(sess.connIDManager.Add(&wire.NewConnectionIDFrame{
+				SequenceNumber:      1,
+				ConnectionID:        newConnID,
+				StatelessResetToken: newResetToken,
+			})).To(Succeed())
+		}
+
+		expectPathChallenge := func() <-chan [8]byte {
+			challenge := make(chan [8]byte, 1)
+			packer.EXPECT().PackPathProbePacket(newConnID, gomock.Any(), protocol.ByteCount(protocol.MinInitialPacketSize)).DoAndReturn(func(_ protocol.ConnectionID, frames []ackhandler.Frame, _ protocol.ByteCount) (*packedPacket, error) {
+				Expect(frames).To(HaveLen(1))
+				Expect(frames[0].Frame).To(BeAssignableToTypeOf(&wire.PathChallengeFrame{}))
+				challenge <- frames[0].Frame.(*wire.PathChallengeFrame).Data
+				return &packedPacket{
+					buffer: getPacketBuffer(),
+					packetContents: &packetContents{
+						header: &wire.ExtendedHeader{PacketNumber: 1},
+						frames: frames,
+					},
+				}, nil
+			})
+			sph.EXPECT().SentPacket(gomock.Any())
+			tracer.EXPECT().SentPacket(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			newConn.EXPECT().Write(gomock.Any())
+			return challenge
+		}
+
+		It("migrates after the new path was validated", func() {
+			addConnectionID()
+			newRunner.EXPECT().Add(srcConnID, sess).Return(true)
+			sess.startMigration(migration)
+			Expect(migration.result).ToNot(Receive())
+			challenge := expectPathChallenge()
+			sess.maybeProbePath(time.Now())
+			var data [8]byte
+			Expect(challenge).To(Receive(&data))
+
+			sender := NewMockSender(mockCtrl)
+			sender.EXPECT().Close()
+			sess.sendQueue = sender
+			sessionRunner.EXPECT().AddResetToken(newResetToken, sess)
+			sessionRunner.EXPECT().RemoveHandler(sess)
+			newRunner.EXPECT().Add(srcConnID, sess)
+			newRunner.EXPECT().AddResetToken(newResetToken, sess)
+			sph.EXPECT().MigratedPath(nil, true)
+			packer.EXPECT().SetMaxPacketSize(getMaxPacketSize(newAddr))
+			Expect(sess.handleFrame(&wire.PathResponseFrame{Data: data}, protocol.Encryption1RTT, srcConnID)).To(Succeed())
+			var err error
+			Expect(migration.result).To(Receive(&err))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sess.LocalAddr()).To(Equal(newAddr))
+			Expect(sess.RemoteAddr()).To(Equal(newAddr))
+			Expect(sess.connIDManager.Get()).To(Equal(newConnID))
+			Expect(sess.runner).To(Equal(newRunner))
+			sess.sendQueue.Close()
+		})
+
+		It("aborts the migration if path validation times out", func() {
+			addConnectionID()
+			newRunner.EXPECT().Add(srcConnID, sess).Return(true)
+			sess.startMigration(migration)
+			newRunner.EXPECT().RemoveHandler(sess)
+			sess.maybeProbePath(sess.pathValidator.Deadline())
+			var err error
+			Expect(migration.result).To(Receive(&err))
+			Expect(err).To(MatchError("path validation timed out"))
+			Expect(sess.pathValidator).To(BeNil())
+			Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+			// the connection ID used for probing is retired
+			frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+			Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+		})
+
+		It("doesn't migrate if the server disabled active migration", func() {
+			addConnectionID()
+			sess.peerParams.DisableActiveMigration = true
+			sess.startMigration(migration)
+			Expect(migration.result).To(Receive(MatchError("the server disabled active migration")))
+			Expect(sess.pathValidator).To(BeNil())
+		})
+
+		It("doesn't migrate if there's no unused connection ID", func() {
+			sess.startMigration(migration)
+			Expect(migration.result).To(Receive(MatchError("no unused connection ID available")))
+			Expect(sess.pathValidator).To(BeNil())
+		})
+
+		It("doesn't migrate before the handshake is confirmed", func() {
+			addConnectionID()
+			sess.handshakeConfirmed = false
+			sess.startMigration(migration)
+			Expect(migration.result).To(Receive(MatchError("can't migrate before the handshake is confirmed")))
+		})
+
+		Context("using a new packet conn", func() {
+			var (
+				mockMultiplexer *MockMultiplexer
+				origMultiplexer multiplexer
+				pconn           *MockPacketConn
+			)
+
+			BeforeEach(func() {
+				mockMultiplexer = NewMockMultiplexer(mockCtrl)
+				origMultiplexer = getMultiplexer()
+				connMuxer = mockMultiplexer
+				pconn = NewMockPacketConn(mockCtrl)
+			})
+
+			AfterEach(func() {
+				connMuxer = origMultiplexer
+			})
+
+			It("doesn't listen on the packet conn if the migration is rejected", func() {
+				migration.runner = nil
+				migration.pconn = pconn
+				sess.startMigration(migration)
+				Expect(migration.result).To(Receive(MatchError("no unused connection ID available")))
+			})
+
+			It("aborts the migration if the packet conn can't be used", func() {
+				addConnectionID()
+				migration.runner = nil
+				migration.pconn = pconn
+				mockMultiplexer.EXPECT().AddConn(pconn, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("test error"))
+				sess.startMigration(migration)
+				Expect(migration.result).To(Receive(MatchError("test error")))
+				Expect(sess.pathValidator).To(BeNil())
+				Expect(sess.migration).To(BeNil())
+				frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+				Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			})
+		})
+
+		Context("migrating to the preferred address", func() {
+			remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1337}
+			var conn *MockSendConn
+
+			JustBeforeEach(func() {
+				conn = NewMockSendConn(mockCtrl)
+				conn.EXPECT().RemoteAddr().Return(remoteAddr).AnyTimes()
+				conn.EXPECT().SupportsGSO().AnyTimes()
+				sess.conn = conn
+				sess.peerParams.PreferredAddress = &wire.PreferredAddress{
+					IPv4:                newAddr.IP,
+					IPv4Port:            uint16(newAddr.Port),
+					IPv6:                net.IPv6zero,
+					ConnectionID:        newConnID,
+					StatelessResetToken: newResetToken,
+				}
+				sess.connIDManager.AddFromPreferredAddress(newConnID, newResetToken)
+			})
+
+			It("migrates, even if the server disabled active migration", func() {
+				sess.peerParams.DisableActiveMigration = true
+				conn.EXPECT().WithRemoteAddr(newAddr, nil).Return(newConn)
+				sess.migrateToPreferredAddress()
+				Expect(sess.pathValidator).ToNot(BeNil())
+				challenge := expectPathChallenge()
+				sess.maybeProbePath(time.Now())
+				var data [8]byte
+				Expect(challenge).To(Receive(&data))
+
+				sender := NewMockSender(mockCtrl)
+				sender.EXPECT().Close()
+				sess.sendQueue = sender
+				// the session stays registered with the same runner
+				sessionRunner.EXPECT().AddResetToken(newResetToken, sess)
+				sph.EXPECT().MigratedPath(nil, true)
+				packer.EXPECT().SetMaxPacketSize(getMaxPacketSize(newAddr))
+				Expect(sess.handleFrame(&wire.PathResponseFrame{Data: data}, protocol.Encryption1RTT, srcConnID)).To(Succeed())
+				Expect(sess.RemoteAddr()).To(Equal(newAddr))
+				Expect(sess.connIDManager.Get()).To(Equal(newConnID))
+				Expect(sess.runner).To(Equal(sessionRunner))
+				sess.sendQueue.Close()
+			})
+
+			It("keeps using the current path if path validation times out", func() {
+				conn.EXPECT().WithRemoteAddr(newAddr, nil).Return(newConn)
+				sess.migrateToPreferredAddress()
+				sess.maybeProbePath(sess.pathValidator.Deadline())
+				Expect(sess.pathValidator).To(BeNil())
+				Expect(sess.RemoteAddr()).To(Equal(remoteAddr))
+				Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+				frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+				Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			})
+
+			It("doesn't migrate if the server didn't send an address of the same address family", func() {
+				sess.peerParams.PreferredAddress.IPv4 = net.IPv4zero
+				sess.peerParams.PreferredAddress.IPv4Port = 0
+				sess.peerParams.PreferredAddress.IPv6 = net.ParseIP("2001:db8::1")
+				sess.peerParams.PreferredAddress.IPv6Port = 1234
+				sess.migrateToPreferredAddress()
+				Expect(sess.pathValidator).To(BeNil())
+				Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+				frames, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
+				Expect(frames).To(ContainElement(ackhandler.Frame{Frame: &wire.RetireConnectionIDFrame{SequenceNumber: 1}}))
+			})
+		})
+	})
+
 	Context("handling tokens", func() {
 		var mockTokenStore *MockTokenStore
 
@@ -2767,7 +3751,7 @@ var _ = Describe("Client Session", func() {
 			Eventually(errChan).Should(BeClosed())
 		})
 
-		It("uses the preferred_address connection ID", func() {
+		It("reserves the preferred_address connection ID", func() {
 			params := &wire.TransportParameters{
 				OriginalDestinationConnectionID: destConnID,
 				InitialSourceConnectionID:       destConnID,
@@ -2786,10 +3770,11 @@ var _ = Describe("Client Session", func() {
 			// make sure the connection ID is not retired
 			cf, _ := sess.framer.AppendControlFrames(nil, protocol.MaxByteCount)
 			Expect(cf).To(BeEmpty())
-			sessionRunner.EXPECT().AddResetToken(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, sess)
-			Expect(sess.connIDManager.Get()).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
-			// shut down
-			sessionRunner.EXPECT().RemoveResetToken(protocol.StatelessResetToken{16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1})
+			// The connection ID is reserved for migrating to the preferred address.
+			Expect(sess.connIDManager.Get()).To(Equal(destConnID))
+			connID, ok := sess.connIDManager.ReserveConnectionID()
+			Expect(ok).To(BeTrue())
+			Expect(connID).To(Equal(protocol.ConnectionID{1, 2, 3, 4}))
 			expectClose()
 		})
 
@@ -2872,6 +3857,56 @@ var _ = Describe("Client Session", func() {
 			sess.handleTransportParameters(params)
 			Eventually(errChan).Should(Receive(MatchError("TRANSPORT_PARAMETER_ERROR: expected original_destination_connection_id to equal deadbeef, is decafbad")))
 		})
+
+		It("errors if the server's chosen version doesn't match", func() {
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     0x1234,
+					AvailableVersions: []protocol.VersionNumber{0x1234},
+				},
+			}
+			expectClose()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+			Eventually(errChan).Should(Receive(MatchError(fmt.Sprintf("VERSION_NEGOTIATION_ERROR: expected chosen version to equal %s, is 0x1234", sess.version))))
+		})
+
+		It("detects version downgrades after version negotiation", func() {
+			sess.versionNegotiated = true
+			// We prefer draft-34, but the Version Negotiation packet made us use v1.
+			sess.config.Versions = []protocol.VersionNumber{protocol.VersionDraft34, protocol.Version1}
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				StatelessResetToken:             &protocol.StatelessResetToken{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     protocol.Version1,
+					AvailableVersions: []protocol.VersionNumber{protocol.Version1, protocol.VersionDraft34},
+				},
+			}
+			expectClose()
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+			Eventually(errChan).Should(Receive(MatchError(ContainSubstring("VERSION_NEGOTIATION_ERROR: version downgrade detected"))))
+		})
+
+		It("accepts the version_information after version negotiation", func() {
+			sess.versionNegotiated = true
+			params := &wire.TransportParameters{
+				OriginalDestinationConnectionID: destConnID,
+				InitialSourceConnectionID:       destConnID,
+				VersionInformation: &wire.VersionInformation{
+					ChosenVersion:     sess.version,
+					AvailableVersions: []protocol.VersionNumber{0x1234, sess.version},
+				},
+			}
+			tracer.EXPECT().ReceivedTransportParameters(params)
+			sess.handleTransportParameters(params)
+			Consistently(errChan).ShouldNot(Receive())
+		})
 	})
 
 	Context("handling potentially injected packets", func() {
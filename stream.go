@@ -25,6 +25,10 @@ var errDeadline net.Error = &deadlineError{}
 type streamSender interface {
 	queueControlFrame(wire.Frame)
 	onHasStreamData(protocol.StreamID)
+	// onQueuedRetransmission is called when a lost STREAM frame of the given length is queued for retransmission
+	onQueuedRetransmission(protocol.ByteCount)
+	// supportsResetStreamAt says if RESET_STREAM_AT frames can be sent
+	supportsResetStreamAt() bool
 	// must be called without holding the mutex that is acquired by closeForShutdown
 	onStreamCompleted(protocol.StreamID)
 }
@@ -55,7 +59,9 @@ type streamI interface {
 	closeForShutdown(error)
 	// for receiving
 	handleStreamFrame(*wire.StreamFrame) error
+	markReceived0RTT()
 	handleResetStreamFrame(*wire.ResetStreamFrame) error
+	handleResetStreamAtFrame(*wire.ResetStreamAtFrame) error
 	getWindowUpdate() protocol.ByteCount
 	// for sending
 	hasData() bool
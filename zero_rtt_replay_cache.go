@@ -0,0 +1,80 @@
+package quic
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+)
+
+// A ZeroRTTReplayCache is an in-memory ZeroRTTReplayProtector.
+// It allows every session ticket to be used for 0-RTT only once.
+// Session tickets older than the maximum ticket age are not accepted for 0-RTT,
+// which limits how long the IDs of used session tickets need to be remembered.
+// It only detects replays to this server, so it is not suitable if multiple servers share their session ticket keys.
+type ZeroRTTReplayCache struct {
+	maxTicketAge time.Duration
+
+	mutex sync.Mutex
+	// The IDs of used session tickets are stored in two generations.
+	// Every maxTicketAge, the older generation is dropped.
+	rotatedAt time.Time
+	current   map[string]struct{}
+	previous  map[string]struct{}
+}
+
+var _ ZeroRTTReplayProtector = &ZeroRTTReplayCache{}
+
+// NewZeroRTTReplayCache creates a new ZeroRTTReplayCache.
+// Session tickets older than maxTicketAge are rejected for 0-RTT.
+// If maxTicketAge is 0, it defaults to 24 hours.
+func NewZeroRTTReplayCache(maxTicketAge time.Duration) *ZeroRTTReplayCache {
+	if maxTicketAge == 0 {
+		maxTicketAge = protocol.DefaultMaxZeroRTTTicketAge
+	}
+	return &ZeroRTTReplayCache{
+		maxTicketAge: maxTicketAge,
+		rotatedAt:    time.Now(),
+		current:      make(map[string]struct{}),
+		previous:     make(map[string]struct{}),
+	}
+}
+
+// Accept0RTT accepts 0-RTT if the session ticket wasn't used for 0-RTT before, and is not too old.
+func (c *ZeroRTTReplayCache) Accept0RTT(ticketID []byte, issuedAt time.Time) bool {
+	now := time.Now()
+	if age := now.Sub(issuedAt); age < 0 || age > c.maxTicketAge {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.maybeRotate(now)
+	id := string(ticketID)
+	if _, ok := c.current[id]; ok {
+		return false
+	}
+	if _, ok := c.previous[id]; ok {
+		return false
+	}
+	c.current[id] = struct{}{}
+	return true
+}
+
+// maybeRotate drops the older generation of session ticket IDs.
+// Every ID is kept for at least maxTicketAge.
+// After that, the session ticket is too old to be accepted anyway.
+func (c *ZeroRTTReplayCache) maybeRotate(now time.Time) {
+	sinceRotation := now.Sub(c.rotatedAt)
+	if sinceRotation < c.maxTicketAge {
+		return
+	}
+	if sinceRotation < 2*c.maxTicketAge {
+		c.previous = c.current
+	} else {
+		c.previous = make(map[string]struct{})
+	}
+	c.current = make(map[string]struct{})
+	c.rotatedAt = now
+}
@@ -0,0 +1,58 @@
+package quic
+
+import (
+	"time"
+
+	"github.com/lucas-clemente/quic-go/internal/protocol"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("0-RTT Replay Cache", func() {
+	It("uses the default maximum ticket age", func() {
+		Expect(NewZeroRTTReplayCache(0).maxTicketAge).To(Equal(protocol.DefaultMaxZeroRTTTicketAge))
+	})
+
+	It("accepts every session ticket only once", func() {
+		c := NewZeroRTTReplayCache(time.Hour)
+		Expect(c.Accept0RTT([]byte("foo"), time.Now())).To(BeTrue())
+		Expect(c.Accept0RTT([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(c.Accept0RTT([]byte("foo"), time.Now())).To(BeFalse())
+	})
+
+	It("rejects session tickets that are too old", func() {
+		c := NewZeroRTTReplayCache(time.Hour)
+		Expect(c.Accept0RTT([]byte("foo"), time.Now().Add(-time.Hour-time.Second))).To(BeFalse())
+		Expect(c.Accept0RTT([]byte("foo"), time.Now().Add(-time.Hour+time.Second))).To(BeTrue())
+	})
+
+	It("rejects session tickets issued in the future", func() {
+		c := NewZeroRTTReplayCache(time.Hour)
+		Expect(c.Accept0RTT([]byte("foo"), time.Now().Add(time.Second))).To(BeFalse())
+	})
+
+	It("remembers session tickets for the maximum ticket age", func() {
+		c := NewZeroRTTReplayCache(time.Hour)
+		Expect(c.Accept0RTT([]byte("foo"), time.Now())).To(BeTrue())
+		c.rotatedAt = c.rotatedAt.Add(-time.Hour)
+		Expect(c.Accept0RTT([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(c.previous).To(HaveKey("foo"))
+		Expect(c.current).To(HaveKey("bar"))
+		Expect(c.Accept0RTT([]byte("foo"), time.Now())).To(BeFalse())
+		// after the next rotation, the first ticket is forgotten
+		c.rotatedAt = c.rotatedAt.Add(-time.Hour)
+		Expect(c.Accept0RTT([]byte("foo"), time.Now())).To(BeTrue())
+		Expect(c.previous).To(HaveKey("bar"))
+		Expect(c.Accept0RTT([]byte("bar"), time.Now())).To(BeFalse())
+	})
+
+	It("forgets all session tickets if it wasn't used for twice the maximum ticket age", func() {
+		c := NewZeroRTTReplayCache(time.Hour)
+		Expect(c.Accept0RTT([]byte("foo"), time.Now())).To(BeTrue())
+		c.rotatedAt = c.rotatedAt.Add(-2 * time.Hour)
+		Expect(c.Accept0RTT([]byte("bar"), time.Now())).To(BeTrue())
+		Expect(c.previous).To(BeEmpty())
+		Expect(c.current).To(HaveLen(1))
+	})
+})